@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// AgentVersion is reported in the hello, set at build time with
+// -ldflags "-X github.com/ThomasCardin/gobservability/cmd/agent/grpc.AgentVersion=..."
+var AgentVersion = "1.0.0"
+
+// probeFeatures checks what the agent can serve on this node, run once at startup
+func (c *StreamingGRPCClient) probeFeatures() []types.AgentFeature {
+	flamegraphErr := c.flamegraphGen.Probe()
+	kernelStacksErr := c.flamegraphGen.ProbeKernelStacks()
+	if flamegraphErr != nil {
+		kernelStacksErr = fmt.Errorf("flamegraphs unavailable")
+	}
+	logsErr := c.logTailer.Probe()
+	logPatternsErr := logsErr
+	if logsErr != nil {
+		logPatternsErr = fmt.Errorf("container logs unavailable")
+	}
+
+	probes := []struct {
+		name string
+		err  error
+	}{
+		{types.FeatureFlamegraph, flamegraphErr},
+		{types.FeatureKernelStacks, kernelStacksErr},
+		{types.FeatureLogs, logsErr},
+		{types.FeatureLogPatterns, logPatternsErr},
+		{types.FeaturePodDetails, nil},
+		{types.FeatureAgentConfig, nil},
+		{types.FeatureCollect, nil},
+	}
+
+	features := make([]types.AgentFeature, 0, len(probes))
+	for _, probe := range probes {
+		feature := types.AgentFeature{Name: probe.name, Available: probe.err == nil}
+		if probe.err != nil {
+			feature.Reason = probe.err.Error()
+			slog.Warn("feature unavailable", "component", "grpc", "feature", probe.name, "reason", feature.Reason)
+		}
+		features = append(features, feature)
+	}
+	return features
+}
+
+// unavailable returns why the agent cannot serve a feature, nil if it can
+func (c *StreamingGRPCClient) unavailable(name string) error {
+	for _, feature := range c.features {
+		if feature.Name == name && !feature.Available {
+			return fmt.Errorf("%s unavailable on node %s: %s", name, c.nodeName, feature.Reason)
+		}
+	}
+	return nil
+}
@@ -78,7 +78,7 @@ func (c *GRPCClient) Close() error {
 
 func (c *GRPCClient) GenerateFlamegraph(nodeName, podName string, duration int32) ([]byte, error) {
 	pid := c.flamegraphGen.GetPIDForPod(podName, c.currentPods)
-	data, err := c.flamegraphGen.GenerateFlamegraph(nodeName, podName, duration, pid)
+	data, err := c.flamegraphGen.GenerateFlamegraph(context.Background(), nodeName, podName, duration, pid)
 	if err != nil {
 		return nil, errors.New("failed to generate flamegraph")
 	}
@@ -0,0 +1,251 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // Registers the gzip compressor
+	"google.golang.org/grpc/keepalive"
+)
+
+// ConnectionOptions configures how the agent detects broken connections and reconnects
+type ConnectionOptions struct {
+	KeepaliveTime    time.Duration // Idle time after which the server is pinged
+	KeepaliveTimeout time.Duration // Time waited for a ping ack before closing the connection
+	MinBackoff       time.Duration // First delay between reconnection rounds
+	MaxBackoff       time.Duration
+	TokenFile        string // Service account token sent to the server, not sent if empty
+	Compression      string // gRPC compressor of the stream, "gzip" or empty for none
+	Delta            bool   // Send stats as deltas against the previous payload to servers supporting them
+	SnapshotEvery    int    // Payloads between two full snapshots in delta mode
+}
+
+// DefaultConnectionOptions are the options of agents started without flags
+var DefaultConnectionOptions = ConnectionOptions{
+	KeepaliveTime:    30 * time.Second,
+	KeepaliveTimeout: 10 * time.Second,
+	MinBackoff:       1 * time.Second,
+	MaxBackoff:       30 * time.Second,
+	SnapshotEvery:    60,
+}
+
+// connectionState tracks the connection to the server, reported with every payload
+type connectionState struct {
+	everConnected  bool
+	reconnects     uint64
+	lastError      string
+	lastErrorAt    time.Time
+	connectedSince time.Time
+	disconnectedAt time.Time // Zero while connected
+	lastOutage     time.Duration
+	disconnected   time.Duration // Sum of the outages, the current one excluded
+}
+
+// dial creates the connection to an endpoint, the previous connection is closed
+func (c *StreamingGRPCClient) dial(index int) error {
+	c.closeConn()
+
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(c.creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.options.KeepaliveTime,
+			Timeout:             c.options.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	}
+	if c.options.TokenFile != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(tokenCredentials{file: c.options.TokenFile}))
+	}
+	if c.options.Compression != "" {
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(grpc.UseCompressor(c.options.Compression)))
+	}
+
+	conn, err := grpc.NewClient(c.endpoints[index], dialOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to gRPC server %s: %v", c.endpoints[index], err)
+	}
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+	return nil
+}
+
+// closeConn closes the connection in use, if any
+func (c *StreamingGRPCClient) closeConn() {
+	c.connMu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.connMu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// streamContext returns the context of the current stream, done when the stream is replaced
+func (c *StreamingGRPCClient) streamContext() context.Context {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.ctx
+}
+
+// connect establishes the stream on the first reachable endpoint, starting with the endpoint in use
+func (c *StreamingGRPCClient) connect() error {
+	var errs []error
+	for i := range c.endpoints {
+		index := (c.endpoint + i) % len(c.endpoints)
+
+		err := c.dial(index)
+		if err == nil {
+			err = c.establishStream()
+		}
+		if err != nil {
+			slog.Warn("connection to server failed", "component", "grpc", "endpoint", c.endpoints[index], "error", err)
+			c.recordError(err)
+			// A fresh connection is dialed on the next attempt instead of waiting for the
+			// gRPC connection backoff
+			c.closeConn()
+			errs = append(errs, err)
+			continue
+		}
+
+		if index != c.endpoint {
+			slog.Info("failed over to server endpoint", "component", "grpc", "from", c.endpoints[c.endpoint], "to", c.endpoints[index])
+			c.stateMu.Lock()
+			c.endpoint = index
+			c.stateMu.Unlock()
+		}
+		c.markConnected()
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// reconnect re-establishes the stream, every endpoint is tried in each round and rounds are
+// separated by an exponential backoff with jitter so that agents do not reconnect all at once
+// after a server restart. The backoff is only reset once the server acknowledged the stream, so
+// that an agent whose hello is rejected does not reconnect in a loop
+func (c *StreamingGRPCClient) reconnect() {
+	slog.Info("attempting to reconnect", "component", "grpc")
+
+	// The requests of the previous stream are canceled, the next stream gets a new context
+	c.connMu.Lock()
+	c.cancel()
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.connMu.Unlock()
+
+	if !c.acknowledged {
+		c.backoffWait()
+	}
+	c.acknowledged = false
+
+	for !c.stopping() {
+		if err := c.connect(); err == nil {
+			go c.handleIncomingMessages()
+			return
+		}
+		c.backoffWait()
+	}
+}
+
+// backoffWait waits before the next connection round and doubles the delay
+func (c *StreamingGRPCClient) backoffWait() {
+	wait := jitter(c.backoff)
+	slog.Error("reconnection failed, retrying", "component", "grpc", "endpoints", len(c.endpoints), "retry_delay", wait)
+	select {
+	case <-time.After(wait):
+	case <-c.lifetime.Done():
+	}
+
+	c.backoff = min(c.backoff*2, c.options.MaxBackoff)
+}
+
+// acknowledge records that the server accepted the stream
+func (c *StreamingGRPCClient) acknowledge() {
+	c.acknowledged = true
+	c.backoff = c.options.MinBackoff
+}
+
+// jitter returns a random delay between half and all of delay
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + rand.N(delay/2)
+}
+
+// markConnected records that the stream is established
+func (c *StreamingGRPCClient) markConnected() {
+	c.stateMu.Lock()
+	now := time.Now()
+	if !c.state.disconnectedAt.IsZero() {
+		c.state.lastOutage = now.Sub(c.state.disconnectedAt)
+		c.state.disconnected += c.state.lastOutage
+		c.state.disconnectedAt = time.Time{}
+	}
+	if c.state.everConnected {
+		c.state.reconnects++
+	}
+	c.state.everConnected = true
+	c.state.connectedSince = now
+	c.stateMu.Unlock()
+
+	// The server decodes deltas per stream, the first payload of a stream is a snapshot
+	c.serverProtocol.Store(0)
+	if c.delta != nil {
+		c.delta.Reset()
+	}
+	c.connected.Store(true)
+	c.startReplay()
+}
+
+// markDisconnected records that the stream is broken
+func (c *StreamingGRPCClient) markDisconnected(err error) {
+	c.connected.Store(false)
+
+	c.stateMu.Lock()
+	if c.state.disconnectedAt.IsZero() {
+		c.state.disconnectedAt = time.Now()
+	}
+	c.stateMu.Unlock()
+
+	c.recordError(err)
+}
+
+// recordError records the last connection error
+func (c *StreamingGRPCClient) recordError(err error) {
+	if err == nil {
+		return
+	}
+	c.stateMu.Lock()
+	c.state.lastError = err.Error()
+	c.state.lastErrorAt = time.Now()
+	c.stateMu.Unlock()
+}
+
+// ConnectionStatus returns the state of the connection to the server
+func (c *StreamingGRPCClient) ConnectionStatus() types.ConnectionStatus {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	status := types.ConnectionStatus{
+		Endpoint:       c.endpoints[c.endpoint],
+		Connected:      c.connected.Load(),
+		Reconnects:     c.state.reconnects,
+		LastError:      c.state.lastError,
+		LastErrorAt:    c.state.lastErrorAt,
+		ConnectedSince: c.state.connectedSince,
+		LastOutageMs:   c.state.lastOutage.Milliseconds(),
+		DisconnectedMs: c.state.disconnected.Milliseconds(),
+	}
+	if !c.state.disconnectedAt.IsZero() {
+		status.DisconnectedMs += time.Since(c.state.disconnectedAt).Milliseconds()
+	}
+	return status
+}
@@ -7,53 +7,112 @@ import (
 	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ThomasCardin/gobservability/cmd/agent/internal"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/buffer"
 	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/flamegraph"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/logs"
 	pb "github.com/ThomasCardin/gobservability/proto"
 	sharedGrpc "github.com/ThomasCardin/gobservability/shared/grpc"
 	"github.com/ThomasCardin/gobservability/shared/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // StreamingGRPCClient handles bidirectional streaming with the server
 type StreamingGRPCClient struct {
-	serverAddr    string
-	nodeName      string
-	conn          *grpc.ClientConn
-	stream        pb.NodeService_AgentStreamClient
-	flamegraphGen *flamegraph.Generator
-	currentPods   []*types.Pod
-	mu            sync.RWMutex
-	ctx           context.Context
-	cancel        context.CancelFunc
-}
-
-// NewStreamingGRPCClient creates a new streaming gRPC client
-func NewStreamingGRPCClient(serverAddr, nodeName, devMode string) (*StreamingGRPCClient, error) {
-	conn, err := grpc.NewClient(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return nil, errors.New("failed to connect to gRPC server")
+	endpoints      []string
+	endpoint       int // Index of the endpoint in use
+	creds          credentials.TransportCredentials
+	options        ConnectionOptions
+	nodeName       string
+	devMode        string
+	conn           *grpc.ClientConn
+	stream         pb.NodeService_AgentStreamClient
+	flamegraphGen  *flamegraph.Generator
+	logTailer      *logs.Tailer
+	logPatterns    *logs.PatternMatcher
+	logFollows     map[string]context.CancelFunc // requestID -> cancel of a running log follow
+	currentPods    []*types.Pod
+	configHandler  ConfigHandler
+	collectHandler func()                   // Runs a collection requested by the server
+	pendingConfig  *types.AgentConfig       // Config received before the handler was set
+	buffer         *buffer.Buffer           // Payloads kept while the server is unreachable, nil to drop them
+	features       []types.AgentFeature     // Probed at startup and reported in every hello
+	delta          *sharedGrpc.DeltaEncoder // nil unless delta payloads are enabled
+	serverProtocol atomic.Uint32            // Protocol version acknowledged by the server of the current stream
+	connected      atomic.Bool
+	replaying      atomic.Bool
+	payloads       PayloadStats
+	payloadsMu     sync.Mutex
+	state          connectionState
+	stateMu        sync.Mutex
+	backoff        time.Duration // Delay before the next connection round, used by the receive loop only
+	acknowledged   bool          // The server acknowledged the current stream
+	mu             sync.RWMutex
+	sendMu         sync.Mutex      // gRPC streams do not support concurrent Send calls
+	ctx            context.Context // Context of the current stream, canceled on reconnection
+	cancel         context.CancelFunc
+	connMu         sync.Mutex      // Guards conn, ctx and cancel, replaced by the receive loop on reconnection
+	lifetime       context.Context // Done when the agent stops, ends reconnections and perf recordings
+	closing        atomic.Bool     // Shutdown started, the stream is not re-established
+	handlers       sync.WaitGroup  // Running flamegraph and pod details requests
+	streamEnded    chan struct{}   // Signaled when the receive loop exits during shutdown
+}
+
+// ConfigHandler applies a config pushed by the server, the returned error is sent back in the ack
+type ConfigHandler func(config types.AgentConfig) error
+
+// NewStreamingGRPCClient creates a new streaming gRPC client, plaintext if creds is nil
+// Endpoints are tried in order, the client fails over to the next one when the server in use is
+// unreachable. Stats sent while disconnected are kept in buf and replayed once reconnected
+// The client stops reconnecting and aborts running requests when lifetime is done
+func NewStreamingGRPCClient(lifetime context.Context, endpoints []string, nodeName, devMode string, creds credentials.TransportCredentials, buf *buffer.Buffer, options ConnectionOptions) (*StreamingGRPCClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no gRPC server endpoint")
+	}
+	if creds == nil {
+		creds = insecure.NewCredentials()
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	logTailer := logs.NewTailer(devMode)
+
 	client := &StreamingGRPCClient{
-		serverAddr:    serverAddr,
+		endpoints:     endpoints,
+		creds:         creds,
+		options:       options,
+		backoff:       options.MinBackoff,
 		nodeName:      nodeName,
-		conn:          conn,
+		devMode:       devMode,
 		flamegraphGen: flamegraph.NewGenerator(devMode),
+		logTailer:     logTailer,
+		logPatterns:   logs.NewPatternMatcher(logTailer),
+		logFollows:    make(map[string]context.CancelFunc),
 		currentPods:   make([]*types.Pod, 0),
+		buffer:        buf,
 		ctx:           ctx,
 		cancel:        cancel,
+		lifetime:      lifetime,
+		streamEnded:   make(chan struct{}, 1),
+	}
+	client.features = client.probeFeatures()
+	if options.Delta {
+		client.delta = sharedGrpc.NewDeltaEncoder(options.SnapshotEvery)
 	}
 
-	// Establish streaming connection
-	if err := client.establishStream(); err != nil {
-		conn.Close()
-		return nil, err
+	// The agent starts even if no server is reachable, stats are buffered until it is
+	if err := client.connect(); err != nil {
+		slog.Error("no server endpoint reachable, retrying in the background", "component", "grpc", "error", err)
+		client.markDisconnected(nil)
+		go client.reconnect()
+		return client, nil
 	}
 
 	// Start background goroutine to handle incoming messages
@@ -64,30 +123,37 @@ func NewStreamingGRPCClient(serverAddr, nodeName, devMode string) (*StreamingGRP
 
 // establishStream creates the bidirectional stream and sends hello
 func (c *StreamingGRPCClient) establishStream() error {
-	client := pb.NewNodeServiceClient(c.conn)
+	c.connMu.Lock()
+	conn, ctx := c.conn, c.ctx
+	c.connMu.Unlock()
+	client := pb.NewNodeServiceClient(conn)
 
-	stream, err := client.AgentStream(c.ctx)
+	stream, err := client.AgentStream(ctx)
 	if err != nil {
-		return errors.New("failed to create stream")
+		return fmt.Errorf("failed to create stream: %v", err)
 	}
 
+	c.sendMu.Lock()
 	c.stream = stream
+	c.sendMu.Unlock()
 
 	// Send hello message
 	hello := &pb.AgentMessage{
 		Message: &pb.AgentMessage_Hello{
 			Hello: &pb.AgentHello{
-				NodeName:     c.nodeName,
-				AgentVersion: "1.0.0",
+				NodeName:        c.nodeName,
+				AgentVersion:    AgentVersion,
+				ProtocolVersion: types.ProtocolVersion,
+				Features:        sharedGrpc.ConvertToGRPCAgentFeatures(c.features),
 			},
 		},
 	}
 
-	if err := c.stream.Send(hello); err != nil {
-		return errors.New("failed to send hello")
+	if err := c.send(hello); err != nil {
+		return fmt.Errorf("failed to send hello: %v", err)
 	}
 
-	slog.Info("node connected to server", "component", "grpc", "node", c.nodeName)
+	slog.Info("node connected to server", "component", "grpc", "node", c.nodeName, "endpoint", conn.Target())
 	return nil
 }
 
@@ -95,22 +161,59 @@ func (c *StreamingGRPCClient) establishStream() error {
 func (c *StreamingGRPCClient) handleIncomingMessages() {
 	for {
 		msg, err := c.stream.Recv()
+		if err != nil && c.stopping() {
+			c.markDisconnected(nil)
+			select {
+			case c.streamEnded <- struct{}{}:
+			default:
+			}
+			return
+		}
 		if err == io.EOF {
 			slog.Info("server closed the stream", "component", "grpc")
+			c.markDisconnected(errors.New("server closed the stream"))
 			c.reconnect()
 			return
 		}
 		if err != nil {
 			slog.Error("receiving message failed", "component", "grpc", "error", err)
+			c.markDisconnected(err)
 			c.reconnect()
 			return
 		}
 
 		switch m := msg.Message.(type) {
 		case *pb.ServerMessage_Ack:
-			slog.Info("received ack from server", "component", "grpc", "message", m.Ack.Message)
+			slog.Info("received ack from server", "component", "grpc", "message", m.Ack.Message, "protocol_version", m.Ack.ProtocolVersion)
+			c.serverProtocol.Store(m.Ack.ProtocolVersion)
+			c.acknowledge()
+		case *pb.ServerMessage_Resync:
+			slog.Warn("server requested a snapshot", "component", "grpc", "reason", m.Resync.Reason)
+			if c.delta != nil {
+				c.delta.Reset()
+			}
 		case *pb.ServerMessage_FlamegraphRequest:
-			go c.handleFlamegraphRequest(m.FlamegraphRequest)
+			c.handlers.Add(1)
+			go func() {
+				defer c.handlers.Done()
+				c.handleFlamegraphRequest(m.FlamegraphRequest)
+			}()
+		case *pb.ServerMessage_LogRequest:
+			go c.handleLogRequest(m.LogRequest)
+		case *pb.ServerMessage_LogPatternRules:
+			c.logPatterns.SetRules(sharedGrpc.ConvertLogPatternRules(m.LogPatternRules))
+		case *pb.ServerMessage_PodDetailsRequest:
+			c.handlers.Add(1)
+			go func() {
+				defer c.handlers.Done()
+				c.handlePodDetailsRequest(m.PodDetailsRequest)
+			}()
+		case *pb.ServerMessage_AgentConfig:
+			c.handleAgentConfig(sharedGrpc.ConvertAgentConfig(m.AgentConfig))
+		case *pb.ServerMessage_Ping:
+			c.handlePing(m.Ping)
+		case *pb.ServerMessage_Collect:
+			c.handleCollect(m.Collect)
 		}
 	}
 }
@@ -119,6 +222,21 @@ func (c *StreamingGRPCClient) handleIncomingMessages() {
 func (c *StreamingGRPCClient) handleFlamegraphRequest(req *pb.FlamegraphRequest) {
 	slog.Info("received flamegraph request", "component", "flamegraph", "pod", req.PodName, "duration", req.Duration)
 
+	if err := c.unavailable(types.FeatureFlamegraph); err != nil {
+		response := &pb.AgentMessage{
+			Message: &pb.AgentMessage_FlamegraphResponse{
+				FlamegraphResponse: &pb.FlamegraphResponse{
+					Error:     fmt.Sprintf("[FLAMEGRAPH] error: %v", err),
+					RequestId: req.RequestId,
+				},
+			},
+		}
+		if err := c.send(response); err != nil {
+			slog.Error("failed to send error response", "component", "flamegraph", "error", err)
+		}
+		return
+	}
+
 	// Get current pods
 	c.mu.RLock()
 	pods := c.currentPods
@@ -138,14 +256,14 @@ func (c *StreamingGRPCClient) handleFlamegraphRequest(req *pb.FlamegraphRequest)
 				},
 			},
 		}
-		if err := c.stream.Send(response); err != nil {
+		if err := c.send(response); err != nil {
 			slog.Error("failed to send error response", "component", "flamegraph", "error", err)
 		}
 		return
 	}
 
 	slog.Info("generating flamegraph", "component", "flamegraph", "pid", pid, "duration", req.Duration)
-	data, err := c.flamegraphGen.GenerateFlamegraph(req.NodeName, req.PodName, req.Duration, pid)
+	data, err := c.flamegraphGen.GenerateFlamegraph(c.lifetime, req.NodeName, req.PodName, req.Duration, pid)
 
 	if err != nil {
 		slog.Error("error generating flamegraph", "component", "flamegraph", "error", err)
@@ -170,75 +288,453 @@ func (c *StreamingGRPCClient) handleFlamegraphRequest(req *pb.FlamegraphRequest)
 
 	// Send response
 	slog.Info("sending flamegraph response", "component", "flamegraph", "request_id", req.RequestId)
-	if err := c.stream.Send(response); err != nil {
+	if err := c.send(response); err != nil {
 		slog.Error("failed to send flamegraph response", "component", "flamegraph", "error", err)
 	} else {
 		slog.Info("successfully sent flamegraph response", "component", "flamegraph", "request_id", req.RequestId)
 	}
 }
 
-// Send sends metrics via the streaming connection
+// handleLogRequest tails or follows the container logs of a pod
+func (c *StreamingGRPCClient) handleLogRequest(req *pb.LogRequest) {
+	if req.Stop {
+		c.mu.Lock()
+		if cancel, found := c.logFollows[req.RequestId]; found {
+			cancel()
+			delete(c.logFollows, req.RequestId)
+		}
+		c.mu.Unlock()
+		slog.Info("stopped log follow", "component", "logs", "request_id", req.RequestId)
+		return
+	}
+
+	slog.Info("received log request", "component", "logs", "pod", req.PodName, "container", req.Container,
+		"tail_lines", req.TailLines, "follow", req.Follow)
+
+	if err := c.unavailable(types.FeatureLogs); err != nil {
+		c.sendLogChunk(req.RequestId, nil, true, fmt.Sprintf("[LOGS] error: %v", err))
+		return
+	}
+
+	// Find the pod in the last collected pods to get its namespace and UID
+	c.mu.RLock()
+	var pod *types.Pod
+	for _, p := range c.currentPods {
+		if p.Name == req.PodName {
+			pod = p
+			break
+		}
+	}
+	c.mu.RUnlock()
+
+	if pod == nil {
+		c.sendLogChunk(req.RequestId, nil, true, fmt.Sprintf("[LOGS] error: pod %s not found on node", req.PodName))
+		return
+	}
+
+	lines, err := c.logTailer.Tail(pod, req.Container, int(req.TailLines))
+	if err != nil {
+		slog.Error("error tailing logs", "component", "logs", "pod", req.PodName, "error", err)
+		c.sendLogChunk(req.RequestId, nil, true, err.Error())
+		return
+	}
+
+	if !req.Follow {
+		c.sendLogChunk(req.RequestId, lines, true, "")
+		return
+	}
+	if len(lines) > 0 {
+		c.sendLogChunk(req.RequestId, lines, false, "")
+	}
+
+	ctx, cancel := context.WithCancel(c.streamContext())
+	c.mu.Lock()
+	c.logFollows[req.RequestId] = cancel
+	c.mu.Unlock()
+
+	defer func() {
+		cancel()
+		c.mu.Lock()
+		delete(c.logFollows, req.RequestId)
+		c.mu.Unlock()
+	}()
+
+	err = c.logTailer.Follow(ctx, pod, req.Container, func(lines []types.LogLine) {
+		if err := c.sendLogChunk(req.RequestId, lines, false, ""); err != nil {
+			// Server is gone, no one is listening anymore
+			cancel()
+		}
+	})
+
+	errMsg := ""
+	if err != nil {
+		slog.Error("error following logs", "component", "logs", "pod", req.PodName, "error", err)
+		errMsg = err.Error()
+	}
+	c.sendLogChunk(req.RequestId, nil, true, errMsg)
+}
+
+// handlePodDetailsRequest reads the detail tier of a pod process
+func (c *StreamingGRPCClient) handlePodDetailsRequest(req *pb.PodDetailsRequest) {
+	response := &pb.PodDetailsResponse{RequestId: req.RequestId}
+
+	c.mu.RLock()
+	pid := 0
+	for _, p := range c.currentPods {
+		if p.Name == req.PodName && p.PID > 0 {
+			pid = p.PID
+			break
+		}
+	}
+	c.mu.RUnlock()
+
+	if pid <= 0 {
+		response.Error = fmt.Sprintf("[DETAILS] error: no valid PID found for pod %s", req.PodName)
+	} else if details, err := internal.CollectPidDetails(c.devMode, pid); err != nil {
+		slog.Error("error reading pod details", "component", "details", "pod", req.PodName, "pid", pid, "error", err)
+		response.Error = err.Error()
+	} else {
+		response.Details = sharedGrpc.ConvertToGRPCPidDetails(*details)
+	}
+
+	msg := &pb.AgentMessage{
+		Message: &pb.AgentMessage_PodDetailsResponse{
+			PodDetailsResponse: response,
+		},
+	}
+	if err := c.send(msg); err != nil {
+		slog.Error("failed to send pod details response", "component", "details", "request_id", req.RequestId, "error", err)
+	}
+}
+
+// SetConfigHandler sets the handler of configs pushed by the server, a config received before
+// is applied right away
+func (c *StreamingGRPCClient) SetConfigHandler(handler ConfigHandler) {
+	c.mu.Lock()
+	c.configHandler = handler
+	pending := c.pendingConfig
+	c.pendingConfig = nil
+	c.mu.Unlock()
+
+	if pending != nil {
+		c.handleAgentConfig(*pending)
+	}
+}
+
+// SetCollectHandler sets the function running a collection requested by the server
+func (c *StreamingGRPCClient) SetCollectHandler(handler func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collectHandler = handler
+}
+
+// handleCollect runs a collection requested by the server, the payload is sent as usual
+func (c *StreamingGRPCClient) handleCollect(req *pb.CollectRequest) {
+	c.mu.RLock()
+	handler := c.collectHandler
+	c.mu.RUnlock()
+
+	slog.Info("server requested a collection", "component", "collector", "reason", req.Reason)
+	if handler != nil {
+		handler()
+	}
+}
+
+// handlePing answers a ping of the server right away, the server measures the round trip and
+// the skew of the agent clock
+func (c *StreamingGRPCClient) handlePing(ping *pb.Ping) {
+	pong := &pb.AgentMessage{
+		Message: &pb.AgentMessage_Pong{
+			Pong: &pb.Pong{Id: ping.Id, AgentTime: timestamppb.Now()},
+		},
+	}
+	if err := c.send(pong); err != nil {
+		slog.Error("failed to send pong", "component", "grpc", "error", err)
+	}
+}
+
+// handleAgentConfig applies a config pushed by the server and acknowledges its version
+func (c *StreamingGRPCClient) handleAgentConfig(config types.AgentConfig) {
+	c.mu.Lock()
+	handler := c.configHandler
+	if handler == nil {
+		c.pendingConfig = &config
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+
+	ack := &pb.AgentConfigAck{Version: config.Version, Applied: true}
+	if err := handler(config); err != nil {
+		slog.Error("rejected config pushed by the server", "component", "config", "version", config.Version, "error", err)
+		ack.Applied = false
+		ack.Error = err.Error()
+	} else {
+		slog.Info("applied config pushed by the server", "component", "config", "version", config.Version)
+	}
+
+	msg := &pb.AgentMessage{
+		Message: &pb.AgentMessage_AgentConfigAck{
+			AgentConfigAck: ack,
+		},
+	}
+	if err := c.send(msg); err != nil {
+		slog.Error("failed to send config ack", "component", "config", "version", config.Version, "error", err)
+	}
+}
+
+// sendLogChunk sends a chunk of log lines for a log request
+func (c *StreamingGRPCClient) sendLogChunk(requestID string, lines []types.LogLine, eof bool, errMsg string) error {
+	chunk := &pb.AgentMessage{
+		Message: &pb.AgentMessage_LogChunk{
+			LogChunk: &pb.LogChunk{
+				RequestId: requestID,
+				Lines:     sharedGrpc.ConvertToGRPCLogLines(lines),
+				Eof:       eof,
+				Error:     errMsg,
+			},
+		},
+	}
+
+	if err := c.send(chunk); err != nil {
+		slog.Error("failed to send log chunk", "component", "logs", "request_id", requestID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// errNoStream is returned by send before a stream was ever established
+var errNoStream = errors.New("no stream to the server")
+
+// send serializes writes on the stream
+func (c *StreamingGRPCClient) send(msg *pb.AgentMessage) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	// The agent starts without stream when no server is reachable
+	if c.stream == nil {
+		return errNoStream
+	}
+	return c.stream.Send(msg)
+}
+
+// PayloadStats counts the stats payloads sent on the stream, replayed payloads included
+type PayloadStats struct {
+	Sent      uint64
+	Bytes     uint64 // Encoded size of the payloads, before compression
+	LastBytes int
+}
+
+// sendStats sends a stats message and counts its size
+func (c *StreamingGRPCClient) sendStats(msg *pb.AgentMessage) error {
+	if err := c.send(msg); err != nil {
+		return err
+	}
+
+	size := proto.Size(msg)
+	c.payloadsMu.Lock()
+	c.payloads.Sent++
+	c.payloads.Bytes += uint64(size)
+	c.payloads.LastBytes = size
+	c.payloadsMu.Unlock()
+	return nil
+}
+
+// PayloadStats returns the counters of the stats payloads sent
+func (c *StreamingGRPCClient) PayloadStats() PayloadStats {
+	c.payloadsMu.Lock()
+	defer c.payloadsMu.Unlock()
+	return c.payloads
+}
+
+// LogPatterns returns the matcher counting log pattern rule matches pushed by the server
+func (c *StreamingGRPCClient) LogPatterns() *logs.PatternMatcher {
+	return c.logPatterns
+}
+
+// Send sends metrics via the streaming connection, they are buffered while the server is
+// unreachable or older payloads are being replayed so that the server receives them in order
 func (c *StreamingGRPCClient) Send(payload *types.NodeStatsPayload) error {
 	// Update cached pods
 	c.mu.Lock()
 	c.currentPods = payload.Metrics.Pods
 	c.mu.Unlock()
 
+	if c.buffer != nil {
+		status := c.buffer.Status()
+		payload.Metrics.Buffer = &status
+	}
+	connection := c.ConnectionStatus()
+	payload.Metrics.Connection = &connection
+
 	// Convert and send stats
+	request := &pb.NodeStatsRequest{
+		NodeName:  payload.NodeName,
+		Timestamp: timestamppb.New(payload.Timestamp),
+		Metrics:   sharedGrpc.ConvertToGRPCMetrics(payload.Metrics),
+		Samples:   sharedGrpc.ConvertToGRPCNodeSamples(payload.Samples),
+
+		CollectionMs: payload.CollectionMs,
+	}
+
+	if c.buffer != nil && (!c.connected.Load() || c.buffer.Len() > 0) {
+		c.bufferStats(request)
+		c.startReplay()
+		return nil
+	}
+
 	stats := &pb.AgentMessage{
 		Message: &pb.AgentMessage_Stats{
-			Stats: &pb.NodeStatsRequest{
-				NodeName:  payload.NodeName,
-				Timestamp: timestamppb.New(payload.Timestamp),
-				Metrics:   sharedGrpc.ConvertToGRPCMetrics(payload.Metrics),
-			},
+			Stats: c.liveStats(request),
 		},
 	}
 
-	if err := c.stream.Send(stats); err != nil {
-		return errors.New("failed to send stats")
+	if err := c.sendStats(stats); err != nil {
+		if c.buffer == nil {
+			return errors.New("failed to send stats")
+		}
+		// The receive loop reconnects, the payload is replayed afterwards
+		c.markDisconnected(err)
+		c.bufferStats(request)
 	}
 
 	return nil
 }
 
-// reconnect attempts to re-establish the connection
-func (c *StreamingGRPCClient) reconnect() {
-	slog.Info("attempting to reconnect", "component", "grpc")
+// liveStats returns the request sent on the stream, a delta against the previous payload if
+// enabled and supported by the server. The full request is kept to be buffered if sending fails
+func (c *StreamingGRPCClient) liveStats(request *pb.NodeStatsRequest) *pb.NodeStatsRequest {
+	if c.delta == nil || c.serverProtocol.Load() < types.ProtocolVersionDelta {
+		return request
+	}
+	live := &pb.NodeStatsRequest{
+		NodeName:  request.NodeName,
+		Timestamp: request.Timestamp,
+		Samples:   request.Samples,
 
-	// Cancel existing context
-	c.cancel()
+		CollectionMs: request.CollectionMs,
+	}
+	c.delta.Encode(live, request.Metrics)
+	return live
+}
 
-	// Create new context
-	c.ctx, c.cancel = context.WithCancel(context.Background())
+// bufferStats keeps stats that could not be sent, they are replayed as backfill
+func (c *StreamingGRPCClient) bufferStats(request *pb.NodeStatsRequest) {
+	request.Backfill = true
+	data, err := proto.Marshal(request)
+	if err != nil {
+		slog.Error("failed to buffer stats", "component", "buffer", "error", err)
+		return
+	}
 
-	// Retry connection with exponential backoff
-	retryDelay := 1 * time.Second
-	maxDelay := 30 * time.Second
+	c.buffer.Push(data)
+	if status := c.buffer.Status(); status.Payloads == 1 {
+		slog.Warn("server unreachable, buffering stats until reconnected", "component", "buffer")
+	} else {
+		slog.Debug("buffered stats", "component", "buffer", "payloads", status.Payloads, "dropped", status.Dropped)
+	}
+}
 
-	for {
-		if err := c.establishStream(); err != nil {
-			slog.Error("reconnection failed, retrying", "component", "grpc", "error", err, "retry_delay", retryDelay)
-			time.Sleep(retryDelay)
-
-			// Exponential backoff
-			retryDelay *= 2
-			if retryDelay > maxDelay {
-				retryDelay = maxDelay
+// startReplay sends the buffered stats in the background if connected, one replay runs at a time
+func (c *StreamingGRPCClient) startReplay() {
+	if c.buffer == nil || !c.connected.Load() || !c.replaying.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		for {
+			sent, err := c.buffer.Replay(func(data []byte) error {
+				request := &pb.NodeStatsRequest{}
+				if err := proto.Unmarshal(data, request); err != nil {
+					slog.Error("dropping unreadable buffered stats", "component", "buffer", "error", err)
+					return nil
+				}
+				return c.sendStats(&pb.AgentMessage{
+					Message: &pb.AgentMessage_Stats{
+						Stats: request,
+					},
+				})
+			})
+			if sent > 0 {
+				slog.Info("replayed buffered stats", "component", "buffer", "payloads", sent, "remaining", c.buffer.Len())
+			}
+			c.replaying.Store(false)
+
+			if err != nil {
+				// Replayed again once reconnected
+				slog.Error("replaying buffered stats failed", "component", "buffer", "error", err)
+				return
+			}
+			// Stats may have been buffered after the last one was replayed
+			if c.buffer.Len() == 0 || !c.connected.Load() || !c.replaying.CompareAndSwap(false, true) {
+				return
 			}
-		} else {
-			// Successfully reconnected
-			go c.handleIncomingMessages()
-			break
 		}
+	}()
+}
+
+// stopping returns true once the agent is stopping, the stream is then not re-established
+func (c *StreamingGRPCClient) stopping() bool {
+	return c.closing.Load() || c.lifetime.Err() != nil
+}
+
+// Shutdown waits for the running requests, which are aborted when the lifetime context is done,
+// then says goodbye so that the server marks the node as intentionally stopped, and closes the
+// stream. Payloads that could not be sent stay in the buffer
+func (c *StreamingGRPCClient) Shutdown(ctx context.Context, reason string) error {
+	c.closing.Store(true)
+
+	handlersDone := make(chan struct{})
+	go func() {
+		c.handlers.Wait()
+		close(handlersDone)
+	}()
+	select {
+	case <-handlersDone:
+	case <-ctx.Done():
+		slog.Warn("requests still running at shutdown", "component", "grpc")
 	}
+
+	if !c.connected.Load() {
+		return c.Close()
+	}
+
+	goodbye := &pb.AgentMessage{
+		Message: &pb.AgentMessage_Goodbye{
+			Goodbye: &pb.AgentGoodbye{Reason: reason},
+		},
+	}
+	if err := c.send(goodbye); err != nil {
+		c.Close()
+		return fmt.Errorf("failed to send goodbye: %v", err)
+	}
+	c.sendMu.Lock()
+	err := c.stream.CloseSend()
+	c.sendMu.Unlock()
+	if err != nil {
+		c.Close()
+		return fmt.Errorf("failed to close stream: %v", err)
+	}
+
+	// The server ends the stream once it recorded the goodbye
+	select {
+	case <-c.streamEnded:
+		slog.Info("said goodbye to server", "component", "grpc", "reason", reason)
+	case <-ctx.Done():
+		slog.Warn("server did not close the stream after goodbye", "component", "grpc")
+	}
+	return c.Close()
 }
 
 // Close closes the streaming connection
 func (c *StreamingGRPCClient) Close() error {
+	c.connMu.Lock()
 	c.cancel()
-	if c.conn != nil {
-		return c.conn.Close()
+	conn := c.conn
+	c.conn = nil
+	c.connMu.Unlock()
+
+	if conn != nil {
+		return conn.Close()
 	}
 	return nil
 }
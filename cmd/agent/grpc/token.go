@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tokenCredentials sends the service account token of the agent in the authorization metadata
+// The file is read on every call as the kubelet rotates projected tokens
+type tokenCredentials struct {
+	file string
+}
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	data, err := os.ReadFile(t.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %v", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return nil, fmt.Errorf("service account token %s is empty", t.file)
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity returns false so that tokens work without TLS, the agent warns about it
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ThomasCardin/gobservability/cmd/agent/shared"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// PodVolumeDir is a volume directory found under /var/lib/kubelet/pods/<uid>/volumes
+type PodVolumeDir struct {
+	Name    string
+	Type    string // Plugin name without the vendor prefix (kubernetes.io~empty-dir -> empty-dir)
+	Path    string
+	Mounted bool // Volume is its own filesystem (PVC, tmpfs emptyDir)
+}
+
+// ListPodVolumes lists the volume directories of a pod from the kubelet pods directory
+func ListPodVolumes(devMode, podUID string) ([]PodVolumeDir, error) {
+	volumesPath := filepath.Join(shared.GetKubeletPodsPath(devMode), podUID, "volumes")
+	plugins, err := os.ReadDir(volumesPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", volumesPath, err)
+	}
+
+	var volumes []PodVolumeDir
+	for _, plugin := range plugins {
+		if !plugin.IsDir() {
+			continue
+		}
+
+		volumeType := plugin.Name()
+		if idx := strings.Index(volumeType, "~"); idx != -1 {
+			volumeType = volumeType[idx+1:]
+		}
+
+		pluginPath := filepath.Join(volumesPath, plugin.Name())
+		entries, err := os.ReadDir(pluginPath)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			volumePath := filepath.Join(pluginPath, entry.Name())
+			// CSI volumes are mounted in a "mount" subdirectory
+			if volumeType == "csi" {
+				volumePath = filepath.Join(volumePath, "mount")
+			}
+
+			volumes = append(volumes, PodVolumeDir{
+				Name:    entry.Name(),
+				Type:    volumeType,
+				Path:    volumePath,
+				Mounted: IsMountPoint(volumePath),
+			})
+		}
+	}
+
+	return volumes, nil
+}
+
+// IsMountPoint reports whether path is on a different device than its parent
+func IsMountPoint(path string) bool {
+	var st, parent syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return false
+	}
+	if err := syscall.Stat(filepath.Dir(path), &parent); err != nil {
+		return false
+	}
+	return st.Dev != parent.Dev
+}
+
+// FilesystemUsage returns used bytes, used inodes and capacity of the filesystem holding path
+func FilesystemUsage(path string) (uint64, uint64, uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, 0, fmt.Errorf("error statfs %s: %v", path, err)
+	}
+
+	blockSize := uint64(stat.Bsize)
+	capacity := stat.Blocks * blockSize
+	used := (stat.Blocks - stat.Bfree) * blockSize
+	inodes := stat.Files - stat.Ffree
+
+	return used, inodes, capacity, nil
+}
+
+// DirectoryUsage walks path and returns used bytes (allocated blocks, like du) and inodes.
+// The walk does not cross filesystem boundaries.
+func DirectoryUsage(path string) (uint64, uint64, error) {
+	var root syscall.Stat_t
+	if err := syscall.Lstat(path, &root); err != nil {
+		return 0, 0, fmt.Errorf("error stat %s: %v", path, err)
+	}
+
+	var usedBytes, usedInodes uint64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can disappear during the walk
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return nil
+		}
+
+		if d.IsDir() && p != path && st.Dev != root.Dev {
+			return filepath.SkipDir
+		}
+
+		usedBytes += uint64(st.Blocks) * 512
+		usedInodes++
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("error walking %s: %v", path, err)
+	}
+
+	return usedBytes, usedInodes, nil
+}
+
+// ProcPIDWritableLayer reads /proc/{PID}/mountinfo and returns the host path of the
+// container root overlay upperdir (the container writable layer)
+func ProcPIDWritableLayer(devMode string, pid int) (string, error) {
+	procPath := fmt.Sprintf("%s/%d/mountinfo", shared.GetProcBasePath(devMode), pid)
+	file, err := os.Open(procPath)
+	if err != nil {
+		return "", fmt.Errorf("error opening %s: %v", procPath, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// 36 35 0:42 / / rw,relatime master:1 - overlay overlay rw,lowerdir=...,upperdir=...,workdir=...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[4] != "/" {
+			continue
+		}
+
+		sep := -1
+		for i, field := range fields {
+			if field == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep == -1 || sep+3 >= len(fields) || fields[sep+1] != "overlay" {
+			continue
+		}
+
+		for _, option := range strings.Split(fields[sep+3], ",") {
+			if upperDir, found := strings.CutPrefix(option, "upperdir="); found {
+				return shared.GetHostRootPath(devMode) + upperDir, nil
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("error reading %s: %v", procPath, err)
+	}
+
+	return "", fmt.Errorf("no overlay root mount in %s", procPath)
+}
+
+// PodVolumeUsage returns the usage of a single volume directory. Mounted volumes
+// use statfs, others are walked.
+func PodVolumeUsage(volume PodVolumeDir) (types.VolumeStats, error) {
+	stats := types.VolumeStats{
+		Name: volume.Name,
+		Type: volume.Type,
+	}
+
+	if volume.Mounted {
+		used, inodes, capacity, err := FilesystemUsage(volume.Path)
+		if err != nil {
+			return stats, err
+		}
+		stats.UsedBytes = used
+		stats.UsedInodes = inodes
+		stats.CapacityBytes = capacity
+		return stats, nil
+	}
+
+	used, inodes, err := DirectoryUsage(volume.Path)
+	if err != nil {
+		return stats, err
+	}
+	stats.UsedBytes = used
+	stats.UsedInodes = inodes
+
+	return stats, nil
+}
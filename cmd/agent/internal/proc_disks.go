@@ -16,7 +16,7 @@ func getProcDiskstats(devMode string) string {
 }
 
 // https://github.com/torvalds/linux/blob/master/Documentation/ABI/testing/procfs-diskstats
-func ProcDiskstats(devMode string) (*types.DiskStats, error) {
+func ProcDiskstats(devMode string, keep func(name string) bool) (*types.DiskStats, error) {
 	procDiskstatsPath := getProcDiskstats(devMode)
 	file, err := os.Open(procDiskstatsPath)
 	if err != nil {
@@ -34,10 +34,11 @@ func ProcDiskstats(devMode string) (*types.DiskStats, error) {
 			continue
 		}
 
-		// Skip partitions and loop devices, only aggregate physical disks
+		// Skip partitions, loop and filtered out devices, only aggregate physical disks
 		deviceName := fields[2]
 		if strings.Contains(deviceName, "loop") ||
-			len(deviceName) > 3 && (deviceName[len(deviceName)-1] >= '0' && deviceName[len(deviceName)-1] <= '9') {
+			len(deviceName) > 3 && (deviceName[len(deviceName)-1] >= '0' && deviceName[len(deviceName)-1] <= '9') ||
+			(keep != nil && !keep(deviceName)) {
 			continue
 		}
 
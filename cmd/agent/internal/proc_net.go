@@ -16,7 +16,7 @@ func getProcNetDev(devMode string) string {
 }
 
 // https://github.com/torvalds/linux/blob/master/Documentation/filesystems/proc.rst#13-networking-info-in-procnet
-func ProcNetDev(devMode string) (*types.NetworkStats, error) {
+func ProcNetDev(devMode string, keep func(name string) bool) (*types.NetworkStats, error) {
 	procNetDevPath := getProcNetDev(devMode)
 	file, err := os.Open(procNetDevPath)
 	if err != nil {
@@ -38,9 +38,9 @@ func ProcNetDev(devMode string) (*types.NetworkStats, error) {
 			continue
 		}
 
-		// Skip loopback interface
+		// Skip loopback and filtered out interfaces
 		interfaceName := strings.TrimSuffix(fields[0], ":")
-		if interfaceName == "lo" {
+		if interfaceName == "lo" || (keep != nil && !keep(interfaceName)) {
 			continue
 		}
 
@@ -374,11 +374,48 @@ func ProcPIDStatm(devMode string, pid int) (uint64, uint64, uint64, uint64, erro
 }
 
 // CollectPodMetrics combines all PID-based metrics collection
+// Only the summary tier of the process details is returned, see CollectPidDetails
 func CollectPodMetrics(devMode string, pid int) (*types.PodMetrics, *types.PidDetails, error) {
+	podMetrics, pidDetails, err := collectPID(devMode, pid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary := pidDetails.Summary()
+	return podMetrics, &summary, nil
+}
+
+// CollectPidDetails reads all the process details of a PID, including the command line,
+// kernel stack, file descriptors and cgroups
+func CollectPidDetails(devMode string, pid int) (*types.PidDetails, error) {
+	_, pidDetails, err := collectPID(devMode, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdline, err := ProcPIDCmdline(devMode, pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cmdline: %v", err)
+	}
+	stack, _ := ProcPIDStack(devMode, pid)
+	openFDs, _ := ProcPIDFDCount(devMode, pid)
+	maxFDs, _ := ProcPIDLimits(devMode, pid)
+	cgroups, _ := ProcPIDCgroup(devMode, pid)
+
+	pidDetails.Cmdline = cmdline
+	pidDetails.Stack = stack
+	pidDetails.OpenFDs = openFDs
+	pidDetails.MaxFDs = maxFDs
+	pidDetails.Cgroup = cgroups
+
+	return pidDetails, nil
+}
+
+// collectPID reads the stat, status, io and net/dev files of a PID
+func collectPID(devMode string, pid int) (*types.PodMetrics, *types.PidDetails, error) {
 	if pid <= 0 {
 		return nil, nil, errors.New("invalid PID")
 	}
-
 	// Collect CPU stats and basic process details
 	cpuStats, pidDetails1, err := ProcPIDStat(devMode, pid)
 	if err != nil {
@@ -403,13 +440,6 @@ func CollectPodMetrics(devMode string, pid int) (*types.PodMetrics, *types.PidDe
 		return nil, nil, fmt.Errorf("failed to read net/dev: %v", err)
 	}
 
-	// Collect additional process information
-	cmdline, _ := ProcPIDCmdline(devMode, pid)
-	stack, _ := ProcPIDStack(devMode, pid)
-	openFDs, _ := ProcPIDFDCount(devMode, pid)
-	maxFDs, _ := ProcPIDLimits(devMode, pid)
-	cgroups, _ := ProcPIDCgroup(devMode, pid)
-
 	// Merge pidDetails from all sources
 	mergedPidDetails := *pidDetails1
 	mergedPidDetails.KThread = pidDetails2.KThread
@@ -434,13 +464,6 @@ func CollectPodMetrics(devMode string, pid int) (*types.PodMetrics, *types.PidDe
 	mergedPidDetails.ErrorsReceived = pidDetails4.ErrorsReceived
 	mergedPidDetails.ErrorsTransmitted = pidDetails4.ErrorsTransmitted
 
-	// Add new process information
-	mergedPidDetails.Cmdline = cmdline
-	mergedPidDetails.Stack = stack
-	mergedPidDetails.OpenFDs = openFDs
-	mergedPidDetails.MaxFDs = maxFDs
-	mergedPidDetails.Cgroup = cgroups
-
 	podMetrics := &types.PodMetrics{
 		CPU:     *cpuStats,
 		Memory:  *memStats,
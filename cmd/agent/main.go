@@ -1,14 +1,26 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	grpcClient "github.com/ThomasCardin/gobservability/cmd/agent/grpc"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/buffer"
 	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/collector"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/config"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/health"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/kubernetes"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/metrics"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/prometheus"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/receiver"
+	"github.com/ThomasCardin/gobservability/cmd/agent/shared"
+	"github.com/ThomasCardin/gobservability/shared/types"
 )
 
 const (
@@ -17,27 +29,98 @@ const (
 
 	ENV_NODE_NAME = "NODE_NAME"
 	ENV_DEV_MODE  = "DEV_MODE"
+
+	CONFIG_POLL_INTERVAL = 5 * time.Second
 )
 
 var (
-	grpcAddr        = flag.String("grpc-server", DEFAULT_GRPC_ADDR, "Server gRPC address")
+	configPath      = flag.String("config", "", "YAML configuration file, reloaded on change or SIGHUP (flags take precedence)")
+	grpcAddr        = flag.String("grpc-server", DEFAULT_GRPC_ADDR, "Comma separated server gRPC addresses, the next one is used when a server is unreachable")
 	collectInterval = flag.Duration("interval", 5*time.Second, "Collect interval")
+	sampleInterval  = flag.Duration("sample-interval", 0, "Interval of the node level samples sent in a batch with every payload, e.g. 1s (disabled if 0)")
 	hostname        = flag.String("hostname", DEFAULT_NODE_NAME, "Custom hostname (overrides NODE_NAME env var)")
 	dev             = flag.Bool("dev", false, "Development mode (use / instead of /host)")
+	scrapeTimeout   = flag.Duration("scrape-timeout", 3*time.Second, "Timeout when scraping Prometheus endpoints of annotated pods")
+	scrapeInclude   = flag.String("scrape-include", "", "Regex of scraped series names forwarded to the server (default all)")
+	scrapeMaxSeries = flag.Int("scrape-max-series", 500, "Maximum scraped series forwarded per pod")
+	textfileDir     = flag.String("textfile-dir", "", "Node directory of *.prom files read by the custom collector (disabled if empty)")
+	execTimeout     = flag.Duration("exec-timeout", 10*time.Second, "Timeout of each custom collector source")
+	execCommands    stringList
+	statsdAddr      = flag.String("statsd-addr", "", "UDP address of the StatsD receiver, e.g. :8125 (disabled if empty)")
+	otlpAddr        = flag.String("otlp-addr", "", "TCP address of the OTLP/gRPC metrics receiver, e.g. :4317 (disabled if empty)")
+	pushFlush       = flag.Duration("push-flush-interval", 10*time.Second, "Aggregation interval of StatsD and OTLP metrics")
+	pushMaxSeries   = flag.Int("push-max-series", 2000, "Maximum StatsD and OTLP series aggregated per flush interval")
+	podWorkers      = flag.Int("pod-workers", 8, "Number of pods whose /proc files are read in parallel")
+	podTimeout      = flag.Duration("pod-timeout", 2*time.Second, "Time after which the metrics of a pod are reported partial")
+	disabled        = flag.String("disable-collectors", "", "Comma separated collectors to disable, e.g. prometheus,logs")
+	enabled         = flag.String("enable-collectors", "", "Comma separated collectors disabled by default to enable, e.g. process")
+	intervals       stringList
+	bufferPayloads  = flag.Int("buffer-max-payloads", 720, "Maximum payloads kept in memory while the server is unreachable")
+	bufferBytes     = flag.Int64("buffer-max-bytes", 16<<20, "Maximum size of the payloads kept in memory while the server is unreachable")
+	bufferDir       = flag.String("buffer-dir", "", "Directory of the write-ahead log receiving the payloads over the memory limits (dropped if empty)")
+	bufferDiskBytes = flag.Int64("buffer-max-disk-bytes", 256<<20, "Maximum size of the write-ahead log")
+	keepaliveTime   = flag.Duration("keepalive-time", grpcClient.DefaultConnectionOptions.KeepaliveTime, "Idle time after which the server is pinged to detect broken connections (at least 10s, the server rejects more frequent pings)")
+	keepaliveWait   = flag.Duration("keepalive-timeout", grpcClient.DefaultConnectionOptions.KeepaliveTimeout, "Time waited for a ping ack before reconnecting")
+	tlsCAFile       = flag.String("tls-ca-file", "", "CA of the server certificate, enables TLS (overrides tls.caFile)")
+	tlsCertFile     = flag.String("tls-cert-file", "", "Client certificate presented to the server (mTLS), enables TLS (overrides tls.certFile)")
+	tlsKeyFile      = flag.String("tls-key-file", "", "Key of the client certificate (overrides tls.keyFile)")
+	tlsServerName   = flag.String("tls-server-name", "", "Name verified in the server certificate, default the host of -grpc-server (overrides tls.serverName)")
+	tokenFile       = flag.String("token-file", "", "Service account token sent to the server to authenticate the agent, read on every connection (disabled if empty)")
+	compression     = flag.String("compression", "", "Compression of the stream to the server: gzip or empty for none")
+	deltaPayloads   = flag.Bool("delta", false, "Send stats as deltas against the previous payload, servers of protocol version 2 rebuild them")
+	deltaSnapshots  = flag.Int("delta-snapshot-every", grpcClient.DefaultConnectionOptions.SnapshotEvery, "Payloads between two full snapshots in delta mode")
+	healthAddr      = flag.String("health-addr", ":8081", "TCP address of the /healthz, /readyz and /metrics endpoints of the agent (disabled if empty)")
+	reconnectMax    = flag.Duration("reconnect-max-delay", grpcClient.DefaultConnectionOptions.MaxBackoff, "Maximum delay between reconnection attempts")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second, "Time given to running requests and the goodbye to the server on SIGTERM")
 )
 
+func init() {
+	flag.Var(&execCommands, "exec", "Custom collector command as name=command args, output in Prometheus text format (repeatable)")
+	flag.Var(&intervals, "collector-interval", "Collector interval as name=duration, e.g. process=1m (repeatable)")
+}
+
+// stringList is a flag that can be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func main() {
 	flag.Parse()
 
 	var nodeName string
 	var err error
 
+	cfg := &config.Config{}
+	if *configPath != "" {
+		cfg, err = config.Load(*configPath)
+		if err != nil {
+			slog.Error("failed to load config", "component", "config", "error", err)
+			os.Exit(1)
+		}
+		applyConfig(cfg)
+		slog.Info("config loaded", "component", "config", "path", *configPath)
+	}
+	if applyTLSFlags(cfg) {
+		if err := cfg.Validate(); err != nil {
+			slog.Error("invalid TLS flags", "component", "config", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	if *dev {
 		os.Setenv(ENV_DEV_MODE, "true")
 		slog.Info("development mode enabled - using / paths", "component", "env")
 	} else {
 		slog.Info("production mode - using /host paths", "component", "env")
 	}
+	shared.SetPaths(cfg.Paths.Proc, cfg.Paths.Sys, cfg.Paths.Root)
 
 	// Priority: flag hostname > NODE_NAME env var > system hostname
 	if *hostname != DEFAULT_NODE_NAME {
@@ -55,15 +138,237 @@ func main() {
 	slog.Info("starting gobservability agent", "component", "env", "node", nodeName, "interval", *collectInterval, "grpc_addr", *grpcAddr)
 
 	// Initialize streaming gRPC connection to server
-	devModeValue := fmt.Sprintf("%t", *dev)
-	grpcSender, err := grpcClient.NewStreamingGRPCClient(*grpcAddr, nodeName, devModeValue)
+	creds, err := cfg.TLS.Credentials()
+	if err != nil {
+		slog.Error("failed to load TLS credentials", "component", "config", "error", err)
+		os.Exit(1)
+	}
+	// Stats are buffered while the server is unreachable and replayed once reconnected
+	statsBuffer, err := buffer.New(buffer.Limits{
+		MaxPayloads:  *bufferPayloads,
+		MaxBytes:     *bufferBytes,
+		Dir:          *bufferDir,
+		MaxDiskBytes: *bufferDiskBytes,
+	})
+	if err != nil {
+		slog.Error("failed to create stats buffer", "component", "buffer", "error", err)
+		os.Exit(1)
+	}
+	defer statsBuffer.Close()
+
+	connectionOptions := grpcClient.DefaultConnectionOptions
+	connectionOptions.KeepaliveTime = *keepaliveTime
+	connectionOptions.KeepaliveTimeout = *keepaliveWait
+	connectionOptions.MaxBackoff = max(*reconnectMax, connectionOptions.MinBackoff)
+	connectionOptions.TokenFile = *tokenFile
+	connectionOptions.Delta = *deltaPayloads
+	connectionOptions.SnapshotEvery = *deltaSnapshots
+	if *compression != "" && *compression != "gzip" {
+		slog.Error("invalid compression, expected gzip", "component", "grpc", "compression", *compression)
+		os.Exit(1)
+	}
+	connectionOptions.Compression = *compression
+	if *tokenFile != "" && !cfg.TLS.Enabled {
+		slog.Warn("service account token sent to the server without TLS", "component", "grpc", "token_file", *tokenFile)
+	}
+
+	// The collection and the running requests stop on SIGTERM or interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	grpcSender, err := grpcClient.NewStreamingGRPCClient(ctx, splitList(*grpcAddr), nodeName, ENV_DEV_MODE, creds, statsBuffer, connectionOptions)
 	if err != nil {
 		slog.Error("failed to create streaming gRPC client", "error", err)
 		os.Exit(1)
 	}
 	defer grpcSender.Close()
 
-	// Initialize metrics collector with gRPC client
-	metricsCollector := collector.NewCollector(ENV_DEV_MODE, grpcSender)
-	metricsCollector.Start(nodeName, *collectInterval)
+	// Prometheus scraping of pods annotated with prometheus.io/scrape
+	scraper, err := prometheus.NewScraper(nil, *scrapeTimeout, *scrapeInclude, *scrapeMaxSeries)
+	if err != nil {
+		slog.Error("failed to create prometheus scraper", "error", err)
+		os.Exit(1)
+	}
+
+	// Custom metrics from .prom files and commands
+	var customSources []collector.CustomSource
+	if *textfileDir != "" {
+		customSources = append(customSources, collector.NewTextfileSource(*textfileDir, ENV_DEV_MODE))
+	}
+	for _, definition := range execCommands {
+		source, err := collector.ParseExecSource(definition)
+		if err != nil {
+			slog.Error("failed to create exec collector", "error", err)
+			os.Exit(1)
+		}
+		customSources = append(customSources, source)
+	}
+	var customCollector *collector.CustomCollector
+	if len(customSources) > 0 {
+		customCollector = collector.NewCustomCollector(customSources, *execTimeout)
+		slog.Info("custom collectors enabled", "component", "custom", "sources", len(customSources))
+	}
+
+	// StatsD and OTLP receivers for metrics pushed by applications
+	var pushed *receiver.Aggregator
+	if *statsdAddr != "" || *otlpAddr != "" {
+		pushed = receiver.NewAggregator(*pushFlush, *pushMaxSeries)
+		go pushed.Run(ctx)
+	}
+	if *statsdAddr != "" {
+		statsd := receiver.NewStatsDReceiver(*statsdAddr, pushed)
+		if err := statsd.Start(); err != nil {
+			slog.Error("failed to start statsd receiver", "error", err)
+			os.Exit(1)
+		}
+		defer statsd.Close()
+	}
+	if *otlpAddr != "" {
+		otlp := receiver.NewOTLPReceiver(*otlpAddr, pushed)
+		if err := otlp.Start(); err != nil {
+			slog.Error("failed to start otlp receiver", "error", err)
+			os.Exit(1)
+		}
+		defer otlp.Close()
+	}
+
+	// Collectors run in registration order, pod collectors after the pods collector
+	cache := metrics.NewCache()
+	calculator := metrics.NewCalculator()
+
+	filters := collector.NewFilters()
+	registry := collector.NewRegistry(grpcSender)
+	for _, nodeCollector := range collector.NewNodeCollector(cache, calculator, ENV_DEV_MODE, filters).Collectors() {
+		registry.Register(nodeCollector)
+	}
+	podCollector := collector.NewPodCollector(cache, calculator, ENV_DEV_MODE, *podWorkers, *podTimeout)
+	registry.Register(collector.NewPodsCollector(podCollector, kubernetes.NewClient(ENV_DEV_MODE), filters))
+	// Process details are requested by the server when displayed, shipping them for every pod is opt-in
+	registry.RegisterDisabled(collector.NewProcessCollector(podCollector))
+	registry.Register(collector.NewPrometheusCollector(scraper))
+	if logPatterns := grpcSender.LogPatterns(); logPatterns != nil {
+		registry.Register(collector.NewLogsCollector(logPatterns))
+	}
+	if customCollector != nil {
+		registry.Register(customCollector)
+	}
+	if pushed != nil {
+		registry.Register(collector.NewPushedCollector(pushed))
+	}
+
+	agentSettings := newSettings(registry, filters, cfg)
+	if err := agentSettings.apply(cfg, types.AgentConfig{}); err != nil {
+		slog.Error("invalid collector configuration", "error", err)
+		os.Exit(1)
+	}
+	grpcSender.SetConfigHandler(agentSettings.applyServer)
+	grpcSender.SetCollectHandler(registry.CollectNow)
+
+	if *configPath != "" {
+		go config.Watch(*configPath, CONFIG_POLL_INTERVAL, agentSettings.reloadFile)
+	}
+
+	// Liveness, readiness and cost of the agent
+	if *healthAddr != "" {
+		healthServer := health.NewServer(*healthAddr, nodeName, registry, grpcSender, statsBuffer)
+		if err := healthServer.Start(); err != nil {
+			slog.Error("failed to start health server", "error", err)
+			os.Exit(1)
+		}
+		defer healthServer.Close()
+	}
+
+	if *sampleInterval > 0 && *sampleInterval >= *collectInterval {
+		slog.Warn("sample interval not shorter than the collect interval, sampling disabled", "component", "collector", "sample_interval", *sampleInterval, "interval", *collectInterval)
+		*sampleInterval = 0
+	}
+	if err := registry.SetSampleInterval(*sampleInterval); err != nil {
+		slog.Error("invalid sample interval", "error", err)
+		os.Exit(1)
+	}
+
+	registry.Start(ctx, nodeName, *collectInterval)
+
+	// Deferred closes then move the buffered payloads to the write-ahead log
+	slog.Info("stopping agent", "component", "env", "node", nodeName)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := grpcSender.Shutdown(shutdownCtx, "agent stopped"); err != nil {
+		slog.Warn("agent did not stop cleanly", "component", "grpc", "error", err)
+	}
+}
+
+// applyConfig sets the flags that were not set on the command line from the config file
+func applyConfig(cfg *config.Config) {
+	if cfg.GRPCServer != "" && !flagSet("grpc-server") {
+		*grpcAddr = cfg.GRPCServer
+	}
+	if cfg.Interval > 0 && !flagSet("interval") {
+		*collectInterval = cfg.Interval
+	}
+	if cfg.Hostname != "" && !flagSet("hostname") {
+		*hostname = cfg.Hostname
+	}
+	if cfg.SampleInterval > 0 && !flagSet("sample-interval") {
+		*sampleInterval = cfg.SampleInterval
+	}
+	if cfg.Buffer.MaxPayloads > 0 && !flagSet("buffer-max-payloads") {
+		*bufferPayloads = cfg.Buffer.MaxPayloads
+	}
+	if cfg.Buffer.MaxBytes > 0 && !flagSet("buffer-max-bytes") {
+		*bufferBytes = cfg.Buffer.MaxBytes
+	}
+	if cfg.Buffer.Dir != "" && !flagSet("buffer-dir") {
+		*bufferDir = cfg.Buffer.Dir
+	}
+	if cfg.Buffer.MaxDiskBytes > 0 && !flagSet("buffer-max-disk-bytes") {
+		*bufferDiskBytes = cfg.Buffer.MaxDiskBytes
+	}
+}
+
+// applyTLSFlags overrides the TLS settings of the config file with the flags set on the command
+// line, TLS is enabled by any of them. Returns true if a flag was set
+func applyTLSFlags(cfg *config.Config) bool {
+	applied := false
+	for _, setting := range []struct {
+		flag  string
+		value string
+		field *string
+	}{
+		{"tls-ca-file", *tlsCAFile, &cfg.TLS.CAFile},
+		{"tls-cert-file", *tlsCertFile, &cfg.TLS.CertFile},
+		{"tls-key-file", *tlsKeyFile, &cfg.TLS.KeyFile},
+		{"tls-server-name", *tlsServerName, &cfg.TLS.ServerName},
+	} {
+		if flagSet(setting.flag) {
+			*setting.field = setting.value
+			applied = true
+		}
+	}
+	if applied {
+		cfg.TLS.Enabled = true
+	}
+	return applied
+}
+
+// flagSet returns true if the flag was set on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// splitList splits a comma separated flag, empty entries are dropped
+func splitList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
 }
@@ -0,0 +1,222 @@
+package buffer
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Limits bounds the payloads kept while the server is unreachable
+type Limits struct {
+	MaxPayloads  int    // Payloads kept in memory
+	MaxBytes     int64  // Size of the payloads kept in memory
+	Dir          string // Write-ahead log directory, payloads over the memory limits are dropped if empty
+	MaxDiskBytes int64  // Size of the write-ahead log
+}
+
+// Buffer is a bounded queue of payloads waiting to be sent. The oldest payloads are spilled to
+// the write-ahead log when the memory limits are reached, or dropped without it. Payloads on disk
+// are always older than the ones in memory, so they are replayed first
+type Buffer struct {
+	limits  Limits
+	memory  []record
+	bytes   int64
+	wal     *wal // nil if payloads are only kept in memory
+	nextSeq uint64
+
+	dropped  uint64
+	spilled  uint64
+	replayed uint64
+
+	mu sync.Mutex
+}
+
+// record is a buffered payload, seq identifies it while it is replayed
+type record struct {
+	seq  uint64
+	data []byte
+}
+
+// New creates a buffer, payloads left in the write-ahead log by a previous run are kept
+func New(limits Limits) (*Buffer, error) {
+	if limits.MaxPayloads <= 0 {
+		return nil, fmt.Errorf("buffer max payloads must be positive, got %d", limits.MaxPayloads)
+	}
+	if limits.MaxBytes <= 0 {
+		return nil, fmt.Errorf("buffer max bytes must be positive, got %d", limits.MaxBytes)
+	}
+
+	b := &Buffer{limits: limits, nextSeq: 1}
+	if limits.Dir == "" {
+		return b, nil
+	}
+	if limits.MaxDiskBytes <= 0 {
+		return nil, fmt.Errorf("buffer max disk bytes must be positive, got %d", limits.MaxDiskBytes)
+	}
+
+	w, err := openWAL(limits.Dir, limits.MaxDiskBytes)
+	if err != nil {
+		return nil, err
+	}
+	b.wal = w
+	b.nextSeq = w.lastSeq + 1
+	if pending := w.len(); pending > 0 {
+		slog.Info("payloads left in the write-ahead log", "component", "buffer", "dir", limits.Dir, "payloads", pending)
+	}
+	return b, nil
+}
+
+// Push appends a payload, enforcing the limits by spilling or dropping the oldest payloads
+func (b *Buffer) Push(data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.memory = append(b.memory, record{seq: b.nextSeq, data: data})
+	b.bytes += int64(len(data))
+	b.nextSeq++
+
+	for len(b.memory) > b.limits.MaxPayloads || b.bytes > b.limits.MaxBytes {
+		oldest := b.memory[0]
+		b.memory[0] = record{}
+		b.memory = b.memory[1:]
+		b.bytes -= int64(len(oldest.data))
+
+		if b.wal == nil {
+			b.dropped++
+			continue
+		}
+		dropped, err := b.wal.append(oldest.seq, oldest.data)
+		b.dropped += uint64(dropped)
+		if err != nil {
+			slog.Error("failed to spill payload to the write-ahead log", "component", "buffer", "error", err)
+			b.dropped++
+			continue
+		}
+		b.spilled++
+	}
+}
+
+// Len returns the number of payloads waiting to be replayed
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.len()
+}
+
+func (b *Buffer) len() int {
+	n := len(b.memory)
+	if b.wal != nil {
+		n += b.wal.len()
+	}
+	return n
+}
+
+// Replay sends the buffered payloads oldest first and stops at the first error, the payload that
+// failed is kept at the head of the buffer. Payloads pushed during the replay are sent too
+func (b *Buffer) Replay(send func(data []byte) error) (int, error) {
+	sent := 0
+	for {
+		seq, data, found := b.peek()
+		if !found {
+			return sent, nil
+		}
+		if err := send(data); err != nil {
+			return sent, err
+		}
+		if err := b.commit(seq); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+}
+
+// peek returns the oldest payload without removing it, unreadable payloads on disk are dropped
+func (b *Buffer) peek() (uint64, []byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for b.wal != nil && b.wal.len() > 0 {
+		seq, data, err := b.wal.peek()
+		if err == nil {
+			return seq, data, true
+		}
+		slog.Error("dropping unreadable payload from the write-ahead log", "component", "buffer", "error", err)
+		b.dropped++
+		if err := b.wal.commit(); err != nil {
+			slog.Error("failed to update the write-ahead log", "component", "buffer", "error", err)
+		}
+	}
+	if len(b.memory) > 0 {
+		return b.memory[0].seq, b.memory[0].data, true
+	}
+	return 0, nil, false
+}
+
+// commit removes a replayed payload. It may have been spilled to disk while it was sent, or
+// dropped by the limits, in which case there is nothing to remove
+func (b *Buffer) commit(seq uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.wal != nil && b.wal.len() > 0 {
+		if b.wal.headSeq() == seq {
+			b.replayed++
+			if err := b.wal.commit(); err != nil {
+				return fmt.Errorf("failed to update the write-ahead log: %v", err)
+			}
+		}
+		return nil
+	}
+	if len(b.memory) > 0 && b.memory[0].seq == seq {
+		b.replayed++
+		b.bytes -= int64(len(b.memory[0].data))
+		b.memory[0] = record{}
+		b.memory = b.memory[1:]
+	}
+	return nil
+}
+
+// Status returns the buffer usage and counters
+func (b *Buffer) Status() types.BufferStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := types.BufferStatus{
+		Payloads:    b.len(),
+		Bytes:       b.bytes,
+		MaxPayloads: b.limits.MaxPayloads,
+		MaxBytes:    b.limits.MaxBytes,
+		Dropped:     b.dropped,
+		Spilled:     b.spilled,
+		Replayed:    b.replayed,
+	}
+	if b.wal != nil {
+		status.DiskBytes = b.wal.size()
+		status.MaxDiskBytes = b.limits.MaxDiskBytes
+	}
+	return status
+}
+
+// Close moves the payloads still in memory to the write-ahead log so that they are replayed by
+// the next run, they are lost without it
+func (b *Buffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.wal == nil {
+		return nil
+	}
+	for _, r := range b.memory {
+		dropped, err := b.wal.append(r.seq, r.data)
+		b.dropped += uint64(dropped)
+		if err != nil {
+			b.dropped++
+			slog.Error("failed to spill payload to the write-ahead log", "component", "buffer", "error", err)
+		}
+	}
+	b.memory = nil
+	b.bytes = 0
+	return b.wal.close()
+}
@@ -0,0 +1,332 @@
+package buffer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	walHeaderSize  = 16 // length uint32, crc32 uint32, seq uint64
+	walSegmentExt  = ".wal"
+	walCursorFile  = "cursor"
+	walSegmentDivs = 8 // Segments are an eighth of the disk limit so that dropping one loses few payloads
+)
+
+// wal is a write-ahead log of payloads split in segment files. Replayed payloads are recorded in
+// a cursor file and segments are deleted once fully replayed, so payloads survive agent restarts
+// without being replayed twice
+type wal struct {
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+	segments     []*segment
+	active       *os.File // Last segment, opened for appending
+	lastSeq      uint64
+	pending      int
+	bytes        int64
+}
+
+type segment struct {
+	id      uint64
+	path    string
+	size    int64
+	records []walRecord
+	next    int // First record not replayed
+}
+
+type walRecord struct {
+	seq    uint64
+	offset int64
+	length uint32
+}
+
+// openWAL opens the write-ahead log in dir, a corrupted segment tail (e.g. the agent was killed
+// while writing) is truncated
+func openWAL(dir string, maxBytes int64) (*wal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create buffer dir %s: %v", dir, err)
+	}
+
+	w := &wal{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		segmentBytes: maxBytes / walSegmentDivs,
+	}
+
+	cursor := w.readCursor()
+	w.lastSeq = cursor
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+walSegmentExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buffer dir %s: %v", dir, err)
+	}
+	for _, path := range paths {
+		id, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), walSegmentExt), 10, 64)
+		if err != nil {
+			slog.Warn("ignoring unknown file in buffer dir", "component", "buffer", "path", path)
+			continue
+		}
+		seg, err := scanSegment(id, path)
+		if err != nil {
+			return nil, err
+		}
+		for seg.next < len(seg.records) && seg.records[seg.next].seq <= cursor {
+			seg.next++
+		}
+		if seg.next == len(seg.records) {
+			os.Remove(path)
+			continue
+		}
+		w.segments = append(w.segments, seg)
+	}
+	sort.Slice(w.segments, func(i, j int) bool {
+		return w.segments[i].id < w.segments[j].id
+	})
+
+	for _, seg := range w.segments {
+		w.pending += len(seg.records) - seg.next
+		w.bytes += seg.size
+		w.lastSeq = max(w.lastSeq, seg.records[len(seg.records)-1].seq)
+	}
+	return w, nil
+}
+
+// scanSegment reads the record index of a segment file
+func scanSegment(id uint64, path string) (*segment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open buffer segment %s: %v", path, err)
+	}
+	defer file.Close()
+
+	seg := &segment{id: id, path: path}
+	header := make([]byte, walHeaderSize)
+	for {
+		if _, err := io.ReadFull(file, header); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return seg, truncateSegment(seg, err)
+			}
+			return seg, nil
+		}
+
+		length := binary.LittleEndian.Uint32(header[0:4])
+		data := make([]byte, length)
+		if _, err := io.ReadFull(file, data); err != nil {
+			return seg, truncateSegment(seg, err)
+		}
+		if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(header[4:8]) {
+			return seg, truncateSegment(seg, errors.New("checksum mismatch"))
+		}
+
+		seg.records = append(seg.records, walRecord{
+			seq:    binary.LittleEndian.Uint64(header[8:16]),
+			offset: seg.size,
+			length: length,
+		})
+		seg.size += walHeaderSize + int64(length)
+	}
+}
+
+// truncateSegment drops the corrupted tail of a segment after its last valid record
+func truncateSegment(seg *segment, cause error) error {
+	slog.Warn("truncating corrupted buffer segment", "component", "buffer", "path", seg.path, "offset", seg.size, "error", cause)
+	if err := os.Truncate(seg.path, seg.size); err != nil {
+		return fmt.Errorf("failed to truncate buffer segment %s: %v", seg.path, err)
+	}
+	return nil
+}
+
+// append writes a payload, the oldest segments are deleted to stay under the disk limit and the
+// number of payloads dropped with them is returned
+func (w *wal) append(seq uint64, data []byte) (int, error) {
+	recordSize := walHeaderSize + int64(len(data))
+	if recordSize > w.maxBytes {
+		return 0, fmt.Errorf("payload of %d bytes is larger than the buffer disk limit", len(data))
+	}
+
+	dropped := 0
+	for len(w.segments) > 0 && w.bytes+recordSize > w.maxBytes {
+		dropped += w.dropOldest()
+	}
+
+	// A payload larger than a segment gets its own segment
+	if w.active == nil || (w.segments[len(w.segments)-1].size > 0 && w.segments[len(w.segments)-1].size+recordSize > w.segmentBytes) {
+		if err := w.rotate(); err != nil {
+			return dropped, err
+		}
+	}
+
+	record := make([]byte, recordSize)
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(data))
+	binary.LittleEndian.PutUint64(record[8:16], seq)
+	copy(record[walHeaderSize:], data)
+
+	seg := w.segments[len(w.segments)-1]
+	_, err := w.active.Write(record)
+	if err == nil {
+		err = w.active.Sync()
+	}
+	if err != nil {
+		// The tail of the segment is truncated when it is scanned again, start a new one
+		w.active.Close()
+		w.active = nil
+		return dropped, fmt.Errorf("failed to write buffer segment %s: %v", seg.path, err)
+	}
+
+	seg.records = append(seg.records, walRecord{seq: seq, offset: seg.size, length: uint32(len(data))})
+	seg.size += recordSize
+	w.bytes += recordSize
+	w.pending++
+	w.lastSeq = max(w.lastSeq, seq)
+	return dropped, nil
+}
+
+// rotate starts a new segment
+func (w *wal) rotate() error {
+	if w.active != nil {
+		w.active.Close()
+		w.active = nil
+	}
+
+	var id uint64 = 1
+	if len(w.segments) > 0 {
+		id = w.segments[len(w.segments)-1].id + 1
+	}
+	path := filepath.Join(w.dir, fmt.Sprintf("%016d%s", id, walSegmentExt))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create buffer segment %s: %v", path, err)
+	}
+
+	w.active = file
+	w.segments = append(w.segments, &segment{id: id, path: path})
+	return nil
+}
+
+// dropOldest deletes the oldest segment and returns the number of payloads not replayed in it
+func (w *wal) dropOldest() int {
+	seg := w.segments[0]
+	if len(w.segments) == 1 && w.active != nil {
+		w.active.Close()
+		w.active = nil
+	}
+	w.removeHead()
+	return len(seg.records) - seg.next
+}
+
+// removeHead deletes the first segment
+func (w *wal) removeHead() {
+	seg := w.segments[0]
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("failed to remove buffer segment", "component", "buffer", "path", seg.path, "error", err)
+	}
+	w.pending -= len(seg.records) - seg.next
+	w.bytes -= seg.size
+	w.segments = w.segments[1:]
+}
+
+// head returns the first segment with a record not replayed
+func (w *wal) head() *segment {
+	for _, seg := range w.segments {
+		if seg.next < len(seg.records) {
+			return seg
+		}
+	}
+	return nil
+}
+
+func (w *wal) len() int {
+	return w.pending
+}
+
+func (w *wal) size() int64 {
+	return w.bytes
+}
+
+// headSeq returns the seq of the oldest record not replayed
+func (w *wal) headSeq() uint64 {
+	seg := w.head()
+	return seg.records[seg.next].seq
+}
+
+// peek reads the oldest record not replayed
+func (w *wal) peek() (uint64, []byte, error) {
+	seg := w.head()
+	record := seg.records[seg.next]
+
+	file, err := os.Open(seg.path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, walHeaderSize+int64(record.length))
+	if _, err := file.ReadAt(buf, record.offset); err != nil {
+		return 0, nil, err
+	}
+	data := buf[walHeaderSize:]
+	if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(buf[4:8]) {
+		return 0, nil, fmt.Errorf("checksum mismatch in %s at offset %d", seg.path, record.offset)
+	}
+	return record.seq, data, nil
+}
+
+// commit marks the oldest record as replayed and deletes the segments fully replayed
+func (w *wal) commit() error {
+	seg := w.head()
+	seq := seg.records[seg.next].seq
+	seg.next++
+	w.pending--
+
+	for len(w.segments) > 0 && w.segments[0].next == len(w.segments[0].records) {
+		if len(w.segments) == 1 && w.active != nil {
+			w.active.Close()
+			w.active = nil
+		}
+		w.removeHead()
+	}
+	return w.writeCursor(seq)
+}
+
+// readCursor returns the seq of the last replayed record, 0 if unknown
+func (w *wal) readCursor() uint64 {
+	data, err := os.ReadFile(filepath.Join(w.dir, walCursorFile))
+	if err != nil {
+		return 0
+	}
+	seq, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		slog.Warn("ignoring invalid buffer cursor", "component", "buffer", "dir", w.dir, "error", err)
+		return 0
+	}
+	return seq
+}
+
+// writeCursor records the seq of the last replayed record, written to a temporary file first so
+// that a crash never leaves a partial cursor
+func (w *wal) writeCursor(seq uint64) error {
+	path := filepath.Join(w.dir, walCursorFile)
+	if err := os.WriteFile(path+".tmp", []byte(strconv.FormatUint(seq, 10)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (w *wal) close() error {
+	if w.active == nil {
+		return nil
+	}
+	err := w.active.Close()
+	w.active = nil
+	return err
+}
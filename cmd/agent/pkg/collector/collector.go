@@ -1,23 +1,57 @@
 package collector
 
 import (
-	"errors"
-	"log/slog"
+	"context"
+	"strings"
 	"time"
 
-	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/kubernetes"
-	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/metrics"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/logs"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/prometheus"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/receiver"
 	"github.com/ThomasCardin/gobservability/shared/types"
 )
 
-type Collector struct {
-	nodeCollector *NodeCollector
-	podCollector  *PodCollector
-	k8sClient     *kubernetes.Client
-	cache         *metrics.Cache
-	calculator    *metrics.Calculator
-	devMode       string
-	grpcClient    GRPCSender
+// Capability describes what a collector reads or produces
+type Capability uint8
+
+const (
+	CapNode       Capability = 1 << iota // Fills node level metrics
+	CapPods                              // Reads or fills the pods of the node (needs the pods collector)
+	CapPrivileged                        // Reads other processes (kernel stacks, fds), needs SYS_PTRACE
+)
+
+// Strings returns the names of the capabilities
+func (c Capability) Strings() []string {
+	var names []string
+	if c&CapNode != 0 {
+		names = append(names, "node")
+	}
+	if c&CapPods != 0 {
+		names = append(names, "pods")
+	}
+	if c&CapPrivileged != 0 {
+		names = append(names, "privileged")
+	}
+	return names
+}
+
+func (c Capability) String() string {
+	return strings.Join(c.Strings(), ",")
+}
+
+// Apply sets the result of a collector run into the metrics sent to the server
+// The last successful result of a collector is applied to every payload until its next run
+type Apply func(metrics *types.NodeMetrics)
+
+// Collector collects one group of metrics on its own interval
+type Collector interface {
+	// Name identifies the collector in the configuration and in the reported status
+	Name() string
+	// DefaultInterval is the interval between two runs, 0 runs it on every collection tick
+	DefaultInterval() time.Duration
+	Capabilities() Capability
+	// Collect runs the collector, metrics contains the results of the collectors registered before it
+	Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error)
 }
 
 // GRPCSender interface pour envoyer les métriques
@@ -25,76 +59,71 @@ type GRPCSender interface {
 	Send(*types.NodeStatsPayload) error
 }
 
-// NewCollector creates a new collector instance
-func NewCollector(devMode string, grpcClient GRPCSender) *Collector {
-	cache := metrics.NewCache()
-	calculator := metrics.NewCalculator()
-
-	return &Collector{
-		nodeCollector: NewNodeCollector(cache, calculator, devMode),
-		podCollector:  NewPodCollector(cache, calculator, devMode),
-		k8sClient:     kubernetes.NewClient(devMode),
-		cache:         cache,
-		calculator:    calculator,
-		devMode:       devMode,
-		grpcClient:    grpcClient,
-	}
+// prometheusCollector scrapes the Prometheus endpoints of annotated pods
+type prometheusCollector struct {
+	scraper *prometheus.Scraper
 }
 
-// CollectAll collects all metrics (node + pods) with calculations
-func (c *Collector) CollectAll(nodeName string) (*types.NodeStatsPayload, error) {
-	nodeMetrics, err := c.nodeCollector.CollectNodeMetrics(nodeName)
-	if err != nil {
-		return nil, errors.New("failed to collect node metrics")
-	}
-
-	pods, err := c.k8sClient.GetPodsForNode(nodeName)
-	if err != nil {
-		return nil, errors.New("failed to get pods")
-	}
-
-	// Get total system memory for pod percentage calculations
-	totalSystemMemoryKB := uint64(nodeMetrics.Memory.MemTotal)
-
-	if err := c.podCollector.CollectAllPodMetrics(pods, totalSystemMemoryKB); err != nil {
-		// Log error but continue - some pods may have succeeded
-		slog.Error("failed to collect pod metrics", "error", err)
-	}
+// NewPrometheusCollector creates the collector scraping annotated pods
+func NewPrometheusCollector(scraper *prometheus.Scraper) Collector {
+	return &prometheusCollector{scraper: scraper}
+}
 
-	nodeMetrics.Pods = pods
+func (c *prometheusCollector) Name() string                   { return "prometheus" }
+func (c *prometheusCollector) DefaultInterval() time.Duration { return 0 }
+func (c *prometheusCollector) Capabilities() Capability       { return CapPods }
+
+func (c *prometheusCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	results := c.scraper.ScrapePods(ctx, metrics.Pods)
+
+	return func(m *types.NodeMetrics) {
+		// Pods with several containers get the result on their first container only
+		applied := make(map[string]bool)
+		for _, pod := range m.Pods {
+			if result, found := results[pod.UID]; found && !applied[pod.UID] {
+				pod.PodMetrics.Scrape = result
+				applied[pod.UID] = true
+			}
+		}
+	}, nil
+}
 
-	payload := &types.NodeStatsPayload{
-		NodeName:  nodeName,
-		Timestamp: time.Now(),
-		Metrics:   *nodeMetrics,
-	}
+// logsCollector follows the logs of pods targeted by log pattern rules and reports match counts
+type logsCollector struct {
+	patterns *logs.PatternMatcher
+}
 
-	return payload, nil
+// NewLogsCollector creates the collector of log pattern match counts
+func NewLogsCollector(patterns *logs.PatternMatcher) Collector {
+	return &logsCollector{patterns: patterns}
 }
 
-func (c *Collector) Start(nodeName string, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+func (c *logsCollector) Name() string                   { return "logs" }
+func (c *logsCollector) DefaultInterval() time.Duration { return 0 }
+func (c *logsCollector) Capabilities() Capability       { return CapPods }
 
-	// Initial collection
-	c.collectAndSend(nodeName)
+func (c *logsCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	c.patterns.Sync(metrics.Pods)
+	counts := c.patterns.Counts()
+	return func(m *types.NodeMetrics) { m.LogPatterns = counts }, nil
+}
 
-	// Collection loop
-	for range ticker.C {
-		c.collectAndSend(nodeName)
-	}
+// pushedCollector reports the StatsD and OTLP metrics of the last flush interval,
+// attributed to pods by source IP
+type pushedCollector struct {
+	aggregator *receiver.Aggregator
 }
 
-func (c *Collector) collectAndSend(nodeName string) {
-	payload, err := c.CollectAll(nodeName)
-	if err != nil {
-		slog.Error("failed to collect metrics", "node", nodeName, "error", err)
-		return
-	}
+// NewPushedCollector creates the collector of metrics pushed to the agent receivers
+func NewPushedCollector(aggregator *receiver.Aggregator) Collector {
+	return &pushedCollector{aggregator: aggregator}
+}
 
-	if err := c.grpcClient.Send(payload); err != nil {
-		slog.Error("failed to send metrics via gRPC", "node", nodeName, "error", err)
-	}
+func (c *pushedCollector) Name() string                   { return "pushed" }
+func (c *pushedCollector) DefaultInterval() time.Duration { return 0 }
+func (c *pushedCollector) Capabilities() Capability       { return CapPods }
 
-	slog.Info("sent metrics via gRPC", "component", "metrics", "node", nodeName)
+func (c *pushedCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	pushed := c.aggregator.Snapshot(metrics.Pods)
+	return func(m *types.NodeMetrics) { m.Pushed = pushed }, nil
 }
@@ -0,0 +1,228 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/prometheus"
+	"github.com/ThomasCardin/gobservability/cmd/agent/shared"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+const (
+	// Maximum size of a .prom file or of a command output
+	maxCustomOutputBytes = 1024 * 1024
+	// Maximum series forwarded per custom source
+	maxCustomSeries = 1000
+	// Series added for every .prom file so stale files can be alerted on
+	textfileMtimeSeries = "gobservability_textfile_mtime_seconds"
+)
+
+// CustomSource produces custom metrics in the Prometheus text format
+// A source may return several results (one per .prom file for a textfile directory)
+type CustomSource interface {
+	Collect(ctx context.Context) []types.CustomMetrics
+}
+
+// CustomCollector runs the textfile and exec custom sources of the node
+type CustomCollector struct {
+	sources []CustomSource
+	timeout time.Duration
+}
+
+// NewCustomCollector creates a new custom collector, timeout applies to each source
+func NewCustomCollector(sources []CustomSource, timeout time.Duration) *CustomCollector {
+	return &CustomCollector{
+		sources: sources,
+		timeout: timeout,
+	}
+}
+
+func (cc *CustomCollector) Name() string                   { return "custom" }
+func (cc *CustomCollector) DefaultInterval() time.Duration { return 30 * time.Second }
+func (cc *CustomCollector) Capabilities() Capability       { return CapNode }
+
+func (cc *CustomCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	custom := cc.CollectCustomMetrics(ctx)
+	return func(m *types.NodeMetrics) { m.CustomMetrics = custom }, nil
+}
+
+// CollectCustomMetrics runs all sources concurrently and returns their results in source order
+func (cc *CustomCollector) CollectCustomMetrics(ctx context.Context) []types.CustomMetrics {
+	results := make([][]types.CustomMetrics, len(cc.sources))
+
+	var wg sync.WaitGroup
+	for i, source := range cc.sources {
+		wg.Add(1)
+		go func(i int, source CustomSource) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, cc.timeout)
+			defer cancel()
+
+			results[i] = source.Collect(ctx)
+		}(i, source)
+	}
+	wg.Wait()
+
+	var custom []types.CustomMetrics
+	for _, result := range results {
+		for _, metrics := range result {
+			if metrics.Error != "" {
+				slog.Warn("custom collector failed", "component", "custom", "source", metrics.Source, "error", metrics.Error)
+			}
+			custom = append(custom, metrics)
+		}
+	}
+	return custom
+}
+
+// TextfileSource reads the *.prom files of a node directory (node_exporter textfile convention)
+// Files must be written atomically (write to a temporary name then rename)
+type TextfileSource struct {
+	dir string
+}
+
+// NewTextfileSource creates a textfile source, dir is a path on the node
+func NewTextfileSource(dir string, devMode string) *TextfileSource {
+	return &TextfileSource{dir: filepath.Join(shared.GetHostRootPath(devMode), dir)}
+}
+
+func (s *TextfileSource) Collect(ctx context.Context) []types.CustomMetrics {
+	if _, err := os.Stat(s.dir); err != nil {
+		return []types.CustomMetrics{{Source: "textfile:" + s.dir, Error: fmt.Sprintf("error opening %s: %v", s.dir, err)}}
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.prom"))
+	if err != nil {
+		return []types.CustomMetrics{{Source: "textfile:" + s.dir, Error: fmt.Sprintf("error listing %s: %v", s.dir, err)}}
+	}
+	sort.Strings(files)
+
+	results := make([]types.CustomMetrics, 0, len(files))
+	for _, file := range files {
+		results = append(results, s.readFile(file))
+	}
+	return results
+}
+
+// readFile parses one .prom file and adds its modification time as a series
+func (s *TextfileSource) readFile(path string) (result types.CustomMetrics) {
+	start := time.Now()
+	name := filepath.Base(path)
+	result.Source = "textfile:" + name
+	defer func() {
+		result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	file, err := os.Open(path)
+	if err != nil {
+		result.Error = fmt.Sprintf("error opening %s: %v", path, err)
+		return result
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		result.Error = fmt.Sprintf("error reading %s: %v", path, err)
+		return result
+	}
+
+	samples, err := prometheus.ParseText(io.LimitReader(file, maxCustomOutputBytes))
+	if err != nil {
+		result.Error = fmt.Sprintf("error parsing %s: %v", name, err)
+		return result
+	}
+
+	result.Samples = limitSeries(&result, samples)
+	result.Samples = append(result.Samples, types.MetricSample{
+		Name:   textfileMtimeSeries,
+		Labels: map[string]string{"file": name},
+		Value:  float64(info.ModTime().Unix()),
+		Type:   "gauge",
+	})
+	return result
+}
+
+// ExecSource runs a command and parses its standard output
+// The command is executed directly (no shell) inside the agent container
+type ExecSource struct {
+	name string
+	args []string
+}
+
+// ParseExecSource parses a "name=command arg..." definition
+func ParseExecSource(definition string) (*ExecSource, error) {
+	name, command, found := strings.Cut(definition, "=")
+	name = strings.TrimSpace(name)
+	args := strings.Fields(command)
+	if !found || name == "" || len(args) == 0 {
+		return nil, fmt.Errorf("invalid exec collector %q (expected name=command args)", definition)
+	}
+	return &ExecSource{name: name, args: args}, nil
+}
+
+func (s *ExecSource) Collect(ctx context.Context) []types.CustomMetrics {
+	return []types.CustomMetrics{s.run(ctx)}
+}
+
+// run executes the command and parses its output
+func (s *ExecSource) run(ctx context.Context) (result types.CustomMetrics) {
+	start := time.Now()
+	result.Source = "exec:" + s.name
+	defer func() {
+		result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.args[0], s.args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children still holding the output pipes must not block past the timeout
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out")
+		}
+		result.Error = fmt.Sprintf("command failed: %v", err)
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			result.Error += ": " + firstLine(msg)
+		}
+		return result
+	}
+
+	samples, err := prometheus.ParseText(io.LimitReader(&stdout, maxCustomOutputBytes))
+	if err != nil {
+		result.Error = fmt.Sprintf("error parsing output: %v", err)
+		return result
+	}
+	result.Samples = limitSeries(&result, samples)
+	return result
+}
+
+// limitSeries truncates the samples of a source to maxCustomSeries
+func limitSeries(result *types.CustomMetrics, samples []types.MetricSample) []types.MetricSample {
+	if len(samples) > maxCustomSeries {
+		result.Error = fmt.Sprintf("series limit reached, %d of %d series forwarded", maxCustomSeries, len(samples))
+		return samples[:maxCustomSeries]
+	}
+	return samples
+}
+
+func firstLine(s string) string {
+	if line, _, found := strings.Cut(s, "\n"); found {
+		return line
+	}
+	return s
+}
@@ -0,0 +1,33 @@
+package collector
+
+import (
+	"sync/atomic"
+
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/config"
+)
+
+// Filters holds the pod, interface and device filters of the collectors, they are replaced
+// when the configuration file is reloaded
+type Filters struct {
+	current atomic.Pointer[config.Filters]
+}
+
+// NewFilters creates filters keeping every pod, interface and device
+func NewFilters() *Filters {
+	f := &Filters{}
+	f.current.Store(&config.Filters{})
+	return f
+}
+
+// Set replaces the filters, collections started before keep the previous filters
+func (f *Filters) Set(filters *config.Filters) {
+	if filters == nil {
+		filters = &config.Filters{}
+	}
+	f.current.Store(filters)
+}
+
+// Get returns the current filters
+func (f *Filters) Get() *config.Filters {
+	return f.current.Load()
+}
@@ -1,7 +1,8 @@
 package collector
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/ThomasCardin/gobservability/cmd/agent/internal"
@@ -13,72 +14,146 @@ type NodeCollector struct {
 	cache      *metrics.Cache
 	calculator *metrics.Calculator
 	devMode    string
+	filters    *Filters
 }
 
-func NewNodeCollector(cache *metrics.Cache, calculator *metrics.Calculator, devMode string) *NodeCollector {
+func NewNodeCollector(cache *metrics.Cache, calculator *metrics.Calculator, devMode string, filters *Filters) *NodeCollector {
 	return &NodeCollector{
 		cache:      cache,
 		calculator: calculator,
 		devMode:    devMode,
+		filters:    filters,
 	}
 }
 
-func (nc *NodeCollector) CollectNodeMetrics(nodeName string) (*types.NodeMetrics, error) {
+// Collectors returns the cpu, memory, network and disk collectors of the node
+func (nc *NodeCollector) Collectors() []Collector {
+	return []Collector{
+		&cpuCollector{nc},
+		&memoryCollector{nc},
+		&networkCollector{nc},
+		&diskCollector{nc},
+	}
+}
+
+// CollectCPU reads node CPU stats and calculates the usage since the previous reading
+func (nc *NodeCollector) CollectCPU(nodeName string) (*types.CPUStats, error) {
 	cpu, err := internal.ProcStat(nc.devMode)
 	if err != nil {
-		return nil, errors.New("failed to read CPU stats")
+		return nil, fmt.Errorf("failed to read CPU stats: %v", err)
+	}
+
+	// First collection - usage stays at 0
+	if prev, timestamp, hasPrev := nc.cache.UpdateNodeCPU(nodeName, cpu); hasPrev {
+		cpu.CPUPercent = nc.calculator.CalculateNodeCPUPercentage(cpu, prev, time.Since(timestamp))
 	}
 
+	return cpu, nil
+}
+
+// CollectMemory reads node memory stats
+func (nc *NodeCollector) CollectMemory() (*types.MemoryStats, error) {
 	memory, err := internal.ProcMeminfo(nc.devMode)
 	if err != nil {
-		return nil, errors.New("failed to read memory stats")
+		return nil, fmt.Errorf("failed to read memory stats: %v", err)
+	}
+	if memory.MemTotal == 0 {
+		return nil, fmt.Errorf("failed to read memory stats: MemTotal is 0")
 	}
 
-	network, err := internal.ProcNetDev(nc.devMode)
+	memory.MemoryPercent = float64(memory.MemTotal-memory.MemAvailable) / float64(memory.MemTotal) * 100.0
+
+	return memory, nil
+}
+
+// CollectNetwork reads node network stats and calculates the rates since the previous reading
+func (nc *NodeCollector) CollectNetwork(nodeName string) (*types.NetworkStats, error) {
+	network, err := internal.ProcNetDev(nc.devMode, nc.filters.Get().Interfaces.Match)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network stats: %v", err)
+	}
+
+	// First collection - rates stay at 0
+	if prev, timestamp, hasPrev := nc.cache.UpdateNodeNetwork(nodeName, network); hasPrev {
+		timeDelta := time.Since(timestamp)
+		network.RxRate = nc.calculator.CalculateNetworkRate(network.BytesReceived, prev.BytesReceived, timeDelta)
+		network.TxRate = nc.calculator.CalculateNetworkRate(network.BytesTransmitted, prev.BytesTransmitted, timeDelta)
+		network.TotalRate = network.RxRate + network.TxRate
+	}
+
+	return network, nil
+}
+
+// CollectDisk reads node disk stats and calculates the rates since the previous reading
+func (nc *NodeCollector) CollectDisk(nodeName string) (*types.DiskStats, error) {
+	disk, err := internal.ProcDiskstats(nc.devMode, nc.filters.Get().Devices.Match)
 	if err != nil {
-		return nil, errors.New("failed to read network stats")
+		return nil, fmt.Errorf("failed to read disk stats: %v", err)
 	}
 
-	disk, err := internal.ProcDiskstats(nc.devMode)
+	// First collection - rates stay at 0
+	if prev, timestamp, hasPrev := nc.cache.UpdateNodeDisk(nodeName, disk); hasPrev {
+		timeDelta := time.Since(timestamp)
+		disk.ReadRate = nc.calculator.CalculateDiskRateFromSectors(disk.SectorsRead, prev.SectorsRead, timeDelta)
+		disk.WriteRate = nc.calculator.CalculateDiskRateFromSectors(disk.SectorsWritten, prev.SectorsWritten, timeDelta)
+		disk.TotalRate = disk.ReadRate + disk.WriteRate
+	}
+
+	return disk, nil
+}
+
+type cpuCollector struct{ nc *NodeCollector }
+
+func (c *cpuCollector) Name() string                   { return "cpu" }
+func (c *cpuCollector) DefaultInterval() time.Duration { return 0 }
+func (c *cpuCollector) Capabilities() Capability       { return CapNode }
+
+func (c *cpuCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	cpu, err := c.nc.CollectCPU(nodeName)
 	if err != nil {
-		return nil, errors.New("failed to read disk stats")
+		return nil, err
 	}
+	return func(m *types.NodeMetrics) { m.CPU = cpu }, nil
+}
 
-	// Get previous metrics from cache
-	prev, hasPrev := nc.cache.UpdateNodeMetrics(nodeName, cpu, network, disk)
+type memoryCollector struct{ nc *NodeCollector }
 
-	// Calculate rates and percentages if we have previous data
-	if hasPrev && prev != nil {
-		timeDelta := time.Since(prev.Timestamp)
+func (c *memoryCollector) Name() string                   { return "memory" }
+func (c *memoryCollector) DefaultInterval() time.Duration { return 0 }
+func (c *memoryCollector) Capabilities() Capability       { return CapNode }
 
-		cpu.CPUPercent = nc.calculator.CalculateNodeCPUPercentage(cpu, prev.CPU, timeDelta)
+func (c *memoryCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	memory, err := c.nc.CollectMemory()
+	if err != nil {
+		return nil, err
+	}
+	return func(m *types.NodeMetrics) { m.Memory = memory }, nil
+}
 
-		network.RxRate = nc.calculator.CalculateNetworkRate(network.BytesReceived, prev.Network.BytesReceived, timeDelta)
-		network.TxRate = nc.calculator.CalculateNetworkRate(network.BytesTransmitted, prev.Network.BytesTransmitted, timeDelta)
-		network.TotalRate = network.RxRate + network.TxRate
+type networkCollector struct{ nc *NodeCollector }
 
-		disk.ReadRate = nc.calculator.CalculateDiskRateFromSectors(disk.SectorsRead, prev.Disk.SectorsRead, timeDelta)
-		disk.WriteRate = nc.calculator.CalculateDiskRateFromSectors(disk.SectorsWritten, prev.Disk.SectorsWritten, timeDelta)
-		disk.TotalRate = disk.ReadRate + disk.WriteRate
+func (c *networkCollector) Name() string                   { return "network" }
+func (c *networkCollector) DefaultInterval() time.Duration { return 0 }
+func (c *networkCollector) Capabilities() Capability       { return CapNode }
 
-		memory.MemoryPercent = float64(memory.MemTotal-memory.MemAvailable) / float64(memory.MemTotal) * 100.0
-	} else {
-		// First collection - set calculated values to 0
-		cpu.CPUPercent = 0
-		network.RxRate = 0
-		network.TxRate = 0
-		network.TotalRate = 0
-		disk.ReadRate = 0
-		disk.WriteRate = 0
-		disk.TotalRate = 0
-		memory.MemoryPercent = float64(memory.MemTotal-memory.MemAvailable) / float64(memory.MemTotal) * 100.0
+func (c *networkCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	network, err := c.nc.CollectNetwork(nodeName)
+	if err != nil {
+		return nil, err
 	}
+	return func(m *types.NodeMetrics) { m.Network = network }, nil
+}
 
-	return &types.NodeMetrics{
-		CPU:     cpu,
-		Memory:  memory,
-		Network: network,
-		Disk:    disk,
-		Pods:    nil, // Pods will be collected separately
-	}, nil
+type diskCollector struct{ nc *NodeCollector }
+
+func (c *diskCollector) Name() string                   { return "disk" }
+func (c *diskCollector) DefaultInterval() time.Duration { return 0 }
+func (c *diskCollector) Capabilities() Capability       { return CapNode }
+
+func (c *diskCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	disk, err := c.nc.CollectDisk(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	return func(m *types.NodeMetrics) { m.Disk = disk }, nil
 }
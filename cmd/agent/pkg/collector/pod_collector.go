@@ -1,27 +1,45 @@
 package collector
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/ThomasCardin/gobservability/cmd/agent/internal"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/kubernetes"
 	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/metrics"
 	"github.com/ThomasCardin/gobservability/shared/types"
 )
 
+// errPodTimeout is returned when the /proc reads of a pod did not finish before its deadline
+var errPodTimeout = errors.New("pod metrics collection timed out")
+
 type PodCollector struct {
 	cache      *metrics.Cache
 	calculator *metrics.Calculator
 	devMode    string
+	workers    int
+	podTimeout time.Duration
+	inFlight   map[int]int  // Reads running per PID, the pod and process collectors read the same PIDs
+	hung       map[int]bool // PIDs with a read still running after its deadline
+	mu         sync.Mutex
 }
 
-func NewPodCollector(cache *metrics.Cache, calculator *metrics.Calculator, devMode string) *PodCollector {
+// NewPodCollector creates a pod collector reading pods with a pool of workers,
+// the reads of a pod are abandoned after podTimeout
+func NewPodCollector(cache *metrics.Cache, calculator *metrics.Calculator, devMode string, workers int, podTimeout time.Duration) *PodCollector {
 	return &PodCollector{
 		cache:      cache,
 		calculator: calculator,
 		devMode:    devMode,
+		workers:    max(workers, 1),
+		podTimeout: podTimeout,
+		inFlight:   make(map[int]int),
+		hung:       make(map[int]bool),
 	}
 }
 
@@ -69,6 +87,9 @@ func (pc *PodCollector) CollectPodMetrics(pod *types.Pod, totalSystemMemoryKB ui
 		podMetrics.Memory.MemPercent = pc.calculator.CalculateMemoryPercentage(podMetrics.Memory.VmRSS, totalSystemMemoryKB)
 	}
 
+	// Storage spec (emptyDir size limits, ephemeral limit) comes from the kubernetes client
+	podMetrics.Storage = pc.collectPodStorage(pod, pod.PodMetrics.Storage)
+
 	// Update pod with calculated metrics
 	pod.PodMetrics = *podMetrics
 	pod.PidDetails = *pidDetails
@@ -76,22 +97,315 @@ func (pc *PodCollector) CollectPodMetrics(pod *types.Pod, totalSystemMemoryKB ui
 	return nil
 }
 
-func (pc *PodCollector) CollectAllPodMetrics(pods []*types.Pod, totalSystemMemoryKB uint64) error {
+// CollectAllPodMetrics collects the metrics of all pods with the worker pool
+// Pods not collected before their deadline or before ctx is done are marked partial
+func (pc *PodCollector) CollectAllPodMetrics(ctx context.Context, pods []*types.Pod, totalSystemMemoryKB uint64) error {
 	activePIDs := make([]int, 0, len(pods))
+	jobs := make(chan *types.Pod)
+
+	var wg sync.WaitGroup
+	for i := 0; i < pc.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pod := range jobs {
+				pc.collectPod(ctx, pod, totalSystemMemoryKB)
+			}
+		}()
+	}
 
 	for _, pod := range pods {
 		if pod.PID > 0 {
 			activePIDs = append(activePIDs, pod.PID)
-
-			if err := pc.CollectPodMetrics(pod, totalSystemMemoryKB); err != nil {
-				slog.Error("failed to collect metrics for pod", "pod", pod.Name, "pid", pod.PID, "error", err)
-				pod.PID = -1 // Mark as failed
-			}
+			jobs <- pod
 		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	// Ephemeral storage is accounted per pod, across all its containers
+	pc.aggregateEphemeralStorage(pods)
 
 	// Clean up stale cache entries
 	pc.cache.CleanupStaleEntries(activePIDs)
 
+	partial := 0
+	for _, pod := range pods {
+		if pod.Partial {
+			partial++
+		}
+	}
+	if partial > 0 {
+		return fmt.Errorf("%d of %d pods timed out", partial, len(activePIDs))
+	}
+
 	return nil
 }
+
+// collectPod collects the metrics of a pod, marking it partial on timeout and failed on error
+func (pc *PodCollector) collectPod(ctx context.Context, pod *types.Pod, totalSystemMemoryKB uint64) {
+	// The reads work on a copy, a pod abandoned after its deadline is never written
+	collected := *pod
+	err := pc.withDeadline(ctx, pod.PID, func() error {
+		return pc.CollectPodMetrics(&collected, totalSystemMemoryKB)
+	})
+
+	switch {
+	case errors.Is(err, errPodTimeout):
+		slog.Warn("pod metrics collection timed out", "component", "collector", "pod", pod.Name, "pid", pod.PID, "timeout", pc.podTimeout)
+		pod.Partial = true
+	case err != nil:
+		slog.Error("failed to collect metrics for pod", "pod", pod.Name, "pid", pod.PID, "error", err)
+		pod.PID = -1 // Mark as failed
+	default:
+		pod.PodMetrics = collected.PodMetrics
+		pod.PidDetails = collected.PidDetails
+	}
+}
+
+// withDeadline runs the /proc reads of a process in the background and stops waiting for them
+// after the pod timeout. Reads of a process in D state can block forever, once a read of a PID
+// missed its deadline no other read of the PID is started until the reads running have returned
+func (pc *PodCollector) withDeadline(ctx context.Context, pid int, read func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, pc.podTimeout)
+	defer cancel()
+
+	if ctx.Err() != nil {
+		return errPodTimeout
+	}
+
+	pc.mu.Lock()
+	if pc.hung[pid] {
+		pc.mu.Unlock()
+		return errPodTimeout
+	}
+	pc.inFlight[pid]++
+	pc.mu.Unlock()
+
+	// Buffered, the goroutine of an abandoned read exits when the read returns
+	done := make(chan error, 1)
+	go func() {
+		err := read()
+
+		pc.mu.Lock()
+		done <- err
+		pc.inFlight[pid]--
+		if pc.inFlight[pid] == 0 {
+			delete(pc.inFlight, pid)
+			delete(pc.hung, pid)
+		}
+		pc.mu.Unlock()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		pc.mu.Lock()
+		defer pc.mu.Unlock()
+		// The read may have returned while the deadline expired
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+		pc.hung[pid] = true
+		return errPodTimeout
+	}
+}
+
+// collectPodStorage collects volume usage from the kubelet pods directory and the
+// container writable layer
+func (pc *PodCollector) collectPodStorage(pod *types.Pod, spec types.PodStorageStats) types.PodStorageStats {
+	storage := types.PodStorageStats{
+		EphemeralLimitBytes: spec.EphemeralLimitBytes,
+	}
+
+	sizeLimits := make(map[string]uint64)
+	for _, volume := range spec.Volumes {
+		sizeLimits[volume.Name] = volume.CapacityBytes
+	}
+
+	if pod.UID != "" {
+		volumes, err := internal.ListPodVolumes(pc.devMode, pod.UID)
+		if err != nil {
+			slog.Debug("failed to list pod volumes", "component", "storage", "pod", pod.Name, "error", err)
+		}
+
+		for _, volume := range volumes {
+			usage, err := pc.volumeUsage(volume)
+			if err != nil {
+				slog.Debug("failed to read volume usage", "component", "storage", "pod", pod.Name, "volume", volume.Name, "error", err)
+				continue
+			}
+
+			if volume.Type == "empty-dir" {
+				if limit := sizeLimits[volume.Name]; limit > 0 {
+					usage.CapacityBytes = limit
+				}
+				// Memory-backed emptyDirs are tmpfs mounts and don't count as ephemeral storage
+				if !volume.Mounted {
+					storage.EphemeralUsedBytes += usage.UsedBytes
+				}
+			}
+
+			usage.UsagePercent = pc.calculator.CalculateStoragePercentage(usage.UsedBytes, usage.CapacityBytes)
+			storage.Volumes = append(storage.Volumes, usage)
+		}
+	}
+
+	layerPath, err := internal.ProcPIDWritableLayer(pc.devMode, pod.PID)
+	if err != nil {
+		slog.Debug("failed to find writable layer", "component", "storage", "pod", pod.Name, "pid", pod.PID, "error", err)
+		return storage
+	}
+
+	layer, err := pc.volumeUsage(internal.PodVolumeDir{Name: "rootfs", Path: layerPath})
+	if err != nil {
+		slog.Debug("failed to read writable layer usage", "component", "storage", "pod", pod.Name, "error", err)
+		return storage
+	}
+	storage.WritableLayerBytes = layer.UsedBytes
+	storage.WritableLayerInodes = layer.UsedInodes
+
+	return storage
+}
+
+// volumeUsage returns volume usage, reusing recent walks of non-mounted directories
+func (pc *PodCollector) volumeUsage(volume internal.PodVolumeDir) (types.VolumeStats, error) {
+	if volume.Mounted {
+		return internal.PodVolumeUsage(volume)
+	}
+
+	if cached, found := pc.cache.GetVolumeUsage(volume.Path); found {
+		return cached, nil
+	}
+
+	usage, err := internal.PodVolumeUsage(volume)
+	if err != nil {
+		return usage, err
+	}
+	pc.cache.SetVolumeUsage(volume.Path, usage)
+
+	return usage, nil
+}
+
+// aggregateEphemeralStorage adds the writable layers of every container of a pod to its
+// emptyDir usage and compares the total against the pod ephemeral-storage limit
+func (pc *PodCollector) aggregateEphemeralStorage(pods []*types.Pod) {
+	if isDev := os.Getenv(pc.devMode); isDev == "true" {
+		return
+	}
+
+	layers := make(map[string]uint64)
+	for _, pod := range pods {
+		if pod.PID > 0 && pod.UID != "" {
+			layers[pod.UID] += pod.PodMetrics.Storage.WritableLayerBytes
+		}
+	}
+
+	for _, pod := range pods {
+		if pod.PID <= 0 || pod.Partial || pod.UID == "" {
+			continue
+		}
+
+		storage := &pod.PodMetrics.Storage
+		storage.EphemeralUsedBytes += layers[pod.UID]
+		storage.EphemeralPercent = pc.calculator.CalculateStoragePercentage(storage.EphemeralUsedBytes, storage.EphemeralLimitBytes)
+	}
+}
+
+// podsCollector lists the pods of the node and collects their metrics
+type podsCollector struct {
+	pc        *PodCollector
+	k8sClient *kubernetes.Client
+	filters   *Filters
+}
+
+// NewPodsCollector creates the collector listing the pods of the node, the pod collectors
+// registered after it read the pods it found. Pods excluded by the namespace and pod filters
+// are not collected nor sent
+func NewPodsCollector(pc *PodCollector, k8sClient *kubernetes.Client, filters *Filters) Collector {
+	return &podsCollector{pc: pc, k8sClient: k8sClient, filters: filters}
+}
+
+func (c *podsCollector) Name() string                   { return "pods" }
+func (c *podsCollector) DefaultInterval() time.Duration { return 0 }
+func (c *podsCollector) Capabilities() Capability       { return CapPods }
+
+func (c *podsCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	pods, err := c.k8sClient.GetPodsForNode(ctx, nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods: %v", err)
+	}
+
+	filters := c.filters.Get()
+	kept := pods[:0]
+	for _, pod := range pods {
+		if filters.KeepPod(pod.Namespace, pod.Name) {
+			kept = append(kept, pod)
+		}
+	}
+	pods = kept
+
+	// Pod memory percentages need the memory collector
+	if err := c.pc.CollectAllPodMetrics(ctx, pods, uint64(metrics.Memory.MemTotal)); err != nil {
+		// Log error but continue - some pods may have succeeded
+		slog.Error("failed to collect pod metrics", "error", err)
+	}
+
+	return func(m *types.NodeMetrics) { m.Pods = pods }, nil
+}
+
+// processCollector is the detail tier of the pod processes collected at a slow interval:
+// command line, kernel stack, file descriptors, cgroups and /proc/{PID}/status details
+// The server requests the details of a single pod over the agent stream when they are
+// displayed, this collector only ships them for every pod when enabled
+type processCollector struct {
+	pc *PodCollector
+}
+
+// NewProcessCollector creates the collector of pod process details, reads share the
+// per-pod deadline of the pod collector
+func NewProcessCollector(pc *PodCollector) Collector {
+	return &processCollector{pc: pc}
+}
+
+func (c *processCollector) Name() string                   { return "process" }
+func (c *processCollector) DefaultInterval() time.Duration { return 5 * time.Minute }
+func (c *processCollector) Capabilities() Capability       { return CapPods | CapPrivileged }
+
+func (c *processCollector) Collect(ctx context.Context, nodeName string, metrics *types.NodeMetrics) (Apply, error) {
+	details := make(map[int]*types.PidDetails)
+
+	if isDev := os.Getenv(c.pc.devMode); isDev != "true" {
+		for _, pod := range metrics.Pods {
+			if pod.PID <= 0 || pod.Partial || details[pod.PID] != nil {
+				continue
+			}
+
+			var pidDetails *types.PidDetails
+			err := c.pc.withDeadline(ctx, pod.PID, func() error {
+				var err error
+				pidDetails, err = internal.CollectPidDetails(c.pc.devMode, pod.PID)
+				return err
+			})
+			if err != nil {
+				slog.Debug("failed to collect process details", "component", "process", "pod", pod.Name, "pid", pod.PID, "error", err)
+				continue
+			}
+			details[pod.PID] = pidDetails
+		}
+	}
+
+	return func(m *types.NodeMetrics) {
+		for _, pod := range m.Pods {
+			if pidDetails, found := details[pod.PID]; found {
+				// The summary tier is fresher than the details
+				summary := pod.PidDetails
+				pod.PidDetails = *pidDetails
+				pod.PidDetails.SetSummary(summary)
+			}
+		}
+	}, nil
+}
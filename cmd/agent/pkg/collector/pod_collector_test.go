@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/metrics"
+	"github.com/ThomasCardin/gobservability/cmd/agent/shared"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Name of an env var never set, the collector reads the /proc of the test process
+const testDevMode = "GOBSERVABILITY_TEST_DEV_MODE"
+
+func newTestPodCollector(workers int, podTimeout time.Duration) *PodCollector {
+	return NewPodCollector(metrics.NewCache(), metrics.NewCalculator(), testDevMode, workers, podTimeout)
+}
+
+func TestWithDeadlineConcurrentReads(t *testing.T) {
+	pc := newTestPodCollector(1, time.Second)
+
+	// The pod and process collectors read the same PID at the same time
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = pc.withDeadline(context.Background(), 42, func() error {
+				<-release
+				return nil
+			})
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("read %d: expected no error, got %v", i, err)
+		}
+	}
+}
+
+func TestWithDeadlineHungRead(t *testing.T) {
+	pc := newTestPodCollector(1, 50*time.Millisecond)
+
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	err := pc.withDeadline(context.Background(), 42, func() error {
+		defer close(returned)
+		<-release
+		return nil
+	})
+	if !errors.Is(err, errPodTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	// Retries are not started while the hung read is running
+	started := false
+	err = pc.withDeadline(context.Background(), 42, func() error {
+		started = true
+		return nil
+	})
+	if !errors.Is(err, errPodTimeout) || started {
+		t.Fatalf("expected the retry to be refused, got %v (started %v)", err, started)
+	}
+
+	// Other PIDs are not blocked
+	if err := pc.withDeadline(context.Background(), 43, func() error { return nil }); err != nil {
+		t.Fatalf("expected no error for another PID, got %v", err)
+	}
+
+	close(release)
+	<-returned
+	if err := pc.withDeadline(context.Background(), 42, func() error { return nil }); err != nil {
+		t.Fatalf("expected no error once the hung read returned, got %v", err)
+	}
+}
+
+// spawnPods starts a process per pod so that every pod reads its own /proc entries, the processes
+// are killed at the end of the benchmark
+func spawnPods(b *testing.B, count int) []types.Pod {
+	b.Helper()
+	pods := make([]types.Pod, count)
+	for i := range pods {
+		cmd := exec.Command("sleep", "3600")
+		if err := cmd.Start(); err != nil {
+			b.Skipf("cannot start the pod processes: %v", err)
+		}
+		b.Cleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+		pods[i] = types.Pod{
+			Name:      fmt.Sprintf("pod-%d", i),
+			Namespace: "bench",
+			UID:       fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+			PID:       cmd.Process.Pid,
+		}
+	}
+	return pods
+}
+
+func BenchmarkCollectAllPodMetrics(b *testing.B) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		b.Skip("/proc is not available")
+	}
+	shared.SetPaths("/proc", "/sys", b.TempDir())
+	defer shared.SetPaths("", "", "")
+	spawned := spawnPods(b, 500)
+
+	for _, workers := range []int{1, 8, 32} {
+		b.Run(fmt.Sprintf("pods=500/workers=%d", workers), func(b *testing.B) {
+			pc := newTestPodCollector(workers, 2*time.Second)
+			pods := make([]*types.Pod, len(spawned))
+			for i := 0; i < b.N; i++ {
+				for j := range spawned {
+					pod := spawned[j]
+					pods[j] = &pod
+				}
+				if err := pc.CollectAllPodMetrics(context.Background(), pods, 32*1024*1024); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
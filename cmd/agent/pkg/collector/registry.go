@@ -0,0 +1,527 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// maxPendingSamples bounds the samples kept until the next payload
+const maxPendingSamples = 600
+
+// entry is a registered collector with its configuration and last run
+type entry struct {
+	collector      Collector
+	enabled        bool
+	defaultEnabled bool
+	interval       time.Duration // 0 runs on every tick
+	lastRun        time.Time
+	apply          Apply
+	status         types.CollectorStatus
+	runs           uint64
+	errors         uint64
+}
+
+// CollectorMetrics are the counters of a collector exposed on the agent metrics endpoint
+type CollectorMetrics struct {
+	Name         string
+	Enabled      bool
+	Runs         uint64
+	Errors       uint64
+	LastDuration time.Duration
+}
+
+// Health is the state of the collection loop reported by the agent health endpoints
+type Health struct {
+	Tick           time.Duration
+	LastAttempt    time.Time // Zero before the first collection
+	LastCollection time.Time // Last collection that produced a payload
+	Collectors     []CollectorMetrics
+}
+
+// Registry runs the registered collectors on their own interval and sends the combined payload
+// Collectors run in registration order on the collection tick, so a collector can use the
+// results of the collectors registered before it (pod collectors after the pods collector)
+type Registry struct {
+	entries    []*entry
+	grpcClient GRPCSender
+	tick       time.Duration // Collection tick, set by Start or SetTick
+	tickReset  chan struct{}
+	collectNow chan struct{}      // Collection requested outside of the tick
+	sampleTick time.Duration      // High resolution sampling of the node collectors, 0 if disabled
+	samples    []types.NodeSample // Samples taken since the last payload
+	mu         sync.Mutex
+	health     Health     // Copied after every collection, collections hold mu for up to a tick
+	healthMu   sync.Mutex // Guards health
+}
+
+// NewRegistry creates an empty collector registry
+func NewRegistry(grpcClient GRPCSender) *Registry {
+	return &Registry{grpcClient: grpcClient, tickReset: make(chan struct{}, 1), collectNow: make(chan struct{}, 1)}
+}
+
+// Register adds an enabled collector with its default interval
+func (r *Registry) Register(collector Collector) {
+	r.register(collector, true)
+}
+
+// RegisterDisabled adds a collector disabled unless enabled by the configuration
+func (r *Registry) RegisterDisabled(collector Collector) {
+	r.register(collector, false)
+}
+
+func (r *Registry) register(collector Collector, enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, &entry{
+		collector:      collector,
+		enabled:        enabled,
+		defaultEnabled: enabled,
+		interval:       collector.DefaultInterval(),
+	})
+}
+
+// Names returns the names of the registered collectors
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, len(r.entries))
+	for i, e := range r.entries {
+		names[i] = e.collector.Name()
+	}
+	return names
+}
+
+// SetEnabled enables or disables a collector, a disabled collector stops contributing to the payload
+func (r *Registry) SetEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, err := r.find(name)
+	if err != nil {
+		return err
+	}
+	e.setEnabled(enabled)
+	return nil
+}
+
+func (e *entry) setEnabled(enabled bool) {
+	e.enabled = enabled
+	if !enabled {
+		e.apply = nil
+		e.lastRun = time.Time{}
+	}
+}
+
+// SetInterval changes the interval of a collector, 0 restores its default interval
+func (r *Registry) SetInterval(name string, interval time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, err := r.find(name)
+	if err != nil {
+		return err
+	}
+	if interval < 0 {
+		return fmt.Errorf("invalid interval %s for collector %s", interval, name)
+	}
+	if interval == 0 {
+		interval = e.collector.DefaultInterval()
+	}
+	e.interval = interval
+	return nil
+}
+
+// Configure sets the state of every collector at once: collectors missing from enabled and
+// intervals get their default state and interval. Nothing is changed if a name is unknown
+func (r *Registry) Configure(enabled map[string]bool, intervals map[string]time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name := range enabled {
+		if _, err := r.find(name); err != nil {
+			return err
+		}
+	}
+	for name, interval := range intervals {
+		if _, err := r.find(name); err != nil {
+			return err
+		}
+		if interval < 0 || (interval > 0 && interval < types.MinCollectInterval) {
+			return fmt.Errorf("invalid interval %s for collector %s, the minimum is %s", interval, name, types.MinCollectInterval)
+		}
+	}
+
+	for _, e := range r.entries {
+		name := e.collector.Name()
+
+		state, found := enabled[name]
+		if !found {
+			state = e.defaultEnabled
+		}
+		if state != e.enabled {
+			e.setEnabled(state)
+			slog.Info("collector toggled", "component", "collector", "collector", name, "enabled", state)
+		}
+
+		interval := intervals[name]
+		if interval == 0 {
+			interval = e.collector.DefaultInterval()
+		}
+		if interval != e.interval {
+			e.interval = interval
+			slog.Info("collector interval changed", "component", "collector", "collector", name, "interval", interval)
+		}
+	}
+
+	r.validate()
+	return nil
+}
+
+func (r *Registry) find(name string) (*entry, error) {
+	for _, e := range r.entries {
+		if e.collector.Name() == name {
+			return e, nil
+		}
+	}
+
+	names := make([]string, len(r.entries))
+	for i, e := range r.entries {
+		names[i] = e.collector.Name()
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown collector %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// validate warns about enabled collectors that need a disabled collector
+func (r *Registry) validate() {
+	podsEnabled := false
+	for _, e := range r.entries {
+		if e.collector.Name() == "pods" && e.enabled {
+			podsEnabled = true
+		}
+	}
+
+	for _, e := range r.entries {
+		if e.enabled && e.collector.Capabilities()&CapPods != 0 && e.collector.Name() != "pods" && !podsEnabled {
+			slog.Warn("collector needs the pods collector which is disabled", "component", "collector", "collector", e.collector.Name())
+		}
+	}
+}
+
+// CollectAll runs the collectors that are due and returns the payload combining the last result of every collector
+// Collectors stop waiting for slow reads when ctx is done
+func (r *Registry) CollectAll(ctx context.Context, nodeName string) (*types.NodeStatsPayload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	started := time.Now()
+
+	// A collection cycle never exceeds the tick, collectors stop waiting for slow reads
+	// at the deadline (a tenth of the tick is kept to send the payload)
+	if r.tick > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.tick-r.tick/10)
+		defer cancel()
+	}
+
+	// Node stats are never nil, a disabled node collector reports zero values
+	nodeMetrics := &types.NodeMetrics{
+		CPU:     &types.CPUStats{},
+		Memory:  &types.MemoryStats{},
+		Network: &types.NetworkStats{},
+		Disk:    &types.DiskStats{},
+	}
+
+	applied := 0
+	for _, e := range r.entries {
+		if !e.enabled {
+			continue
+		}
+
+		// Sampled collectors run on the sample tick, the payload gets their last sample
+		if e.due(r.tick) && (!r.sampled(e) || e.apply == nil) {
+			r.run(ctx, e, nodeName, nodeMetrics)
+		}
+		if e.apply != nil {
+			e.apply(nodeMetrics)
+			applied++
+		}
+	}
+	if applied == 0 {
+		return nil, fmt.Errorf("no collector produced metrics")
+	}
+
+	nodeMetrics.Collectors = r.statuses()
+
+	payload := &types.NodeStatsPayload{
+		NodeName:  nodeName,
+		Timestamp: time.Now(),
+		Metrics:   *nodeMetrics,
+		Samples:   r.samples,
+
+		CollectionMs: float64(time.Since(started)) / float64(time.Millisecond),
+	}
+	r.samples = nil
+
+	return payload, nil
+}
+
+// SetSampleInterval samples the node collectors every interval between two collection ticks,
+// 0 disables sampling. The samples are sent in a batch with the next payload. Set before Start
+func (r *Registry) SetSampleInterval(interval time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("invalid sample interval %s", interval)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sampleTick = interval
+	return nil
+}
+
+// sampled returns true if the collector runs on the sample tick, node level collectors only
+func (r *Registry) sampled(e *entry) bool {
+	return r.sampleTick > 0 && e.collector.Capabilities() == CapNode
+}
+
+// sample runs the node collectors and keeps their metrics until the next payload
+func (r *Registry) sample(ctx context.Context, nodeName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.sampleTick-r.sampleTick/10)
+	defer cancel()
+
+	nodeMetrics := &types.NodeMetrics{
+		CPU:     &types.CPUStats{},
+		Memory:  &types.MemoryStats{},
+		Network: &types.NetworkStats{},
+		Disk:    &types.DiskStats{},
+	}
+	for _, e := range r.entries {
+		if !e.enabled || !r.sampled(e) {
+			continue
+		}
+		r.run(ctx, e, nodeName, nodeMetrics)
+		if e.apply != nil {
+			e.apply(nodeMetrics)
+		}
+	}
+
+	// Samples pile up if payloads cannot be collected, the oldest are dropped
+	if len(r.samples) >= maxPendingSamples {
+		r.samples = r.samples[1:]
+	}
+	r.samples = append(r.samples, types.NodeSample{
+		Timestamp: time.Now(),
+		CPU:       nodeMetrics.CPU,
+		Memory:    nodeMetrics.Memory,
+		Network:   nodeMetrics.Network,
+		Disk:      nodeMetrics.Disk,
+	})
+}
+
+// run runs a collector and records its duration and error, the previous result is kept on error
+func (r *Registry) run(ctx context.Context, e *entry, nodeName string, nodeMetrics *types.NodeMetrics) {
+	start := time.Now()
+	apply, err := e.collector.Collect(ctx, nodeName, nodeMetrics)
+	duration := time.Since(start)
+
+	e.lastRun = start
+	e.runs++
+	e.status.LastRun = start
+	e.status.DurationMs = float64(duration.Microseconds()) / 1000
+	e.status.Error = ""
+
+	if err != nil {
+		e.errors++
+		e.status.Error = err.Error()
+		slog.Error("collector failed", "component", "collector", "collector", e.collector.Name(), "duration", duration, "error", err)
+		return
+	}
+	e.apply = apply
+}
+
+// due returns true if the collector interval elapsed since its last run
+// Half a tick of tolerance keeps a 30s collector on a 5s tick from drifting to 35s
+func (e *entry) due(tick time.Duration) bool {
+	return e.lastRun.IsZero() || time.Since(e.lastRun) >= e.interval-tick/2
+}
+
+// statuses returns the status of every registered collector
+func (r *Registry) statuses() []types.CollectorStatus {
+	statuses := make([]types.CollectorStatus, len(r.entries))
+	for i, e := range r.entries {
+		status := e.status
+		status.Name = e.collector.Name()
+		status.Enabled = e.enabled
+		status.IntervalMs = e.interval.Milliseconds()
+		status.Capabilities = e.collector.Capabilities().Strings()
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// SetTick changes the collection tick, before Start it overrides the Start interval
+func (r *Registry) SetTick(tick time.Duration) error {
+	r.mu.Lock()
+	if err := r.checkTick(tick); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	changed := r.tick != 0 && r.tick != tick // Start reads the tick set before it
+	r.tick = tick
+	r.mu.Unlock()
+
+	if changed {
+		select {
+		case r.tickReset <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// CheckTick returns an error if tick cannot be set as the collection interval
+func (r *Registry) CheckTick(tick time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.checkTick(tick)
+}
+
+// checkTick checks the minimum interval, and that the samples are still taken between payloads
+func (r *Registry) checkTick(tick time.Duration) error {
+	if tick < types.MinCollectInterval {
+		return fmt.Errorf("invalid collection interval %s, the minimum is %s", tick, types.MinCollectInterval)
+	}
+	if r.sampleTick > 0 && r.sampleTick >= tick {
+		return fmt.Errorf("collection interval %s not longer than the sample interval %s", tick, r.sampleTick)
+	}
+	return nil
+}
+
+// CollectNow collects and sends a payload without waiting for the next tick, the collectors that
+// are not due keep their last result. Requests made during a collection are merged
+func (r *Registry) CollectNow() {
+	select {
+	case r.collectNow <- struct{}{}:
+	default:
+	}
+}
+
+// Start collects and sends metrics on every interval tick, collectors with a longer interval
+// run on the first tick after their interval elapsed. It returns when ctx is done, the cycle
+// running at that time is aborted and its payload is not sent
+func (r *Registry) Start(ctx context.Context, nodeName string, interval time.Duration) {
+	r.mu.Lock()
+	if r.tick == 0 {
+		r.tick = interval
+	}
+	interval = r.tick
+	sampleTick := r.sampleTick
+	r.validate()
+	for _, e := range r.entries {
+		slog.Info("collector registered", "component", "collector", "collector", e.collector.Name(),
+			"enabled", e.enabled, "interval", e.interval, "capabilities", e.collector.Capabilities().String())
+	}
+	r.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// A nil channel never fires when sampling is disabled
+	var samples <-chan time.Time
+	if sampleTick > 0 {
+		sampleTicker := time.NewTicker(sampleTick)
+		defer sampleTicker.Stop()
+		samples = sampleTicker.C
+		slog.Info("high resolution sampling enabled", "component", "collector", "sample_interval", sampleTick, "interval", interval)
+	}
+
+	// Initial collection
+	r.collectAndSend(ctx, nodeName)
+
+	// Collection loop
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("collection stopped", "component", "collector", "node", nodeName)
+			return
+		case <-ticker.C:
+			r.collectAndSend(ctx, nodeName)
+		case <-r.collectNow:
+			slog.Info("collection requested", "component", "collector", "node", nodeName)
+			r.collectAndSend(ctx, nodeName)
+		case <-samples:
+			r.sample(ctx, nodeName)
+		case <-r.tickReset:
+			r.mu.Lock()
+			interval = r.tick
+			r.mu.Unlock()
+			ticker.Reset(interval)
+			slog.Info("collection interval changed", "component", "collector", "interval", interval)
+		}
+	}
+}
+
+// Health returns the state of the collection loop as of the last collection
+func (r *Registry) Health() Health {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	health := r.health
+	health.Collectors = append([]CollectorMetrics(nil), r.health.Collectors...)
+	return health
+}
+
+// recordHealth copies the collector counters for the health endpoints
+func (r *Registry) recordHealth(attempt time.Time, collected bool) {
+	r.mu.Lock()
+	tick := r.tick
+	collectors := make([]CollectorMetrics, len(r.entries))
+	for i, e := range r.entries {
+		collectors[i] = CollectorMetrics{
+			Name:         e.collector.Name(),
+			Enabled:      e.enabled,
+			Runs:         e.runs,
+			Errors:       e.errors,
+			LastDuration: time.Duration(e.status.DurationMs * float64(time.Millisecond)),
+		}
+	}
+	r.mu.Unlock()
+
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	r.health.Tick = tick
+	r.health.LastAttempt = attempt
+	if collected {
+		r.health.LastCollection = attempt
+	}
+	r.health.Collectors = collectors
+}
+
+func (r *Registry) collectAndSend(ctx context.Context, nodeName string) {
+	payload, err := r.CollectAll(ctx, nodeName)
+	if ctx.Err() != nil {
+		slog.Info("collection aborted by shutdown", "component", "collector", "node", nodeName)
+		return
+	}
+	r.recordHealth(time.Now(), err == nil)
+	if err != nil {
+		slog.Error("failed to collect metrics", "node", nodeName, "error", err)
+		return
+	}
+
+	if err := r.grpcClient.Send(payload); err != nil {
+		slog.Error("failed to send metrics via gRPC", "node", nodeName, "error", err)
+	}
+
+	slog.Info("sent metrics via gRPC", "component", "metrics", "node", nodeName)
+}
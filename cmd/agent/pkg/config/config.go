@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the agent configuration file, flags set on the command line take precedence
+type Config struct {
+	GRPCServer     string          `yaml:"grpcServer"` // Comma separated addresses, tried in order
+	Hostname       string          `yaml:"hostname"`
+	Interval       time.Duration   `yaml:"interval"`
+	SampleInterval time.Duration   `yaml:"sampleInterval"` // Node level samples batched with every payload, 0 disables them
+	Collectors     CollectorConfig `yaml:"collectors"`
+	Filters        FilterConfig    `yaml:"filters"`
+	Paths          PathConfig      `yaml:"paths"`
+	TLS            TLSConfig       `yaml:"tls"`
+	Buffer         BufferConfig    `yaml:"buffer"`
+}
+
+// CollectorConfig toggles collectors and overrides their default interval
+type CollectorConfig struct {
+	Enabled   []string                 `yaml:"enabled"`
+	Disabled  []string                 `yaml:"disabled"`
+	Intervals map[string]time.Duration `yaml:"intervals"`
+}
+
+// FilterConfig selects the pods, network interfaces and block devices collected
+type FilterConfig struct {
+	Namespaces Rule `yaml:"namespaces"`
+	Pods       Rule `yaml:"pods"`
+	Interfaces Rule `yaml:"interfaces"`
+	Devices    Rule `yaml:"devices"`
+}
+
+// Rule is a list of include and exclude regexes, a name is kept if it matches an include
+// regex (or no include regex is set) and no exclude regex
+type Rule struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// PathConfig overrides where the node /proc, /sys and root filesystem are mounted
+type PathConfig struct {
+	Proc string `yaml:"proc"`
+	Sys  string `yaml:"sys"`
+	Root string `yaml:"root"`
+}
+
+// TLSConfig configures the connection to the server
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"caFile"`
+	CertFile           string `yaml:"certFile"`
+	KeyFile            string `yaml:"keyFile"`
+	ServerName         string `yaml:"serverName"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+// BufferConfig limits the payloads kept while the server is unreachable
+type BufferConfig struct {
+	MaxPayloads  int    `yaml:"maxPayloads"`
+	MaxBytes     int64  `yaml:"maxBytes"`
+	Dir          string `yaml:"dir"` // Write-ahead log directory, payloads are only kept in memory if empty
+	MaxDiskBytes int64  `yaml:"maxDiskBytes"`
+}
+
+// Load reads and validates a configuration file, unknown keys are rejected
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %v", path, err)
+	}
+
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the configuration values, it does not check collector names which
+// are only known once the collectors are registered
+func (c *Config) Validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("interval must be positive, got %s", c.Interval)
+	}
+	if c.SampleInterval < 0 {
+		return fmt.Errorf("sampleInterval must be positive, got %s", c.SampleInterval)
+	}
+
+	for name, interval := range c.Collectors.Intervals {
+		if interval < 0 {
+			return fmt.Errorf("collectors.intervals.%s must be positive, got %s", name, interval)
+		}
+	}
+
+	if _, err := c.Filters.Compile(); err != nil {
+		return err
+	}
+
+	for key, path := range map[string]string{"proc": c.Paths.Proc, "sys": c.Paths.Sys, "root": c.Paths.Root} {
+		if path != "" && !filepath.IsAbs(path) {
+			return fmt.Errorf("paths.%s must be an absolute path, got %q", key, path)
+		}
+	}
+
+	if err := c.TLS.validate(); err != nil {
+		return err
+	}
+
+	if c.Buffer.MaxPayloads < 0 {
+		return fmt.Errorf("buffer.maxPayloads must be positive, got %d", c.Buffer.MaxPayloads)
+	}
+	if c.Buffer.MaxBytes < 0 {
+		return fmt.Errorf("buffer.maxBytes must be positive, got %d", c.Buffer.MaxBytes)
+	}
+	if c.Buffer.MaxDiskBytes < 0 {
+		return fmt.Errorf("buffer.maxDiskBytes must be positive, got %d", c.Buffer.MaxDiskBytes)
+	}
+	if c.Buffer.Dir != "" && !filepath.IsAbs(c.Buffer.Dir) {
+		return fmt.Errorf("buffer.dir must be an absolute path, got %q", c.Buffer.Dir)
+	}
+
+	return nil
+}
+
+func (t TLSConfig) validate() error {
+	if !t.Enabled {
+		if t.CAFile != "" || t.CertFile != "" || t.KeyFile != "" {
+			return errors.New("tls files are set but tls.enabled is false")
+		}
+		return nil
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("tls.certFile and tls.keyFile must be set together")
+	}
+	for key, path := range map[string]string{"caFile": t.CAFile, "certFile": t.CertFile, "keyFile": t.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("tls.%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// Matcher is a compiled Rule, a nil Matcher keeps every name
+type Matcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// Match returns true if the name is kept by the rule
+func (m *Matcher) Match(name string) bool {
+	if m == nil {
+		return true
+	}
+
+	for _, re := range m.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(m.include) == 0 {
+		return true
+	}
+	for _, re := range m.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) compile(key string) (*Matcher, error) {
+	if len(r.Include) == 0 && len(r.Exclude) == 0 {
+		return nil, nil
+	}
+
+	m := &Matcher{}
+	for _, list := range []struct {
+		name     string
+		patterns []string
+		compiled *[]*regexp.Regexp
+	}{{"include", r.Include, &m.include}, {"exclude", r.Exclude, &m.exclude}} {
+		for _, pattern := range list.patterns {
+			// Anchored so that "kube-system" does not match "not-kube-system"
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid filters.%s.%s regex %q: %v", key, list.name, pattern, err)
+			}
+			*list.compiled = append(*list.compiled, re)
+		}
+	}
+	return m, nil
+}
+
+// Filters are the compiled filters of the configuration
+type Filters struct {
+	Namespaces *Matcher
+	Pods       *Matcher
+	Interfaces *Matcher
+	Devices    *Matcher
+}
+
+// Compile compiles the filter regexes
+func (f FilterConfig) Compile() (*Filters, error) {
+	filters := &Filters{}
+	var err error
+	if filters.Namespaces, err = f.Namespaces.compile("namespaces"); err != nil {
+		return nil, err
+	}
+	if filters.Pods, err = f.Pods.compile("pods"); err != nil {
+		return nil, err
+	}
+	if filters.Interfaces, err = f.Interfaces.compile("interfaces"); err != nil {
+		return nil, err
+	}
+	if filters.Devices, err = f.Devices.compile("devices"); err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+// KeepPod returns true if the pod namespace and name are kept by the filters
+func (f *Filters) KeepPod(namespace, name string) bool {
+	return f.Namespaces.Match(namespace) && f.Pods.Match(name)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/ThomasCardin/gobservability/shared/certs"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Credentials returns the transport credentials of the server connection, plaintext if TLS is disabled
+// The CA and the client certificate are read again on each handshake once their files change
+func (t TLSConfig) Credentials() (credentials.TransportCredentials, error) {
+	if !t.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	reloader, err := certs.NewReloader(t.CertFile, t.KeyFile, t.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	// The server certificate is verified against the current CA in VerifyConnection, RootCAs
+	// being read once when the connection is created
+	if t.CAFile != "" && !t.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyServer(state, reloader.CAPool())
+		}
+	}
+
+	// Client certificate presented to the server (mTLS)
+	if t.CertFile != "" {
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return reloader.Certificate(), nil
+		}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// verifyServer verifies the server certificate chain and name like the default verification
+func verifyServer(state tls.ConnectionState, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server sent no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       state.ServerName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify server certificate: %v", err)
+	}
+	return nil
+}
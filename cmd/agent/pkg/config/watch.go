@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Watch reloads the configuration file when it changes or on SIGHUP and calls onReload with
+// the new configuration, an invalid file is logged and the running configuration is kept
+// The file is polled because Kubernetes updates mounted ConfigMaps by swapping a symlink
+func Watch(path string, pollInterval time.Duration, onReload func(*Config)) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastMod, lastSize := stat(path)
+	for {
+		select {
+		case <-hangup:
+			slog.Info("SIGHUP received, reloading config", "component", "config", "path", path)
+		case <-ticker.C:
+			mod, size := stat(path)
+			if mod.Equal(lastMod) && size == lastSize {
+				continue
+			}
+			slog.Info("config file changed, reloading", "component", "config", "path", path)
+		}
+
+		lastMod, lastSize = stat(path)
+		cfg, err := Load(path)
+		if err != nil {
+			slog.Error("failed to reload config, keeping the running config", "component", "config", "error", err)
+			continue
+		}
+		onReload(cfg)
+	}
+}
+
+func stat(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
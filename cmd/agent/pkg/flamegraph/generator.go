@@ -1,6 +1,7 @@
 package flamegraph
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/ThomasCardin/gobservability/shared/types"
@@ -25,8 +27,11 @@ func NewGenerator(devMode string) *Generator {
 	}
 }
 
-// GenerateFlamegraph generates a flamegraph for a specific PID
-func (g *Generator) GenerateFlamegraph(nodeName, podName string, duration int32, pid int) ([]byte, error) {
+// perfStopDelay is how long perf is given to exit after SIGINT before being killed
+const perfStopDelay = 5 * time.Second
+
+// GenerateFlamegraph generates a flamegraph for a specific PID, perf is stopped when ctx is done
+func (g *Generator) GenerateFlamegraph(ctx context.Context, nodeName, podName string, duration int32, pid int) ([]byte, error) {
 	if isDev := os.Getenv(g.devMode); isDev == "true" {
 		return []byte(fmt.Sprintf("Mock flamegraph data for node:%s pod:%s duration:%ds",
 			nodeName, podName, duration)), nil
@@ -41,16 +46,31 @@ func (g *Generator) GenerateFlamegraph(nodeName, podName string, duration int32,
 
 	// Generate perf data
 	perfDataFile := filepath.Join(tmpDir, "perf.data")
-	if err := g.recordPerfData(perfDataFile, duration, pid); err != nil {
+	if err := g.recordPerfData(ctx, perfDataFile, duration, pid); err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.New("perf recording aborted, agent stopping")
+		}
 		return nil, errors.New("failed to record perf data")
 	}
 
 	// Only generate JSON format
-	return g.generateJSONOutput(perfDataFile)
+	return g.generateJSONOutput(ctx, perfDataFile)
+}
+
+// perfCommand creates a perf command stopped when ctx is done: perf and the command it runs get
+// SIGINT so that perf exits cleanly, and are killed if still running after perfStopDelay
+func perfCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "perf", args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+	}
+	cmd.WaitDelay = perfStopDelay
+	return cmd
 }
 
 // recordPerfData records performance data using perf
-func (g *Generator) recordPerfData(outputFile string, duration int32, pid int) error {
+func (g *Generator) recordPerfData(ctx context.Context, outputFile string, duration int32, pid int) error {
 	if pid <= 0 {
 		return errors.New("invalid PID")
 	}
@@ -68,47 +88,34 @@ func (g *Generator) recordPerfData(outputFile string, duration int32, pid int) e
 
 	// Test if we can profile this process first
 	slog.Info("testing if PID can be profiled", "component", "flamegraph", "pid", pid)
-	testCmd := exec.Command("perf", "stat", "-p", strconv.Itoa(pid), "sleep", "1")
+	testCmd := perfCommand(ctx, "stat", "-p", strconv.Itoa(pid), "sleep", "1")
 	_, testErr := testCmd.CombinedOutput()
 	if testErr != nil {
 		return errors.New("process cannot be profiled")
 	}
 	slog.Info("PID can be profiled successfully", "component", "flamegraph", "pid", pid)
 
+	// Run with a longer timeout to allow perf to finish naturally
+	recordCtx, cancel := context.WithTimeout(ctx, time.Duration(duration*3+60)*time.Second)
+	defer cancel()
+
 	// Use very simple perf command that should work in containers
-	cmd := exec.Command("perf", "record", "-F", "99", "-p", strconv.Itoa(pid),
+	cmd := perfCommand(recordCtx, "record", "-F", "99", "-p", strconv.Itoa(pid),
 		"-g", "-o", outputFile, "sleep", strconv.Itoa(int(duration)))
 
 	slog.Info("running perf command", "component", "flamegraph", "pid", pid, "output_file", outputFile, "duration", duration)
 
-	// Run with a longer timeout to allow perf to finish naturally
-	// Use a channel to get the result or timeout
-	done := make(chan error, 1)
-
-	go func() {
-		// Start the command and capture output
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			done <- errors.New("perf record failed")
-		} else {
-			slog.Info("perf record completed", "component", "flamegraph", "output", string(output))
-			done <- nil
-		}
-	}()
-
-	// Wait for completion or timeout
-	select {
-	case err := <-done:
-		if err != nil {
-			return err
-		}
-	case <-time.After(time.Duration(duration*3+60) * time.Second):
-		// Kill the process if it's still running (only after a very long time)
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
+	output, err := cmd.CombinedOutput()
+	switch {
+	case ctx.Err() != nil:
+		slog.Info("perf record stopped, agent stopping", "component", "flamegraph", "pid", pid)
+		return ctx.Err()
+	case recordCtx.Err() != nil:
 		return errors.New("perf record timed out")
+	case err != nil:
+		return errors.New("perf record failed")
 	}
+	slog.Info("perf record completed", "component", "flamegraph", "output", string(output))
 
 	// Check if perf.data was created
 	info, err := os.Stat(outputFile)
@@ -121,9 +128,9 @@ func (g *Generator) recordPerfData(outputFile string, duration int32, pid int) e
 }
 
 // generateFoldedOutput returns the folded stack format
-func (g *Generator) generateFoldedOutput(perfDataFile string) ([]byte, error) {
+func (g *Generator) generateFoldedOutput(ctx context.Context, perfDataFile string) ([]byte, error) {
 	// Use perf script to get stack traces
-	cmd1 := exec.Command("perf", "script", "-i", perfDataFile)
+	cmd1 := perfCommand(ctx, "script", "-i", perfDataFile)
 	scriptOutput, err := cmd1.CombinedOutput()
 	if err != nil {
 		return nil, errors.New("perf script failed")
@@ -134,7 +141,7 @@ func (g *Generator) generateFoldedOutput(perfDataFile string) ([]byte, error) {
 	}
 
 	// Process with stackcollapse-perf.pl
-	cmd2 := exec.Command("stackcollapse-perf.pl")
+	cmd2 := exec.CommandContext(ctx, "stackcollapse-perf.pl")
 	cmd2.Stdin = strings.NewReader(string(scriptOutput))
 	foldedOutput, err := cmd2.CombinedOutput()
 	if err != nil {
@@ -156,9 +163,9 @@ type FlameNode struct {
 }
 
 // generateJSONOutput converts perf data to d3-flame-graph compatible JSON
-func (g *Generator) generateJSONOutput(perfDataFile string) ([]byte, error) {
+func (g *Generator) generateJSONOutput(ctx context.Context, perfDataFile string) ([]byte, error) {
 	// First get the folded stack trace data
-	foldedData, err := g.generateFoldedOutput(perfDataFile)
+	foldedData, err := g.generateFoldedOutput(ctx, perfDataFile)
 	if err != nil {
 		return nil, errors.New("failed to generate folded data")
 	}
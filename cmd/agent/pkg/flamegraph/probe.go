@@ -0,0 +1,77 @@
+package flamegraph
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	ENV_ENABLE_FLAMEGRAPH = "ENABLE_FLAMEGRAPH"
+
+	capSysAdmin = 21
+	capPerfmon  = 38
+)
+
+// Probe returns why flamegraphs cannot be generated, nil if they can
+func (g *Generator) Probe() error {
+	if os.Getenv(ENV_ENABLE_FLAMEGRAPH) == "false" {
+		return fmt.Errorf("disabled by %s", ENV_ENABLE_FLAMEGRAPH)
+	}
+	if isDev := os.Getenv(g.devMode); isDev == "true" {
+		return nil
+	}
+
+	for _, tool := range []string{"perf", "stackcollapse-perf.pl"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("%s not found in PATH", tool)
+		}
+	}
+	return nil
+}
+
+// ProbeKernelStacks returns why perf cannot record kernel frames, nil if it can
+// Kernel sampling requires CAP_SYS_ADMIN or CAP_PERFMON, or kernel.perf_event_paranoid <= 1
+func (g *Generator) ProbeKernelStacks() error {
+	effective, err := effectiveCapabilities()
+	if err != nil {
+		return err
+	}
+	if effective&(1<<capSysAdmin) != 0 || effective&(1<<capPerfmon) != 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile("/proc/sys/kernel/perf_event_paranoid")
+	if err != nil {
+		return fmt.Errorf("failed to read perf_event_paranoid: %v", err)
+	}
+	paranoid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("invalid perf_event_paranoid: %v", err)
+	}
+	if paranoid > 1 {
+		return fmt.Errorf("no CAP_SYS_ADMIN or CAP_PERFMON and kernel.perf_event_paranoid is %d", paranoid)
+	}
+	return nil
+}
+
+// effectiveCapabilities returns the effective capability set of the agent process
+func effectiveCapabilities() (uint64, error) {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read capabilities: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, found := strings.CutPrefix(scanner.Text(), "CapEff:"); found {
+			return strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		}
+	}
+	return 0, errors.New("CapEff not found in /proc/self/status")
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	grpcClient "github.com/ThomasCardin/gobservability/cmd/agent/grpc"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/buffer"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/collector"
+)
+
+const (
+	// readyTicks is the number of collection ticks without payload after which the agent is not ready
+	readyTicks = 3
+	// liveTicks is the number of collection ticks without collection attempt after which the
+	// collection loop is considered stuck and the agent restarted by its liveness probe
+	liveTicks = 5
+	// minLiveDelay keeps short intervals from failing the liveness probe on a slow collection
+	minLiveDelay = 1 * time.Minute
+)
+
+// Server serves the liveness, readiness and self-metrics endpoints of the agent
+type Server struct {
+	addr     string
+	nodeName string
+	registry *collector.Registry
+	client   *grpcClient.StreamingGRPCClient
+	buffer   *buffer.Buffer // nil if stats are not buffered
+	started  time.Time
+	server   *http.Server
+}
+
+// NewServer creates the health server of the agent listening on addr
+func NewServer(addr, nodeName string, registry *collector.Registry, client *grpcClient.StreamingGRPCClient, buf *buffer.Buffer) *Server {
+	return &Server{
+		addr:     addr,
+		nodeName: nodeName,
+		registry: registry,
+		client:   client,
+		buffer:   buf,
+		started:  time.Now(),
+	}
+}
+
+// Start listens on the TCP address and serves /healthz, /readyz and /metrics in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", s.addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	slog.Info("health server listening", "component", "health", "addr", listener.Addr().String())
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("health server stopped", "component", "health", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	if s.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		s.server.Shutdown(ctx)
+	}
+}
+
+// handleHealthz fails when the collection loop stopped running, the agent must be restarted
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	health := s.registry.Health()
+
+	last := health.LastAttempt
+	if last.IsZero() {
+		last = s.started
+	}
+	limit := max(liveTicks*health.Tick, minLiveDelay)
+	if age := time.Since(last); age > limit {
+		writeStatus(w, http.StatusServiceUnavailable, fmt.Sprintf("no collection for %s", age.Round(time.Second)))
+		return
+	}
+	writeStatus(w, http.StatusOK, "ok")
+}
+
+// handleReadyz fails while the stream to the server is down or no payload was collected recently
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	var reasons []string
+	if connection := s.client.ConnectionStatus(); !connection.Connected {
+		reason := "not connected to " + connection.Endpoint
+		if connection.LastError != "" {
+			reason += ": " + connection.LastError
+		}
+		reasons = append(reasons, reason)
+	}
+
+	health := s.registry.Health()
+	switch {
+	case health.LastCollection.IsZero():
+		reasons = append(reasons, "no collection yet")
+	case time.Since(health.LastCollection) > readyTicks*health.Tick:
+		reasons = append(reasons, fmt.Sprintf("last collection %s ago", time.Since(health.LastCollection).Round(time.Second)))
+	}
+
+	if len(reasons) > 0 {
+		writeStatus(w, http.StatusServiceUnavailable, strings.Join(reasons, "\n"))
+		return
+	}
+	writeStatus(w, http.StatusOK, "ok")
+}
+
+func writeStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	fmt.Fprintln(w, message)
+}
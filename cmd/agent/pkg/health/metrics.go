@@ -0,0 +1,150 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	grpcClient "github.com/ThomasCardin/gobservability/cmd/agent/grpc"
+)
+
+// handleMetrics exposes the cost and state of the agent in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	health := s.registry.Health()
+	connection := s.client.ConnectionStatus()
+	payloads := s.client.PayloadStats()
+
+	m := &metricsWriter{w: w}
+	m.gauge("gobservability_agent_info", "Agent version and node", 1,
+		"version", grpcClient.AgentVersion, "node", s.nodeName)
+	m.gauge("gobservability_agent_start_time_seconds", "Start time of the agent", unixSeconds(s.started))
+
+	m.header("gobservability_agent_collector_enabled", "gauge", "Whether the collector is enabled")
+	for _, c := range health.Collectors {
+		m.sample("gobservability_agent_collector_enabled", boolValue(c.Enabled), "collector", c.Name)
+	}
+	m.header("gobservability_agent_collector_runs_total", "counter", "Runs of the collector")
+	for _, c := range health.Collectors {
+		m.sample("gobservability_agent_collector_runs_total", float64(c.Runs), "collector", c.Name)
+	}
+	m.header("gobservability_agent_collector_errors_total", "counter", "Failed runs of the collector")
+	for _, c := range health.Collectors {
+		m.sample("gobservability_agent_collector_errors_total", float64(c.Errors), "collector", c.Name)
+	}
+	m.header("gobservability_agent_collector_duration_seconds", "gauge", "Duration of the last run of the collector")
+	for _, c := range health.Collectors {
+		m.sample("gobservability_agent_collector_duration_seconds", c.LastDuration.Seconds(), "collector", c.Name)
+	}
+	m.gauge("gobservability_agent_collection_interval_seconds", "Collection tick", health.Tick.Seconds())
+	m.gauge("gobservability_agent_last_collection_timestamp_seconds", "Time of the last collection that produced a payload", unixSeconds(health.LastCollection))
+
+	m.counter("gobservability_agent_payloads_sent_total", "Stats payloads sent to the server, replayed payloads included", float64(payloads.Sent))
+	m.counter("gobservability_agent_payload_bytes_total", "Encoded size of the stats payloads sent, before compression", float64(payloads.Bytes))
+	m.gauge("gobservability_agent_last_payload_bytes", "Encoded size of the last stats payload sent", float64(payloads.LastBytes))
+
+	m.gauge("gobservability_agent_connected", "Whether the stream to the server is established", boolValue(connection.Connected),
+		"endpoint", connection.Endpoint)
+	m.counter("gobservability_agent_reconnects_total", "Reconnections to the server", float64(connection.Reconnects))
+	m.counter("gobservability_agent_disconnected_seconds_total", "Time spent disconnected from the server", float64(connection.DisconnectedMs)/1000)
+
+	if s.buffer != nil {
+		buffer := s.buffer.Status()
+		m.gauge("gobservability_agent_buffer_payloads", "Payloads waiting to be replayed", float64(buffer.Payloads))
+		m.gauge("gobservability_agent_buffer_bytes", "Size of the payloads buffered in memory", float64(buffer.Bytes))
+		m.gauge("gobservability_agent_buffer_disk_bytes", "Size of the write-ahead log", float64(buffer.DiskBytes))
+		m.counter("gobservability_agent_buffer_dropped_total", "Payloads dropped because a buffer limit was reached", float64(buffer.Dropped))
+		m.counter("gobservability_agent_buffer_replayed_total", "Payloads replayed after reconnecting", float64(buffer.Replayed))
+	}
+
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err == nil {
+		cpu := time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+		m.counter("process_cpu_seconds_total", "User and system CPU time of the agent", cpu.Seconds())
+	}
+	if rss, err := residentMemory(); err == nil {
+		m.gauge("process_resident_memory_bytes", "Resident memory of the agent", float64(rss))
+	}
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	m.gauge("go_memstats_heap_inuse_bytes", "Heap in use by the agent", float64(memory.HeapInuse))
+	m.gauge("go_goroutines", "Goroutines of the agent", float64(runtime.NumGoroutine()))
+}
+
+// residentMemory reads the resident set size of the agent from /proc/self/statm
+func residentMemory() (uint64, error) {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("invalid /proc/self/statm")
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return pages * uint64(os.Getpagesize()), nil
+}
+
+// labelEscaper escapes label values as required by the text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsWriter writes series in the Prometheus text format
+type metricsWriter struct {
+	w io.Writer
+}
+
+func (m *metricsWriter) header(name, kind, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a series, labels are name and value pairs
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	if len(labels) == 0 {
+		fmt.Fprintf(m.w, "%s %s\n", name, formatValue(value))
+		return
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+	}
+	fmt.Fprintf(m.w, "%s{%s} %s\n", name, strings.Join(pairs, ","), formatValue(value))
+}
+
+func (m *metricsWriter) gauge(name, help string, value float64, labels ...string) {
+	m.header(name, "gauge", help)
+	m.sample(name, value, labels...)
+}
+
+func (m *metricsWriter) counter(name, help string, value float64, labels ...string) {
+	m.header(name, "counter", help)
+	m.sample(name, value, labels...)
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// unixSeconds returns the time in seconds since the epoch, 0 for the zero time
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
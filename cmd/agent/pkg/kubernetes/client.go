@@ -1,6 +1,8 @@
 package kubernetes
 
 import (
+	"context"
+
 	"github.com/ThomasCardin/gobservability/shared/types"
 )
 
@@ -17,6 +19,6 @@ func NewClient(devMode string) *Client {
 }
 
 // GetPodsForNode returns pods for a specific node
-func (c *Client) GetPodsForNode(nodeName string) ([]*types.Pod, error) {
-	return GetPodsPID(c.devMode, nodeName)
+func (c *Client) GetPodsForNode(ctx context.Context, nodeName string) ([]*types.Pod, error) {
+	return GetPodsPID(ctx, c.devMode, nodeName)
 }
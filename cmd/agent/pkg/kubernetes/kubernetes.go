@@ -18,7 +18,9 @@ import (
 	"k8s.io/client-go/rest"
 )
 
-func GetPodsPID(devMode, nodeName string) ([]*types.Pod, error) {
+// GetPodsPID lists the pods of the node and resolves the PID of their containers, the list is
+// canceled when ctx is done
+func GetPodsPID(ctx context.Context, devMode, nodeName string) ([]*types.Pod, error) {
 	if isDev := os.Getenv(devMode); isDev == "true" {
 		return generateFakePods(nodeName), nil
 	}
@@ -34,7 +36,7 @@ func GetPodsPID(devMode, nodeName string) ([]*types.Pod, error) {
 		return nil, err
 	}
 
-	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
 		FieldSelector: "spec.nodeName=" + nodeName,
 	})
 	if err != nil {
@@ -46,14 +48,20 @@ func GetPodsPID(devMode, nodeName string) ([]*types.Pod, error) {
 		// Get resource limits and requests
 		resourceLimits := getResourceLimits(pod.Spec.Containers)
 		resourceRequests := getResourceRequests(pod.Spec.Containers)
+		storageSpec := getStorageSpec(pod.Spec)
 
 		// Get containerid
 		containerIDs, err := getContainerID(pod.Name, pod.Status.ContainerStatuses)
 		if err != nil {
 			result = append(result, &types.Pod{
 				Name:             pod.Name,
+				Namespace:        pod.Namespace,
+				UID:              string(pod.UID),
+				IP:               pod.Status.PodIP,
+				Annotations:      pod.Annotations,
 				ContainerID:      "Not found",
 				PID:              -1,
+				PodMetrics:       types.PodMetrics{Storage: storageSpec},
 				ResourceLimits:   resourceLimits,
 				ResourceRequests: resourceRequests,
 			})
@@ -66,10 +74,14 @@ func GetPodsPID(devMode, nodeName string) ([]*types.Pod, error) {
 			if err != nil {
 				result = append(result, &types.Pod{
 					Name:             pod.Name,
+					Namespace:        pod.Namespace,
+					UID:              string(pod.UID),
+					IP:               pod.Status.PodIP,
+					Annotations:      pod.Annotations,
 					ContainerID:      containerID,
 					PID:              -1,
-					PodMetrics:       types.PodMetrics{}, // Empty metrics for failed pods
-					PidDetails:       types.PidDetails{}, // Empty details for failed pods
+					PodMetrics:       types.PodMetrics{Storage: storageSpec}, // Empty metrics for failed pods
+					PidDetails:       types.PidDetails{},                     // Empty details for failed pods
 					ResourceLimits:   resourceLimits,
 					ResourceRequests: resourceRequests,
 				})
@@ -79,16 +91,25 @@ func GetPodsPID(devMode, nodeName string) ([]*types.Pod, error) {
 				if metricsErr != nil {
 					result = append(result, &types.Pod{
 						Name:             pod.Name,
+						Namespace:        pod.Namespace,
+						UID:              string(pod.UID),
+						IP:               pod.Status.PodIP,
+						Annotations:      pod.Annotations,
 						ContainerID:      containerID,
 						PID:              pid,
-						PodMetrics:       types.PodMetrics{},
+						PodMetrics:       types.PodMetrics{Storage: storageSpec},
 						PidDetails:       types.PidDetails{},
 						ResourceLimits:   resourceLimits,
 						ResourceRequests: resourceRequests,
 					})
 				} else {
+					podMetrics.Storage = storageSpec
 					result = append(result, &types.Pod{
 						Name:             pod.Name,
+						Namespace:        pod.Namespace,
+						UID:              string(pod.UID),
+						IP:               pod.Status.PodIP,
+						Annotations:      pod.Annotations,
 						ContainerID:      containerID,
 						PID:              pid,
 						PodMetrics:       *podMetrics,
@@ -174,7 +195,7 @@ func getPID(devMode, containerID string) (int, error) {
 
 // getResourceLimits extracts resource limits from pod containers
 func getResourceLimits(containers []v1.Container) types.ResourceInfo {
-	var totalCPU, totalMemory string
+	var totalCPU, totalMemory, totalEphemeral string
 
 	for _, container := range containers {
 		if container.Resources.Limits != nil {
@@ -203,6 +224,19 @@ func getResourceLimits(containers []v1.Container) types.ResourceInfo {
 					}
 				}
 			}
+
+			// Get ephemeral storage limits
+			if ephemeral, ok := container.Resources.Limits[v1.ResourceEphemeralStorage]; ok {
+				ephemeralStr := ephemeral.String()
+				if ephemeralStr != "" && ephemeralStr != "0" {
+					if totalEphemeral == "" {
+						totalEphemeral = ephemeralStr
+					} else {
+						// If multiple containers, concatenate values
+						totalEphemeral = totalEphemeral + "+" + ephemeralStr
+					}
+				}
+			}
 		}
 	}
 
@@ -213,16 +247,20 @@ func getResourceLimits(containers []v1.Container) types.ResourceInfo {
 	if totalMemory == "" {
 		totalMemory = "∞"
 	}
+	if totalEphemeral == "" {
+		totalEphemeral = "∞"
+	}
 
 	return types.ResourceInfo{
-		CPU:    totalCPU,
-		Memory: totalMemory,
+		CPU:              totalCPU,
+		Memory:           totalMemory,
+		EphemeralStorage: totalEphemeral,
 	}
 }
 
 // getResourceRequests extracts resource requests from pod containers
 func getResourceRequests(containers []v1.Container) types.ResourceInfo {
-	var totalCPU, totalMemory string
+	var totalCPU, totalMemory, totalEphemeral string
 
 	for _, container := range containers {
 		if container.Resources.Requests != nil {
@@ -251,6 +289,19 @@ func getResourceRequests(containers []v1.Container) types.ResourceInfo {
 					}
 				}
 			}
+
+			// Get ephemeral storage requests
+			if ephemeral, ok := container.Resources.Requests[v1.ResourceEphemeralStorage]; ok {
+				ephemeralStr := ephemeral.String()
+				if ephemeralStr != "" && ephemeralStr != "0" {
+					if totalEphemeral == "" {
+						totalEphemeral = ephemeralStr
+					} else {
+						// If multiple containers, concatenate values
+						totalEphemeral = totalEphemeral + "+" + ephemeralStr
+					}
+				}
+			}
 		}
 	}
 
@@ -261,9 +312,42 @@ func getResourceRequests(containers []v1.Container) types.ResourceInfo {
 	if totalMemory == "" {
 		totalMemory = "∞"
 	}
+	if totalEphemeral == "" {
+		totalEphemeral = "∞"
+	}
 
 	return types.ResourceInfo{
-		CPU:    totalCPU,
-		Memory: totalMemory,
+		CPU:              totalCPU,
+		Memory:           totalMemory,
+		EphemeralStorage: totalEphemeral,
 	}
 }
+
+// getStorageSpec extracts emptyDir size limits and the pod ephemeral-storage limit.
+// Usage is filled in later by the pod collector.
+func getStorageSpec(spec v1.PodSpec) types.PodStorageStats {
+	var storage types.PodStorageStats
+
+	for _, container := range spec.Containers {
+		if ephemeral, ok := container.Resources.Limits[v1.ResourceEphemeralStorage]; ok {
+			storage.EphemeralLimitBytes += uint64(ephemeral.Value())
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.EmptyDir == nil {
+			continue
+		}
+
+		stats := types.VolumeStats{
+			Name: volume.Name,
+			Type: "empty-dir",
+		}
+		if volume.EmptyDir.SizeLimit != nil {
+			stats.CapacityBytes = uint64(volume.EmptyDir.SizeLimit.Value())
+		}
+		storage.Volumes = append(storage.Volumes, stats)
+	}
+
+	return storage
+}
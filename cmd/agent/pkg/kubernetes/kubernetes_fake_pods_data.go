@@ -7,6 +7,8 @@ func generateFakePods(nodeName string) []*types.Pod {
 	fakePods := []*types.Pod{
 		{
 			Name:        "nginx-deployment-abc123",
+			Namespace:   "default",
+			UID:         "6f1c2a4e-0001-4b7e-9a10-5d2f8c0e1a01",
 			ContainerID: "docker://1234567890abcdef",
 			PID:         1,
 			ResourceLimits: types.ResourceInfo{
@@ -78,6 +80,8 @@ func generateFakePods(nodeName string) []*types.Pod {
 		},
 		{
 			Name:        "redis-server-xyz789",
+			Namespace:   "default",
+			UID:         "6f1c2a4e-0002-4b7e-9a10-5d2f8c0e1a02",
 			ContainerID: "containerd://fedcba0987654321",
 			PID:         1,
 			ResourceLimits: types.ResourceInfo{
@@ -143,11 +147,14 @@ func generateFakePods(nodeName string) []*types.Pod {
 		},
 		{
 			Name:        "api-service-def456",
+			Namespace:   "production",
+			UID:         "6f1c2a4e-0003-4b7e-9a10-5d2f8c0e1a03",
 			ContainerID: "docker://abcdef1234567890",
 			PID:         1,
 			ResourceLimits: types.ResourceInfo{
-				CPU:    "2",
-				Memory: "2Gi",
+				CPU:              "2",
+				Memory:           "2Gi",
+				EphemeralStorage: "2Gi",
 			},
 			ResourceRequests: types.ResourceInfo{
 				CPU:    "500m",
@@ -172,6 +179,29 @@ func generateFakePods(nodeName string) []*types.Pod {
 					ReadBytes:  20480000, // 20MB
 					WriteBytes: 15360000, // 15MB
 				},
+				Storage: types.PodStorageStats{
+					Volumes: []types.VolumeStats{
+						{
+							Name:          "cache",
+							Type:          "empty-dir",
+							UsedBytes:     858993459, // 819MB
+							UsedInodes:    1420,
+							CapacityBytes: 1073741824, // 1Gi sizeLimit
+							UsagePercent:  80.0,
+						},
+						{
+							Name:       "kube-api-access-x7k2p",
+							Type:       "projected",
+							UsedBytes:  12288,
+							UsedInodes: 9,
+						},
+					},
+					WritableLayerBytes:  104857600, // 100MB
+					WritableLayerInodes: 340,
+					EphemeralUsedBytes:  963851059,  // emptyDir + writable layer
+					EphemeralLimitBytes: 2147483648, // 2Gi
+					EphemeralPercent:    44.9,
+				},
 			},
 			PidDetails: types.PidDetails{
 				Name:               "node",
@@ -212,6 +242,8 @@ func generateFakePods(nodeName string) []*types.Pod {
 		},
 		{
 			Name:        "postgres-db-ghi789",
+			Namespace:   "production",
+			UID:         "6f1c2a4e-0004-4b7e-9a10-5d2f8c0e1a04",
 			ContainerID: "containerd://567890abcdef1234",
 			PID:         1,
 			ResourceLimits: types.ResourceInfo{
@@ -241,6 +273,21 @@ func generateFakePods(nodeName string) []*types.Pod {
 					ReadBytes:  102400000, // 100MB
 					WriteBytes: 51200000,  // 50MB
 				},
+				Storage: types.PodStorageStats{
+					Volumes: []types.VolumeStats{
+						{
+							Name:          "pvc-3f9b6c1e-8d2a-4c55-b0e7-2a1d9f4c7e10",
+							Type:          "csi",
+							UsedBytes:     9663676416, // 9Gi
+							UsedInodes:    2841,
+							CapacityBytes: 10737418240, // 10Gi
+							UsagePercent:  90.0,
+						},
+					},
+					WritableLayerBytes:  52428800, // 50MB
+					WritableLayerInodes: 120,
+					EphemeralUsedBytes:  52428800,
+				},
 			},
 			PidDetails: types.PidDetails{
 				Name:               "postgres",
@@ -280,6 +327,8 @@ func generateFakePods(nodeName string) []*types.Pod {
 		},
 		{
 			Name:        "failing-pod-error",
+			Namespace:   "default",
+			UID:         "6f1c2a4e-0005-4b7e-9a10-5d2f8c0e1a05",
 			ContainerID: "Not found",
 			PID:         -1,
 			ResourceLimits: types.ResourceInfo{
@@ -295,6 +344,8 @@ func generateFakePods(nodeName string) []*types.Pod {
 		},
 		{
 			Name:        "partial-pod-test",
+			Namespace:   "default",
+			UID:         "6f1c2a4e-0006-4b7e-9a10-5d2f8c0e1a06",
 			ContainerID: "docker://errorcontainer123",
 			PID:         -1,
 			ResourceLimits: types.ResourceInfo{
@@ -0,0 +1,99 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// ParseCRILine parses a line written by the container runtime:
+// "<RFC3339Nano timestamp> <stdout|stderr> <P|F> <message>"
+// partial is true when the runtime split a long line (tag P)
+func ParseCRILine(raw string) (line types.LogLine, partial bool, err error) {
+	raw = strings.TrimRight(raw, "\r\n")
+
+	parts := strings.SplitN(raw, " ", 4)
+	if len(parts) < 3 {
+		return types.LogLine{}, false, fmt.Errorf("invalid CRI log line: %q", raw)
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return types.LogLine{}, false, fmt.Errorf("invalid CRI log timestamp %q: %v", parts[0], err)
+	}
+
+	line = types.LogLine{
+		Timestamp: ts,
+		Stream:    parts[1],
+	}
+	if len(parts) == 4 {
+		line.Message = parts[3]
+	}
+
+	// The tag field may contain several flags separated by ':', P/F is always first
+	return line, strings.HasPrefix(parts[2], "P"), nil
+}
+
+// assembler joins partial CRI lines back into full log lines
+type assembler struct {
+	container string
+	pending   *types.LogLine
+}
+
+// add parses a raw line and returns the full log line once complete
+func (a *assembler) add(raw string) (types.LogLine, bool) {
+	line, partial, err := ParseCRILine(raw)
+	if err != nil {
+		// Keep unparsable lines rather than dropping them
+		line = types.LogLine{Timestamp: time.Now(), Message: strings.TrimRight(raw, "\r\n")}
+	}
+	line.Container = a.container
+
+	if a.pending != nil {
+		a.pending.Message += line.Message
+		line = *a.pending
+		a.pending = nil
+	}
+
+	if partial {
+		a.pending = &line
+		return types.LogLine{}, false
+	}
+	return line, true
+}
+
+// CurrentLogFile returns the log file of the latest container restart (<restart>.log)
+func CurrentLogFile(containerDir string) (string, error) {
+	entries, err := os.ReadDir(containerDir)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %v", containerDir, err)
+	}
+
+	latest := -1
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".log") {
+			continue
+		}
+
+		// Rotated files (N.log.<date>, N.log.<date>.gz) are ignored
+		restart, err := strconv.Atoi(strings.TrimSuffix(name, ".log"))
+		if err != nil {
+			continue
+		}
+		if restart > latest {
+			latest = restart
+		}
+	}
+
+	if latest < 0 {
+		return "", errors.New("no log file found in " + containerDir)
+	}
+	return filepath.Join(containerDir, strconv.Itoa(latest)+".log"), nil
+}
@@ -0,0 +1,260 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Number of one-second buckets kept for the per-minute match count
+const patternWindowSeconds = 60
+
+// Delays before following the logs of a pod again after the follower failed, e.g. because the
+// kubelet did not create the log directory of the pod yet. Doubled on every consecutive failure
+const (
+	patternRetryMin = 5 * time.Second
+	patternRetryMax = 5 * time.Minute
+)
+
+// patternWatch follows the logs of one pod and counts the lines matching a rule
+type patternWatch struct {
+	rule    types.LogPatternRule
+	regex   *regexp.Regexp
+	podUID  string
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	buckets [patternWindowSeconds]uint64
+	seconds [patternWindowSeconds]int64 // Unix second of each bucket
+	total   uint64
+	err     string
+	started time.Time     // Start of the current follower
+	failed  bool          // The follower ended before the watch was cancelled
+	delay   time.Duration // Delay before the next follower after a failure
+	retryAt time.Time
+}
+
+// record counts matching lines in the bucket of the current second
+func (w *patternWatch) record(lines []types.LogLine) {
+	var matches uint64
+	for _, line := range lines {
+		if w.regex.MatchString(line.Message) {
+			matches++
+		}
+	}
+	if matches == 0 {
+		return
+	}
+
+	now := time.Now().Unix()
+	idx := now % patternWindowSeconds
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.seconds[idx] != now {
+		w.seconds[idx] = now
+		w.buckets[idx] = 0
+	}
+	w.buckets[idx] += matches
+	w.total += matches
+}
+
+// count returns the match counts of the last minute and since the watch started
+func (w *patternWatch) count() types.LogPatternCount {
+	now := time.Now().Unix()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var lastMinute uint64
+	for i := range w.buckets {
+		if now-w.seconds[i] < patternWindowSeconds {
+			lastMinute += w.buckets[i]
+		}
+	}
+
+	return types.LogPatternCount{
+		RuleID:            w.rule.RuleID,
+		PodName:           w.rule.PodName,
+		MatchesLastMinute: lastMinute,
+		TotalMatches:      w.total,
+		Error:             w.err,
+	}
+}
+
+// fail records why the follower ended and when to start the next one. A follower that ran longer
+// than the delay restarts the backoff from the shortest delay
+func (w *patternWatch) fail(err error, retryMin, retryMax time.Duration) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.delay == 0 || time.Since(w.started) > w.delay {
+		w.delay = retryMin
+	} else {
+		w.delay = min(w.delay*2, retryMax)
+	}
+	w.err = err.Error()
+	w.failed = true
+	w.retryAt = time.Now().Add(w.delay)
+	return w.delay
+}
+
+// retryDue returns true if the follower failed and its delay has elapsed
+func (w *patternWatch) retryDue(now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.failed && !now.Before(w.retryAt)
+}
+
+// restart clears the failure of the previous follower
+func (w *patternWatch) restart() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = ""
+	w.failed = false
+	w.started = time.Now()
+}
+
+// PatternMatcher tails pod logs for log pattern alert rules and counts regex matches
+type PatternMatcher struct {
+	tailer  *Tailer
+	rules   []types.LogPatternRule
+	watches map[string]*patternWatch // ruleID -> watch
+	mu      sync.Mutex
+
+	retryMin time.Duration
+	retryMax time.Duration
+}
+
+// NewPatternMatcher creates a new log pattern matcher
+func NewPatternMatcher(tailer *Tailer) *PatternMatcher {
+	return &PatternMatcher{
+		tailer:   tailer,
+		watches:  make(map[string]*patternWatch),
+		retryMin: patternRetryMin,
+		retryMax: patternRetryMax,
+	}
+}
+
+// SetRules replaces the log pattern rules (watches are started on the next Sync)
+func (m *PatternMatcher) SetRules(rules []types.LogPatternRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rules = rules
+
+	// Stop watches of removed or modified rules
+	for ruleID, watch := range m.watches {
+		if !containsRule(rules, watch.rule) {
+			watch.cancel()
+			delete(m.watches, ruleID)
+		}
+	}
+
+	slog.Info("log pattern rules updated", "component", "logs", "rules", len(rules))
+}
+
+// Sync starts and stops log followers according to the rules and the pods currently on the node
+func (m *PatternMatcher) Sync(pods []*types.Pod) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	podsByName := make(map[string]*types.Pod, len(pods))
+	for _, pod := range pods {
+		podsByName[pod.Name] = pod
+	}
+
+	for _, rule := range m.rules {
+		pod := podsByName[rule.PodName]
+		watch, found := m.watches[rule.RuleID]
+
+		// Pod was recreated with the same name, follow the new one
+		if found && pod != nil && watch.podUID != pod.UID {
+			watch.cancel()
+			delete(m.watches, rule.RuleID)
+			found = false
+		}
+		// Retried on the next Sync once the pod shows up
+		if pod == nil {
+			continue
+		}
+
+		// The follower failed, it is started again once its delay elapsed
+		if found {
+			if watch.retryDue(time.Now()) {
+				m.start(watch, pod)
+			}
+			continue
+		}
+
+		watch = &patternWatch{rule: rule, podUID: pod.UID, cancel: func() {}}
+		m.watches[rule.RuleID] = watch
+
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			watch.err = fmt.Sprintf("invalid pattern: %v", err)
+			continue
+		}
+		watch.regex = regex
+		m.start(watch, pod)
+	}
+}
+
+// start follows the logs of the pod of a watch, m.mu must be held
+func (m *PatternMatcher) start(watch *patternWatch, pod *types.Pod) {
+	watch.restart()
+	ctx, cancel := context.WithCancel(context.Background())
+	watch.cancel = cancel
+	go m.follow(ctx, watch, pod)
+}
+
+// follow feeds the lines of a pod to a watch until it is cancelled
+func (m *PatternMatcher) follow(ctx context.Context, watch *patternWatch, pod *types.Pod) {
+	slog.Info("watching logs for pattern", "component", "logs", "rule_id", watch.rule.RuleID,
+		"pod", pod.Name, "pattern", watch.rule.Pattern)
+
+	err := m.tailer.Follow(ctx, pod, "", watch.record)
+	if ctx.Err() != nil {
+		return
+	}
+	// Follow returns at once when the pod has no container log yet
+	if err == nil {
+		err = fmt.Errorf("no container log to follow in %s", m.tailer.PodLogDir(pod))
+	}
+	delay := watch.fail(err, m.retryMin, m.retryMax)
+	slog.Error("error following logs for pattern", "component", "logs", "rule_id", watch.rule.RuleID,
+		"pod", pod.Name, "error", err, "retry_in", delay)
+}
+
+// Counts returns the match counts of all rules
+func (m *PatternMatcher) Counts() []types.LogPatternCount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make([]types.LogPatternCount, 0, len(m.rules))
+	for _, rule := range m.rules {
+		if watch, found := m.watches[rule.RuleID]; found {
+			counts = append(counts, watch.count())
+		} else {
+			counts = append(counts, types.LogPatternCount{
+				RuleID:  rule.RuleID,
+				PodName: rule.PodName,
+				Error:   fmt.Sprintf("pod %s not found on node", rule.PodName),
+			})
+		}
+	}
+	return counts
+}
+
+func containsRule(rules []types.LogPatternRule, rule types.LogPatternRule) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/cmd/agent/shared"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Name of an env var never set, the tailer reads the logs under the root set by the test
+const testDevMode = "GOBSERVABILITY_TEST_DEV_MODE"
+
+// waitFor polls Sync until the count of the rule satisfies done
+func waitFor(t *testing.T, m *PatternMatcher, pods []*types.Pod, what string, done func(types.LogPatternCount) bool, tick func()) types.LogPatternCount {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.Sync(pods)
+		count := m.Counts()[0]
+		if done(count) {
+			return count
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s, last count %+v", what, count)
+		}
+		if tick != nil {
+			tick()
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPatternMatcherRetriesMissingLogDir(t *testing.T) {
+	root := t.TempDir()
+	shared.SetPaths("", "", root)
+	defer shared.SetPaths("", "", "")
+
+	tailer := NewTailer(testDevMode)
+	m := NewPatternMatcher(tailer)
+	m.retryMin = 20 * time.Millisecond
+	m.retryMax = 100 * time.Millisecond
+
+	pod := &types.Pod{Name: "api-7d9f", Namespace: "shop", UID: "0b6f2c1e-uid"}
+	pods := []*types.Pod{pod}
+	m.SetRules([]types.LogPatternRule{{RuleID: "rule-1", PodName: pod.Name, Pattern: "ERROR"}})
+	defer m.SetRules(nil)
+
+	// The kubelet did not create the log directory of the pod yet
+	waitFor(t, m, pods, "the missing directory error", func(count types.LogPatternCount) bool {
+		return strings.Contains(count.Error, "error reading")
+	}, nil)
+
+	// The pod directory exists before its containers
+	podDir := tailer.PodLogDir(pod)
+	if err := os.MkdirAll(podDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m, pods, "the empty directory error", func(count types.LogPatternCount) bool {
+		return strings.Contains(count.Error, "no container log to follow")
+	}, nil)
+
+	containerDir := filepath.Join(podDir, "api")
+	if err := os.MkdirAll(containerDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logFile := filepath.Join(containerDir, "0.log")
+	if err := os.WriteFile(logFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The watch follows the log once it is retried, lines are written until they are counted
+	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	write := func() {
+		fmt.Fprintf(file, "%s stdout F ERROR payment declined\n", time.Now().UTC().Format(time.RFC3339Nano))
+		fmt.Fprintf(file, "%s stdout F INFO request handled\n", time.Now().UTC().Format(time.RFC3339Nano))
+	}
+	count := waitFor(t, m, pods, "matches", func(count types.LogPatternCount) bool {
+		return count.MatchesLastMinute > 0
+	}, write)
+	if count.Error != "" {
+		t.Errorf("expected the error to be cleared, got %q", count.Error)
+	}
+}
+
+func TestPatternWatchBackoff(t *testing.T) {
+	w := &patternWatch{}
+	w.restart()
+
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		delays = append(delays, w.fail(fmt.Errorf("no log"), time.Minute, 3*time.Minute))
+	}
+	if fmt.Sprint(delays) != "[1m0s 2m0s 3m0s 3m0s]" {
+		t.Errorf("expected the delay to double up to the maximum, got %v", delays)
+	}
+	if w.retryDue(time.Now()) || !w.retryDue(time.Now().Add(3*time.Minute)) {
+		t.Error("expected the retry to be due after the delay only")
+	}
+
+	// A follower that ran longer than the delay restarts from the shortest delay
+	w.restart()
+	w.started = time.Now().Add(-time.Hour)
+	if delay := w.fail(fmt.Errorf("rotated"), time.Minute, 3*time.Minute); delay != time.Minute {
+		t.Errorf("expected the shortest delay, got %s", delay)
+	}
+}
@@ -0,0 +1,186 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+const (
+	tailBlockSize = 32 * 1024
+	// Maximum number of lines passed to the callback in a single batch
+	maxBatchLines = 500
+)
+
+// TailFile returns the last n full lines of a CRI log file
+func TailFile(path, container string, n int) ([]types.LogLine, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	// Read blocks backwards until enough newlines have been seen
+	// One extra line is read so a line cut at the block boundary can be dropped
+	offset := info.Size()
+	var data []byte
+	for offset > 0 && bytes.Count(data, []byte{'\n'}) <= n {
+		size := int64(tailBlockSize)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+
+		block := make([]byte, size)
+		if _, err := file.ReadAt(block, offset); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		data = append(block, data...)
+	}
+
+	rawLines := strings.Split(string(data), "\n")
+	// The first line is incomplete when the file was not read from the start
+	if offset > 0 && len(rawLines) > 0 {
+		rawLines = rawLines[1:]
+	}
+
+	a := &assembler{container: container}
+	lines := make([]types.LogLine, 0, len(rawLines))
+	for _, raw := range rawLines {
+		if raw == "" {
+			continue
+		}
+		if line, ok := a.add(raw); ok {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// FollowContainer streams new lines of a container log directory until ctx is done
+// It follows log rotation and container restarts (new <restart>.log file)
+func FollowContainer(ctx context.Context, containerDir, container string, pollInterval time.Duration, onLines func([]types.LogLine)) error {
+	path, err := CurrentLogFile(containerDir)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", path, err)
+	}
+	defer func() { file.Close() }()
+
+	// Only lines written after the follow started are streamed
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	reader := bufio.NewReader(file)
+	a := &assembler{container: container}
+	var pending string
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var batch []types.LogLine
+		for {
+			chunk, err := reader.ReadString('\n')
+			offset += int64(len(chunk))
+			if err != nil {
+				// Incomplete line, wait for the rest of it
+				pending += chunk
+				break
+			}
+
+			raw := pending + chunk
+			pending = ""
+			if line, ok := a.add(raw); ok {
+				batch = append(batch, line)
+			}
+
+			if len(batch) >= maxBatchLines {
+				onLines(batch)
+				batch = nil
+			}
+		}
+		if len(batch) > 0 {
+			onLines(batch)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Check if the file was rotated, truncated or replaced by a new restart
+		current, err := CurrentLogFile(containerDir)
+		if err != nil {
+			continue
+		}
+		if current == path && !rotated(file, path, offset) {
+			continue
+		}
+
+		newFile, err := os.Open(current)
+		if err != nil {
+			continue
+		}
+
+		// Drain what is left in the old file before switching
+		if rest, err := io.ReadAll(reader); err == nil && len(rest) > 0 {
+			for _, raw := range strings.Split(pending+string(rest), "\n") {
+				if raw == "" {
+					continue
+				}
+				if line, ok := a.add(raw); ok {
+					onLines([]types.LogLine{line})
+				}
+			}
+		}
+
+		file.Close()
+		file = newFile
+		path = current
+		offset = 0
+		pending = ""
+		reader.Reset(file)
+	}
+}
+
+// rotated reports whether path no longer points to the open file or was truncated
+func rotated(file *os.File, path string, offset int64) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return true
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return current.Size() < offset || !os.SameFile(opened, current)
+}
@@ -0,0 +1,194 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/cmd/agent/shared"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+const followPollInterval = 500 * time.Millisecond
+
+// Tailer reads pod container logs from the CRI log directory
+type Tailer struct {
+	devMode string
+}
+
+// NewTailer creates a new container log tailer
+func NewTailer(devMode string) *Tailer {
+	return &Tailer{
+		devMode: devMode,
+	}
+}
+
+// PodLogDir returns the log directory of a pod (/var/log/pods/<namespace>_<name>_<uid>)
+func (t *Tailer) PodLogDir(pod *types.Pod) string {
+	return filepath.Join(shared.GetPodLogsPath(t.devMode), fmt.Sprintf("%s_%s_%s", pod.Namespace, pod.Name, pod.UID))
+}
+
+// Probe returns why container logs cannot be read, nil if they can
+func (t *Tailer) Probe() error {
+	if isDev := os.Getenv(t.devMode); isDev == "true" {
+		return nil
+	}
+
+	dir := shared.GetPodLogsPath(t.devMode)
+	if _, err := os.ReadDir(dir); err != nil {
+		return fmt.Errorf("cannot read %s: %v", dir, err)
+	}
+	return nil
+}
+
+// Containers returns the containers of a pod that have a log directory
+func (t *Tailer) Containers(pod *types.Pod) ([]string, error) {
+	if isDev := os.Getenv(t.devMode); isDev == "true" {
+		return []string{pod.Name}, nil
+	}
+
+	podDir := t.PodLogDir(pod)
+	entries, err := os.ReadDir(podDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", podDir, err)
+	}
+
+	var containers []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			containers = append(containers, entry.Name())
+		}
+	}
+	return containers, nil
+}
+
+// resolveContainers returns the requested container or all containers if empty
+func (t *Tailer) resolveContainers(pod *types.Pod, container string) ([]string, error) {
+	containers, err := t.Containers(pod)
+	if err != nil {
+		return nil, err
+	}
+	if container == "" {
+		return containers, nil
+	}
+
+	for _, c := range containers {
+		if c == container {
+			return []string{container}, nil
+		}
+	}
+	return nil, fmt.Errorf("container %s not found in pod %s", container, pod.Name)
+}
+
+// Tail returns the last n lines of a pod, merged across containers by timestamp
+func (t *Tailer) Tail(pod *types.Pod, container string, n int) ([]types.LogLine, error) {
+	containers, err := t.resolveContainers(pod, container)
+	if err != nil {
+		return nil, err
+	}
+
+	if isDev := os.Getenv(t.devMode); isDev == "true" {
+		return mockLines(containers[0], n, time.Now().Add(-time.Duration(n)*time.Second)), nil
+	}
+
+	var lines []types.LogLine
+	for _, c := range containers {
+		path, err := CurrentLogFile(filepath.Join(t.PodLogDir(pod), c))
+		if err != nil {
+			return nil, err
+		}
+
+		containerLines, err := TailFile(path, c, n)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, containerLines...)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Timestamp.Before(lines[j].Timestamp)
+	})
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// Follow streams new log lines of a pod until ctx is done
+func (t *Tailer) Follow(ctx context.Context, pod *types.Pod, container string, onLines func([]types.LogLine)) error {
+	containers, err := t.resolveContainers(pod, container)
+	if err != nil {
+		return err
+	}
+
+	if isDev := os.Getenv(t.devMode); isDev == "true" {
+		return followMock(ctx, containers[0], onLines)
+	}
+
+	// Callbacks from several containers are serialized
+	var mu sync.Mutex
+	safeOnLines := func(lines []types.LogLine) {
+		mu.Lock()
+		defer mu.Unlock()
+		onLines(lines)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(containers))
+	for _, c := range containers {
+		wg.Add(1)
+		go func(c string) {
+			defer wg.Done()
+			if err := FollowContainer(ctx, filepath.Join(t.PodLogDir(pod), c), c, followPollInterval, safeOnLines); err != nil {
+				errs <- err
+			}
+		}(c)
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+var mockMessages = []string{
+	"INFO request handled method=GET path=/api/health status=200",
+	"DEBUG cache lookup key=session hit=true",
+	"INFO request handled method=POST path=/api/orders status=201",
+	"WARN slow query duration=1.2s table=orders",
+	"ERROR upstream connection refused host=payments:8080",
+	"INFO worker processed jobs=12",
+}
+
+// mockLines generates fake log lines for dev mode
+func mockLines(container string, n int, start time.Time) []types.LogLine {
+	lines := make([]types.LogLine, 0, n)
+	for i := 0; i < n; i++ {
+		lines = append(lines, types.LogLine{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Container: container,
+			Stream:    "stdout",
+			Message:   mockMessages[rand.Intn(len(mockMessages))],
+		})
+	}
+	return lines
+}
+
+// followMock emits a fake log line every second for dev mode
+func followMock(ctx context.Context, container string, onLines func([]types.LogLine)) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			onLines(mockLines(container, 1, now))
+		}
+	}
+}
@@ -9,15 +9,15 @@ import (
 )
 
 type Cache struct {
-	nodeCache *gocache.Cache
-	podCache  *gocache.Cache
+	nodeCache   *gocache.Cache
+	podCache    *gocache.Cache
+	volumeCache *gocache.Cache
 }
 
-type CachedNodeMetrics struct {
-	CPU       *types.CPUStats
-	Network   *types.NetworkStats
-	Disk      *types.DiskStats
-	Timestamp time.Time
+// cachedNodeValue is the previous reading of one node metric
+type cachedNodeValue struct {
+	value     any
+	timestamp time.Time
 }
 
 type CachedPodMetrics struct {
@@ -31,34 +31,60 @@ type CachedPodMetrics struct {
 NewCache creates a new cache instance
 - Node cache: keep for 1 minute, cleanup every 30 seconds
 - Pod cache: keep for 1 minute, cleanup every 30 seconds
+- Volume cache: walked volume usage is reused for 1 minute (like kubelet du), cleanup every minute
 */
 func NewCache() *Cache {
 	return &Cache{
-		nodeCache: gocache.New(1*time.Minute, 30*time.Second),
-		podCache:  gocache.New(1*time.Minute, 30*time.Second),
+		nodeCache:   gocache.New(1*time.Minute, 30*time.Second),
+		podCache:    gocache.New(1*time.Minute, 30*time.Second),
+		volumeCache: gocache.New(1*time.Minute, 1*time.Minute),
 	}
 }
 
-// UpdateNodeMetrics stores current node metrics and returns previous values
-func (c *Cache) UpdateNodeMetrics(nodeName string, cpu *types.CPUStats, network *types.NetworkStats, disk *types.DiskStats) (*CachedNodeMetrics, bool) {
-	key := fmt.Sprintf("node:%s", nodeName)
+// updateNodeValue stores the current reading of a node metric and returns the previous one
+func (c *Cache) updateNodeValue(nodeName, metric string, value any) (any, time.Time, bool) {
+	key := fmt.Sprintf("node:%s:%s", nodeName, metric)
 
-	// Get previous metrics
-	var prev *CachedNodeMetrics
+	// Get previous value
+	var prev *cachedNodeValue
 	if cached, found := c.nodeCache.Get(key); found {
-		prev = cached.(*CachedNodeMetrics)
+		prev = cached.(*cachedNodeValue)
 	}
 
-	// Store new metrics
-	newMetrics := &CachedNodeMetrics{
-		CPU:       cpu,
-		Network:   network,
-		Disk:      disk,
-		Timestamp: time.Now(),
+	// Store new value
+	c.nodeCache.Set(key, &cachedNodeValue{value: value, timestamp: time.Now()}, gocache.DefaultExpiration)
+
+	if prev == nil {
+		return nil, time.Time{}, false
 	}
-	c.nodeCache.Set(key, newMetrics, gocache.DefaultExpiration)
+	return prev.value, prev.timestamp, true
+}
 
-	return prev, prev != nil
+// UpdateNodeCPU stores current node CPU stats and returns the previous ones with their timestamp
+func (c *Cache) UpdateNodeCPU(nodeName string, cpu *types.CPUStats) (*types.CPUStats, time.Time, bool) {
+	prev, timestamp, found := c.updateNodeValue(nodeName, "cpu", cpu)
+	if !found {
+		return nil, timestamp, false
+	}
+	return prev.(*types.CPUStats), timestamp, true
+}
+
+// UpdateNodeNetwork stores current node network stats and returns the previous ones with their timestamp
+func (c *Cache) UpdateNodeNetwork(nodeName string, network *types.NetworkStats) (*types.NetworkStats, time.Time, bool) {
+	prev, timestamp, found := c.updateNodeValue(nodeName, "network", network)
+	if !found {
+		return nil, timestamp, false
+	}
+	return prev.(*types.NetworkStats), timestamp, true
+}
+
+// UpdateNodeDisk stores current node disk stats and returns the previous ones with their timestamp
+func (c *Cache) UpdateNodeDisk(nodeName string, disk *types.DiskStats) (*types.DiskStats, time.Time, bool) {
+	prev, timestamp, found := c.updateNodeValue(nodeName, "disk", disk)
+	if !found {
+		return nil, timestamp, false
+	}
+	return prev.(*types.DiskStats), timestamp, true
 }
 
 // UpdatePodMetrics stores current pod metrics and returns previous values
@@ -100,3 +126,16 @@ func (c *Cache) CleanupStaleEntries(activePIDs []int) {
 		}
 	}
 }
+
+// GetVolumeUsage returns the cached usage for a walked directory
+func (c *Cache) GetVolumeUsage(path string) (types.VolumeStats, bool) {
+	if cached, found := c.volumeCache.Get(path); found {
+		return cached.(types.VolumeStats), true
+	}
+	return types.VolumeStats{}, false
+}
+
+// SetVolumeUsage stores the usage of a walked directory
+func (c *Cache) SetVolumeUsage(path string, usage types.VolumeStats) {
+	c.volumeCache.Set(path, usage, gocache.DefaultExpiration)
+}
@@ -75,6 +75,15 @@ func (c *Calculator) CalculateMemoryPercentage(vmRSS uint64, totalSystemMemory u
 	return float64(vmRSS) / float64(totalSystemMemory) * 100.0
 }
 
+// CalculateStoragePercentage calculates storage usage percentage (0 if capacity is unknown)
+func (c *Calculator) CalculateStoragePercentage(usedBytes, capacityBytes uint64) float64 {
+	if capacityBytes == 0 {
+		return 0
+	}
+
+	return float64(usedBytes) / float64(capacityBytes) * 100.0
+}
+
 // CalculateNetworkRate calculates network throughput in MB/s
 func (c *Calculator) CalculateNetworkRate(currentBytes, previousBytes uint64, timeDelta time.Duration) float64 {
 	if previousBytes == 0 || timeDelta == 0 || currentBytes < previousBytes {
@@ -0,0 +1,166 @@
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Suffixes of the series generated by histograms and summaries
+var typedSuffixes = []string{"_bucket", "_sum", "_count"}
+
+// ParseText parses the Prometheus text exposition format (version 0.0.4)
+// Non-finite values (NaN, ±Inf) are skipped since they cannot be forwarded as JSON
+func ParseText(r io.Reader) ([]types.MetricSample, error) {
+	metricTypes := make(map[string]string)
+	var samples []types.MetricSample
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			// # TYPE <name> <type>, HELP and other comments are ignored
+			fields := strings.Fields(line)
+			if len(fields) >= 4 && fields[1] == "TYPE" {
+				metricTypes[fields[2]] = fields[3]
+			}
+			continue
+		}
+
+		sample, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			continue
+		}
+
+		sample.Type = lookupType(metricTypes, sample.Name)
+		samples = append(samples, sample)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading metrics: %v", err)
+	}
+	return samples, nil
+}
+
+// parseSample parses "<name>[{<labels>}] <value> [<timestamp>]"
+func parseSample(line string) (types.MetricSample, error) {
+	var sample types.MetricSample
+
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return sample, fmt.Errorf("invalid sample %q", line)
+	}
+	sample.Name = line[:nameEnd]
+	rest := line[nameEnd:]
+
+	if strings.HasPrefix(rest, "{") {
+		labels, consumed, err := parseLabels(rest)
+		if err != nil {
+			return sample, err
+		}
+		sample.Labels = labels
+		rest = rest[consumed:]
+	}
+
+	// The optional timestamp is ignored, samples are timestamped by the agent
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return sample, fmt.Errorf("invalid sample %q", line)
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("invalid value %q for %s", fields[0], sample.Name)
+	}
+	sample.Value = value
+
+	return sample, nil
+}
+
+// parseLabels parses a label set starting with '{' and returns the number of bytes consumed
+func parseLabels(s string) (map[string]string, int, error) {
+	labels := make(map[string]string)
+	i := 1 // Skip '{'
+
+	for {
+		// Skip whitespace and separators
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, fmt.Errorf("unterminated label set")
+		}
+		if s[i] == '}' {
+			return labels, i + 1, nil
+		}
+
+		eq := strings.IndexByte(s[i:], '=')
+		if eq <= 0 {
+			return nil, 0, fmt.Errorf("invalid label in %q", s)
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+
+		if i >= len(s) || s[i] != '"' {
+			return nil, 0, fmt.Errorf("label %s value must be quoted", name)
+		}
+		i++
+
+		var value strings.Builder
+		for {
+			if i >= len(s) {
+				return nil, 0, fmt.Errorf("unterminated value for label %s", name)
+			}
+			c := s[i]
+			if c == '"' {
+				i++
+				break
+			}
+			if c == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					// \\ and \"
+					value.WriteByte(s[i])
+				}
+			} else {
+				value.WriteByte(c)
+			}
+			i++
+		}
+		labels[name] = value.String()
+	}
+}
+
+// lookupType returns the declared type of a series, handling histogram and summary suffixes
+func lookupType(metricTypes map[string]string, name string) string {
+	if metricType, found := metricTypes[name]; found {
+		return metricType
+	}
+	for _, suffix := range typedSuffixes {
+		if base, found := strings.CutSuffix(name, suffix); found {
+			if metricType, found := metricTypes[base]; found {
+				return metricType
+			}
+		}
+	}
+	return "untyped"
+}
@@ -0,0 +1,216 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Pod annotations used to discover scrape targets
+const (
+	AnnotationScrape = "prometheus.io/scrape"
+	AnnotationPort   = "prometheus.io/port"
+	AnnotationPath   = "prometheus.io/path"
+	AnnotationScheme = "prometheus.io/scheme"
+	// Regex of series names to forward, overrides the agent default
+	AnnotationInclude = "gobservability.io/scrape-include"
+)
+
+// Maximum size of a scraped response body
+const maxResponseBytes = 10 * 1024 * 1024
+
+// Scraper scrapes the Prometheus endpoints of annotated pods
+type Scraper struct {
+	client    *http.Client
+	timeout   time.Duration
+	include   *regexp.Regexp // Series names forwarded to the server (nil for all)
+	maxSeries int            // Maximum series forwarded per pod
+}
+
+// NewScraper creates a new scraper, client can be replaced to scrape test servers
+func NewScraper(client *http.Client, timeout time.Duration, include string, maxSeries int) (*Scraper, error) {
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	scraper := &Scraper{
+		client:    client,
+		timeout:   timeout,
+		maxSeries: maxSeries,
+	}
+
+	if include != "" {
+		regex, err := regexp.Compile(include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrape include pattern: %v", err)
+		}
+		scraper.include = regex
+	}
+
+	return scraper, nil
+}
+
+// TargetURL returns the scrape URL of a pod, or "" if the pod is not annotated for scraping
+func TargetURL(pod *types.Pod) (string, error) {
+	if pod.Annotations[AnnotationScrape] != "true" {
+		return "", nil
+	}
+	if pod.IP == "" {
+		return "", fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+
+	port := pod.Annotations[AnnotationPort]
+	if port == "" {
+		return "", fmt.Errorf("pod %s is missing the %s annotation", pod.Name, AnnotationPort)
+	}
+
+	path := pod.Annotations[AnnotationPath]
+	if path == "" {
+		path = "/metrics"
+	}
+	scheme := pod.Annotations[AnnotationScheme]
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(pod.IP, port), path), nil
+}
+
+// ScrapePods scrapes the annotated pods concurrently and returns the results by pod UID
+// Pods with several containers appear once per container, each pod is scraped once
+func (s *Scraper) ScrapePods(ctx context.Context, pods []*types.Pod) map[string]*types.ScrapeResult {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make(map[string]*types.ScrapeResult)
+	scraped := make(map[string]bool)
+
+	for _, pod := range pods {
+		if scraped[pod.UID] {
+			continue
+		}
+
+		url, err := TargetURL(pod)
+		if url == "" && err == nil {
+			continue
+		}
+		scraped[pod.UID] = true
+
+		if err != nil {
+			mu.Lock()
+			results[pod.UID] = &types.ScrapeResult{Error: err.Error()}
+			mu.Unlock()
+			continue
+		}
+
+		include := s.include
+		if pattern := pod.Annotations[AnnotationInclude]; pattern != "" {
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				mu.Lock()
+				results[pod.UID] = &types.ScrapeResult{URL: url, Error: fmt.Sprintf("invalid %s annotation: %v", AnnotationInclude, err)}
+				mu.Unlock()
+				continue
+			}
+			include = regex
+		}
+
+		wg.Add(1)
+		go func(pod *types.Pod, url string, include *regexp.Regexp) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+
+			result := s.Scrape(ctx, url, include)
+			if result.Error != "" {
+				slog.Warn("scrape failed", "component", "prometheus", "pod", pod.Name, "url", url, "error", result.Error)
+			}
+
+			mu.Lock()
+			results[pod.UID] = result
+			mu.Unlock()
+		}(pod, url, include)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// Scrape fetches and parses a Prometheus endpoint, keeping the series matching include
+func (s *Scraper) Scrape(ctx context.Context, url string, include *regexp.Regexp) *types.ScrapeResult {
+	start := time.Now()
+	result := &types.ScrapeResult{URL: url}
+	defer func() {
+		result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid scrape request: %v", err)
+		return result
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		result.Error = fmt.Sprintf("scrape failed: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = fmt.Sprintf("scrape returned status %d", resp.StatusCode)
+		return result
+	}
+
+	// One byte past the limit tells an oversized response from one of exactly the limit
+	body := &countingReader{r: io.LimitReader(resp.Body, maxResponseBytes+1)}
+	samples, err := ParseText(body)
+	if body.n > maxResponseBytes {
+		result.Error = fmt.Sprintf("response larger than %d bytes", maxResponseBytes)
+		return result
+	}
+	if err != nil {
+		result.Error = fmt.Sprintf("error parsing metrics: %v", err)
+		return result
+	}
+
+	for _, sample := range samples {
+		if include != nil && !include.MatchString(sample.Name) {
+			continue
+		}
+		result.Samples = append(result.Samples, sample)
+	}
+
+	if s.maxSeries > 0 && len(result.Samples) > s.maxSeries {
+		result.Error = fmt.Sprintf("series limit reached, %d of %d series forwarded", s.maxSeries, len(result.Samples))
+		result.Samples = result.Samples[:s.maxSeries]
+	}
+
+	sort.SliceStable(result.Samples, func(i, j int) bool {
+		return result.Samples[i].Name < result.Samples[j].Name
+	})
+
+	return result
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,245 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+const testExposition = `# HELP http_requests_total Requests served
+# TYPE http_requests_total counter
+http_requests_total{method="GET",path="/api"} 1027 1395066363000
+http_requests_total{method="POST",path="/api"} 3
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 12
+request_duration_seconds_bucket{le="+Inf"} 14
+request_duration_seconds_sum 1.5
+request_duration_seconds_count 14
+# TYPE queue_depth gauge
+queue_depth NaN
+go_goroutines 42
+`
+
+// newTestScraper starts a stand-in endpoint and returns a scraper and a pod annotated to be scraped on it
+func newTestScraper(t *testing.T, handler http.HandlerFunc, timeout time.Duration, include string, maxSeries int) (*Scraper, *types.Pod) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := &types.Pod{
+		Name: "app",
+		UID:  "uid-1",
+		IP:   host,
+		Annotations: map[string]string{
+			AnnotationScrape: "true",
+			AnnotationPort:   port,
+		},
+	}
+
+	scraper, err := NewScraper(server.Client(), timeout, include, maxSeries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scraper, pod
+}
+
+// scrapePod scrapes a single pod and returns its result
+func scrapePod(scraper *Scraper, pod *types.Pod) *types.ScrapeResult {
+	return scraper.ScrapePods(context.Background(), []*types.Pod{pod})[pod.UID]
+}
+
+func serveText(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, body)
+	}
+}
+
+func TestScrapePods(t *testing.T) {
+	scraper, pod := newTestScraper(t, serveText(testExposition), time.Second, "", 0)
+
+	// The pod appears once per container and is scraped once
+	second := *pod
+	results := scraper.ScrapePods(context.Background(), []*types.Pod{pod, &second, {Name: "not-annotated", UID: "uid-2"}})
+	if len(results) != 1 {
+		t.Fatalf("expected the result of the annotated pod, got %v", results)
+	}
+
+	result := results["uid-1"]
+	if result.Error != "" {
+		t.Fatalf("expected no error, got %s", result.Error)
+	}
+	if !strings.HasSuffix(result.URL, "/metrics") {
+		t.Errorf("expected the default /metrics path, got %s", result.URL)
+	}
+
+	// NaN is skipped, the series are sorted by name
+	var names []string
+	for _, sample := range result.Samples {
+		names = append(names, sample.Name+"="+sample.Type)
+	}
+	expected := "go_goroutines=untyped http_requests_total=counter http_requests_total=counter " +
+		"request_duration_seconds_bucket=histogram request_duration_seconds_bucket=histogram " +
+		"request_duration_seconds_count=histogram request_duration_seconds_sum=histogram"
+	if strings.Join(names, " ") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(names, " "))
+	}
+
+	first := result.Samples[1]
+	if first.Value != 1027 || first.Labels["method"] != "GET" || first.Labels["path"] != "/api" {
+		t.Errorf("unexpected sample %+v", first)
+	}
+}
+
+func TestScrapeInclude(t *testing.T) {
+	scraper, pod := newTestScraper(t, serveText(testExposition), time.Second, "^http_.*", 0)
+
+	result := scrapePod(scraper, pod)
+	if result.Error != "" || len(result.Samples) != 2 {
+		t.Fatalf("expected the 2 http_requests_total series, got %+v", result)
+	}
+
+	// The pod annotation overrides the agent pattern
+	pod.Annotations[AnnotationInclude] = "go_.*"
+	result = scrapePod(scraper, pod)
+	if len(result.Samples) != 1 || result.Samples[0].Name != "go_goroutines" {
+		t.Fatalf("expected go_goroutines, got %+v", result.Samples)
+	}
+
+	pod.Annotations[AnnotationInclude] = "("
+	result = scrapePod(scraper, pod)
+	if !strings.Contains(result.Error, "invalid "+AnnotationInclude) {
+		t.Fatalf("expected an invalid annotation error, got %q", result.Error)
+	}
+}
+
+func TestScrapeTimeout(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		// The endpoint never answers, the request is canceled by the scraper
+		<-r.Context().Done()
+	}
+	scraper, pod := newTestScraper(t, handler, 50*time.Millisecond, "", 0)
+
+	start := time.Now()
+	result := scrapePod(scraper, pod)
+	if !strings.Contains(result.Error, "scrape failed") || !strings.Contains(result.Error, "deadline exceeded") {
+		t.Fatalf("expected a timeout, got %q", result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the scrape to stop after the timeout, took %s", elapsed)
+	}
+}
+
+func TestScrapeOversizedBody(t *testing.T) {
+	line := "padding_series 1\n"
+	body := strings.Repeat(line, maxResponseBytes/len(line)+1)
+	scraper, pod := newTestScraper(t, serveText(body), 5*time.Second, "", 0)
+
+	result := scrapePod(scraper, pod)
+	if !strings.Contains(result.Error, "response larger than") || len(result.Samples) != 0 {
+		t.Fatalf("expected the oversized response to be rejected, got %q with %d samples", result.Error, len(result.Samples))
+	}
+
+	// A response of exactly the limit is accepted
+	body = strings.Repeat(line, maxResponseBytes/len(line))
+	body += strings.Repeat("#", maxResponseBytes-len(body)-1) + "\n"
+	scraper, pod = newTestScraper(t, serveText(body), 5*time.Second, "", 0)
+	result = scrapePod(scraper, pod)
+	if result.Error != "" || len(result.Samples) != maxResponseBytes/len(line) {
+		t.Fatalf("expected a response of the limit to be accepted, got %q with %d samples", result.Error, len(result.Samples))
+	}
+}
+
+func TestScrapeSeriesLimit(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&body, "series_total{id=\"%d\"} %d\n", i, i)
+	}
+	scraper, pod := newTestScraper(t, serveText(body.String()), time.Second, "", 4)
+
+	result := scrapePod(scraper, pod)
+	if result.Error != "series limit reached, 4 of 10 series forwarded" {
+		t.Errorf("expected the series limit error, got %q", result.Error)
+	}
+	if len(result.Samples) != 4 {
+		t.Fatalf("expected 4 forwarded series, got %d", len(result.Samples))
+	}
+	// The first series of the response are kept
+	for i, sample := range result.Samples {
+		if sample.Labels["id"] != fmt.Sprint(i) {
+			t.Errorf("expected series %d, got %v", i, sample.Labels)
+		}
+	}
+
+	// The limit applies after the include pattern
+	result = scraper.Scrape(context.Background(), result.URL, regexp.MustCompile("^series_total$"))
+	if len(result.Samples) != 4 {
+		t.Errorf("expected 4 forwarded series, got %d", len(result.Samples))
+	}
+	result = scraper.Scrape(context.Background(), result.URL, regexp.MustCompile("^other$"))
+	if result.Error != "" || len(result.Samples) != 0 {
+		t.Errorf("expected no series and no error, got %q with %d series", result.Error, len(result.Samples))
+	}
+}
+
+func TestScrapeErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		handler http.HandlerFunc
+		err     string
+	}{
+		{"status", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "down", http.StatusServiceUnavailable) }, "scrape returned status 503"},
+		{"invalid line", serveText("ok 1\nbroken\n"), "error parsing metrics: line 2: invalid sample"},
+		{"invalid value", serveText("ok one\n"), `invalid value "one" for ok`},
+		{"unterminated labels", serveText("ok{a=\"1 2\n"), "unterminated value for label a"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			scraper, pod := newTestScraper(t, test.handler, time.Second, "", 0)
+			result := scrapePod(scraper, pod)
+			if !strings.Contains(result.Error, test.err) {
+				t.Fatalf("expected an error containing %q, got %q", test.err, result.Error)
+			}
+		})
+	}
+}
+
+func TestTargetURL(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		pod  types.Pod
+		url  string
+		err  string
+	}{
+		{"not annotated", types.Pod{Name: "app", IP: "10.0.0.1"}, "", ""},
+		{"defaults", types.Pod{Name: "app", IP: "10.0.0.1", Annotations: map[string]string{AnnotationScrape: "true", AnnotationPort: "9100"}}, "http://10.0.0.1:9100/metrics", ""},
+		{"path and scheme", types.Pod{Name: "app", IP: "fd00::1", Annotations: map[string]string{AnnotationScrape: "true", AnnotationPort: "8443", AnnotationPath: "/stats", AnnotationScheme: "https"}}, "https://[fd00::1]:8443/stats", ""},
+		{"missing port", types.Pod{Name: "app", IP: "10.0.0.1", Annotations: map[string]string{AnnotationScrape: "true"}}, "", "missing the " + AnnotationPort},
+		{"missing IP", types.Pod{Name: "app", Annotations: map[string]string{AnnotationScrape: "true", AnnotationPort: "9100"}}, "", "has no IP"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			url, err := TargetURL(&test.pod)
+			if url != test.url {
+				t.Errorf("expected URL %q, got %q", test.url, url)
+			}
+			if (test.err == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), test.err)) {
+				t.Errorf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package receiver
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Kinds of aggregated series
+const (
+	kindCounter   = "counter"   // Sum of the increments of the interval
+	kindGauge     = "gauge"     // Last value, kept until stale
+	kindHistogram = "histogram" // Count, sum, min, max and quantiles of the interval
+	kindSet       = "set"       // Number of unique members of the interval
+)
+
+const (
+	// Flush intervals a gauge or a cumulative OTLP point is kept without updates
+	staleIntervals = 5
+	// Values kept per histogram and interval to compute quantiles
+	maxHistogramValues = 1000
+)
+
+// Quantiles reported for histograms observed value by value (StatsD timers)
+var quantiles = []float64{0.5, 0.9, 0.99}
+
+type seriesKey struct {
+	sourceIP string
+	name     string
+	labels   string // Canonical label set
+}
+
+type series struct {
+	name     string
+	labels   map[string]string
+	kind     string
+	value    float64 // Counter increment or gauge value
+	count    uint64
+	sum      float64
+	min      float64
+	max      float64
+	values   []float64
+	members  map[string]struct{}
+	lastSeen time.Time
+}
+
+type cumulativePoint struct {
+	value    float64
+	lastSeen time.Time
+}
+
+// Aggregator aggregates the metrics pushed by each source IP over a flush interval
+type Aggregator struct {
+	interval   time.Duration
+	maxSeries  int
+	current    map[seriesKey]*series
+	cumulative map[seriesKey]cumulativePoint   // Last cumulative OTLP values, used to compute increments
+	snapshot   map[string][]types.MetricSample // sourceIP -> series of the last flush
+	dropped    uint64
+	mu         sync.Mutex
+}
+
+// NewAggregator creates a new aggregator, maxSeries limits the series kept per interval
+func NewAggregator(interval time.Duration, maxSeries int) *Aggregator {
+	return &Aggregator{
+		interval:   interval,
+		maxSeries:  maxSeries,
+		current:    make(map[seriesKey]*series),
+		cumulative: make(map[seriesKey]cumulativePoint),
+		snapshot:   make(map[string][]types.MetricSample),
+	}
+}
+
+// Run flushes the aggregated series at every interval until ctx is done
+func (a *Aggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.flush()
+		}
+	}
+}
+
+// AddCounter adds an increment to a counter
+func (a *Aggregator) AddCounter(sourceIP, name string, labels map[string]string, delta float64) {
+	a.update(sourceIP, name, labels, kindCounter, func(s *series) {
+		s.value += delta
+	})
+}
+
+// SetGauge sets the value of a gauge
+func (a *Aggregator) SetGauge(sourceIP, name string, labels map[string]string, value float64) {
+	a.update(sourceIP, name, labels, kindGauge, func(s *series) {
+		s.value = value
+	})
+}
+
+// AddGauge adds a delta to the value of a gauge (StatsD "+N" and "-N" gauges)
+func (a *Aggregator) AddGauge(sourceIP, name string, labels map[string]string, delta float64) {
+	a.update(sourceIP, name, labels, kindGauge, func(s *series) {
+		s.value += delta
+	})
+}
+
+// Observe records a single histogram value
+func (a *Aggregator) Observe(sourceIP, name string, labels map[string]string, value float64) {
+	a.update(sourceIP, name, labels, kindHistogram, func(s *series) {
+		s.mergeHistogram(1, value, value, value)
+		if len(s.values) < maxHistogramValues {
+			s.values = append(s.values, value)
+		}
+	})
+}
+
+// MergeHistogram merges pre-aggregated histogram values, min and max are NaN if unknown
+func (a *Aggregator) MergeHistogram(sourceIP, name string, labels map[string]string, count uint64, sum, min, max float64) {
+	if count == 0 {
+		return
+	}
+	a.update(sourceIP, name, labels, kindHistogram, func(s *series) {
+		s.mergeHistogram(count, sum, min, max)
+	})
+}
+
+// AddSetMember adds a member to a set
+func (a *Aggregator) AddSetMember(sourceIP, name string, labels map[string]string, member string) {
+	a.update(sourceIP, name, labels, kindSet, func(s *series) {
+		if s.members == nil {
+			s.members = make(map[string]struct{})
+		}
+		s.members[member] = struct{}{}
+	})
+}
+
+// CumulativeDelta returns the increment of a cumulative OTLP value since its previous point
+// The first point of a series has no increment, after a reset the value itself is the increment
+func (a *Aggregator) CumulativeDelta(sourceIP, name string, labels map[string]string, value float64) float64 {
+	key := seriesKey{sourceIP: sourceIP, name: name, labels: canonicalLabels(labels)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous, found := a.cumulative[key]
+	a.cumulative[key] = cumulativePoint{value: value, lastSeen: time.Now()}
+
+	switch {
+	case !found:
+		return 0
+	case value < previous.value:
+		// Counter reset (application restarted)
+		return value
+	default:
+		return value - previous.value
+	}
+}
+
+// update applies fn to a series, creating it if the series limit allows it
+func (a *Aggregator) update(sourceIP, name string, labels map[string]string, kind string, fn func(*series)) {
+	name = sanitizeName(name)
+	labels = sanitizeLabels(labels)
+	key := seriesKey{sourceIP: sourceIP, name: name, labels: canonicalLabels(labels)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, found := a.current[key]
+	if found && s.kind != kind {
+		// The same name is pushed with another type, the latest type wins
+		delete(a.current, key)
+		found = false
+	}
+	if !found {
+		if a.maxSeries > 0 && len(a.current) >= a.maxSeries {
+			a.dropped++
+			return
+		}
+		s = &series{name: name, labels: labels, kind: kind}
+		a.current[key] = s
+	}
+
+	fn(s)
+	s.lastSeen = time.Now()
+}
+
+// flush turns the aggregated series into samples and starts a new interval
+func (a *Aggregator) flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	staleAfter := staleIntervals * a.interval
+	snapshot := make(map[string][]types.MetricSample)
+
+	for key, s := range a.current {
+		snapshot[key.sourceIP] = append(snapshot[key.sourceIP], s.samples()...)
+
+		// Gauges are kept until stale, the other kinds restart from zero
+		if s.kind != kindGauge || now.Sub(s.lastSeen) > staleAfter {
+			delete(a.current, key)
+		}
+	}
+
+	for key, point := range a.cumulative {
+		if now.Sub(point.lastSeen) > staleAfter {
+			delete(a.cumulative, key)
+		}
+	}
+
+	for _, samples := range snapshot {
+		sort.SliceStable(samples, func(i, j int) bool {
+			return samples[i].Name < samples[j].Name
+		})
+	}
+	a.snapshot = snapshot
+
+	if a.dropped > 0 {
+		slog.Warn("pushed series limit reached", "component", "receiver", "max_series", a.maxSeries, "dropped", a.dropped)
+		a.dropped = 0
+	}
+}
+
+// Snapshot returns the series of the last flush grouped by source, resolving source IPs to pods
+func (a *Aggregator) Snapshot(pods []*types.Pod) []types.PushedMetrics {
+	// Host network pods share the node IP and cannot be told apart
+	podsByIP := make(map[string]*types.Pod, len(pods))
+	ambiguous := make(map[string]bool)
+	for _, pod := range pods {
+		if pod.IP == "" {
+			continue
+		}
+		if other, found := podsByIP[pod.IP]; found && other.UID != pod.UID {
+			ambiguous[pod.IP] = true
+		}
+		podsByIP[pod.IP] = pod
+	}
+	for ip := range ambiguous {
+		delete(podsByIP, ip)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pushed := make([]types.PushedMetrics, 0, len(a.snapshot))
+	for sourceIP, samples := range a.snapshot {
+		metrics := types.PushedMetrics{SourceIP: sourceIP, Samples: samples}
+		if pod, found := podsByIP[sourceIP]; found {
+			metrics.PodName = pod.Name
+			metrics.Namespace = pod.Namespace
+		}
+		pushed = append(pushed, metrics)
+	}
+
+	sort.Slice(pushed, func(i, j int) bool {
+		if pushed[i].PodName != pushed[j].PodName {
+			return pushed[i].PodName < pushed[j].PodName
+		}
+		return pushed[i].SourceIP < pushed[j].SourceIP
+	})
+	return pushed
+}
+
+func (s *series) mergeHistogram(count uint64, sum, min, max float64) {
+	if s.count == 0 {
+		s.min, s.max = math.NaN(), math.NaN()
+	}
+	s.count += count
+	s.sum += sum
+	if !math.IsNaN(min) && (math.IsNaN(s.min) || min < s.min) {
+		s.min = min
+	}
+	if !math.IsNaN(max) && (math.IsNaN(s.max) || max > s.max) {
+		s.max = max
+	}
+}
+
+// samples returns the samples of a series for the current interval
+func (s *series) samples() []types.MetricSample {
+	switch s.kind {
+	case kindCounter:
+		return []types.MetricSample{{Name: s.name, Labels: s.labels, Value: s.value, Type: "counter"}}
+	case kindGauge:
+		return []types.MetricSample{{Name: s.name, Labels: s.labels, Value: s.value, Type: "gauge"}}
+	case kindSet:
+		return []types.MetricSample{{Name: s.name, Labels: s.labels, Value: float64(len(s.members)), Type: "gauge"}}
+	}
+
+	// Histograms are reported like Prometheus summaries
+	samples := []types.MetricSample{
+		{Name: s.name + "_count", Labels: s.labels, Value: float64(s.count), Type: "summary"},
+		{Name: s.name + "_sum", Labels: s.labels, Value: s.sum, Type: "summary"},
+	}
+	if !math.IsNaN(s.min) {
+		samples = append(samples, types.MetricSample{Name: s.name + "_min", Labels: s.labels, Value: s.min, Type: "summary"})
+	}
+	if !math.IsNaN(s.max) {
+		samples = append(samples, types.MetricSample{Name: s.name + "_max", Labels: s.labels, Value: s.max, Type: "summary"})
+	}
+
+	if len(s.values) > 0 {
+		values := append([]float64(nil), s.values...)
+		sort.Float64s(values)
+		for _, q := range quantiles {
+			labels := map[string]string{"quantile": strconv.FormatFloat(q, 'f', -1, 64)}
+			for name, value := range s.labels {
+				labels[name] = value
+			}
+			idx := int(math.Ceil(q*float64(len(values)))) - 1
+			samples = append(samples, types.MetricSample{Name: s.name, Labels: labels, Value: values[max(idx, 0)], Type: "summary"})
+		}
+	}
+	return samples
+}
+
+// canonicalLabels returns a stable representation of a label set
+func canonicalLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\xff")
+}
+
+// sanitizeName turns a pushed metric or label name into a valid Prometheus name
+// (dots and dashes of OTLP and StatsD names become underscores)
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+			b.WriteRune(c)
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func sanitizeLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	sanitized := make(map[string]string, len(labels))
+	for name, value := range labels {
+		sanitized[strings.ReplaceAll(sanitizeName(name), ":", "_")] = value
+	}
+	return sanitized
+}
+
+// hostIP returns the IP of a network address
+func hostIP(addr net.Addr) string {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP.String()
+	case *net.TCPAddr:
+		return a.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
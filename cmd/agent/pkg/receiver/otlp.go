@@ -0,0 +1,205 @@
+package receiver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// Resource attribute added as "service" label to the series of an OTLP export
+const serviceNameAttribute = "service.name"
+
+// OTLPReceiver implements the OTLP/gRPC metrics service
+type OTLPReceiver struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	addr       string
+	aggregator *Aggregator
+	server     *grpc.Server
+}
+
+// NewOTLPReceiver creates a new OTLP/gRPC receiver listening on addr
+func NewOTLPReceiver(addr string, aggregator *Aggregator) *OTLPReceiver {
+	return &OTLPReceiver{
+		addr:       addr,
+		aggregator: aggregator,
+	}
+}
+
+// Start listens on the TCP address and serves OTLP exports in the background
+func (r *OTLPReceiver) Start() error {
+	listener, err := net.Listen("tcp", r.addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", r.addr, err)
+	}
+
+	r.server = grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(r.server, r)
+
+	slog.Info("otlp receiver listening", "component", "receiver", "addr", listener.Addr().String())
+	go func() {
+		if err := r.server.Serve(listener); err != nil {
+			slog.Error("otlp receiver stopped", "component", "receiver", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the receiver
+func (r *OTLPReceiver) Close() {
+	if r.server != nil {
+		r.server.Stop()
+	}
+}
+
+// Export aggregates the data points of an OTLP metrics export
+func (r *OTLPReceiver) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	sourceIP := ""
+	if p, found := peer.FromContext(ctx); found {
+		sourceIP = hostIP(p.Addr)
+	}
+
+	for _, resourceMetrics := range req.GetResourceMetrics() {
+		service := ""
+		for _, attribute := range resourceMetrics.GetResource().GetAttributes() {
+			if attribute.GetKey() == serviceNameAttribute {
+				service = anyValueString(attribute.GetValue())
+			}
+		}
+
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			for _, metric := range scopeMetrics.GetMetrics() {
+				r.record(sourceIP, service, metric)
+			}
+		}
+	}
+
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+// record adds the data points of an OTLP metric to the aggregator
+func (r *OTLPReceiver) record(sourceIP, service string, metric *metricspb.Metric) {
+	name := metric.GetName()
+
+	switch data := metric.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		for _, point := range data.Gauge.GetDataPoints() {
+			r.aggregator.SetGauge(sourceIP, name, attributeLabels(point.GetAttributes(), service), numberValue(point))
+		}
+
+	case *metricspb.Metric_Sum:
+		cumulative := data.Sum.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		for _, point := range data.Sum.GetDataPoints() {
+			labels := attributeLabels(point.GetAttributes(), service)
+			value := numberValue(point)
+
+			switch {
+			case data.Sum.GetIsMonotonic() && cumulative:
+				r.aggregator.AddCounter(sourceIP, name, labels, r.aggregator.CumulativeDelta(sourceIP, name, labels, value))
+			case data.Sum.GetIsMonotonic():
+				r.aggregator.AddCounter(sourceIP, name, labels, value)
+			case cumulative:
+				// Non monotonic cumulative sums (up-down counters) are current values
+				r.aggregator.SetGauge(sourceIP, name, labels, value)
+			default:
+				r.aggregator.AddGauge(sourceIP, name, labels, value)
+			}
+		}
+
+	case *metricspb.Metric_Histogram:
+		cumulative := data.Histogram.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		for _, point := range data.Histogram.GetDataPoints() {
+			labels := attributeLabels(point.GetAttributes(), service)
+			min, max := math.NaN(), math.NaN()
+			if point.Min != nil {
+				min = point.GetMin()
+			}
+			if point.Max != nil {
+				max = point.GetMax()
+			}
+			r.mergeHistogram(sourceIP, name, labels, cumulative, point.GetCount(), point.GetSum(), min, max)
+		}
+
+	case *metricspb.Metric_ExponentialHistogram:
+		cumulative := data.ExponentialHistogram.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		for _, point := range data.ExponentialHistogram.GetDataPoints() {
+			labels := attributeLabels(point.GetAttributes(), service)
+			min, max := math.NaN(), math.NaN()
+			if point.Min != nil {
+				min = point.GetMin()
+			}
+			if point.Max != nil {
+				max = point.GetMax()
+			}
+			r.mergeHistogram(sourceIP, name, labels, cumulative, point.GetCount(), point.GetSum(), min, max)
+		}
+
+	case *metricspb.Metric_Summary:
+		// Summaries are always cumulative, quantiles are current values
+		for _, point := range data.Summary.GetDataPoints() {
+			labels := attributeLabels(point.GetAttributes(), service)
+			r.mergeHistogram(sourceIP, name, labels, true, point.GetCount(), point.GetSum(), math.NaN(), math.NaN())
+
+			for _, quantile := range point.GetQuantileValues() {
+				quantileLabels := map[string]string{"quantile": strconv.FormatFloat(quantile.GetQuantile(), 'f', -1, 64)}
+				for k, v := range labels {
+					quantileLabels[k] = v
+				}
+				r.aggregator.SetGauge(sourceIP, name, quantileLabels, quantile.GetValue())
+			}
+		}
+	}
+}
+
+// mergeHistogram merges a histogram point, cumulative points are turned into increments
+// (min and max of cumulative points cover the whole lifetime of the series and are dropped)
+func (r *OTLPReceiver) mergeHistogram(sourceIP, name string, labels map[string]string, cumulative bool, count uint64, sum, min, max float64) {
+	if cumulative {
+		count = uint64(r.aggregator.CumulativeDelta(sourceIP, name+"_count", labels, float64(count)))
+		sum = r.aggregator.CumulativeDelta(sourceIP, name+"_sum", labels, sum)
+		min, max = math.NaN(), math.NaN()
+	}
+	r.aggregator.MergeHistogram(sourceIP, name, labels, count, sum, min, max)
+}
+
+func numberValue(point *metricspb.NumberDataPoint) float64 {
+	if _, isInt := point.GetValue().(*metricspb.NumberDataPoint_AsInt); isInt {
+		return float64(point.GetAsInt())
+	}
+	return point.GetAsDouble()
+}
+
+// attributeLabels turns data point attributes into labels, with the service name of the resource
+func attributeLabels(attributes []*commonpb.KeyValue, service string) map[string]string {
+	labels := make(map[string]string, len(attributes)+1)
+	if service != "" {
+		labels["service"] = service
+	}
+	for _, attribute := range attributes {
+		labels[attribute.GetKey()] = anyValueString(attribute.GetValue())
+	}
+	return labels
+}
+
+func anyValueString(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
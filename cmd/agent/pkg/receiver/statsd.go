@@ -0,0 +1,165 @@
+package receiver
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Maximum size of a StatsD datagram
+const maxDatagramBytes = 65535
+
+// statsdMetric is a parsed StatsD line: <name>:<value>|<type>[|@<rate>][|#<tag>:<value>,...]
+type statsdMetric struct {
+	name     string
+	value    float64
+	raw      string // Raw value, used as member of sets
+	kind     string // c, g, ms, h, d or s
+	rate     float64
+	relative bool // Gauge update with an explicit sign (+N or -N)
+	labels   map[string]string
+}
+
+// StatsDReceiver receives StatsD metrics (with optional DogStatsD tags) over UDP
+type StatsDReceiver struct {
+	addr       string
+	aggregator *Aggregator
+	conn       net.PacketConn
+}
+
+// NewStatsDReceiver creates a new StatsD receiver listening on addr
+func NewStatsDReceiver(addr string, aggregator *Aggregator) *StatsDReceiver {
+	return &StatsDReceiver{
+		addr:       addr,
+		aggregator: aggregator,
+	}
+}
+
+// Start listens on the UDP address and handles datagrams in the background
+func (r *StatsDReceiver) Start() error {
+	conn, err := net.ListenPacket("udp", r.addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", r.addr, err)
+	}
+	r.conn = conn
+
+	slog.Info("statsd receiver listening", "component", "receiver", "addr", conn.LocalAddr().String())
+	go r.serve()
+	return nil
+}
+
+// Close stops the receiver
+func (r *StatsDReceiver) Close() error {
+	if r.conn == nil {
+		return nil
+	}
+	return r.conn.Close()
+}
+
+func (r *StatsDReceiver) serve() {
+	buf := make([]byte, maxDatagramBytes)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Error("error reading statsd datagram", "component", "receiver", "error", err)
+			continue
+		}
+
+		sourceIP := hostIP(addr)
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			metric, err := parseStatsDLine(line)
+			if err != nil {
+				slog.Debug("invalid statsd line", "component", "receiver", "source", sourceIP, "error", err)
+				continue
+			}
+			r.record(sourceIP, metric)
+		}
+	}
+}
+
+// record adds a parsed StatsD metric to the aggregator
+func (r *StatsDReceiver) record(sourceIP string, metric statsdMetric) {
+	switch metric.kind {
+	case "c":
+		r.aggregator.AddCounter(sourceIP, metric.name, metric.labels, metric.value/metric.rate)
+	case "g":
+		if metric.relative {
+			r.aggregator.AddGauge(sourceIP, metric.name, metric.labels, metric.value)
+		} else {
+			r.aggregator.SetGauge(sourceIP, metric.name, metric.labels, metric.value)
+		}
+	case "ms", "h", "d":
+		r.aggregator.Observe(sourceIP, metric.name, metric.labels, metric.value)
+	case "s":
+		r.aggregator.AddSetMember(sourceIP, metric.name, metric.labels, metric.raw)
+	}
+}
+
+// parseStatsDLine parses a single StatsD line
+func parseStatsDLine(line string) (statsdMetric, error) {
+	metric := statsdMetric{rate: 1}
+
+	name, rest, found := strings.Cut(line, ":")
+	if !found || name == "" {
+		return metric, fmt.Errorf("invalid line %q", line)
+	}
+	metric.name = name
+
+	parts := strings.Split(rest, "|")
+	if len(parts) < 2 {
+		return metric, fmt.Errorf("missing type in %q", line)
+	}
+	metric.raw = parts[0]
+	metric.kind = parts[1]
+
+	switch metric.kind {
+	case "c", "g", "ms", "h", "d":
+		value, err := strconv.ParseFloat(metric.raw, 64)
+		if err != nil {
+			return metric, fmt.Errorf("invalid value %q for %s", metric.raw, name)
+		}
+		metric.value = value
+		metric.relative = metric.kind == "g" && (strings.HasPrefix(metric.raw, "+") || strings.HasPrefix(metric.raw, "-"))
+	case "s":
+	default:
+		return metric, fmt.Errorf("unsupported type %q for %s", metric.kind, name)
+	}
+
+	for _, part := range parts[2:] {
+		switch {
+		case strings.HasPrefix(part, "@"):
+			rate, err := strconv.ParseFloat(part[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return metric, fmt.Errorf("invalid sample rate %q for %s", part, name)
+			}
+			metric.rate = rate
+		case strings.HasPrefix(part, "#"):
+			metric.labels = parseTags(part[1:])
+		}
+	}
+
+	return metric, nil
+}
+
+// parseTags parses DogStatsD tags (tag:value,...), tags without value are ignored
+func parseTags(tags string) map[string]string {
+	labels := make(map[string]string)
+	for _, tag := range strings.Split(tags, ",") {
+		name, value, found := strings.Cut(tag, ":")
+		if !found || name == "" {
+			continue
+		}
+		labels[name] = value
+	}
+	return labels
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/collector"
+	"github.com/ThomasCardin/gobservability/cmd/agent/pkg/config"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// settings combines the config file, the command line flags and the config pushed by the server,
+// in increasing precedence, and applies them to the collectors without reconnecting
+type settings struct {
+	registry *collector.Registry
+	filters  *collector.Filters
+	file     *config.Config
+	server   types.AgentConfig
+	mu       sync.Mutex
+}
+
+func newSettings(registry *collector.Registry, filters *collector.Filters, file *config.Config) *settings {
+	return &settings{registry: registry, filters: filters, file: file}
+}
+
+// reloadFile applies a reloaded config file, settings only read at startup are logged
+func (s *settings) reloadFile(reloaded *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	running := s.file
+	applyTLSFlags(reloaded) // Compared with the running TLS settings which include the flags
+	if err := s.apply(reloaded, s.server); err != nil {
+		slog.Error("invalid config, keeping the running config", "component", "config", "error", err)
+		return
+	}
+
+	for setting, changed := range map[string]bool{
+		"grpcServer":     running.GRPCServer != reloaded.GRPCServer,
+		"hostname":       running.Hostname != reloaded.Hostname,
+		"sampleInterval": running.SampleInterval != reloaded.SampleInterval,
+		"paths":          running.Paths != reloaded.Paths,
+		"tls":            running.TLS != reloaded.TLS,
+		"buffer":         running.Buffer != reloaded.Buffer,
+	} {
+		if changed {
+			slog.Warn("config setting changed, restart the agent to apply it", "component", "config", "setting", setting)
+		}
+	}
+
+	slog.Info("config reloaded", "component", "config")
+}
+
+// applyServer applies a config pushed by the server, it replaces the previously pushed config
+func (s *settings) applyServer(server types.AgentConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.apply(s.file, server)
+}
+
+// apply validates and applies the combined settings, nothing is changed on error
+func (s *settings) apply(file *config.Config, server types.AgentConfig) error {
+	enabledCollectors, collectorIntervals, err := collectorSettings(file, server)
+	if err != nil {
+		return err
+	}
+
+	filterConfig := file.Filters
+	for _, rule := range []struct {
+		file   *config.Rule
+		server types.FilterRule
+	}{
+		{&filterConfig.Namespaces, server.Filters.Namespaces},
+		{&filterConfig.Pods, server.Filters.Pods},
+		{&filterConfig.Interfaces, server.Filters.Interfaces},
+		{&filterConfig.Devices, server.Filters.Devices},
+	} {
+		if !rule.server.IsEmpty() {
+			*rule.file = config.Rule{Include: rule.server.Include, Exclude: rule.server.Exclude}
+		}
+	}
+	compiled, err := filterConfig.Compile()
+	if err != nil {
+		return err
+	}
+
+	// *collectInterval holds the interval of the config file loaded at startup
+	tick := *collectInterval
+	if !flagSet("interval") {
+		tick, _ = time.ParseDuration(flag.Lookup("interval").DefValue)
+		if file.Interval > 0 {
+			tick = file.Interval
+		}
+	}
+	if server.IntervalMs < 0 {
+		return fmt.Errorf("invalid interval %dms", server.IntervalMs)
+	}
+	if server.IntervalMs > 0 {
+		tick = time.Duration(server.IntervalMs) * time.Millisecond
+	}
+	// A lower tick must stay above the minimum and the sample interval
+	if err := s.registry.CheckTick(tick); err != nil {
+		return err
+	}
+
+	if err := s.registry.Configure(enabledCollectors, collectorIntervals); err != nil {
+		return err
+	}
+	s.filters.Set(compiled)
+	if err := s.registry.SetTick(tick); err != nil {
+		return err
+	}
+
+	s.file = file
+	s.server = server
+	return nil
+}
+
+// collectorSettings combines the collectors of the config file, the -enable-collectors,
+// -disable-collectors and -collector-interval flags and the config pushed by the server
+func collectorSettings(file *config.Config, server types.AgentConfig) (map[string]bool, map[string]time.Duration, error) {
+	enabledCollectors := make(map[string]bool)
+	collectorIntervals := make(map[string]time.Duration)
+
+	for _, toggle := range []struct {
+		names   []string
+		enabled bool
+	}{
+		{file.Collectors.Enabled, true},
+		{file.Collectors.Disabled, false},
+		{strings.Split(*enabled, ","), true},
+		{strings.Split(*disabled, ","), false},
+		{server.EnabledCollectors, true},
+		{server.DisabledCollectors, false},
+	} {
+		for _, name := range toggle.names {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			enabledCollectors[name] = toggle.enabled
+		}
+	}
+
+	for name, interval := range file.Collectors.Intervals {
+		collectorIntervals[name] = interval
+	}
+	for _, definition := range intervals {
+		name, value, found := strings.Cut(definition, "=")
+		if !found {
+			return nil, nil, fmt.Errorf("invalid collector interval %q, expected name=duration", definition)
+		}
+		interval, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid collector interval %q: %v", definition, err)
+		}
+		collectorIntervals[strings.TrimSpace(name)] = interval
+	}
+	for name, intervalMs := range server.CollectorIntervalsMs {
+		collectorIntervals[name] = time.Duration(intervalMs) * time.Millisecond
+	}
+
+	return enabledCollectors, collectorIntervals, nil
+}
@@ -2,17 +2,57 @@ package shared
 
 import "os"
 
+// Paths set from the agent configuration file, they override the dev mode defaults
+var (
+	procPath string
+	sysPath  string
+	rootPath string
+)
+
+// SetPaths overrides where the node /proc, /sys and root filesystem are mounted, empty values
+// keep the defaults. It must be called before collecting
+func SetPaths(proc, sys, root string) {
+	procPath, sysPath, rootPath = proc, sys, root
+}
+
 func GetProcBasePath(devMode string) string {
+	if procPath != "" {
+		return procPath
+	}
 	if isDev := os.Getenv(devMode); isDev == "true" {
 		return "/proc"
 	}
 	return "/host/proc"
 }
 
-// func getSysBasePath() string {
-// 	// Check if running in dev mode
-// 	if isDev := os.Getenv("AGENT_DEV_MODE"); isDev == "true" {
-// 		return "/sys"
-// 	}
-// 	return "/host/sys"
-// }
+// GetHostRootPath returns the path where the node root filesystem is mounted
+func GetHostRootPath(devMode string) string {
+	if rootPath != "" {
+		return rootPath
+	}
+	if isDev := os.Getenv(devMode); isDev == "true" {
+		return ""
+	}
+	return "/host/root"
+}
+
+// GetKubeletPodsPath returns the kubelet pods directory (volumes live under <uid>/volumes)
+func GetKubeletPodsPath(devMode string) string {
+	return GetHostRootPath(devMode) + "/var/lib/kubelet/pods"
+}
+
+// GetPodLogsPath returns the directory where the container runtime writes pod logs
+func GetPodLogsPath(devMode string) string {
+	return GetHostRootPath(devMode) + "/var/log/pods"
+}
+
+// GetSysBasePath returns the path where the node /sys is mounted
+func GetSysBasePath(devMode string) string {
+	if sysPath != "" {
+		return sysPath
+	}
+	if isDev := os.Getenv(devMode); isDev == "true" {
+		return "/sys"
+	}
+	return "/host/sys"
+}
@@ -51,7 +51,7 @@ func (d *DiscordNotifier) SendAlertTriggered(rule AlertRule, alert *Alert) error
 		Fields: []DiscordField{
 			{Name: "Node", Value: rule.NodeName, Inline: true},
 			{Name: "Target", Value: rule.Target, Inline: true},
-			{Name: "Metric", Value: string(rule.Metric), Inline: true},
+			{Name: "Metric", Value: d.getMetricName(rule), Inline: true},
 			{Name: "Current Value", Value: fmt.Sprintf("%.2f%s", alert.CurrentValue, unit), Inline: true},
 			{Name: "Threshold", Value: fmt.Sprintf("%s %.2f%s", rule.Operator, rule.Threshold, unit), Inline: true},
 			{Name: "Duration", Value: fmt.Sprintf("%ds", rule.DurationSeconds), Inline: true},
@@ -78,7 +78,7 @@ func (d *DiscordNotifier) SendAlertResolved(rule AlertRule, alert *Alert, curren
 		Fields: []DiscordField{
 			{Name: "Node", Value: rule.NodeName, Inline: true},
 			{Name: "Target", Value: rule.Target, Inline: true},
-			{Name: "Metric", Value: string(rule.Metric), Inline: true},
+			{Name: "Metric", Value: d.getMetricName(rule), Inline: true},
 			{Name: "Current Value", Value: fmt.Sprintf("%.2f%s", currentValue, unit), Inline: true},
 			{Name: "Was Above", Value: fmt.Sprintf("%.2f%s", rule.Threshold, unit), Inline: true},
 			{Name: "Active Duration", Value: duration.Round(time.Second).String(), Inline: true},
@@ -106,7 +106,7 @@ func (d *DiscordNotifier) SendAlertOngoing(rule AlertRule, alert *Alert) error {
 		Fields: []DiscordField{
 			{Name: "Node", Value: rule.NodeName, Inline: true},
 			{Name: "Target", Value: rule.Target, Inline: true},
-			{Name: "Metric", Value: string(rule.Metric), Inline: true},
+			{Name: "Metric", Value: d.getMetricName(rule), Inline: true},
 			{Name: "Current Value", Value: fmt.Sprintf("%.2f%s", alert.CurrentValue, unit), Inline: true},
 			{Name: "Threshold", Value: fmt.Sprintf("%s %.2f%s", rule.Operator, rule.Threshold, unit), Inline: true},
 			{Name: "Active For", Value: duration.Round(time.Second).String(), Inline: true},
@@ -135,7 +135,7 @@ func (d *DiscordNotifier) SendAlertDismissed(rule AlertRule, alert *Alert, curre
 		Fields: []DiscordField{
 			{Name: "Node", Value: rule.NodeName, Inline: true},
 			{Name: "Target", Value: rule.Target, Inline: true},
-			{Name: "Metric", Value: string(rule.Metric), Inline: true},
+			{Name: "Metric", Value: d.getMetricName(rule), Inline: true},
 			{Name: "Last Value", Value: fmt.Sprintf("%.2f%s", currentValue, unit), Inline: true},
 			{Name: "Threshold", Value: fmt.Sprintf("%s %.2f%s", rule.Operator, rule.Threshold, unit), Inline: true},
 			{Name: "Was Active For", Value: duration.Round(time.Second).String(), Inline: true},
@@ -151,12 +151,28 @@ func (d *DiscordNotifier) SendAlertDismissed(rule AlertRule, alert *Alert, curre
 	return d.sendMessage(message)
 }
 
+// getMetricName returns the metric with its log pattern or series selector
+func (d *DiscordNotifier) getMetricName(rule AlertRule) string {
+	switch rule.Metric {
+	case MetricLog:
+		return fmt.Sprintf("log /%s/", rule.Pattern)
+	case MetricProm, MetricCustom, MetricPushed:
+		return rule.Series
+	default:
+		return string(rule.Metric)
+	}
+}
+
 func (d *DiscordNotifier) getMetricUnit(metric MetricType) string {
 	switch metric {
-	case MetricCPU, MetricMemory:
+	case MetricCPU, MetricMemory, MetricVolume:
 		return "%"
 	case MetricNetwork, MetricDisk:
 		return "MB/s"
+	case MetricLog:
+		return " matches/min"
+	case MetricAgent:
+		return "s offline"
 	default:
 		return ""
 	}
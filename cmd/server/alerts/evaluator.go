@@ -30,6 +30,10 @@ func (e *AlertEvaluator) EvaluateMetrics(nodeStats types.NodeStatsPayload) error
 	
 	// Evaluate each rule
 	for _, rule := range rules {
+		if rule.Metric == MetricAgent {
+			// Evaluated from the node state by EvaluateAgentRules
+			continue
+		}
 		if err := e.evaluateRule(rule, nodeStats); err != nil {
 			// Log error but continue with other rules
 			fmt.Printf("Error evaluating rule %s: %v\n", rule.ID, err)
@@ -39,13 +43,36 @@ func (e *AlertEvaluator) EvaluateMetrics(nodeStats types.NodeStatsPayload) error
 	return nil
 }
 
+// EvaluateAgentRules evaluates the agent rules of a node against the time it has been offline
+func (e *AlertEvaluator) EvaluateAgentRules(nodeName string, offlineSeconds float64) error {
+	rules, err := e.storage.GetEnabledRulesByNode(nodeName)
+	if err != nil {
+		return fmt.Errorf("failed to get enabled rules for node %s: %w", nodeName, err)
+	}
+
+	for _, rule := range rules {
+		if rule.Metric != MetricAgent {
+			continue
+		}
+		if err := e.evaluateValue(rule, offlineSeconds); err != nil {
+			fmt.Printf("Error evaluating rule %s: %v\n", rule.ID, err)
+		}
+	}
+	return nil
+}
+
 func (e *AlertEvaluator) evaluateRule(rule AlertRule, nodeStats types.NodeStatsPayload) error {
 	// Extract value according to rule
 	value, err := e.extractMetricValue(rule, nodeStats)
 	if err != nil {
 		return err
 	}
+	return e.evaluateValue(rule, value)
+}
 
+// evaluateValue updates the evaluation of a rule with its current value, and triggers or resolves
+// its alert
+func (e *AlertEvaluator) evaluateValue(rule AlertRule, value float64) error {
 	// Check if threshold is exceeded
 	isTriggered := e.checkThreshold(rule.Operator, value, rule.Threshold)
 	
@@ -54,7 +81,7 @@ func (e *AlertEvaluator) evaluateRule(rule AlertRule, nodeStats types.NodeStatsP
 		rule.ID, rule.Target, rule.Metric, value, rule.Threshold, rule.Operator, isTriggered)
 
 	// Get previous evaluation
-	eval := e.storage.GetEvaluation(rule.NodeName, rule.Target, rule.Metric)
+	eval := e.storage.GetEvaluation(rule.ID, rule.NodeName, rule.Target, rule.Metric)
 
 	if eval == nil {
 		// First evaluation
@@ -121,18 +148,41 @@ func (e *AlertEvaluator) extractMetricValue(rule AlertRule, nodeStats types.Node
 			return nodeStats.Metrics.CPU.CPUPercent, nil
 		case MetricMemory:
 			total := float64(nodeStats.Metrics.Memory.MemTotal)
+			if total == 0 {
+				return 0, fmt.Errorf("memory collector disabled on node %s", nodeStats.NodeName)
+			}
 			available := float64(nodeStats.Metrics.Memory.MemAvailable)
 			return ((total - available) / total) * 100, nil
 		case MetricNetwork:
 			return nodeStats.Metrics.Network.TotalRate, nil
 		case MetricDisk:
 			return nodeStats.Metrics.Disk.TotalRate, nil
+		case MetricVolume:
+			// Highest usage among all pods of the node
+			maxPercent := 0.0
+			for _, pod := range nodeStats.Metrics.Pods {
+				if percent := pod.PodMetrics.Storage.MaxUsagePercent(); percent > maxPercent {
+					maxPercent = percent
+				}
+			}
+			return maxPercent, nil
+		case MetricCustom:
+			return e.extractCustomValue(rule, nodeStats)
+		case MetricPushed:
+			return e.extractPushedValue(rule, nodeStats, "")
 		}
 	} else if len(rule.Target) > 4 && rule.Target[:4] == "pod:" {
 		// Pod metrics
 		podName := rule.Target[4:] // Remove "pod:"
 		for _, pod := range nodeStats.Metrics.Pods {
 			if pod.Name == podName {
+				switch rule.Metric {
+				case MetricCPU, MetricMemory, MetricNetwork, MetricDisk, MetricVolume:
+					// Reads of the pod timed out on the agent, its metrics are not set
+					if pod.Partial {
+						return 0, fmt.Errorf("metrics of pod %s timed out on the agent", podName)
+					}
+				}
 				switch rule.Metric {
 				case MetricCPU:
 					return pod.PodMetrics.CPU.CPUPercent, nil
@@ -144,6 +194,14 @@ func (e *AlertEvaluator) extractMetricValue(rule AlertRule, nodeStats types.Node
 				case MetricDisk:
 					return float64(pod.PodMetrics.Disk.ReadBytes+
 						pod.PodMetrics.Disk.WriteBytes) / 1024 / 1024, nil
+				case MetricVolume:
+					return pod.PodMetrics.Storage.MaxUsagePercent(), nil
+				case MetricLog:
+					return e.extractLogPatternValue(rule, nodeStats)
+				case MetricProm:
+					return e.extractSeriesValue(rule, pod)
+				case MetricPushed:
+					return e.extractPushedValue(rule, nodeStats, pod.Name)
 				}
 			}
 		}
@@ -153,6 +211,88 @@ func (e *AlertEvaluator) extractMetricValue(rule AlertRule, nodeStats types.Node
 	return 0, fmt.Errorf("unsupported metric %s for target %s", rule.Metric, rule.Target)
 }
 
+// extractLogPatternValue returns the matches per minute reported by the agent for a log pattern rule
+func (e *AlertEvaluator) extractLogPatternValue(rule AlertRule, nodeStats types.NodeStatsPayload) (float64, error) {
+	for _, count := range nodeStats.Metrics.LogPatterns {
+		if count.RuleID != rule.ID.String() {
+			continue
+		}
+		if count.Error != "" {
+			return 0, fmt.Errorf("log pattern error on agent: %s", count.Error)
+		}
+		return float64(count.MatchesLastMinute), nil
+	}
+	return 0, fmt.Errorf("no log pattern count reported yet for rule %s", rule.ID)
+}
+
+// extractSeriesValue returns the sum of the scraped Prometheus series selected by a rule
+func (e *AlertEvaluator) extractSeriesValue(rule AlertRule, pod *types.Pod) (float64, error) {
+	scrape := pod.PodMetrics.Scrape
+	if scrape == nil {
+		return 0, fmt.Errorf("pod %s is not scraped (missing prometheus.io/scrape annotation)", pod.Name)
+	}
+	if len(scrape.Samples) == 0 && scrape.Error != "" {
+		return 0, fmt.Errorf("scrape of pod %s failed: %s", pod.Name, scrape.Error)
+	}
+
+	selector, err := ParseSeriesSelector(rule.Series)
+	if err != nil {
+		return 0, err
+	}
+
+	value, found := selector.Sum(scrape.Samples)
+	if !found {
+		return 0, fmt.Errorf("no series matching %s for pod %s", rule.Series, pod.Name)
+	}
+	return value, nil
+}
+
+// extractCustomValue returns the sum of the custom collector series selected by a rule
+func (e *AlertEvaluator) extractCustomValue(rule AlertRule, nodeStats types.NodeStatsPayload) (float64, error) {
+	selector, err := ParseSeriesSelector(rule.Series)
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	found := false
+	for _, custom := range nodeStats.Metrics.CustomMetrics {
+		if value, ok := selector.Sum(custom.Samples); ok {
+			sum += value
+			found = true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no custom series matching %s on node %s", rule.Series, nodeStats.NodeName)
+	}
+	return sum, nil
+}
+
+// extractPushedValue returns the sum of the StatsD / OTLP series selected by a rule,
+// for all sources of the node if podName is empty
+func (e *AlertEvaluator) extractPushedValue(rule AlertRule, nodeStats types.NodeStatsPayload, podName string) (float64, error) {
+	selector, err := ParseSeriesSelector(rule.Series)
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	found := false
+	for _, pushed := range nodeStats.Metrics.Pushed {
+		if podName != "" && pushed.PodName != podName {
+			continue
+		}
+		if value, ok := selector.Sum(pushed.Samples); ok {
+			sum += value
+			found = true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no pushed series matching %s on %s", rule.Series, rule.Target)
+	}
+	return sum, nil
+}
+
 func (e *AlertEvaluator) checkThreshold(operator OperatorType, value, threshold float64) bool {
 	switch operator {
 	case OpGreater:
@@ -3,6 +3,7 @@ package alerts
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ThomasCardin/gobservability/shared/types"
@@ -56,6 +57,35 @@ func (m *AlertsManager) EvaluateMetrics(nodeStats types.NodeStatsPayload) {
 	}
 }
 
+// EvaluateNodeState evaluates the agent rules of a node, called periodically with the time the node
+// has been offline since agent rules cannot wait for a payload
+func (m *AlertsManager) EvaluateNodeState(nodeName string, offlineSeconds float64) {
+	if err := m.evaluator.EvaluateAgentRules(nodeName, offlineSeconds); err != nil {
+		fmt.Printf("Error evaluating agent alerts for node %s: %v\n", nodeName, err)
+	}
+}
+
+// LogPatternRules returns the enabled log pattern rules of a node, pushed to its agent
+func (m *AlertsManager) LogPatternRules(nodeName string) ([]types.LogPatternRule, error) {
+	rules, err := m.storage.GetEnabledRulesByNode(nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var logRules []types.LogPatternRule
+	for _, rule := range rules {
+		if rule.Metric != MetricLog || !strings.HasPrefix(rule.Target, "pod:") {
+			continue
+		}
+		logRules = append(logRules, types.LogPatternRule{
+			RuleID:  rule.ID.String(),
+			PodName: strings.TrimPrefix(rule.Target, "pod:"),
+			Pattern: rule.Pattern,
+		})
+	}
+	return logRules, nil
+}
+
 // API pour l'interface web
 func (m *AlertsManager) GetStorage() *AlertsStorage {
 	return m.storage
@@ -0,0 +1,87 @@
+package alerts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// SeriesSelector selects scraped Prometheus series by name and label values
+// Syntax: metric_name{label="value",other!="value"} (labels are optional)
+type SeriesSelector struct {
+	Name     string
+	Matchers []LabelMatcher
+}
+
+// LabelMatcher matches a label value (= or !=)
+type LabelMatcher struct {
+	Name     string
+	Value    string
+	Negative bool
+}
+
+var (
+	selectorRegex = regexp.MustCompile(`^\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(?:\{(.*)\})?\s*$`)
+	matcherRegex  = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*(!=|=)\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// ParseSeriesSelector parses a series selector used by prometheus alert rules
+func ParseSeriesSelector(selector string) (*SeriesSelector, error) {
+	match := selectorRegex.FindStringSubmatch(selector)
+	if match == nil {
+		return nil, fmt.Errorf("invalid series selector %q (expected name{label=\"value\"})", selector)
+	}
+
+	result := &SeriesSelector{Name: match[1]}
+	if strings.TrimSpace(match[2]) == "" {
+		return result, nil
+	}
+
+	// Everything between matchers must be separators, so values may contain commas
+	labels := match[2]
+	last := 0
+	for _, m := range matcherRegex.FindAllStringSubmatchIndex(labels, -1) {
+		if strings.Trim(labels[last:m[0]], " ,") != "" {
+			return nil, fmt.Errorf("invalid label matchers %q", labels)
+		}
+		last = m[1]
+
+		result.Matchers = append(result.Matchers, LabelMatcher{
+			Name:     labels[m[2]:m[3]],
+			Value:    strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(labels[m[6]:m[7]]),
+			Negative: labels[m[4]:m[5]] == "!=",
+		})
+	}
+	if strings.Trim(labels[last:], " ,") != "" {
+		return nil, fmt.Errorf("invalid label matchers %q", labels)
+	}
+	return result, nil
+}
+
+// Matches returns true if the sample has the selector name and all label matchers match
+func (s *SeriesSelector) Matches(sample types.MetricSample) bool {
+	if sample.Name != s.Name {
+		return false
+	}
+	for _, matcher := range s.Matchers {
+		if (sample.Labels[matcher.Name] == matcher.Value) == matcher.Negative {
+			return false
+		}
+	}
+	return true
+}
+
+// Sum returns the sum of the matching samples and whether any sample matched
+func (s *SeriesSelector) Sum(samples []types.MetricSample) (float64, bool) {
+	sum := 0.0
+	found := false
+	for _, sample := range samples {
+		if s.Matches(sample) {
+			sum += sample.Value
+			found = true
+		}
+	}
+	return sum, found
+}
@@ -195,13 +195,15 @@ func (s *AlertsStorage) invalidateRulesCache(nodeName string) {
 	s.rulesCache.Delete("rules_enabled_" + nodeName)
 }
 
+// Evaluations are keyed by rule so several rules can watch the same target and metric
+// (e.g. two log patterns on the same pod)
 func (s *AlertsStorage) SetEvaluation(eval *AlertEvaluation) {
-	key := fmt.Sprintf("eval_%s_%s_%s", eval.NodeName, eval.Target, eval.Metric)
+	key := fmt.Sprintf("eval_%s_%s_%s_%s", eval.NodeName, eval.Target, eval.Metric, eval.RuleID)
 	s.evaluationCache.Set(key, eval, cache.DefaultExpiration)
 }
 
-func (s *AlertsStorage) GetEvaluation(nodeName, target string, metric MetricType) *AlertEvaluation {
-	key := fmt.Sprintf("eval_%s_%s_%s", nodeName, target, metric)
+func (s *AlertsStorage) GetEvaluation(ruleID uuid.UUID, nodeName, target string, metric MetricType) *AlertEvaluation {
+	key := fmt.Sprintf("eval_%s_%s_%s_%s", nodeName, target, metric, ruleID)
 	if cached, found := s.evaluationCache.Get(key); found {
 		eval := cached.(*AlertEvaluation)
 		return eval
@@ -14,6 +14,12 @@ const (
 	MetricMemory  MetricType = "memory"
 	MetricNetwork MetricType = "network"
 	MetricDisk    MetricType = "disk"
+	MetricVolume  MetricType = "volume"     // Highest volume / ephemeral storage usage (%)
+	MetricLog     MetricType = "log"        // Log lines matching Pattern per minute (pod targets only)
+	MetricProm    MetricType = "prometheus" // Sum of the scraped series matching Series (pod targets only)
+	MetricCustom  MetricType = "custom"     // Sum of the custom collector series matching Series (node targets only)
+	MetricPushed  MetricType = "pushed"     // Sum of the StatsD / OTLP series matching Series (node or pod targets)
+	MetricAgent   MetricType = "agent"      // Seconds the node has been offline, 0 while online, stale or stopped (node targets only)
 )
 
 type OperatorType string
@@ -40,6 +46,8 @@ type AlertRule struct {
 	Metric                 MetricType   `gorm:"type:varchar(20);not null" json:"metric"`
 	Operator               OperatorType `gorm:"type:varchar(5);not null" json:"operator"`
 	Threshold              float64      `gorm:"not null" json:"threshold"`
+	Pattern                string       `gorm:"default:''" json:"pattern,omitempty"`         // Regex for log metric rules
+	Series                 string       `gorm:"default:''" json:"series,omitempty"`          // Series selector for prometheus, custom and pushed metric rules
 	ResolveThreshold       *float64     `gorm:"default:null" json:"resolve_threshold,omitempty"`
 	DurationSeconds        int          `gorm:"default:60" json:"duration_seconds"`
 	DiscordFrequencyMinutes int          `gorm:"default:5" json:"discord_frequency_minutes"` // Discord notification frequency
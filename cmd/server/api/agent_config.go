@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ThomasCardin/gobservability/cmd/server/formatter"
+	grpcServer "github.com/ThomasCardin/gobservability/cmd/server/grpc"
+	"github.com/ThomasCardin/gobservability/cmd/server/storage"
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"github.com/gin-gonic/gin"
+)
+
+// GET /api/agent-config - default config and config status of every node
+func GetAgentConfigsHandler(c *gin.Context) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	var nodeNames []string
+	for name := range storage.GlobalStore.GetAllNodes() {
+		nodeNames = append(nodeNames, name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default": server.DefaultAgentConfig(),
+		"nodes":   server.AgentConfigStatuses(nodeNames),
+	})
+}
+
+// GET /api/agent-config/:nodename - config status of a node
+func GetAgentConfigHandler(c *gin.Context) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	c.JSON(http.StatusOK, server.AgentConfigStatus(c.Param("nodename")))
+}
+
+// PUT /api/agent-config and /api/agent-config/:nodename - set the config of all agents or a node
+func SetAgentConfigHandler(c *gin.Context) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	var config types.AgentConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	normalizeAgentConfig(&config)
+
+	nodeName := c.Param("nodename")
+	if err := server.SetAgentConfig(nodeName, config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if nodeName == "" {
+		c.JSON(http.StatusOK, gin.H{"default": server.DefaultAgentConfig()})
+		return
+	}
+	c.JSON(http.StatusOK, server.AgentConfigStatus(nodeName))
+}
+
+// DELETE /api/agent-config and /api/agent-config/:nodename - remove the config of all agents or a node
+func ResetAgentConfigHandler(c *gin.Context) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	server.ResetAgentConfig(c.Param("nodename"))
+	c.JSON(http.StatusOK, gin.H{"message": "Agent config reset"})
+}
+
+// GET /agent-config/:nodename - agent config page of a node
+func AgentConfigPageHandler(c *gin.Context) {
+	nodeName := c.Param("nodename")
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.HTML(http.StatusServiceUnavailable, "error.html", gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	// Collectors reported by the agent with their running state
+	var collectors []formatter.UICollector
+	if uiNode, found := getUINode(nodeName); found {
+		collectors = uiNode.Collectors
+	}
+
+	status := server.AgentConfigStatus(nodeName)
+	c.HTML(http.StatusOK, "agent-config.html", gin.H{
+		"NodeName":   nodeName,
+		"Status":     status,
+		"Config":     formatter.FormatAgentConfigForUI(status.Config),
+		"Collectors": collectors,
+	})
+}
+
+// normalizeAgentConfig trims the names and drops empty entries entered in the UI
+func normalizeAgentConfig(config *types.AgentConfig) {
+	config.Version = 0
+	config.EnabledCollectors = trimList(config.EnabledCollectors)
+	config.DisabledCollectors = trimList(config.DisabledCollectors)
+	for _, rule := range []*types.FilterRule{
+		&config.Filters.Namespaces,
+		&config.Filters.Pods,
+		&config.Filters.Interfaces,
+		&config.Filters.Devices,
+	} {
+		rule.Include = trimList(rule.Include)
+		rule.Exclude = trimList(rule.Exclude)
+	}
+}
+
+func trimList(values []string) []string {
+	var trimmed []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
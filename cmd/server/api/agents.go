@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	grpcServer "github.com/ThomasCardin/gobservability/cmd/server/grpc"
+	"github.com/gin-gonic/gin"
+)
+
+// GET /agents - fleet page of the connected agents
+func AgentsPageHandler(c *gin.Context) {
+	if grpcServer.GetServerInstance() == nil {
+		c.HTML(http.StatusServiceUnavailable, "error.html", gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	c.HTML(http.StatusOK, "agents.html", gin.H{
+		"PingInterval": grpcServer.PingInterval.String(),
+	})
+}
+
+// GET /api/agents - connected agents with their stream statistics
+func GetAgentsHandler(c *gin.Context) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"agents": server.Agents()})
+}
+
+// POST /api/agents/:nodename/disconnect - close the stream of an agent, it reconnects after its backoff
+func DisconnectAgentHandler(c *gin.Context) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	nodeName := c.Param("nodename")
+	if err := server.DisconnectAgent(nodeName); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "agent " + nodeName + " disconnected"})
+}
+
+// POST /api/agents/:nodename/collect - ask an agent to collect and send its metrics now
+func CollectAgentHandler(c *gin.Context) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gRPC server not started"})
+		return
+	}
+
+	nodeName := c.Param("nodename")
+	if err := server.RequestCollection(nodeName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "collection requested from " + nodeName})
+}
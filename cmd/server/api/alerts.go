@@ -3,9 +3,12 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/ThomasCardin/gobservability/cmd/server/alerts"
+	grpcServer "github.com/ThomasCardin/gobservability/cmd/server/grpc"
 	"github.com/ThomasCardin/gobservability/cmd/server/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -26,6 +29,64 @@ func getDiscordNotifier() *alerts.DiscordNotifier {
 	return discordNotifier
 }
 
+// validateRuleSelector checks the selector fields needed by the metric of a rule, the log pattern
+// or the series selector, and clears the ones it does not use
+func validateRuleSelector(rule *alerts.AlertRule) error {
+	switch rule.Metric {
+	case alerts.MetricLog:
+		rule.Series = ""
+		if !strings.HasPrefix(rule.Target, "pod:") {
+			return fmt.Errorf("%s rules must target a pod", rule.Metric)
+		}
+		if rule.Pattern == "" {
+			return fmt.Errorf("pattern required for log rules")
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	case alerts.MetricProm:
+		rule.Pattern = ""
+		if !strings.HasPrefix(rule.Target, "pod:") {
+			return fmt.Errorf("%s rules must target a pod", rule.Metric)
+		}
+		if _, err := alerts.ParseSeriesSelector(rule.Series); err != nil {
+			return err
+		}
+	case alerts.MetricCustom:
+		rule.Pattern = ""
+		if rule.Target != "node" {
+			return fmt.Errorf("%s rules must target the node", rule.Metric)
+		}
+		if _, err := alerts.ParseSeriesSelector(rule.Series); err != nil {
+			return err
+		}
+	case alerts.MetricPushed:
+		rule.Pattern = ""
+		if _, err := alerts.ParseSeriesSelector(rule.Series); err != nil {
+			return err
+		}
+	case alerts.MetricAgent:
+		rule.Pattern, rule.Series = "", ""
+		if rule.Target != "node" {
+			return fmt.Errorf("%s rules must target the node", rule.Metric)
+		}
+	default:
+		rule.Pattern, rule.Series = "", ""
+	}
+	return nil
+}
+
+// pushLogPatternRules sends the updated log pattern rules to the agent of the node
+func pushLogPatternRules(nodeName string) {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		return
+	}
+	if err := server.PushLogPatternRules(nodeName); err != nil {
+		fmt.Printf("[ALERT ERROR] %v\n", err)
+	}
+}
+
 // GET /api/alerts/:nodename - JSON API
 func GetAlertsHandler(c *gin.Context) {
 	nodeName := c.Param("nodename")
@@ -86,10 +147,16 @@ func CreateAlertRuleHandler(c *gin.Context) {
 
 	rule.NodeName = nodeName
 
+	if err := validateRuleSelector(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := alertsStorage.CreateRule(&rule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	pushLogPatternRules(nodeName)
 	
 	fmt.Printf("[ALERT DEBUG] Rule created: ID=%s, NodeName=%s, Target=%s, Metric=%s\n", 
 		rule.ID, rule.NodeName, rule.Target, rule.Metric)
@@ -136,10 +203,16 @@ func UpdateAlertRuleHandler(c *gin.Context) {
 	updates.NodeName = existingRule.NodeName
 	updates.CreatedAt = existingRule.CreatedAt
 
+	if err := validateRuleSelector(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := alertsStorage.UpdateRule(&updates); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	pushLogPatternRules(updates.NodeName)
 
 	c.JSON(http.StatusOK, updates)
 }
@@ -174,6 +247,7 @@ func DeleteAlertRuleHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	pushLogPatternRules(rule.NodeName)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Rule deleted successfully"})
 }
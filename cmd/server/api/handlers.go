@@ -16,6 +16,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Time to wait for the agent to read the details of a pod process
+const podDetailsTimeout = 3 * time.Second
+
 func ReceiveStatsHandler(c *gin.Context) {
 	var payload types.NodeStatsPayload
 
@@ -194,8 +197,47 @@ func PodProcessDetailsHandler(c *gin.Context) {
 		return
 	}
 
-	// Return the PidDetails directly
-	c.JSON(http.StatusOK, targetPod.PidDetails)
+	c.JSON(http.StatusOK, podProcessDetails(c, nodeName, targetPod))
+}
+
+// podProcessDetails requests the detail tier of a pod process from its agent
+// Stats only carry the summary tier (all the details if the agent process collector is enabled),
+// which is returned if the agent cannot be reached
+func podProcessDetails(c *gin.Context, nodeName string, pod *types.Pod) *types.PidDetails {
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		return &pod.PidDetails
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), podDetailsTimeout)
+	defer cancel()
+
+	details, err := server.RequestPodDetails(ctx, nodeName, pod.Name)
+	if err != nil {
+		log.Printf("Warning: failed to get process details of pod %s on node %s: %v", pod.Name, nodeName, err)
+		return &pod.PidDetails
+	}
+	return details
+}
+
+// unavailableFeatures returns the features the agent of a node cannot serve with the reason, the
+// UI disables the matching actions
+func unavailableFeatures(nodeName string) map[string]string {
+	unavailable := make(map[string]string)
+	server := grpcServer.GetServerInstance()
+	if server == nil {
+		return unavailable
+	}
+	capabilities, found := server.AgentCapabilities(nodeName)
+	if !found {
+		return unavailable
+	}
+	for _, feature := range []string{types.FeatureFlamegraph, types.FeatureKernelStacks, types.FeatureLogs} {
+		if available, reason := capabilities.Supports(feature); !available {
+			unavailable[feature] = reason
+		}
+	}
+	return unavailable
 }
 
 // ProcessDetailsPageHandler returns the complete process details page
@@ -235,6 +277,7 @@ func ProcessDetailsPageHandler(c *gin.Context) {
 			"PodName":        podName,
 			"Pod":            nil,
 			"ProcessDetails": nil,
+			"Unavailable":    unavailableFeatures(nodeName),
 		})
 		return
 	}
@@ -244,13 +287,15 @@ func ProcessDetailsPageHandler(c *gin.Context) {
 
 	// Format pod for UI display
 	uiPod := formatter.FormatPodForUI(targetPod)
+	uiPod.PushedSeries = formatter.FormatPodPushedForUI(nodeStats.Metrics.Pushed, targetPod.Name)
 
 	if !uiFound {
 		c.HTML(http.StatusOK, "process-details.html", gin.H{
 			"NodeName":       nodeName,
 			"PodName":        podName,
 			"Pod":            &uiPod,
-			"ProcessDetails": &targetPod.PidDetails,
+			"ProcessDetails": podProcessDetails(c, nodeName, targetPod),
+			"Unavailable":    unavailableFeatures(nodeName),
 		})
 		return
 	}
@@ -259,7 +304,8 @@ func ProcessDetailsPageHandler(c *gin.Context) {
 		"NodeName":       nodeName,
 		"PodName":        podName,
 		"Pod":            &uiPod,
-		"ProcessDetails": &targetPod.PidDetails,
+		"ProcessDetails": podProcessDetails(c, nodeName, targetPod),
+		"Unavailable":    unavailableFeatures(nodeName),
 		// Node metrics
 		"CPU":          uiNode.CPU,
 		"CPUTotal":     uiNode.CPUTotal,
@@ -319,6 +365,7 @@ func PodInfoHandler(c *gin.Context) {
 
 	// Format pod for UI display
 	uiPod := formatter.FormatPodForUI(targetPod)
+	uiPod.PushedSeries = formatter.FormatPodPushedForUI(nodeStats.Metrics.Pushed, targetPod.Name)
 
 	c.HTML(http.StatusOK, "pod-info-fragment.html", gin.H{
 		"Pod": &uiPod,
@@ -367,7 +414,7 @@ func ProcessDetailsFragmentHandler(c *gin.Context) {
 	c.HTML(http.StatusOK, "process-details-fragment.html", gin.H{
 		"PodName":            podName,
 		"PID":                targetPod.PID,
-		"ProcessDetails":     &targetPod.PidDetails,
+		"ProcessDetails":     podProcessDetails(c, nodeName, targetPod),
 		"ResourceLimits":     &targetPod.ResourceLimits,
 		"ResourceRequests":   &targetPod.ResourceRequests,
 	})
@@ -426,6 +473,11 @@ func GenerateFlamegraphHandler(c *gin.Context) {
 		return
 	}
 
+	if reason, found := unavailableFeatures(nodeName)[types.FeatureFlamegraph]; found {
+		c.JSON(http.StatusConflict, gin.H{"error": "Flamegraphs are unavailable on this node: " + reason})
+		return
+	}
+
 	// Generate a unique task ID
 	taskID := fmt.Sprintf("%s-%s-%d", nodeName, podName, time.Now().UnixNano())
 
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	grpcServer "github.com/ThomasCardin/gobservability/cmd/server/grpc"
+	"github.com/ThomasCardin/gobservability/cmd/server/storage"
+	pb "github.com/ThomasCardin/gobservability/proto"
+	sharedGrpc "github.com/ThomasCardin/gobservability/shared/grpc"
+	"github.com/gin-gonic/gin"
+)
+
+const maxTailLines = 5000
+
+// PodLogsHandler streams the container logs of a pod as Server-Sent Events
+// Query: container (optional), tail (default 200), follow (true|false)
+// Events: "lines" (JSON array of log lines), "dropped" (lines lost because the viewer was too
+// slow), "failed" (error message) and "end"
+func PodLogsHandler(c *gin.Context) {
+	nodeName := c.Param("nodename")
+	podName := c.Param("podname")
+
+	tail, err := strconv.Atoi(c.DefaultQuery("tail", "200"))
+	if err != nil || tail < 0 || tail > maxTailLines {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tail (0-5000 lines)"})
+		return
+	}
+	follow := c.DefaultQuery("follow", "false") == "true"
+
+	// Verify the pod exists
+	nodeStats, found := storage.GlobalStore.GetNodeStats(nodeName)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Node not found"})
+		return
+	}
+
+	podFound := false
+	for _, pod := range nodeStats.Metrics.Pods {
+		if pod.Name == podName {
+			podFound = true
+			break
+		}
+	}
+	if !podFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pod not found"})
+		return
+	}
+
+	grpcServer := grpcServer.GetServerInstance()
+	if grpcServer == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "gRPC server not initialized"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	req := &pb.LogRequest{
+		NodeName:  nodeName,
+		PodName:   podName,
+		Container: c.Query("container"),
+		TailLines: int32(tail),
+		Follow:    follow,
+	}
+
+	// The request context is cancelled when the browser closes the viewer
+	err = grpcServer.StreamLogs(c.Request.Context(), req, func(chunk *pb.LogChunk, dropped int) error {
+		if dropped > 0 {
+			c.SSEvent("dropped", dropped)
+		}
+		if len(chunk.Lines) > 0 {
+			c.SSEvent("lines", sharedGrpc.ConvertLogLines(chunk.Lines))
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		log.Printf("Log stream for %s/%s failed: %v", nodeName, podName, err)
+		c.SSEvent("failed", err.Error())
+	}
+
+	c.SSEvent("end", "")
+	c.Writer.Flush()
+}
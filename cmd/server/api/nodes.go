@@ -1,26 +1,24 @@
 package api
 
 import (
-	"sort"
+	"net/http"
+	"time"
 
 	"github.com/ThomasCardin/gobservability/cmd/server/formatter"
 	"github.com/ThomasCardin/gobservability/cmd/server/storage"
+	"github.com/gin-gonic/gin"
 )
 
+// getUINodes returns the nodes seen during the retention with their state, nodes that are not
+// online show their last metrics
 func getUINodes() []formatter.UINode {
-	nodes := storage.GlobalStore.GetAllNodes()
 	var uiNodes []formatter.UINode
 
-	for name, stats := range nodes {
-		// Format node for UI display
-		uiNode := formatter.FormatNodeForUI(name, stats)
-		uiNodes = append(uiNodes, uiNode)
+	for _, status := range storage.GlobalStore.GetNodeStatuses() {
+		stats, _ := storage.GlobalStore.GetLastNodeStats(status.NodeName)
+		uiNodes = append(uiNodes, formatter.FormatNodeStateForUI(status, stats))
 	}
 
-	sort.Slice(uiNodes, func(i, j int) bool {
-		return uiNodes[i].Name < uiNodes[j].Name
-	})
-
 	return uiNodes
 }
 
@@ -34,3 +32,43 @@ func getUINode(nodeName string) (*formatter.UINode, bool) {
 	uiNode := formatter.FormatNodeForUI(nodeName, stats)
 	return &uiNode, true
 }
+
+// GET /api/nodes - state of the nodes seen during the retention
+func NodeStatusesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"nodes": storage.GlobalStore.GetNodeStatuses()})
+}
+
+// GET /api/nodes/:nodename - state of a node
+func NodeStatusHandler(c *gin.Context) {
+	nodeName := c.Param("nodename")
+	status, found := storage.GlobalStore.GetNodeStatus(nodeName)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "node " + nodeName + " not seen"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// GET /api/nodes/:nodename/samples - node samples under their own timestamp, oldest first
+// since is an RFC 3339 timestamp or a duration before now (e.g. 5m), all kept samples if unset
+func NodeSamplesHandler(c *gin.Context) {
+	since := time.Time{}
+	if value := c.Query("since"); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			since = time.Now().Add(-duration)
+		} else if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+			since = timestamp
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 timestamp or a duration"})
+			return
+		}
+	}
+
+	nodeName := c.Param("nodename")
+	samples, found := storage.GlobalStore.GetNodeSamples(nodeName, since)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no samples for node " + nodeName})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"node_name": nodeName, "samples": samples})
+}
@@ -0,0 +1,43 @@
+package formatter
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// UIAgentConfig is an agent config with durations and lists formatted for the form fields
+type UIAgentConfig struct {
+	Interval           string
+	EnabledCollectors  string
+	DisabledCollectors string
+	CollectorIntervals string
+	Filters            map[string][2]string // name -> include, exclude (one regex per line)
+}
+
+// FormatAgentConfigForUI formats a pushed agent config for the config page form
+func FormatAgentConfigForUI(config types.AgentConfig) UIAgentConfig {
+	ui := UIAgentConfig{
+		EnabledCollectors:  strings.Join(config.EnabledCollectors, ", "),
+		DisabledCollectors: strings.Join(config.DisabledCollectors, ", "),
+		Filters: map[string][2]string{
+			"namespaces": {strings.Join(config.Filters.Namespaces.Include, "\n"), strings.Join(config.Filters.Namespaces.Exclude, "\n")},
+			"pods":       {strings.Join(config.Filters.Pods.Include, "\n"), strings.Join(config.Filters.Pods.Exclude, "\n")},
+			"interfaces": {strings.Join(config.Filters.Interfaces.Include, "\n"), strings.Join(config.Filters.Interfaces.Exclude, "\n")},
+			"devices":    {strings.Join(config.Filters.Devices.Include, "\n"), strings.Join(config.Filters.Devices.Exclude, "\n")},
+		},
+	}
+	if config.IntervalMs > 0 {
+		ui.Interval = (time.Duration(config.IntervalMs) * time.Millisecond).String()
+	}
+
+	var intervals []string
+	for name, intervalMs := range config.CollectorIntervalsMs {
+		intervals = append(intervals, name+"="+(time.Duration(intervalMs)*time.Millisecond).String())
+	}
+	sort.Strings(intervals)
+	ui.CollectorIntervals = strings.Join(intervals, ", ")
+	return ui
+}
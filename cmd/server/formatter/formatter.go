@@ -2,6 +2,10 @@ package formatter
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ThomasCardin/gobservability/shared/types"
 )
@@ -31,6 +35,73 @@ type UINode struct {
 	DiskTotal    float64 `json:"disk_total"`
 	DiskRead     float64 `json:"disk_read"`
 	DiskWrite    float64 `json:"disk_write"`
+
+	// Custom metrics from the textfile and exec collectors of the agent
+	Custom []UICustomSource `json:"custom"`
+
+	// StatsD and OTLP metrics pushed to the agent, one source per sending IP
+	Pushed []UICustomSource `json:"pushed"`
+
+	// Status of the agent collectors
+	Collectors []UICollector `json:"collectors"`
+
+	// Offline buffer of the agent, nil if not reported
+	Buffer *UIBuffer `json:"buffer"`
+
+	// Connection of the agent to the server, nil if not reported
+	Connection *UIConnection `json:"connection"`
+
+	// State of the node derived by the server, the metrics are the last received when not online
+	State       string `json:"state"`
+	StateReason string `json:"state_reason"`
+	LastSeen    string `json:"last_seen"`
+
+	// Skew of the agent clock, e.g. "+3.2s", empty if not measured
+	ClockSkew       string `json:"clock_skew"`
+	ClockSkewSource string `json:"clock_skew_source"`
+	ClockSkewed     bool   `json:"clock_skewed"`
+
+	// Payloads of the agent rejected by the server validation
+	RejectedPayloads uint64 `json:"rejected_payloads"`
+	LastRejection    string `json:"last_rejection"`
+}
+
+// UIConnection represents the connection of an agent to the server for the UI display
+type UIConnection struct {
+	Endpoint     string `json:"endpoint"`
+	Reconnects   uint64 `json:"reconnects"`
+	LastError    string `json:"last_error"`
+	LastErrorAt  string `json:"last_error_at"`
+	LastOutage   string `json:"last_outage"`  // Duration of the last disconnection
+	Disconnected string `json:"disconnected"` // Time disconnected since the agent started
+}
+
+// UIBuffer represents the offline buffer of an agent for the UI display
+type UIBuffer struct {
+	Payloads int     `json:"payloads"`
+	Memory   float64 `json:"memory"` // Size of the payloads in memory in MB
+	Disk     float64 `json:"disk"`   // Size of the write-ahead log in MB
+	HasDisk  bool    `json:"has_disk"`
+	Dropped  uint64  `json:"dropped"`
+	Replayed uint64  `json:"replayed"`
+}
+
+// UICollector represents the status of an agent collector for the UI display
+type UICollector struct {
+	Name     string  `json:"name"`
+	Enabled  bool    `json:"enabled"`
+	Interval string  `json:"interval"`
+	Duration float64 `json:"duration"` // Duration of the last run in ms
+	LastRun  string  `json:"last_run"`
+	Error    string  `json:"error"`
+}
+
+// UICustomSource represents the series of one custom collector or pushed metrics source for the UI display
+type UICustomSource struct {
+	Source   string     `json:"source"`
+	Error    string     `json:"error"`
+	Duration float64    `json:"duration"` // Collection duration in ms
+	Series   []UISeries `json:"series"`
 }
 
 // UIPod represents a formatted pod for the UI display
@@ -64,6 +135,22 @@ type UIPod struct {
 	DiskRead  float64 `json:"disk_read"`  // Read in MB
 	DiskWrite float64 `json:"disk_write"` // Write in MB
 
+	// Storage metrics
+	EphemeralUsed    float64    `json:"ephemeral_used"`    // Ephemeral storage used in MB
+	EphemeralLimit   string     `json:"ephemeral_limit"`   // Ephemeral storage limit
+	EphemeralPercent float64    `json:"ephemeral_percent"` // Percentage of the ephemeral limit
+	WritableLayer    float64    `json:"writable_layer"`    // Container writable layer in MB
+	Volumes          []UIVolume `json:"volumes"`
+
+	// Prometheus metrics scraped from the pod endpoint
+	ScrapeURL      string     `json:"scrape_url"`
+	ScrapeError    string     `json:"scrape_error"`
+	ScrapeDuration float64    `json:"scrape_duration"` // Scrape duration in ms
+	Series         []UISeries `json:"series"`
+
+	// StatsD and OTLP metrics pushed by the pod
+	PushedSeries []UISeries `json:"pushed_series"`
+
 	// Process details
 	ProcessName string `json:"process_name"` // Name of the process
 	State       string `json:"state"`        // Process state
@@ -76,6 +163,24 @@ type UIPod struct {
 	ResourceRequestMemory string `json:"resource_request_memory"` // Memory request
 }
 
+// UIVolume represents a formatted pod volume for the UI display
+type UIVolume struct {
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	Used         float64 `json:"used"`          // Used in MB
+	Capacity     float64 `json:"capacity"`      // Capacity in MB (0 if unknown)
+	Inodes       uint64  `json:"inodes"`        // Inodes used
+	UsagePercent float64 `json:"usage_percent"` // Percentage of capacity
+}
+
+// UISeries represents a scraped Prometheus series for the UI display
+type UISeries struct {
+	Name   string `json:"name"`
+	Labels string `json:"labels"` // Formatted label set ({code="200",method="get"})
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+}
+
 // FormatNodeForUI formats raw node stats for UI display
 func FormatNodeForUI(name string, stats *types.NodeStatsPayload) UINode {
 	cpu := stats.Metrics.CPU
@@ -85,6 +190,10 @@ func FormatNodeForUI(name string, stats *types.NodeStatsPayload) UINode {
 
 	// Calculate component percentages for detailed view (only for display breakdown)
 	totalCPU := float64(cpu.Total)
+	if totalCPU == 0 {
+		// CPU collector disabled or not run yet, every component is 0
+		totalCPU = 1
+	}
 
 	return UINode{
 		Name:         name,
@@ -110,7 +219,112 @@ func FormatNodeForUI(name string, stats *types.NodeStatsPayload) UINode {
 		DiskTotal:    disk.TotalRate, // From agent calculation
 		DiskRead:     disk.ReadRate,  // From agent calculation
 		DiskWrite:    disk.WriteRate, // From agent calculation
+		Custom:       formatCustomForUI(stats.Metrics.CustomMetrics),
+		Pushed:       formatPushedForUI(stats.Metrics.Pushed),
+		Collectors:   formatCollectorsForUI(stats.Metrics.Collectors),
+		Buffer:       formatBufferForUI(stats.Metrics.Buffer),
+		Connection:   formatConnectionForUI(stats.Metrics.Connection),
+	}
+}
+
+// FormatNodeStateForUI sets the state of a node, stats is nil if the node sent no payload yet
+func FormatNodeStateForUI(status types.NodeStatus, stats *types.NodeStatsPayload) UINode {
+	uiNode := UINode{Name: status.NodeName}
+	if stats != nil {
+		uiNode = FormatNodeForUI(status.NodeName, stats)
+	}
+	uiNode.State = string(status.State)
+	uiNode.StateReason = status.Reason
+	if !status.LastSeen.IsZero() {
+		uiNode.LastSeen = status.LastSeen.Format("15:04:05")
+	}
+	if status.ClockSkewSource != "" {
+		uiNode.ClockSkew = formatClockSkew(status.ClockSkewMs)
+		uiNode.ClockSkewSource = string(status.ClockSkewSource)
+		uiNode.ClockSkewed = status.ClockSkewed
+	}
+	uiNode.RejectedPayloads = status.RejectedPayloads
+	uiNode.LastRejection = status.LastRejection
+	return uiNode
+}
+
+// formatClockSkew formats a clock skew in milliseconds with its sign
+func formatClockSkew(ms float64) string {
+	skew := time.Duration(ms * float64(time.Millisecond))
+	if skew < 0 {
+		return "-" + (-skew).Round(time.Millisecond).String()
+	}
+	return "+" + skew.Round(time.Millisecond).String()
+}
+
+// formatConnectionForUI formats the connection of an agent for UI display
+func formatConnectionForUI(status *types.ConnectionStatus) *UIConnection {
+	if status == nil {
+		return nil
+	}
+	connection := &UIConnection{
+		Endpoint:     status.Endpoint,
+		Reconnects:   status.Reconnects,
+		LastError:    status.LastError,
+		LastOutage:   (time.Duration(status.LastOutageMs) * time.Millisecond).Round(time.Second).String(),
+		Disconnected: (time.Duration(status.DisconnectedMs) * time.Millisecond).Round(time.Second).String(),
+	}
+	if !status.LastErrorAt.IsZero() {
+		connection.LastErrorAt = status.LastErrorAt.Format("15:04:05")
+	}
+	return connection
+}
+
+// formatBufferForUI formats the offline buffer of an agent for UI display
+func formatBufferForUI(status *types.BufferStatus) *UIBuffer {
+	if status == nil {
+		return nil
+	}
+	return &UIBuffer{
+		Payloads: status.Payloads,
+		Memory:   float64(status.Bytes) / 1024 / 1024,
+		Disk:     float64(status.DiskBytes) / 1024 / 1024,
+		HasDisk:  status.MaxDiskBytes > 0,
+		Dropped:  status.Dropped,
+		Replayed: status.Replayed,
+	}
+}
+
+// formatCollectorsForUI formats the collector statuses of a node for UI display
+func formatCollectorsForUI(statuses []types.CollectorStatus) []UICollector {
+	uiCollectors := make([]UICollector, 0, len(statuses))
+	for _, status := range statuses {
+		collector := UICollector{
+			Name:     status.Name,
+			Enabled:  status.Enabled,
+			Interval: "every tick",
+			Duration: status.DurationMs,
+			LastRun:  "never",
+			Error:    status.Error,
+		}
+		if status.IntervalMs > 0 {
+			collector.Interval = (time.Duration(status.IntervalMs) * time.Millisecond).String()
+		}
+		if !status.LastRun.IsZero() {
+			collector.LastRun = status.LastRun.Format("15:04:05")
+		}
+		uiCollectors = append(uiCollectors, collector)
+	}
+	return uiCollectors
+}
+
+// formatCustomForUI formats the custom collector sources of a node for UI display
+func formatCustomForUI(custom []types.CustomMetrics) []UICustomSource {
+	uiCustom := make([]UICustomSource, 0, len(custom))
+	for _, source := range custom {
+		uiCustom = append(uiCustom, UICustomSource{
+			Source:   source.Source,
+			Error:    source.Error,
+			Duration: source.DurationMs,
+			Series:   formatSeriesForUI(source.Samples),
+		})
 	}
+	return uiCustom
 }
 
 // FormatPodForUI formats pod data for UI display (simplified - agent already calculated percentages)
@@ -141,11 +355,17 @@ func FormatPodForUI(pod *types.Pod) UIPod {
 		systemPercent = float64(pod.PodMetrics.CPU.STime) / totalCPUTime * pod.PodMetrics.CPU.CPUPercent
 	}
 
-	return UIPod{
+	status := "RUNNING"
+	if pod.Partial {
+		// Reads of the process timed out on the agent (process in D state)
+		status = "PARTIAL"
+	}
+
+	uiPod := UIPod{
 		Name:        pod.Name,
 		ContainerID: pod.ContainerID,
 		PID:         pod.PID,
-		Status:      "RUNNING",
+		Status:      status,
 
 		CPU:        formatPercentage(pod.PodMetrics.CPU.CPUPercent),
 		CPUPercent: pod.PodMetrics.CPU.CPUPercent, // From agent calculation
@@ -167,6 +387,12 @@ func FormatPodForUI(pod *types.Pod) UIPod {
 		DiskRead:  float64(pod.PodMetrics.Disk.ReadBytes) / 1024 / 1024,
 		DiskWrite: float64(pod.PodMetrics.Disk.WriteBytes) / 1024 / 1024,
 
+		EphemeralUsed:    float64(pod.PodMetrics.Storage.EphemeralUsedBytes) / 1024 / 1024,
+		EphemeralLimit:   pod.ResourceLimits.EphemeralStorage,
+		EphemeralPercent: pod.PodMetrics.Storage.EphemeralPercent, // From agent calculation
+		WritableLayer:    float64(pod.PodMetrics.Storage.WritableLayerBytes) / 1024 / 1024,
+		Volumes:          formatVolumesForUI(pod.PodMetrics.Storage.Volumes),
+
 		ProcessName:           pod.PidDetails.Name,
 		State:                 pod.PidDetails.State,
 		Threads:               pod.PidDetails.Threads,
@@ -176,6 +402,91 @@ func FormatPodForUI(pod *types.Pod) UIPod {
 		ResourceRequestCPU:    pod.ResourceRequests.CPU,
 		ResourceRequestMemory: pod.ResourceRequests.Memory,
 	}
+
+	if scrape := pod.PodMetrics.Scrape; scrape != nil {
+		uiPod.ScrapeURL = scrape.URL
+		uiPod.ScrapeError = scrape.Error
+		uiPod.ScrapeDuration = scrape.DurationMs
+		uiPod.Series = formatSeriesForUI(scrape.Samples)
+	}
+
+	return uiPod
+}
+
+// formatPushedForUI formats the pushed metrics of a node for UI display
+func formatPushedForUI(pushed []types.PushedMetrics) []UICustomSource {
+	uiPushed := make([]UICustomSource, 0, len(pushed))
+	for _, source := range pushed {
+		name := fmt.Sprintf("unknown pod (%s)", source.SourceIP)
+		if source.PodName != "" {
+			name = fmt.Sprintf("%s/%s (%s)", source.Namespace, source.PodName, source.SourceIP)
+		}
+		uiPushed = append(uiPushed, UICustomSource{
+			Source: name,
+			Series: formatSeriesForUI(source.Samples),
+		})
+	}
+	return uiPushed
+}
+
+// FormatPodPushedForUI formats the pushed metrics sent by a pod for UI display
+func FormatPodPushedForUI(pushed []types.PushedMetrics, podName string) []UISeries {
+	var samples []types.MetricSample
+	for _, source := range pushed {
+		if source.PodName == podName {
+			samples = append(samples, source.Samples...)
+		}
+	}
+	return formatSeriesForUI(samples)
+}
+
+// formatSeriesForUI formats scraped Prometheus and custom series for UI display
+func formatSeriesForUI(samples []types.MetricSample) []UISeries {
+	uiSeries := make([]UISeries, 0, len(samples))
+	for _, sample := range samples {
+		uiSeries = append(uiSeries, UISeries{
+			Name:   sample.Name,
+			Labels: formatLabels(sample.Labels),
+			Type:   sample.Type,
+			Value:  strconv.FormatFloat(sample.Value, 'g', 6, 64),
+		})
+	}
+	return uiSeries
+}
+
+// formatLabels formats a label set like Prometheus does ({a="1",b="2"}, sorted by name)
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%q", name, labels[name])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatVolumesForUI formats pod volumes for UI display
+func formatVolumesForUI(volumes []types.VolumeStats) []UIVolume {
+	uiVolumes := make([]UIVolume, 0, len(volumes))
+	for _, volume := range volumes {
+		uiVolumes = append(uiVolumes, UIVolume{
+			Name:         volume.Name,
+			Type:         volume.Type,
+			Used:         float64(volume.UsedBytes) / 1024 / 1024,
+			Capacity:     float64(volume.CapacityBytes) / 1024 / 1024,
+			Inodes:       volume.UsedInodes,
+			UsagePercent: volume.UsagePercent, // From agent calculation
+		})
+	}
+	return uiVolumes
 }
 
 // Helper functions for consistent formatting
@@ -0,0 +1,269 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/ThomasCardin/gobservability/proto"
+	sharedGrpc "github.com/ThomasCardin/gobservability/shared/grpc"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// Sources of the config of a node
+const (
+	AgentConfigSourceNode    = "node"    // Config set for the node
+	AgentConfigSourceDefault = "default" // Config set for all agents
+	AgentConfigSourceNone    = "none"    // The agent uses its config file and flags
+)
+
+// AgentConfigStatus is the config of a node with the version last pushed and acknowledged
+type AgentConfigStatus struct {
+	NodeName      string                `json:"node_name"`
+	Connected     bool                  `json:"connected"`
+	Source        string                `json:"source"`
+	Config        types.AgentConfig     `json:"config"`
+	PushedVersion int64                 `json:"pushed_version"` // 0 if never pushed
+	Ack           *types.AgentConfigAck `json:"ack,omitempty"`
+	Unsupported   string                `json:"unsupported,omitempty"` // Why the agent cannot apply pushed configs
+}
+
+// Pending returns true if the agent did not acknowledge the last pushed version
+func (s AgentConfigStatus) Pending() bool {
+	return s.PushedVersion > 0 && (s.Ack == nil || s.Ack.Version != s.PushedVersion)
+}
+
+// AgentConfigStore keeps the configs pushed to agents: a default for all agents and per-node
+// configs replacing it. Configs are kept in memory and pushed again when an agent connects
+type AgentConfigStore struct {
+	version       int64 // Incremented on every push
+	defaultConfig *types.AgentConfig
+	nodes         map[string]types.AgentConfig
+	pushed        map[string]int64 // nodeName -> last pushed version
+	acks          map[string]types.AgentConfigAck
+	mu            sync.RWMutex
+}
+
+// NewAgentConfigStore creates an empty agent config store
+func NewAgentConfigStore() *AgentConfigStore {
+	return &AgentConfigStore{
+		nodes:  make(map[string]types.AgentConfig),
+		pushed: make(map[string]int64),
+		acks:   make(map[string]types.AgentConfigAck),
+	}
+}
+
+// ValidateAgentConfig checks the intervals and filter regexes of a config, collector names
+// are checked by the agent which rejects unknown collectors in its ack
+func ValidateAgentConfig(config types.AgentConfig) error {
+	if !validIntervalMs(config.IntervalMs) {
+		return fmt.Errorf("interval must be at least %s", types.MinCollectInterval)
+	}
+	for name, intervalMs := range config.CollectorIntervalsMs {
+		if !validIntervalMs(intervalMs) {
+			return fmt.Errorf("interval of collector %s must be at least %s", name, types.MinCollectInterval)
+		}
+	}
+
+	for key, rule := range map[string]types.FilterRule{
+		"namespaces": config.Filters.Namespaces,
+		"pods":       config.Filters.Pods,
+		"interfaces": config.Filters.Interfaces,
+		"devices":    config.Filters.Devices,
+	} {
+		for _, pattern := range append(append([]string{}, rule.Include...), rule.Exclude...) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid %s filter %q: %v", key, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validIntervalMs returns true for 0, which keeps the interval of the agent, and for intervals of
+// at least types.MinCollectInterval
+func validIntervalMs(intervalMs int64) bool {
+	return intervalMs == 0 || time.Duration(intervalMs)*time.Millisecond >= types.MinCollectInterval
+}
+
+// effective returns the config of a node and its source
+func (s *AgentConfigStore) effective(nodeName string) (types.AgentConfig, string) {
+	if config, found := s.nodes[nodeName]; found {
+		return config, AgentConfigSourceNode
+	}
+	if s.defaultConfig != nil {
+		return *s.defaultConfig, AgentConfigSourceDefault
+	}
+	return types.AgentConfig{}, AgentConfigSourceNone
+}
+
+// SetAgentConfig sets the config of a node, or of all agents if nodeName is empty, and pushes
+// it to the connected agents it applies to
+func (s *Server) SetAgentConfig(nodeName string, config types.AgentConfig) error {
+	if err := ValidateAgentConfig(config); err != nil {
+		return err
+	}
+
+	s.agentConfigs.mu.Lock()
+	if nodeName == "" {
+		s.agentConfigs.defaultConfig = &config
+	} else {
+		s.agentConfigs.nodes[nodeName] = config
+	}
+	s.agentConfigs.mu.Unlock()
+
+	s.pushAgentConfigs(nodeName)
+	return nil
+}
+
+// ResetAgentConfig removes the config of a node, which gets the default config again, or the
+// default config if nodeName is empty. Agents without config revert to their own settings
+func (s *Server) ResetAgentConfig(nodeName string) {
+	s.agentConfigs.mu.Lock()
+	if nodeName == "" {
+		s.agentConfigs.defaultConfig = nil
+	} else {
+		delete(s.agentConfigs.nodes, nodeName)
+	}
+	s.agentConfigs.mu.Unlock()
+
+	s.pushAgentConfigs(nodeName)
+}
+
+// pushAgentConfigs pushes the config to a node, or to the agents using the default config
+func (s *Server) pushAgentConfigs(nodeName string) {
+	if nodeName != "" {
+		if err := s.PushAgentConfig(nodeName); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return
+	}
+
+	for _, agent := range s.agentManager.GetConnectedAgents() {
+		s.agentConfigs.mu.RLock()
+		_, hasNodeConfig := s.agentConfigs.nodes[agent]
+		s.agentConfigs.mu.RUnlock()
+		if hasNodeConfig {
+			continue
+		}
+		if err := s.PushAgentConfig(agent); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// PushAgentConfig sends the config of a node to its agent with a new version
+// An empty config is pushed too so that an agent reverts to its own settings
+func (s *Server) PushAgentConfig(nodeName string) error {
+	agent, err := s.agentManager.GetAgent(nodeName)
+	if err != nil {
+		// The config is pushed when the agent connects
+		return nil
+	}
+	if err := agent.Require(types.FeatureAgentConfig); err != nil {
+		return err
+	}
+
+	// Held while sending so that an agent receives its versions in order
+	s.agentConfigs.mu.Lock()
+	defer s.agentConfigs.mu.Unlock()
+
+	config, source := s.agentConfigs.effective(nodeName)
+	s.agentConfigs.version++
+	config.Version = s.agentConfigs.version
+	s.agentConfigs.pushed[nodeName] = config.Version
+
+	err = agent.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_AgentConfig{
+			AgentConfig: sharedGrpc.ConvertToGRPCAgentConfig(config),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send agent config to %s: %v", nodeName, err)
+	}
+
+	log.Printf("Pushed %s agent config version %d to agent %s", source, config.Version, nodeName)
+	return nil
+}
+
+// acknowledgeAgentConfig records the ack of an agent for a pushed config
+func (s *Server) acknowledgeAgentConfig(nodeName string, ack *pb.AgentConfigAck) {
+	s.agentConfigs.mu.Lock()
+	defer s.agentConfigs.mu.Unlock()
+
+	s.agentConfigs.acks[nodeName] = types.AgentConfigAck{
+		Version:    ack.Version,
+		Applied:    ack.Applied,
+		Error:      ack.Error,
+		ReceivedAt: time.Now(),
+	}
+
+	if !ack.Applied {
+		log.Printf("Agent %s rejected config version %d: %s", nodeName, ack.Version, ack.Error)
+	}
+}
+
+// DefaultAgentConfig returns the config of all agents, nil if not set
+func (s *Server) DefaultAgentConfig() *types.AgentConfig {
+	s.agentConfigs.mu.RLock()
+	defer s.agentConfigs.mu.RUnlock()
+
+	if s.agentConfigs.defaultConfig == nil {
+		return nil
+	}
+	config := *s.agentConfigs.defaultConfig
+	return &config
+}
+
+// AgentConfigStatus returns the config of a node and the state of its last push
+func (s *Server) AgentConfigStatus(nodeName string) AgentConfigStatus {
+	s.agentConfigs.mu.RLock()
+	defer s.agentConfigs.mu.RUnlock()
+
+	config, source := s.agentConfigs.effective(nodeName)
+	status := AgentConfigStatus{
+		NodeName:      nodeName,
+		Source:        source,
+		Config:        config,
+		PushedVersion: s.agentConfigs.pushed[nodeName],
+	}
+	if agent, err := s.agentManager.GetAgent(nodeName); err == nil {
+		status.Connected = true
+		if err := agent.Require(types.FeatureAgentConfig); err != nil {
+			status.Unsupported = err.Error()
+		}
+	}
+	if ack, found := s.agentConfigs.acks[nodeName]; found {
+		status.Ack = &ack
+	}
+	return status
+}
+
+// AgentConfigStatuses returns the config status of the given nodes, the connected agents and
+// the nodes with a config, sorted by node name
+func (s *Server) AgentConfigStatuses(nodeNames []string) []AgentConfigStatus {
+	names := make(map[string]bool)
+	for _, name := range nodeNames {
+		names[name] = true
+	}
+	for _, name := range s.agentManager.GetConnectedAgents() {
+		names[name] = true
+	}
+	s.agentConfigs.mu.RLock()
+	for name := range s.agentConfigs.nodes {
+		names[name] = true
+	}
+	s.agentConfigs.mu.RUnlock()
+
+	statuses := make([]AgentConfigStatus, 0, len(names))
+	for name := range names {
+		statuses = append(statuses, s.AgentConfigStatus(name))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].NodeName < statuses[j].NodeName
+	})
+	return statuses
+}
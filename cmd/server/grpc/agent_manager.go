@@ -4,24 +4,50 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/ThomasCardin/gobservability/proto"
+	"github.com/ThomasCardin/gobservability/shared/types"
 	cache "github.com/patrickmn/go-cache"
 )
 
 // AgentConnection represents a connected agent with its stream
 type AgentConnection struct {
-	NodeName string
-	Stream   pb.NodeService_AgentStreamServer
-	Context  context.Context
-	Cancel   context.CancelFunc
+	NodeName     string
+	Stream       pb.NodeService_AgentStreamServer
+	Context      context.Context
+	Cancel       context.CancelFunc
+	Capabilities types.AgentCapabilities // Reported in the hello
+	ConnectedAt  time.Time
+	RemoteAddr   string
+	sendMu       sync.Mutex // gRPC streams do not support concurrent Send calls
+	stats        connectionStats
+	statsMu      sync.Mutex
+}
+
+// Send sends a message to the agent, serializing concurrent senders
+func (c *AgentConnection) Send(msg *pb.ServerMessage) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.Stream.Send(msg)
+}
+
+// Require returns why the agent cannot serve a feature, nil if it can
+func (c *AgentConnection) Require(feature string) error {
+	if available, reason := c.Capabilities.Supports(feature); !available {
+		return fmt.Errorf("agent on node %s cannot serve %s: %s", c.NodeName, feature, reason)
+	}
+	return nil
 }
 
 // AgentManager manages all connected agents using go-cache
 type AgentManager struct {
-	agents   *cache.Cache // nodeName -> *AgentConnection
-	requests *cache.Cache // requestID -> chan *pb.FlamegraphResponse
+	agents     *cache.Cache // nodeName -> *AgentConnection
+	requests   *cache.Cache // requestID -> chan *pb.FlamegraphResponse
+	logStreams *cache.Cache // requestID -> chan *pb.LogChunk
+	details    *cache.Cache // requestID -> chan *pb.PodDetailsResponse
 }
 
 // NewAgentManager creates a new agent manager with go-cache
@@ -31,11 +57,15 @@ func NewAgentManager() *AgentManager {
 		agents: cache.New(5*time.Minute, 1*time.Minute),
 		// Requests expire after 5 minutes (to handle long flamegraph operations), cleanup every 30 seconds
 		requests: cache.New(5*time.Minute, 30*time.Second),
+		// Log streams live as long as the viewer is open and are removed explicitly
+		logStreams: cache.New(cache.NoExpiration, 1*time.Minute),
+		// Pod details are read in milliseconds, requests expire after 1 minute
+		details: cache.New(1*time.Minute, 30*time.Second),
 	}
 }
 
 // RegisterAgent registers a new agent connection
-func (am *AgentManager) RegisterAgent(nodeName string, stream pb.NodeService_AgentStreamServer, ctx context.Context, cancel context.CancelFunc) {
+func (am *AgentManager) RegisterAgent(nodeName string, capabilities types.AgentCapabilities, stream pb.NodeService_AgentStreamServer, ctx context.Context, cancel context.CancelFunc) *AgentConnection {
 	// Check if there's an existing connection
 	if existing, found := am.agents.Get(nodeName); found {
 		if conn, ok := existing.(*AgentConnection); ok {
@@ -45,10 +75,13 @@ func (am *AgentManager) RegisterAgent(nodeName string, stream pb.NodeService_Age
 	}
 
 	conn := &AgentConnection{
-		NodeName: nodeName,
-		Stream:   stream,
-		Context:  ctx,
-		Cancel:   cancel,
+		NodeName:     nodeName,
+		Stream:       stream,
+		Context:      ctx,
+		Cancel:       cancel,
+		Capabilities: capabilities,
+		ConnectedAt:  time.Now(),
+		RemoteAddr:   remoteAddr(stream.Context()),
 	}
 
 	// Set with default expiration (5 minutes)
@@ -63,6 +96,7 @@ func (am *AgentManager) RegisterAgent(nodeName string, stream pb.NodeService_Age
 	})
 
 	log.Printf("Registered agent for node %s", nodeName)
+	return conn
 }
 
 // UnregisterAgent removes an agent connection
@@ -76,6 +110,15 @@ func (am *AgentManager) UnregisterAgent(nodeName string) {
 	log.Printf("Unregistered agent for node %s", nodeName)
 }
 
+// UnregisterConnection removes an agent connection unless a newer stream of the node replaced it
+func (am *AgentManager) UnregisterConnection(conn *AgentConnection) {
+	if current, found := am.agents.Get(conn.NodeName); found && current != conn {
+		conn.Cancel()
+		return
+	}
+	am.UnregisterAgent(conn.NodeName)
+}
+
 // GetAgent returns the connection for a specific node
 func (am *AgentManager) GetAgent(nodeName string) (*AgentConnection, error) {
 	if item, found := am.agents.Get(nodeName); found {
@@ -121,6 +164,97 @@ func (am *AgentManager) CompleteRequest(requestID string, response *pb.Flamegrap
 	}
 }
 
+// LogStream receives the chunks of a log request
+type LogStream struct {
+	Chunks  chan *pb.LogChunk // Chunks with lines, dropped when the viewer is too slow
+	Last    chan *pb.LogChunk // The chunk ending the request (eof or error), never dropped
+	dropped atomic.Int64      // Lines dropped since the last call of TakeDropped
+}
+
+// TakeDropped returns the lines dropped since its previous call
+func (ls *LogStream) TakeDropped() int {
+	return int(ls.dropped.Swap(0))
+}
+
+// RegisterLogStream registers a log request and returns the stream receiving its chunks
+func (am *AgentManager) RegisterLogStream(requestID string) *LogStream {
+	stream := &LogStream{
+		Chunks: make(chan *pb.LogChunk, 64),
+		Last:   make(chan *pb.LogChunk, 1),
+	}
+	am.logStreams.Set(requestID, stream, cache.DefaultExpiration)
+	return stream
+}
+
+// DeliverLogChunk forwards a log chunk to the viewer waiting for it
+// Chunks are dropped if the viewer is too slow, to never block the agent stream, except the last
+// chunk of the request which has its own channel
+func (am *AgentManager) DeliverLogChunk(requestID string, chunk *pb.LogChunk) {
+	item, found := am.logStreams.Get(requestID)
+	if !found {
+		return
+	}
+	stream, ok := item.(*LogStream)
+	if !ok {
+		return
+	}
+
+	if chunk.Eof || chunk.Error != "" {
+		select {
+		case stream.Last <- chunk:
+		default:
+			log.Printf("Warning: log stream %s already ended, ignoring its last chunk", requestID)
+		}
+		return
+	}
+	select {
+	case stream.Chunks <- chunk:
+	default:
+		stream.dropped.Add(int64(len(chunk.Lines)))
+		log.Printf("Warning: log stream %s is full, dropping %d lines", requestID, len(chunk.Lines))
+	}
+}
+
+// UnregisterLogStream removes a log request
+func (am *AgentManager) UnregisterLogStream(requestID string) {
+	am.logStreams.Delete(requestID)
+}
+
+// RegisterDetailsRequest registers a pod details request with a response channel
+func (am *AgentManager) RegisterDetailsRequest(requestID string) chan *pb.PodDetailsResponse {
+	ch := make(chan *pb.PodDetailsResponse, 1)
+	am.details.Set(requestID, ch, cache.DefaultExpiration)
+	return ch
+}
+
+// CompleteDetailsRequest completes a pod details request
+func (am *AgentManager) CompleteDetailsRequest(requestID string, response *pb.PodDetailsResponse) {
+	if item, found := am.details.Get(requestID); found {
+		if ch, ok := item.(chan *pb.PodDetailsResponse); ok {
+			ch <- response
+			close(ch)
+		}
+		am.details.Delete(requestID)
+	}
+}
+
+// UnregisterDetailsRequest removes a pod details request that is no longer waited for
+func (am *AgentManager) UnregisterDetailsRequest(requestID string) {
+	am.details.Delete(requestID)
+}
+
+// Connections returns the connections of the connected agents
+func (am *AgentManager) Connections() []*AgentConnection {
+	items := am.agents.Items()
+	connections := make([]*AgentConnection, 0, len(items))
+	for _, item := range items {
+		if conn, ok := item.Object.(*AgentConnection); ok {
+			connections = append(connections, conn)
+		}
+	}
+	return connections
+}
+
 // GetConnectedAgents returns a list of currently connected agents
 func (am *AgentManager) GetConnectedAgents() []string {
 	items := am.agents.Items()
@@ -0,0 +1,174 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"google.golang.org/grpc/metadata"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Extra fields of bound service account tokens identifying the pod of the agent
+const (
+	podNameExtra  = "authentication.kubernetes.io/pod-name"
+	podUIDExtra   = "authentication.kubernetes.io/pod-uid"
+	nodeNameExtra = "authentication.kubernetes.io/node-name"
+)
+
+// authCacheTTL is how long an accepted token is trusted before being reviewed again
+const authCacheTTL = 1 * time.Minute
+
+// AuthOptions configures the authentication of agents with their service account token
+type AuthOptions struct {
+	Enabled        bool
+	Audience       string // Audience of the projected token, any audience accepted by the API server if empty
+	ServiceAccount string // namespace/name of the agent service account
+	DaemonSet      string // Name of the DaemonSet owning the agent pods, in the service account namespace
+}
+
+// authenticator returns the token authenticator using the in-cluster Kubernetes API
+func (o AuthOptions) authenticator() (*TokenAuthenticator, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-cluster config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	return NewTokenAuthenticator(client, o)
+}
+
+// TokenAuthenticator validates the service account token of agents with a TokenReview and checks
+// that it belongs to a pod of the agent DaemonSet scheduled on the node the agent claims to be
+type TokenAuthenticator struct {
+	client    kubernetes.Interface
+	options   AuthOptions
+	namespace string
+	username  string       // Service account user of the agent tokens
+	accepted  *cache.Cache // Token hash and node name of accepted agents
+}
+
+// NewTokenAuthenticator creates an authenticator using client, a fake clientset in tests
+func NewTokenAuthenticator(client kubernetes.Interface, options AuthOptions) (*TokenAuthenticator, error) {
+	namespace, name, found := strings.Cut(options.ServiceAccount, "/")
+	if !found || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid agent service account %q, expected namespace/name", options.ServiceAccount)
+	}
+	if options.DaemonSet == "" {
+		return nil, errors.New("agent DaemonSet name is required")
+	}
+
+	log.Printf("Agents authenticated with service account %s tokens (audience %q, DaemonSet %s)", options.ServiceAccount, options.Audience, options.DaemonSet)
+	return &TokenAuthenticator{
+		client:    client,
+		options:   options,
+		namespace: namespace,
+		username:  "system:serviceaccount:" + namespace + ":" + name,
+		accepted:  cache.New(authCacheTTL, 5*time.Minute),
+	}, nil
+}
+
+// Authenticate checks the token of an agent claiming to run on nodeName
+func (a *TokenAuthenticator) Authenticate(ctx context.Context, token, nodeName string) error {
+	if token == "" {
+		return errors.New("missing service account token")
+	}
+	if nodeName == "" {
+		return errors.New("missing node name")
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(hash[:]) + "/" + nodeName
+	if _, found := a.accepted.Get(key); found {
+		return nil
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	if a.options.Audience != "" {
+		review.Spec.Audiences = []string{a.options.Audience}
+	}
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to review token: %v", err)
+	}
+	if !review.Status.Authenticated {
+		return fmt.Errorf("token rejected: %s", review.Status.Error)
+	}
+
+	user := review.Status.User
+	if user.Username != a.username {
+		return fmt.Errorf("token of %s is not issued for the agent service account", user.Username)
+	}
+	podName := firstExtra(user.Extra, podNameExtra)
+	podUID := firstExtra(user.Extra, podUIDExtra)
+	if podName == "" || podUID == "" {
+		return errors.New("token is not bound to a pod, use a projected service account token")
+	}
+	if tokenNode := firstExtra(user.Extra, nodeNameExtra); tokenNode != "" && tokenNode != nodeName {
+		return fmt.Errorf("token of pod %s is bound to node %s", podName, tokenNode)
+	}
+
+	pod, err := a.client.CoreV1().Pods(a.namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get agent pod %s: %v", podName, err)
+	}
+	if string(pod.UID) != podUID {
+		return fmt.Errorf("agent pod %s was replaced since the token was issued", podName)
+	}
+	if pod.Spec.NodeName != nodeName {
+		return fmt.Errorf("agent pod %s is scheduled on node %s", podName, pod.Spec.NodeName)
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "DaemonSet" || owner.Name != a.options.DaemonSet {
+		return fmt.Errorf("pod %s is not managed by DaemonSet %s", podName, a.options.DaemonSet)
+	}
+
+	a.accepted.SetDefault(key, struct{}{})
+	return nil
+}
+
+// firstExtra returns the first value of an extra field of a token, empty if unset
+func firstExtra(extra map[string]authenticationv1.ExtraValue, key string) string {
+	if values := extra[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// bearerToken returns the token of the authorization metadata of a call
+func bearerToken(ctx context.Context) string {
+	md, found := metadata.FromIncomingContext(ctx)
+	if !found {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if token, found := strings.CutPrefix(value, "Bearer "); found {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// authenticateAgent checks the certificate (mTLS) and the token of an agent claiming to run on nodeName
+func (s *Server) authenticateAgent(ctx context.Context, nodeName string) error {
+	if err := s.verifyNodeIdentity(ctx, nodeName); err != nil {
+		return err
+	}
+	if s.tokenAuth == nil {
+		return nil
+	}
+	return s.tokenAuth.Authenticate(ctx, bearerToken(ctx), nodeName)
+}
@@ -0,0 +1,204 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	testNamespace      = "gobservability"
+	testServiceAccount = testNamespace + "/gobservability-agent"
+	testDaemonSet      = "gobservability-agent"
+)
+
+// agentPod returns a pod of a controller scheduled on nodeName
+func agentPod(name, uid, nodeName, ownerKind, ownerName string) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			UID:       types.UID(uid),
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: ownerKind, Name: ownerName, UID: "owner", Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+// boundUser returns the user of a token bound to a pod
+func boundUser(username, podName, podUID, nodeName string) authenticationv1.UserInfo {
+	extra := map[string]authenticationv1.ExtraValue{
+		podNameExtra: {podName},
+		podUIDExtra:  {podUID},
+	}
+	if nodeName != "" {
+		extra[nodeNameExtra] = authenticationv1.ExtraValue{nodeName}
+	}
+	return authenticationv1.UserInfo{Username: username, Extra: extra}
+}
+
+// newTestAuthenticator returns an authenticator whose TokenReviews answer with the status of the
+// reviewed token, unknown tokens are not authenticated. reviews counts the TokenReviews
+func newTestAuthenticator(t *testing.T, tokens map[string]authenticationv1.TokenReviewStatus, pods ...runtime.Object) (*TokenAuthenticator, *int) {
+	t.Helper()
+	client := fake.NewClientset(pods...)
+	reviews := 0
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		if len(review.Spec.Audiences) != 1 || review.Spec.Audiences[0] != "gobservability" {
+			t.Errorf("expected the gobservability audience, got %v", review.Spec.Audiences)
+		}
+		status, found := tokens[review.Spec.Token]
+		if !found {
+			status = authenticationv1.TokenReviewStatus{Error: "invalid bearer token"}
+		}
+		review.Status = status
+		return true, review, nil
+	})
+
+	authenticator, err := NewTokenAuthenticator(client, AuthOptions{
+		Enabled:        true,
+		Audience:       "gobservability",
+		ServiceAccount: testServiceAccount,
+		DaemonSet:      testDaemonSet,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authenticator, &reviews
+}
+
+func TestNewTokenAuthenticatorOptions(t *testing.T) {
+	client := fake.NewClientset()
+	for _, options := range []AuthOptions{
+		{ServiceAccount: "gobservability-agent", DaemonSet: testDaemonSet},
+		{ServiceAccount: "/gobservability-agent", DaemonSet: testDaemonSet},
+		{ServiceAccount: testServiceAccount},
+	} {
+		if _, err := NewTokenAuthenticator(client, options); err == nil {
+			t.Errorf("expected an error for %+v", options)
+		}
+	}
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	agentUser := "system:serviceaccount:gobservability:gobservability-agent"
+	tokens := map[string]authenticationv1.TokenReviewStatus{
+		"valid": {
+			Authenticated: true,
+			User:          boundUser(agentUser, "agent-1", "uid-1", "node-1"),
+		},
+		"other-service-account": {
+			Authenticated: true,
+			User:          boundUser("system:serviceaccount:default:default", "agent-1", "uid-1", "node-1"),
+		},
+		"unbound": {
+			Authenticated: true,
+			User:          authenticationv1.UserInfo{Username: agentUser},
+		},
+		"bound-to-node-2": {
+			Authenticated: true,
+			User:          boundUser(agentUser, "agent-1", "uid-1", "node-2"),
+		},
+		"pod-on-node-2": {
+			Authenticated: true,
+			User:          boundUser(agentUser, "agent-2", "uid-2", ""),
+		},
+		"replaced-pod": {
+			Authenticated: true,
+			User:          boundUser(agentUser, "agent-1", "old-uid", "node-1"),
+		},
+		"deployment-pod": {
+			Authenticated: true,
+			User:          boundUser(agentUser, "impostor", "uid-3", "node-1"),
+		},
+		"other-daemonset-pod": {
+			Authenticated: true,
+			User:          boundUser(agentUser, "other-agent", "uid-4", "node-1"),
+		},
+		"missing-pod": {
+			Authenticated: true,
+			User:          boundUser(agentUser, "deleted", "uid-5", "node-1"),
+		},
+	}
+	authenticator, _ := newTestAuthenticator(t, tokens,
+		agentPod("agent-1", "uid-1", "node-1", "DaemonSet", testDaemonSet),
+		agentPod("agent-2", "uid-2", "node-2", "DaemonSet", testDaemonSet),
+		agentPod("impostor", "uid-3", "node-1", "ReplicaSet", "impostor-5d8f"),
+		agentPod("other-agent", "uid-4", "node-1", "DaemonSet", "other-agent"),
+	)
+
+	for _, test := range []struct {
+		name     string
+		token    string
+		nodeName string
+		err      string // Expected in the error, empty if the agent is accepted
+	}{
+		{"valid token", "valid", "node-1", ""},
+		{"missing token", "", "node-1", "missing service account token"},
+		{"missing node name", "valid", "", "missing node name"},
+		{"unauthenticated token", "forged", "node-1", "token rejected: invalid bearer token"},
+		{"wrong service account", "other-service-account", "node-1", "not issued for the agent service account"},
+		{"token not bound to a pod", "unbound", "node-1", "not bound to a pod"},
+		{"token bound to another node", "bound-to-node-2", "node-1", "bound to node node-2"},
+		{"valid token claiming another node", "valid", "node-2", "bound to node node-1"},
+		{"pod on another node", "pod-on-node-2", "node-1", "scheduled on node node-2"},
+		{"replaced pod", "replaced-pod", "node-1", "was replaced"},
+		{"pod not owned by a DaemonSet", "deployment-pod", "node-1", "not managed by DaemonSet gobservability-agent"},
+		{"pod of another DaemonSet", "other-daemonset-pod", "node-1", "not managed by DaemonSet gobservability-agent"},
+		{"deleted pod", "missing-pod", "node-1", "failed to get agent pod deleted"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := authenticator.Authenticate(context.Background(), test.token, test.nodeName)
+			switch {
+			case test.err == "" && err != nil:
+				t.Fatalf("expected the agent to be accepted, got %v", err)
+			case test.err != "" && err == nil:
+				t.Fatalf("expected an error containing %q, the agent was accepted", test.err)
+			case test.err != "" && !strings.Contains(err.Error(), test.err):
+				t.Fatalf("expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestTokenAuthenticatorCache(t *testing.T) {
+	tokens := map[string]authenticationv1.TokenReviewStatus{
+		"valid": {
+			Authenticated: true,
+			User:          boundUser("system:serviceaccount:gobservability:gobservability-agent", "agent-1", "uid-1", ""),
+		},
+	}
+	authenticator, reviews := newTestAuthenticator(t, tokens,
+		agentPod("agent-1", "uid-1", "node-1", "DaemonSet", testDaemonSet),
+	)
+
+	for i := 0; i < 3; i++ {
+		if err := authenticator.Authenticate(context.Background(), "valid", "node-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *reviews != 1 {
+		t.Errorf("expected the accepted token to be reviewed once, got %d reviews", *reviews)
+	}
+
+	// The cache is keyed by node, the same token claiming another node is reviewed again
+	if err := authenticator.Authenticate(context.Background(), "valid", "node-2"); err == nil {
+		t.Fatal("expected the token to be rejected for another node")
+	}
+	if *reviews != 2 {
+		t.Errorf("expected a second review, got %d reviews", *reviews)
+	}
+}
@@ -0,0 +1,200 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/cmd/server/storage"
+	pb "github.com/ThomasCardin/gobservability/proto"
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"google.golang.org/grpc/peer"
+)
+
+// PingInterval is the interval of the pings measuring the latency of the agent streams
+const PingInterval = 30 * time.Second
+
+// connectionStats are updated by the stream of an agent
+type connectionStats struct {
+	lastSeen     time.Time // Last payload or pong
+	payloads     uint64
+	payloadBytes int     // Encoded size of the last payload, a delta is smaller than a snapshot
+	collectionMs float64 // Duration of the collection of the last payload
+	pingID       uint64
+	pingSentAt   time.Time // Zero once the pong of pingID is received
+	latency      time.Duration
+	lastPong     time.Time
+}
+
+// AgentInfo describes a connected agent for the fleet page
+type AgentInfo struct {
+	NodeName        string               `json:"node_name"`
+	AgentVersion    string               `json:"agent_version"`
+	ProtocolVersion uint32               `json:"protocol_version"`
+	Features        []types.AgentFeature `json:"features"`
+	RemoteAddr      string               `json:"remote_addr"`
+	ConnectedAt     time.Time            `json:"connected_at"`
+	LastSeen        time.Time            `json:"last_seen"`
+	LatencyMs       float64              `json:"latency_ms"`    // Round trip of the last ping, 0 if unknown
+	LastPong        time.Time            `json:"last_pong"`     // Zero if the agent never answered a ping
+	Payloads        uint64               `json:"payloads"`      // Payloads received on the stream
+	PayloadBytes    int                  `json:"payload_bytes"` // Encoded size of the last payload
+	CollectionMs    float64              `json:"collection_ms"` // Duration of the last collection on the agent
+	ClockSkewMs     float64              `json:"clock_skew_ms"` // Agent clock minus server clock
+	ClockSkewed     bool                 `json:"clock_skewed"`  // Skew above the warning threshold
+
+	RejectedPayloads  uint64 `json:"rejected_payloads"` // Payloads rejected by the validation
+	LastRejection     string `json:"last_rejection,omitempty"`
+	SanitizedPayloads uint64 `json:"sanitized_payloads"` // Payloads accepted once their values were clamped
+	LastSanitization  string `json:"last_sanitization,omitempty"`
+}
+
+// remoteAddr returns the address of the agent of a stream
+func remoteAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// recordStats counts a payload received on the stream
+func (c *AgentConnection) recordStats(size int, collectionMs float64) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.stats.lastSeen = time.Now()
+	c.stats.payloads++
+	c.stats.payloadBytes = size
+	c.stats.collectionMs = collectionMs
+}
+
+// ping sends a ping to the agent, the previous ping is forgotten if it was not answered
+func (c *AgentConnection) ping() error {
+	c.statsMu.Lock()
+	c.stats.pingID++
+	id := c.stats.pingID
+	c.stats.pingSentAt = time.Now()
+	c.statsMu.Unlock()
+
+	return c.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_Ping{
+			Ping: &pb.Ping{Id: id},
+		},
+	})
+}
+
+// recordPong measures the round trip of the last ping, pongs of older pings are ignored. It returns
+// the skew of the agent clock against the middle of the round trip, false if the agent sent no time
+func (c *AgentConnection) recordPong(pong *pb.Pong) (time.Duration, bool) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	now := time.Now()
+	c.stats.lastSeen = now
+	if pong.Id != c.stats.pingID || c.stats.pingSentAt.IsZero() {
+		return 0, false
+	}
+	sentAt := c.stats.pingSentAt
+	c.stats.latency = now.Sub(sentAt)
+	c.stats.lastPong = now
+	c.stats.pingSentAt = time.Time{}
+
+	if pong.AgentTime == nil {
+		return 0, false
+	}
+	return pong.AgentTime.AsTime().Sub(sentAt.Add(c.stats.latency / 2)), true
+}
+
+// info returns the description of the agent for the fleet page
+func (c *AgentConnection) info() AgentInfo {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	return AgentInfo{
+		NodeName:        c.NodeName,
+		AgentVersion:    c.Capabilities.AgentVersion,
+		ProtocolVersion: c.Capabilities.ProtocolVersion,
+		Features:        c.Capabilities.Features,
+		RemoteAddr:      c.RemoteAddr,
+		ConnectedAt:     c.ConnectedAt,
+		LastSeen:        c.stats.lastSeen,
+		LatencyMs:       float64(c.stats.latency) / float64(time.Millisecond),
+		LastPong:        c.stats.lastPong,
+		Payloads:        c.stats.payloads,
+		PayloadBytes:    c.stats.payloadBytes,
+		CollectionMs:    c.stats.collectionMs,
+	}
+}
+
+// Agents returns the connected agents sorted by node name
+func (s *Server) Agents() []AgentInfo {
+	connections := s.agentManager.Connections()
+	agents := make([]AgentInfo, 0, len(connections))
+	for _, conn := range connections {
+		info := conn.info()
+		if status, found := storage.GlobalStore.GetNodeStatus(conn.NodeName); found {
+			info.ClockSkewMs = status.ClockSkewMs
+			info.ClockSkewed = status.ClockSkewed
+			info.RejectedPayloads = status.RejectedPayloads
+			info.LastRejection = status.LastRejection
+			info.SanitizedPayloads = status.SanitizedPayloads
+			info.LastSanitization = status.LastSanitization
+		}
+		agents = append(agents, info)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].NodeName < agents[j].NodeName
+	})
+	return agents
+}
+
+// DisconnectAgent closes the stream of the agent of a node, the agent reconnects after its backoff
+func (s *Server) DisconnectAgent(nodeName string) error {
+	agent, err := s.agentManager.GetAgent(nodeName)
+	if err != nil {
+		return err
+	}
+	log.Printf("Disconnecting agent %s", nodeName)
+	agent.Cancel()
+	return nil
+}
+
+// RequestCollection asks the agent of a node to collect and send a payload right away
+func (s *Server) RequestCollection(nodeName string) error {
+	agent, err := s.agentManager.GetAgent(nodeName)
+	if err != nil {
+		return err
+	}
+	if err := agent.Require(types.FeatureCollect); err != nil {
+		return err
+	}
+
+	err = agent.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_Collect{
+			Collect: &pb.CollectRequest{Reason: "requested from the fleet page"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request a collection from %s: %v", nodeName, err)
+	}
+	log.Printf("Requested a collection from agent %s", nodeName)
+	return nil
+}
+
+// pingAgents pings the agents answering pings every interval, it never returns
+func (s *Server) pingAgents(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, conn := range s.agentManager.Connections() {
+			if conn.Capabilities.ProtocolVersion < types.ProtocolVersionPing {
+				continue
+			}
+			if err := conn.ping(); err != nil {
+				log.Printf("Failed to ping agent %s: %v", conn.NodeName, err)
+			}
+		}
+	}
+}
@@ -12,14 +12,34 @@ import (
 	sharedGrpc "github.com/ThomasCardin/gobservability/shared/grpc"
 	"github.com/ThomasCardin/gobservability/shared/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // Agents may compress their stream
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Keepalive settings of the agent connections
+const (
+	KeepaliveMinTime = 10 * time.Second
+	KeepaliveTime    = 1 * time.Minute
+	KeepaliveTimeout = 20 * time.Second
 )
 
 // Server implémente le service gRPC NodeService
 type Server struct {
 	pb.UnimplementedNodeServiceServer
-	agentManager *AgentManager
+	agentManager     *AgentManager
+	agentConfigs     *AgentConfigStore
+	logRulesProvider LogPatternRulesProvider
+	verifyNodeName   bool                // Agents must present a certificate issued for their node
+	tokenAuth        *TokenAuthenticator // Agents must present a service account token if set
+	limits           PayloadLimits
 }
 
+// LogPatternRulesProvider returns the log pattern alert rules of a node
+type LogPatternRulesProvider func(nodeName string) ([]types.LogPatternRule, error)
+
 var serverInstance *Server
 
 // NewServer creates a new gRPC server with agent management
@@ -27,6 +47,8 @@ func NewServer() *Server {
 	if serverInstance == nil {
 		serverInstance = &Server{
 			agentManager: NewAgentManager(),
+			agentConfigs: NewAgentConfigStore(),
+			limits:       DefaultPayloadLimits,
 		}
 	}
 	return serverInstance
@@ -39,11 +61,35 @@ func GetServerInstance() *Server {
 
 // SendStats remplace le handler HTTP /api/stats
 func (s *Server) SendStats(ctx context.Context, req *pb.NodeStatsRequest) (*pb.StatsResponse, error) {
+	if err := s.authenticateAgent(ctx, req.NodeName); err != nil {
+		log.Printf("Rejected stats of node %s: %v", req.NodeName, err)
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	}
+
+	if err := validNodeName(req.NodeName); err != nil {
+		log.Printf("Rejected stats: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	// The stats of a node streaming them are only written by its own agent
+	if agent, err := s.agentManager.GetAgent(req.NodeName); err == nil && !sameHost(agent.RemoteAddr, remoteAddr(ctx)) {
+		err := fmt.Errorf("stats from %s while the agent of node %s streams from %s", remoteAddr(ctx), req.NodeName, agent.RemoteAddr)
+		log.Printf("Rejected stats of node %s: %v", req.NodeName, err)
+		storage.GlobalStore.RecordPayloadRejected(req.NodeName, err.Error())
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	}
+
 	// Convertir la requête gRPC vers les types Go existants
 	payload := types.NodeStatsPayload{
 		NodeName:  req.NodeName,
 		Timestamp: req.Timestamp.AsTime(),
 		Metrics:   sharedGrpc.ConvertNodeMetrics(req.Metrics),
+		Backfill:  req.Backfill,
+		Samples:   sharedGrpc.ConvertNodeSamples(req.Samples),
+
+		CollectionMs: req.CollectionMs,
+	}
+	if err := s.checkStats(req.NodeName, &payload); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
 	}
 
 	// Utiliser le storage existant (même logique que l'ancien /api/stats)
@@ -69,6 +115,9 @@ func (s *Server) GenerateFlamegraph(ctx context.Context, req *pb.FlamegraphReque
 			Error: fmt.Sprintf("Agent not connected: %v", err),
 		}, nil
 	}
+	if err := agent.Require(types.FeatureFlamegraph); err != nil {
+		return &pb.FlamegraphResponse{Error: err.Error()}, nil
+	}
 
 	// Generate a unique request ID
 	requestID := fmt.Sprintf("%s-%s-%d", req.NodeName, req.PodName, time.Now().UnixNano())
@@ -87,7 +136,7 @@ func (s *Server) GenerateFlamegraph(ctx context.Context, req *pb.FlamegraphReque
 	}
 
 	// Send the flamegraph request to the agent
-	err = agent.Stream.Send(&pb.ServerMessage{
+	err = agent.Send(&pb.ServerMessage{
 		Message: &pb.ServerMessage_FlamegraphRequest{
 			FlamegraphRequest: reqWithID,
 		},
@@ -112,38 +161,276 @@ func (s *Server) GenerateFlamegraph(ctx context.Context, req *pb.FlamegraphReque
 	}
 }
 
+// StreamLogs asks the agent to tail the logs of a pod and calls onChunk for every chunk received,
+// with the lines dropped before it because the viewer was too slow
+// It returns when the agent sends the last chunk or ctx is done (a running follow is then stopped)
+func (s *Server) StreamLogs(ctx context.Context, req *pb.LogRequest, onChunk func(chunk *pb.LogChunk, dropped int) error) error {
+	agent, err := s.agentManager.GetAgent(req.NodeName)
+	if err != nil {
+		return fmt.Errorf("agent not connected: %v", err)
+	}
+	if err := agent.Require(types.FeatureLogs); err != nil {
+		return err
+	}
+
+	requestID := fmt.Sprintf("logs-%s-%s-%d", req.NodeName, req.PodName, time.Now().UnixNano())
+	stream := s.agentManager.RegisterLogStream(requestID)
+	defer s.agentManager.UnregisterLogStream(requestID)
+
+	err = agent.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_LogRequest{
+			LogRequest: &pb.LogRequest{
+				RequestId: requestID,
+				NodeName:  req.NodeName,
+				PodName:   req.PodName,
+				Container: req.Container,
+				TailLines: req.TailLines,
+				Follow:    req.Follow,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send log request to agent: %v", err)
+	}
+
+	for {
+		select {
+		case chunk := <-stream.Chunks:
+			if err := onChunk(chunk, stream.TakeDropped()); err != nil {
+				s.stopLogStream(agent, requestID, req.Follow)
+				return err
+			}
+		case last := <-stream.Last:
+			// The chunks delivered before the last one are still queued
+			for queued := true; queued; {
+				select {
+				case chunk := <-stream.Chunks:
+					if err := onChunk(chunk, stream.TakeDropped()); err != nil {
+						return err
+					}
+				default:
+					queued = false
+				}
+			}
+			if err := onChunk(last, stream.TakeDropped()); err != nil {
+				return err
+			}
+			if last.Error != "" {
+				return fmt.Errorf("agent error: %s", last.Error)
+			}
+			return nil
+		case <-agent.Context.Done():
+			return fmt.Errorf("agent %s disconnected", req.NodeName)
+		case <-ctx.Done():
+			s.stopLogStream(agent, requestID, req.Follow)
+			return nil
+		}
+	}
+}
+
+// stopLogStream tells the agent to stop following logs for a request
+func (s *Server) stopLogStream(agent *AgentConnection, requestID string, follow bool) {
+	if !follow {
+		return
+	}
+	err := agent.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_LogRequest{
+			LogRequest: &pb.LogRequest{
+				RequestId: requestID,
+				Stop:      true,
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to stop log stream %s: %v", requestID, err)
+	}
+}
+
+// RequestPodDetails asks the agent for the detail tier of a pod process
+// Stats only carry the summary tier, the details are read when they are displayed
+func (s *Server) RequestPodDetails(ctx context.Context, nodeName, podName string) (*types.PidDetails, error) {
+	agent, err := s.agentManager.GetAgent(nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("agent not connected: %v", err)
+	}
+	if err := agent.Require(types.FeaturePodDetails); err != nil {
+		return nil, err
+	}
+
+	requestID := fmt.Sprintf("details-%s-%s-%d", nodeName, podName, time.Now().UnixNano())
+	responseChan := s.agentManager.RegisterDetailsRequest(requestID)
+	defer s.agentManager.UnregisterDetailsRequest(requestID)
+
+	err = agent.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_PodDetailsRequest{
+			PodDetailsRequest: &pb.PodDetailsRequest{
+				RequestId: requestID,
+				NodeName:  nodeName,
+				PodName:   podName,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to agent: %v", err)
+	}
+
+	select {
+	case response := <-responseChan:
+		if response.Error != "" {
+			return nil, fmt.Errorf("%s", response.Error)
+		}
+		details := sharedGrpc.ConvertPidDetails(response.Details)
+		return &details, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for pod details from agent %s", nodeName)
+	}
+}
+
+// AgentCapabilities returns the capabilities reported by the agent of a node, false if it is not connected
+func (s *Server) AgentCapabilities(nodeName string) (types.AgentCapabilities, bool) {
+	agent, err := s.agentManager.GetAgent(nodeName)
+	if err != nil {
+		return types.AgentCapabilities{}, false
+	}
+	return agent.Capabilities, true
+}
+
+// SetLogPatternRulesProvider sets the source of log pattern rules pushed to agents
+func (s *Server) SetLogPatternRulesProvider(provider LogPatternRulesProvider) {
+	s.logRulesProvider = provider
+}
+
+// PushLogPatternRules sends the current log pattern rules of a node to its agent
+func (s *Server) PushLogPatternRules(nodeName string) error {
+	if s.logRulesProvider == nil {
+		return nil
+	}
+
+	agent, err := s.agentManager.GetAgent(nodeName)
+	if err != nil {
+		// Rules are pushed again when the agent connects
+		return nil
+	}
+	if err := agent.Require(types.FeatureLogPatterns); err != nil {
+		return err
+	}
+
+	rules, err := s.logRulesProvider(nodeName)
+	if err != nil {
+		return fmt.Errorf("failed to get log pattern rules for node %s: %v", nodeName, err)
+	}
+
+	err = agent.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_LogPatternRules{
+			LogPatternRules: sharedGrpc.ConvertToGRPCLogPatternRules(rules),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send log pattern rules to %s: %v", nodeName, err)
+	}
+
+	log.Printf("Pushed %d log pattern rules to agent %s", len(rules), nodeName)
+	return nil
+}
+
 // AgentStream implements bidirectional streaming for agents
 func (s *Server) AgentStream(stream pb.NodeService_AgentStreamServer) error {
 	ctx, cancel := context.WithCancel(stream.Context())
 	defer cancel()
 
 	var nodeName string
+	backfilled := 0 // Buffered stats replayed by the agent since its last live stats
+	decoder := &sharedGrpc.DeltaDecoder{}
+	resyncing := false // A snapshot was requested, the deltas sent in the meantime are dropped
+	connected := false // The node state tracks the stream from the hello to its end
+	var conn *AgentConnection
+	defer func() {
+		if connected {
+			storage.GlobalStore.MarkNodeDisconnected(nodeName)
+		}
+	}()
+
+	// Recv blocks until the agent sends, it is read apart so that cancelling the stream ends it
+	messages := make(chan *pb.AgentMessage)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	// Handle incoming messages from agent
 	for {
-		msg, err := stream.Recv()
-		if err != nil {
+		var msg *pb.AgentMessage
+		select {
+		case msg = <-messages:
+		case err := <-recvErr:
 			log.Printf("Stream error for node %s: %v", nodeName, err)
-			if nodeName != "" {
-				s.agentManager.UnregisterAgent(nodeName)
+			if conn != nil {
+				s.agentManager.UnregisterConnection(conn)
 			}
 			return err
+		case <-ctx.Done():
+			if conn != nil {
+				s.agentManager.UnregisterConnection(conn)
+			}
+			if err := stream.Context().Err(); err != nil {
+				log.Printf("Stream error for node %s: %v", nodeName, err)
+				return err
+			}
+			// Disconnected from the fleet page or replaced by a new stream of the node
+			log.Printf("Closing the stream of agent %s", nodeName)
+			return status.Error(codes.Aborted, "disconnected by the server")
 		}
 
 		switch m := msg.Message.(type) {
 		case *pb.AgentMessage_Hello:
 			// Agent registration
+			if err := validNodeName(m.Hello.NodeName); err != nil {
+				log.Printf("Rejected agent hello: %v", err)
+				return status.Errorf(codes.InvalidArgument, "%v", err)
+			}
+			// The stream stays bound to the node of its first hello
+			if nodeName != "" && m.Hello.NodeName != nodeName {
+				log.Printf("Rejected agent hello from node %s on the stream of node %s", m.Hello.NodeName, nodeName)
+				s.agentManager.UnregisterConnection(conn)
+				return status.Errorf(codes.InvalidArgument, "the stream is bound to node %s", nodeName)
+			}
+			if err := s.authenticateAgent(stream.Context(), m.Hello.NodeName); err != nil {
+				log.Printf("Rejected agent hello from node %s: %v", m.Hello.NodeName, err)
+				return status.Errorf(codes.PermissionDenied, "%v", err)
+			}
 			nodeName = m.Hello.NodeName
-			log.Printf("Agent hello from node %s, version %s", nodeName, m.Hello.AgentVersion)
+			capabilities := sharedGrpc.ConvertAgentCapabilities(m.Hello)
+			log.Printf("Agent hello from node %s, version %s, protocol %d", nodeName, m.Hello.AgentVersion, m.Hello.ProtocolVersion)
+			for _, feature := range capabilities.Features {
+				if !feature.Available {
+					log.Printf("Agent %s cannot serve %s: %s", nodeName, feature.Name, feature.Reason)
+				}
+			}
 
 			// Register the agent
-			s.agentManager.RegisterAgent(nodeName, stream, ctx, cancel)
+			conn = s.agentManager.RegisterAgent(nodeName, capabilities, stream, ctx, cancel)
+			if !connected {
+				storage.GlobalStore.MarkNodeConnected(nodeName)
+				connected = true
+			}
 
 			// Send acknowledgment
-			err = stream.Send(&pb.ServerMessage{
+			err := conn.Send(&pb.ServerMessage{
 				Message: &pb.ServerMessage_Ack{
 					Ack: &pb.ServerAck{
-						Message: fmt.Sprintf("Welcome agent %s", nodeName),
+						Message:         fmt.Sprintf("Welcome agent %s", nodeName),
+						ProtocolVersion: types.ProtocolVersion,
 					},
 				},
 			})
@@ -152,15 +439,71 @@ func (s *Server) AgentStream(stream pb.NodeService_AgentStreamServer) error {
 				return err
 			}
 
+			// A first ping measures the latency and clock skew without waiting for the ping interval
+			if capabilities.ProtocolVersion >= types.ProtocolVersionPing {
+				if err := conn.ping(); err != nil {
+					log.Printf("Failed to ping agent %s: %v", nodeName, err)
+				}
+			}
+
+			if err := s.PushLogPatternRules(nodeName); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			if err := s.PushAgentConfig(nodeName); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+
 		case *pb.AgentMessage_Stats:
+			// The stream is bound to the node of its hello
+			if nodeName == "" {
+				log.Printf("Warning: dropping stats of node %s sent before the hello", m.Stats.NodeName)
+				continue
+			}
+			if m.Stats.NodeName != nodeName {
+				log.Printf("Warning: dropping stats of node %.64q sent on the stream of node %s", m.Stats.NodeName, nodeName)
+				storage.GlobalStore.RecordPayloadRejected(nodeName, fmt.Sprintf("stats of node %.64q sent on the stream of the node", m.Stats.NodeName))
+				continue
+			}
+
+			// Deltas are rebuilt against the previous payload of the stream
+			metrics, err := decoder.Decode(m.Stats)
+			if err != nil {
+				if !resyncing {
+					log.Printf("Warning: dropping stats of node %s, requesting a snapshot: %v", nodeName, err)
+					s.requestResync(nodeName, err.Error())
+					resyncing = true
+				}
+				continue
+			}
+			if m.Stats.Sequence > 0 {
+				resyncing = false
+			}
+
 			// Handle stats submission via streaming
 			payload := types.NodeStatsPayload{
 				NodeName:  m.Stats.NodeName,
 				Timestamp: m.Stats.Timestamp.AsTime(),
-				Metrics:   sharedGrpc.ConvertNodeMetrics(m.Stats.Metrics),
+				Metrics:   sharedGrpc.ConvertNodeMetrics(metrics),
+				Backfill:  m.Stats.Backfill,
+				Samples:   sharedGrpc.ConvertNodeSamples(m.Stats.Samples),
+
+				CollectionMs: m.Stats.CollectionMs,
+			}
+			if err := s.checkStats(nodeName, &payload); err != nil {
+				continue
 			}
 			storage.GlobalStore.StoreNodeStats(payload)
 			s.agentManager.UpdateLastSeen(m.Stats.NodeName)
+			if conn != nil {
+				conn.recordStats(proto.Size(m.Stats), m.Stats.CollectionMs)
+			}
+
+			if payload.Backfill {
+				backfilled++
+			} else if backfilled > 0 {
+				log.Printf("Received %d backfilled stats from agent %s", backfilled, nodeName)
+				backfilled = 0
+			}
 
 		case *pb.AgentMessage_FlamegraphResponse:
 			// Handle flamegraph response
@@ -171,20 +514,109 @@ func (s *Server) AgentStream(stream pb.NodeService_AgentStreamServer) error {
 			} else {
 				log.Printf("Warning: Received flamegraph response without request ID from agent %s", nodeName)
 			}
+
+		case *pb.AgentMessage_LogChunk:
+			// Forward log lines to the viewer that requested them
+			s.agentManager.DeliverLogChunk(m.LogChunk.RequestId, m.LogChunk)
+
+		case *pb.AgentMessage_PodDetailsResponse:
+			s.agentManager.CompleteDetailsRequest(m.PodDetailsResponse.RequestId, m.PodDetailsResponse)
+
+		case *pb.AgentMessage_AgentConfigAck:
+			s.acknowledgeAgentConfig(nodeName, m.AgentConfigAck)
+
+		case *pb.AgentMessage_Pong:
+			if conn != nil {
+				if skew, ok := conn.recordPong(m.Pong); ok {
+					storage.GlobalStore.RecordClockSkew(nodeName, skew, types.ClockSkewPing)
+				}
+				s.agentManager.UpdateLastSeen(nodeName)
+			}
+
+		case *pb.AgentMessage_Ping:
+			// Messages are processed in order, the pong confirms that the stats sent before are stored
+			if conn == nil {
+				continue
+			}
+			err := conn.Send(&pb.ServerMessage{
+				Message: &pb.ServerMessage_Pong{
+					Pong: &pb.Pong{Id: m.Ping.Id},
+				},
+			})
+			if err != nil {
+				log.Printf("Failed to answer the ping of %s: %v", nodeName, err)
+			}
+
+		case *pb.AgentMessage_Goodbye:
+			// The agent stops on purpose, ending the stream lets it exit without waiting
+			if nodeName == "" {
+				return nil
+			}
+			log.Printf("Agent %s is stopping: %s", nodeName, m.Goodbye.Reason)
+			storage.GlobalStore.MarkNodeStopped(nodeName, m.Goodbye.Reason)
+			s.agentManager.UnregisterConnection(conn)
+			return nil
 		}
 	}
 }
 
+// requestResync asks an agent to send a full snapshot, its deltas cannot be rebuilt
+func (s *Server) requestResync(nodeName, reason string) {
+	agent, err := s.agentManager.GetAgent(nodeName)
+	if err != nil {
+		return
+	}
+	err = agent.Send(&pb.ServerMessage{
+		Message: &pb.ServerMessage_Resync{
+			Resync: &pb.ResyncRequest{Reason: reason},
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to request a snapshot from %s: %v", nodeName, err)
+	}
+}
+
 // StartGRPCServer démarre le serveur gRPC sur le port spécifié
-func StartGRPCServer(port string) error {
+// Agents connect in plaintext unless tlsOptions sets a certificate, and are authenticated by
+// their service account token if authOptions is enabled. Payloads beyond limits are rejected
+func StartGRPCServer(port string, tlsOptions TLSOptions, authOptions AuthOptions, limits PayloadLimits) error {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	options := []grpc.ServerOption{
+		// Agents ping every 30s by default, pings more frequent than MinTime close the connection
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+		// Detect agents gone without closing their stream (node down, network partition)
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    KeepaliveTime,
+			Timeout: KeepaliveTimeout,
+		}),
+	}
+	if tlsOptions.CertFile != "" {
+		creds, err := tlsOptions.credentials()
+		if err != nil {
+			return err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	grpcServer := grpc.NewServer(options...)
 	server := NewServer()
+	server.verifyNodeName = tlsOptions.ClientCAFile != "" && tlsOptions.VerifyNodeName
+	server.limits = limits
+	if authOptions.Enabled {
+		server.tokenAuth, err = authOptions.authenticator()
+		if err != nil {
+			return err
+		}
+	}
 	pb.RegisterNodeServiceServer(grpcServer, server)
+	go server.pingAgents(PingInterval)
 
 	log.Printf("gRPC server starting on port %s", port)
 	return grpcServer.Serve(lis)
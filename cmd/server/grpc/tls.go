@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/ThomasCardin/gobservability/shared/certs"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// TLSOptions configures TLS on the agent connections, agents connect in plaintext if CertFile is empty
+type TLSOptions struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // Agents must present a certificate signed by this CA (mTLS) if set
+	// The agent certificate must be issued for the node name sent in its hello (common name,
+	// DNS name or system:node:<name> common name), only checked with mTLS
+	VerifyNodeName bool
+}
+
+// credentials returns the server transport credentials, the certificate and client CA are read
+// again on each handshake once their files change
+func (o TLSOptions) credentials() (credentials.TransportCredentials, error) {
+	reloader, err := certs.NewReloader(o.CertFile, o.KeyFile, o.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*reloader.Certificate()},
+			}
+			if o.ClientCAFile != "" {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+				config.ClientCAs = reloader.CAPool()
+			}
+			return config, nil
+		},
+	}
+
+	if o.ClientCAFile != "" {
+		log.Printf("gRPC server using mTLS, node name verification: %t", o.VerifyNodeName)
+	} else {
+		log.Printf("gRPC server using TLS, agents are not authenticated")
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// verifyNodeIdentity checks that the agent certificate was issued for the node it claims to be
+func (s *Server) verifyNodeIdentity(ctx context.Context, nodeName string) error {
+	if !s.verifyNodeName {
+		return nil
+	}
+
+	p, found := peer.FromContext(ctx)
+	if !found {
+		return fmt.Errorf("unknown peer")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return fmt.Errorf("no verified client certificate from %s", p.Addr)
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	if !certificateMatchesNode(cert, nodeName) {
+		return fmt.Errorf("client certificate of %s (CN=%s, DNS=%v) is not issued for node %s",
+			p.Addr, cert.Subject.CommonName, cert.DNSNames, nodeName)
+	}
+	return nil
+}
+
+// certificateMatchesNode returns true if the certificate identifies the node
+func certificateMatchesNode(cert *x509.Certificate, nodeName string) bool {
+	if nodeName == "" {
+		return false
+	}
+	return cert.Subject.CommonName == nodeName ||
+		cert.Subject.CommonName == "system:node:"+nodeName ||
+		slices.Contains(cert.DNSNames, nodeName)
+}
@@ -0,0 +1,304 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/cmd/server/storage"
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// PayloadLimits bounds the payloads accepted from the agents
+type PayloadLimits struct {
+	MaxPods    int // Pods of a node, payloads with more pods are rejected
+	MaxSeries  int // Scraped, custom and pushed series of a payload, payloads with more are rejected
+	MaxSamples int // High resolution samples of a payload, the oldest are dropped beyond
+}
+
+// DefaultPayloadLimits are used until StartGRPCServer sets the limits of the flags
+var DefaultPayloadLimits = PayloadLimits{
+	MaxPods:    1000,
+	MaxSeries:  50000,
+	MaxSamples: 600, // Samples kept by an agent waiting for its next payload
+}
+
+// Kubernetes node and pod names are DNS subdomains
+const maxNameLength = 253
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+
+// validNodeName checks that a node name can be used as a key of the node data
+func validNodeName(nodeName string) error {
+	if nodeName == "" {
+		return fmt.Errorf("missing node name")
+	}
+	if len(nodeName) > maxNameLength || !namePattern.MatchString(nodeName) {
+		return fmt.Errorf("invalid node name %.64q", nodeName)
+	}
+	return nil
+}
+
+// sameHost returns true if two peer addresses have the same IP
+func sameHost(a, b string) bool {
+	hostA, _, errA := net.SplitHostPort(a)
+	hostB, _, errB := net.SplitHostPort(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return hostA == hostB
+}
+
+// checkStats validates a payload sent by the agent of agentNode before it is stored, rejected and
+// sanitized payloads are counted on the node of the agent, never on the node the payload claims
+func (s *Server) checkStats(agentNode string, payload *types.NodeStatsPayload) error {
+	fixes, err := validatePayload(payload, s.limits, time.Now())
+	if err != nil {
+		log.Printf("Warning: rejected stats of node %s: %v", agentNode, err)
+		storage.GlobalStore.RecordPayloadRejected(agentNode, err.Error())
+		return err
+	}
+	if fixes != "" && storage.GlobalStore.RecordPayloadSanitized(agentNode, fixes) {
+		log.Printf("Warning: sanitized stats of node %s: %s", agentNode, fixes)
+	}
+	return nil
+}
+
+// sanitizer clamps the values of a payload and counts what it fixed
+type sanitizer struct {
+	fixes map[string]int
+}
+
+func (z *sanitizer) fix(kind string) {
+	z.fixN(kind, 1)
+}
+
+func (z *sanitizer) fixN(kind string, n int) {
+	if z.fixes == nil {
+		z.fixes = make(map[string]int)
+	}
+	z.fixes[kind] += n
+}
+
+// value replaces NaN, infinite and negative values by 0
+func (z *sanitizer) value(v *float64) {
+	switch {
+	case math.IsNaN(*v) || math.IsInf(*v, 0):
+		*v = 0
+		z.fix("non finite value")
+	case *v < 0:
+		*v = 0
+		z.fix("negative value")
+	}
+}
+
+// percent clamps a percentage to [0, 100]
+func (z *sanitizer) percent(v *float64) {
+	z.value(v)
+	if *v > 100 {
+		*v = 100
+		z.fix("percentage above 100")
+	}
+}
+
+// counter replaces a negative counter by 0
+func (z *sanitizer) counter(v *int) {
+	if *v < 0 {
+		*v = 0
+		z.fix("negative counter")
+	}
+}
+
+// summary describes the fixes, e.g. "2 non finite value, 1 percentage above 100"
+func (z *sanitizer) summary() string {
+	kinds := make([]string, 0, len(z.fixes))
+	for kind := range z.fixes {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%d %s", z.fixes[kind], kind))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// validatePayload rejects a payload whose size exceeds the limits, and clamps the values out of
+// their range. It returns the fixes applied, empty if the payload was valid
+func validatePayload(payload *types.NodeStatsPayload, limits PayloadLimits, received time.Time) (string, error) {
+	if err := validNodeName(payload.NodeName); err != nil {
+		return "", err
+	}
+	metrics := &payload.Metrics
+	// The node metrics are always collected, the UI and the alerts read them
+	switch {
+	case metrics.CPU == nil:
+		return "", fmt.Errorf("missing cpu metrics")
+	case metrics.Memory == nil:
+		return "", fmt.Errorf("missing memory metrics")
+	case metrics.Network == nil:
+		return "", fmt.Errorf("missing network metrics")
+	case metrics.Disk == nil:
+		return "", fmt.Errorf("missing disk metrics")
+	}
+	if limits.MaxPods > 0 && len(metrics.Pods) > limits.MaxPods {
+		return "", fmt.Errorf("%d pods, the limit is %d", len(metrics.Pods), limits.MaxPods)
+	}
+	if series := countSeries(metrics); limits.MaxSeries > 0 && series > limits.MaxSeries {
+		return "", fmt.Errorf("%d series, the limit is %d", series, limits.MaxSeries)
+	}
+
+	z := &sanitizer{}
+	if payload.Timestamp.IsZero() || payload.Timestamp.Unix() <= 0 {
+		payload.Timestamp = received
+		z.fix("missing timestamp")
+	}
+	z.value(&payload.CollectionMs)
+
+	z.cpu(metrics.CPU)
+	z.memory(metrics.Memory)
+	z.network(metrics.Network)
+	z.disk(metrics.Disk)
+	metrics.Pods = z.pods(metrics.Pods)
+	for i := range metrics.CustomMetrics {
+		metrics.CustomMetrics[i].Samples = z.samples(metrics.CustomMetrics[i].Samples)
+		z.value(&metrics.CustomMetrics[i].DurationMs)
+	}
+	for i := range metrics.Pushed {
+		metrics.Pushed[i].Samples = z.samples(metrics.Pushed[i].Samples)
+	}
+	for i := range metrics.Collectors {
+		z.value(&metrics.Collectors[i].DurationMs)
+	}
+
+	payload.Samples = z.nodeSamples(payload.Samples, limits.MaxSamples)
+	return z.summary(), nil
+}
+
+// countSeries counts the scraped, custom and pushed series of a payload
+func countSeries(metrics *types.NodeMetrics) int {
+	series := 0
+	for _, pod := range metrics.Pods {
+		if pod != nil && pod.PodMetrics.Scrape != nil {
+			series += len(pod.PodMetrics.Scrape.Samples)
+		}
+	}
+	for _, custom := range metrics.CustomMetrics {
+		series += len(custom.Samples)
+	}
+	for _, pushed := range metrics.Pushed {
+		series += len(pushed.Samples)
+	}
+	return series
+}
+
+func (z *sanitizer) cpu(cpu *types.CPUStats) {
+	if cpu == nil {
+		return
+	}
+	for _, v := range []*int{&cpu.User, &cpu.Nice, &cpu.System, &cpu.Idle, &cpu.IOWait, &cpu.IRQ, &cpu.SoftIRQ, &cpu.Steal, &cpu.Total} {
+		z.counter(v)
+	}
+	z.percent(&cpu.CPUPercent)
+}
+
+func (z *sanitizer) memory(memory *types.MemoryStats) {
+	if memory == nil {
+		return
+	}
+	for _, v := range []*int{&memory.MemTotal, &memory.MemFree, &memory.MemAvailable, &memory.Buffers, &memory.Cached, &memory.SwapCached, &memory.SwapTotal, &memory.SwapFree} {
+		z.counter(v)
+	}
+	z.percent(&memory.MemoryPercent)
+}
+
+func (z *sanitizer) network(network *types.NetworkStats) {
+	if network == nil {
+		return
+	}
+	z.value(&network.RxRate)
+	z.value(&network.TxRate)
+	z.value(&network.TotalRate)
+}
+
+func (z *sanitizer) disk(disk *types.DiskStats) {
+	if disk == nil {
+		return
+	}
+	z.value(&disk.ReadRate)
+	z.value(&disk.WriteRate)
+	z.value(&disk.TotalRate)
+}
+
+// pods drops the pods without a valid name and the duplicate entries, and clamps the pod metrics. A pod
+// has an entry per container, entries are keyed like sharedGrpc.PodKey
+func (z *sanitizer) pods(pods []*types.Pod) []*types.Pod {
+	seen := make(map[string]bool, len(pods))
+	kept := pods[:0]
+	for _, pod := range pods {
+		if pod == nil || pod.Name == "" || len(pod.Name) > maxNameLength || len(pod.Namespace) > maxNameLength {
+			z.fix("invalid pod dropped")
+			continue
+		}
+		key := pod.Namespace + "/" + pod.Name + "/" + pod.ContainerID
+		if seen[key] {
+			z.fix("duplicate pod dropped")
+			continue
+		}
+		seen[key] = true
+
+		metrics := &pod.PodMetrics
+		z.value(&metrics.CPU.CPUPercent) // Above 100 when the pod uses more than one core
+		z.percent(&metrics.Memory.MemPercent)
+		z.percent(&metrics.Storage.EphemeralPercent)
+		for i := range metrics.Storage.Volumes {
+			z.percent(&metrics.Storage.Volumes[i].UsagePercent)
+		}
+		if metrics.Scrape != nil {
+			metrics.Scrape.Samples = z.samples(metrics.Scrape.Samples)
+			z.value(&metrics.Scrape.DurationMs)
+		}
+		kept = append(kept, pod)
+	}
+	return kept
+}
+
+// samples drops the series without a name or with a non finite value, gauges may be negative
+func (z *sanitizer) samples(samples []types.MetricSample) []types.MetricSample {
+	kept := samples[:0]
+	for _, sample := range samples {
+		if sample.Name == "" || math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			z.fix("invalid series dropped")
+			continue
+		}
+		kept = append(kept, sample)
+	}
+	return kept
+}
+
+// nodeSamples drops the samples without timestamp and the oldest beyond max, and clamps the others
+func (z *sanitizer) nodeSamples(samples []types.NodeSample, max int) []types.NodeSample {
+	kept := samples[:0]
+	for _, sample := range samples {
+		if sample.Timestamp.IsZero() || sample.Timestamp.Unix() <= 0 {
+			z.fix("sample without timestamp dropped")
+			continue
+		}
+		z.cpu(sample.CPU)
+		z.memory(sample.Memory)
+		z.network(sample.Network)
+		z.disk(sample.Disk)
+		kept = append(kept, sample)
+	}
+	if max > 0 && len(kept) > max {
+		z.fixN("sample above the limit dropped", len(kept)-max)
+		kept = kept[len(kept)-max:]
+	}
+	return kept
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"log"
+	"time"
 
 	"github.com/ThomasCardin/gobservability/cmd/server/alerts"
 	"github.com/ThomasCardin/gobservability/cmd/server/api"
@@ -15,12 +16,45 @@ var (
 	port     = flag.String("port", "8080", "Port d'écoute du serveur HTTP")
 	grpcPort = flag.String("grpc-port", "9090", "Port d'écoute du serveur gRPC")
 	ginMode  = flag.String("mode", "release", "Mode Gin (debug|release)")
+
+	sampleRetention = flag.Duration("sample-retention", storage.DefaultSampleRetention, "Durée de conservation des échantillons des nœuds")
+
+	nodeStaleAfter   = flag.Duration("node-stale-after", storage.DefaultNodeStateOptions.StaleAfter, "Délai sans données après lequel un nœud connecté est « stale »")
+	nodeOfflineAfter = flag.Duration("node-offline-after", storage.DefaultNodeStateOptions.OfflineAfter, "Délai après la perte du flux de l'agent après lequel un nœud est « offline »")
+	nodeRetention    = flag.Duration("node-retention", storage.DefaultNodeStateOptions.Retention, "Durée pendant laquelle un nœud déconnecté reste affiché")
+
+	clockSkewWarn       = flag.Duration("clock-skew-warn", storage.DefaultClockOptions.WarnAbove, "Écart d'horloge agent/serveur au-delà duquel un nœud est signalé (0: désactivé)")
+	normalizeTimestamps = flag.Bool("normalize-timestamps", false, "Recale les horodatages des agents sur l'horloge du serveur")
+
+	tlsCert       = flag.String("tls-cert", "", "Certificat TLS du serveur gRPC (PEM, rechargé à la rotation)")
+	tlsKey        = flag.String("tls-key", "", "Clé privée du certificat TLS du serveur gRPC")
+	tlsClientCA   = flag.String("tls-client-ca", "", "CA des certificats clients des agents (active le mTLS)")
+	tlsVerifyNode = flag.Bool("tls-verify-node-name", true, "Vérifie que le certificat de l'agent est émis pour le nœud annoncé (mTLS)")
+
+	authTokens         = flag.Bool("auth-tokens", false, "Authentifie les agents par leur jeton de compte de service (TokenReview)")
+	authAudience       = flag.String("auth-audience", "gobservability", "Audience attendue des jetons des agents (vide: audience de l'API server)")
+	authServiceAccount = flag.String("auth-service-account", "gobservability/gobservability-agent", "Compte de service des agents (namespace/nom)")
+	authDaemonSet      = flag.String("auth-daemonset", "gobservability-agent", "DaemonSet des pods agents, dans le namespace du compte de service")
+
+	maxPods    = flag.Int("max-pods", grpcServer.DefaultPayloadLimits.MaxPods, "Nombre maximum de pods par nœud, au-delà les données sont rejetées (0: illimité)")
+	maxSeries  = flag.Int("max-series", grpcServer.DefaultPayloadLimits.MaxSeries, "Nombre maximum de séries (Prometheus, custom, StatsD/OTLP) par envoi, au-delà les données sont rejetées (0: illimité)")
+	maxSamples = flag.Int("max-samples", grpcServer.DefaultPayloadLimits.MaxSamples, "Nombre maximum d'échantillons par envoi, les plus anciens sont ignorés (0: illimité)")
 )
 
 func main() {
 	flag.Parse()
 
 	gin.SetMode(*ginMode)
+	storage.GlobalStore.SetSampleRetention(*sampleRetention)
+	storage.GlobalStore.SetNodeStateOptions(storage.NodeStateOptions{
+		StaleAfter:   *nodeStaleAfter,
+		OfflineAfter: *nodeOfflineAfter,
+		Retention:    *nodeRetention,
+	})
+	storage.GlobalStore.SetClockOptions(storage.ClockOptions{
+		WarnAbove: *clockSkewWarn,
+		Normalize: *normalizeTimestamps,
+	})
 	r := gin.Default()
 
 	r.LoadHTMLGlob("cmd/server/templates/*.html")
@@ -43,6 +77,21 @@ func main() {
 	r.GET("/api/flamegraph/:taskid/status", api.FlamegraphStatusHandler)                      // API pour vérifier statut flamegraph
 	r.GET("/api/flamegraph/:taskid/download", api.DownloadFlamegraphHandler)                  // API pour télécharger flamegraph
 	r.GET("/flamegraph/:nodename/:podname", api.FlamegraphPageHandler)                        // Page dédiée pour afficher flamegraph
+	r.GET("/api/pods/:nodename/:podname/logs", api.PodLogsHandler)                            // SSE pour les logs des conteneurs
+	r.GET("/api/nodes", api.NodeStatusesHandler)                                              // API état des nœuds
+	r.GET("/api/nodes/:nodename", api.NodeStatusHandler)                                      // API état d'un nœud
+	r.GET("/api/nodes/:nodename/samples", api.NodeSamplesHandler)                             // API échantillons d'un nœud
+	r.GET("/agent-config/:nodename", api.AgentConfigPageHandler)                              // Page configuration agent
+	r.GET("/api/agent-config", api.GetAgentConfigsHandler)                                    // API config de tous les agents
+	r.GET("/api/agent-config/:nodename", api.GetAgentConfigHandler)                           // API config d'un agent
+	r.PUT("/api/agent-config", api.SetAgentConfigHandler)                                     // Pousser une config à tous les agents
+	r.PUT("/api/agent-config/:nodename", api.SetAgentConfigHandler)                           // Pousser une config à un agent
+	r.DELETE("/api/agent-config", api.ResetAgentConfigHandler)                                // Supprimer la config par défaut
+	r.DELETE("/api/agent-config/:nodename", api.ResetAgentConfigHandler)                      // Supprimer la config d'un agent
+	r.GET("/agents", api.AgentsPageHandler)                                                   // Page flotte des agents
+	r.GET("/api/agents", api.GetAgentsHandler)                                                // API agents connectés
+	r.POST("/api/agents/:nodename/disconnect", api.DisconnectAgentHandler)                    // Déconnecter un agent
+	r.POST("/api/agents/:nodename/collect", api.CollectAgentHandler)                          // Demander une collecte immédiate
 
 	// Initialiser le système d'alertes
 	alertsManager, err := alerts.NewAlertsManager()
@@ -56,6 +105,7 @@ func main() {
 		api.SetAlertsStorage(alertsManager.GetStorage())
 		api.SetDiscordNotifier(alertsManager.GetDiscord())
 		storage.GlobalStore.SetAlertsManager(alertsManager)
+		grpcServer.NewServer().SetLogPatternRulesProvider(alertsManager.LogPatternRules)
 		
 		// Routes pour les alertes
 		r.GET("/alerts/:nodename", api.AlertsPageHandler)                    // Page principale des alertes
@@ -75,10 +125,30 @@ func main() {
 		}()
 	}
 
+	// Suivre l'état des nœuds et évaluer les alertes « agent down »
+	go storage.GlobalStore.WatchNodes(10 * time.Second)
+
 	// Démarrer le serveur gRPC en goroutine
 	go func() {
 		log.Printf("Starting gRPC server on port %s", *grpcPort)
-		if err := grpcServer.StartGRPCServer(*grpcPort); err != nil {
+		tlsOptions := grpcServer.TLSOptions{
+			CertFile:       *tlsCert,
+			KeyFile:        *tlsKey,
+			ClientCAFile:   *tlsClientCA,
+			VerifyNodeName: *tlsVerifyNode,
+		}
+		authOptions := grpcServer.AuthOptions{
+			Enabled:        *authTokens,
+			Audience:       *authAudience,
+			ServiceAccount: *authServiceAccount,
+			DaemonSet:      *authDaemonSet,
+		}
+		limits := grpcServer.PayloadLimits{
+			MaxPods:    *maxPods,
+			MaxSeries:  *maxSeries,
+			MaxSamples: *maxSamples,
+		}
+		if err := grpcServer.StartGRPCServer(*grpcPort, tlsOptions, authOptions, limits); err != nil {
 			log.Fatalf("error: starting gRPC server: %v", err)
 		}
 	}()
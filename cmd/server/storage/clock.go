@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"log"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// ClockOptions sets when the clock of an agent is reported as skewed and if its timestamps are
+// shifted to the server clock
+type ClockOptions struct {
+	WarnAbove time.Duration // Skew above which the node is flagged and a warning is logged
+	Normalize bool          // Shift the timestamps of the payloads and samples by the skew of their node
+}
+
+// DefaultClockOptions are used until SetClockOptions is called
+var DefaultClockOptions = ClockOptions{
+	WarnAbove: 2 * time.Second,
+}
+
+// pingSkewValidity is how long a skew measured by ping is preferred over the payload estimates,
+// payloads include the transit time and pings are only answered by recent agents
+const pingSkewValidity = 5 * time.Minute
+
+// SetClockOptions changes the clock skew threshold and normalization
+func (s *CacheStore) SetClockOptions(options ClockOptions) {
+	s.clock = options
+}
+
+// RecordClockSkew records the skew of the clock of the agent of a node, skew is the agent clock
+// minus the server clock. Crossing the warning threshold is logged
+func (s *CacheStore) RecordClockSkew(nodeName string, skew time.Duration, source types.ClockSkewSource) {
+	var warn, recovered bool
+	s.updateNode(nodeName, func(r *nodeRecord, now time.Time) {
+		if source == types.ClockSkewPayload && r.skewSource == types.ClockSkewPing && now.Sub(r.skewAt) < pingSkewValidity {
+			return
+		}
+		r.skew = skew
+		r.skewSource = source
+		r.skewAt = now
+
+		skewed := s.clock.WarnAbove > 0 && skew.Abs() > s.clock.WarnAbove
+		warn = skewed && !r.skewWarned
+		recovered = !skewed && r.skewWarned
+		r.skewWarned = skewed
+	})
+
+	switch {
+	case warn && skew > 0:
+		log.Printf("Warning: clock of node %s is %s ahead of the server (%s)", nodeName, skew.Round(time.Millisecond), source)
+	case warn:
+		log.Printf("Warning: clock of node %s is %s behind the server (%s)", nodeName, (-skew).Round(time.Millisecond), source)
+	case recovered:
+		log.Printf("Clock of node %s is back within %s of the server", nodeName, s.clock.WarnAbove)
+	}
+}
+
+// observeClock estimates the skew of the agent from a live payload received at now, and shifts
+// the timestamps of the payload to the server clock if normalization is enabled
+func (s *CacheStore) observeClock(stats *types.NodeStatsPayload, now time.Time) {
+	// Live payloads are sent right after their collection, backfilled ones were delayed
+	if !stats.Backfill && !stats.Timestamp.IsZero() {
+		s.RecordClockSkew(stats.NodeName, stats.Timestamp.Sub(now), types.ClockSkewPayload)
+	}
+	if !s.clock.Normalize {
+		return
+	}
+
+	record := s.node(stats.NodeName)
+	record.mu.Lock()
+	skew := record.skew
+	record.mu.Unlock()
+	if skew == 0 {
+		return
+	}
+
+	stats.Timestamp = stats.Timestamp.Add(-skew)
+	// The samples may be shared with the caller, they are copied before being shifted
+	samples := make([]types.NodeSample, len(stats.Samples))
+	for i, sample := range stats.Samples {
+		sample.Timestamp = sample.Timestamp.Add(-skew)
+		samples[i] = sample
+	}
+	stats.Samples = samples
+}
+
+// clockStatus sets the skew of the agent of the node in its status
+func (r *nodeRecord) clockStatus(status *types.NodeStatus, options ClockOptions) {
+	if r.skewSource == "" {
+		return
+	}
+	status.ClockSkewMs = float64(r.skew) / float64(time.Millisecond)
+	status.ClockSkewSource = r.skewSource
+	status.ClockSkewed = options.WarnAbove > 0 && r.skew.Abs() > options.WarnAbove
+}
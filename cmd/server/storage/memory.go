@@ -15,6 +15,11 @@ type CacheStore struct {
 	cache           *cache.Cache
 	flamegraphTasks *cache.Cache
 	alertsManager   *alerts.AlertsManager
+	samples         *cache.Cache // Node name -> *sampleSeries
+	sampleRetention time.Duration
+	nodes           *cache.Cache // Node name -> *nodeRecord
+	nodeStates      NodeStateOptions
+	clock           ClockOptions
 }
 
 type FlamegraphTask struct {
@@ -29,11 +34,15 @@ type FlamegraphTask struct {
 }
 
 func NewCacheStore(defaultExpiration, cleanupInterval time.Duration) *CacheStore {
-	return &CacheStore{
+	store := &CacheStore{
 		cache:           cache.New(defaultExpiration, cleanupInterval),
 		flamegraphTasks: cache.New(30*time.Minute, 5*time.Minute), // Tasks expire after 30 minutes
 		alertsManager:   nil, // Set later via SetAlertsManager
 	}
+	store.SetSampleRetention(DefaultSampleRetention)
+	store.SetNodeStateOptions(DefaultNodeStateOptions)
+	store.SetClockOptions(DefaultClockOptions)
+	return store
 }
 
 // SetAlertsManager sets the alerts manager for metric evaluation
@@ -41,8 +50,22 @@ func (s *CacheStore) SetAlertsManager(manager *alerts.AlertsManager) {
 	s.alertsManager = manager
 }
 
-// StoreNodeStats stores incoming node statistics from agents
+// StoreNodeStats stores incoming node statistics from agents, and their samples in the node series
+// Backfilled stats replayed by an agent after an outage never replace newer stats and are not
+// evaluated by the alerts, which only apply to the current state of a node
 func (s *CacheStore) StoreNodeStats(stats types.NodeStatsPayload) {
+	s.observeClock(&stats, time.Now())
+	s.storeSamples(stats)
+	s.seeNode(stats)
+
+	if stats.Backfill {
+		if current, found := s.GetNodeStats(stats.NodeName); found && !current.Timestamp.Before(stats.Timestamp) {
+			return
+		}
+		s.cache.Set(stats.NodeName, stats, cache.DefaultExpiration)
+		return
+	}
+
 	s.cache.Set(stats.NodeName, stats, cache.DefaultExpiration)
 	
 	// Evaluate alerts if alerts manager is available
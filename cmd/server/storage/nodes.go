@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"github.com/patrickmn/go-cache"
+)
+
+// NodeStateOptions sets when a node is considered stale or offline and how long it is listed
+type NodeStateOptions struct {
+	StaleAfter   time.Duration // Time without payload after which a connected node is stale
+	OfflineAfter time.Duration // Time after a stream loss after which the node is offline
+	Retention    time.Duration // Time a node is listed after its last payload or stream event
+}
+
+// DefaultNodeStateOptions are used until SetNodeStateOptions is called
+var DefaultNodeStateOptions = NodeStateOptions{
+	StaleAfter:   30 * time.Second,
+	OfflineAfter: 1 * time.Minute,
+	Retention:    24 * time.Hour,
+}
+
+// nodeRecord tracks the payloads and the agent streams of a node
+type nodeRecord struct {
+	streams        int // Open agent streams, an agent reconnecting briefly has two
+	lastSeen       time.Time
+	connectedAt    time.Time
+	disconnectedAt time.Time
+	stopped        bool
+	stopReason     string
+	last           *types.NodeStatsPayload // Last payload, shown while the node is not online
+	state          types.NodeState         // State at the last watch, to log transitions
+	skew           time.Duration           // Clock of the agent minus clock of the server
+	skewSource     types.ClockSkewSource   // Empty until the skew is measured
+	skewAt         time.Time
+	skewWarned     bool // The skew is above the threshold and was logged
+
+	rejected         uint64 // Payloads rejected by the validation
+	lastRejection    string
+	lastRejectionAt  time.Time
+	sanitized        uint64 // Payloads accepted once their values were clamped
+	lastSanitization string
+
+	mu sync.Mutex
+}
+
+// SetNodeStateOptions changes the node state thresholds, the tracked nodes are dropped
+func (s *CacheStore) SetNodeStateOptions(options NodeStateOptions) {
+	s.nodeStates = options
+	s.nodes = cache.New(options.Retention, time.Minute)
+}
+
+// node returns the record of a node, created on its first payload or stream
+func (s *CacheStore) node(nodeName string) *nodeRecord {
+	if item, found := s.nodes.Get(nodeName); found {
+		return item.(*nodeRecord)
+	}
+	record := &nodeRecord{}
+	if err := s.nodes.Add(nodeName, record, cache.DefaultExpiration); err != nil {
+		if item, found := s.nodes.Get(nodeName); found {
+			return item.(*nodeRecord)
+		}
+	}
+	return record
+}
+
+// updateNode applies an event to the record of a node and restarts its retention
+func (s *CacheStore) updateNode(nodeName string, update func(r *nodeRecord, now time.Time)) {
+	record := s.node(nodeName)
+	record.mu.Lock()
+	update(record, time.Now())
+	record.mu.Unlock()
+	s.nodes.SetDefault(nodeName, record)
+}
+
+// seeNode records a payload of a node, backfilled payloads never replace a newer one
+func (s *CacheStore) seeNode(stats types.NodeStatsPayload) {
+	s.updateNode(stats.NodeName, func(r *nodeRecord, now time.Time) {
+		r.lastSeen = now
+		if r.last == nil || !stats.Backfill || r.last.Timestamp.Before(stats.Timestamp) {
+			r.last = &stats
+		}
+	})
+}
+
+// MarkNodeConnected records an agent hello, the node is no longer stopped
+func (s *CacheStore) MarkNodeConnected(nodeName string) {
+	s.updateNode(nodeName, func(r *nodeRecord, now time.Time) {
+		r.streams++
+		r.connectedAt = now
+		r.stopped = false
+		r.stopReason = ""
+	})
+}
+
+// MarkNodeDisconnected records the end of an agent stream
+func (s *CacheStore) MarkNodeDisconnected(nodeName string) {
+	s.updateNode(nodeName, func(r *nodeRecord, now time.Time) {
+		r.streams = max(r.streams-1, 0)
+		r.disconnectedAt = now
+	})
+}
+
+// MarkNodeStopped records that the agent of a node stopped on purpose, its stream ends next
+func (s *CacheStore) MarkNodeStopped(nodeName, reason string) {
+	s.updateNode(nodeName, func(r *nodeRecord, now time.Time) {
+		r.stopped = true
+		r.stopReason = reason
+	})
+}
+
+// status derives the state of the node at now
+func (r *nodeRecord) status(nodeName string, now time.Time, options NodeStateOptions, clock ClockOptions) types.NodeStatus {
+	status := types.NodeStatus{
+		NodeName:       nodeName,
+		Connected:      r.streams > 0,
+		LastSeen:       r.lastSeen,
+		ConnectedAt:    r.connectedAt,
+		DisconnectedAt: r.disconnectedAt,
+	}
+
+	// A node without stream, e.g. sending with the unary API, is judged on its payloads alone
+	lost := r.lastSeen
+	if r.disconnectedAt.After(lost) {
+		lost = r.disconnectedAt
+	}
+	fresh := r.lastSeen
+	if r.connectedAt.After(fresh) {
+		fresh = r.connectedAt
+	}
+
+	switch {
+	case r.stopped && !status.Connected:
+		status.State = types.NodeStopped
+		status.Reason = r.stopReason
+	case status.Connected && now.Sub(fresh) <= options.StaleAfter:
+		status.State = types.NodeOnline
+	case status.Connected:
+		status.State = types.NodeStale
+		status.Reason = fmt.Sprintf("no payload for %s", now.Sub(fresh).Round(time.Second))
+	case r.connectedAt.IsZero() && now.Sub(lost) <= options.StaleAfter:
+		status.State = types.NodeOnline
+	case now.Sub(lost) < options.OfflineAfter:
+		status.State = types.NodeStale
+		status.Reason = fmt.Sprintf("agent disconnected %s ago", now.Sub(lost).Round(time.Second))
+	default:
+		status.State = types.NodeOffline
+		status.Reason = fmt.Sprintf("no agent for %s", now.Sub(lost).Round(time.Second))
+		status.OfflineFor = now.Sub(lost).Seconds()
+	}
+	r.clockStatus(&status, clock)
+	r.payloadStatus(&status)
+	return status
+}
+
+// GetNodeStatus returns the state of a node, false if it was not seen during the retention
+func (s *CacheStore) GetNodeStatus(nodeName string) (types.NodeStatus, bool) {
+	item, found := s.nodes.Get(nodeName)
+	if !found {
+		return types.NodeStatus{}, false
+	}
+	record := item.(*nodeRecord)
+
+	record.mu.Lock()
+	defer record.mu.Unlock()
+	return record.status(nodeName, time.Now(), s.nodeStates, s.clock), true
+}
+
+// GetNodeStatuses returns the state of the nodes seen during the retention, sorted by name
+func (s *CacheStore) GetNodeStatuses() []types.NodeStatus {
+	now := time.Now()
+	statuses := make([]types.NodeStatus, 0, s.nodes.ItemCount())
+	for nodeName, item := range s.nodes.Items() {
+		record := item.Object.(*nodeRecord)
+		record.mu.Lock()
+		statuses = append(statuses, record.status(nodeName, now, s.nodeStates, s.clock))
+		record.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].NodeName < statuses[j].NodeName
+	})
+	return statuses
+}
+
+// GetLastNodeStats returns the last payload of a node during the retention, unlike GetNodeStats
+// it is still returned once the node stopped sending
+func (s *CacheStore) GetLastNodeStats(nodeName string) (*types.NodeStatsPayload, bool) {
+	item, found := s.nodes.Get(nodeName)
+	if !found {
+		return nil, false
+	}
+	record := item.(*nodeRecord)
+
+	record.mu.Lock()
+	defer record.mu.Unlock()
+	return record.last, record.last != nil
+}
+
+// WatchNodes derives the state of the nodes every interval, logs the transitions and evaluates
+// the agent down alerts. It never returns
+func (s *CacheStore) WatchNodes(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		for nodeName, item := range s.nodes.Items() {
+			record := item.Object.(*nodeRecord)
+
+			record.mu.Lock()
+			status := record.status(nodeName, now, s.nodeStates, s.clock)
+			previous := record.state
+			record.state = status.State
+			connected := status.Connected
+			record.mu.Unlock()
+
+			if previous != "" && previous != status.State {
+				if status.Reason != "" {
+					log.Printf("Node %s is %s (was %s): %s", nodeName, status.State, previous, status.Reason)
+				} else {
+					log.Printf("Node %s is %s (was %s)", nodeName, status.State, previous)
+				}
+			}
+			// Connected nodes stay listed even if their agent stopped sending
+			if connected {
+				s.nodes.SetDefault(nodeName, record)
+			}
+			if s.alertsManager != nil {
+				s.alertsManager.EvaluateNodeState(nodeName, status.OfflineFor)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+)
+
+// RecordPayloadRejected counts a payload of the agent of a node rejected by the validation
+func (s *CacheStore) RecordPayloadRejected(nodeName, reason string) {
+	s.updateNode(nodeName, func(r *nodeRecord, now time.Time) {
+		r.rejected++
+		r.lastRejection = reason
+		r.lastRejectionAt = now
+	})
+}
+
+// RecordPayloadSanitized counts a payload of the agent of a node whose values were clamped, it
+// returns true if the fixes differ from the previous sanitized payload
+func (s *CacheStore) RecordPayloadSanitized(nodeName, fixes string) bool {
+	changed := false
+	s.updateNode(nodeName, func(r *nodeRecord, now time.Time) {
+		r.sanitized++
+		changed = r.lastSanitization != fixes
+		r.lastSanitization = fixes
+	})
+	return changed
+}
+
+// payloadStatus sets the validation counters of the agent of the node in its status
+func (r *nodeRecord) payloadStatus(status *types.NodeStatus) {
+	status.RejectedPayloads = r.rejected
+	status.LastRejection = r.lastRejection
+	status.LastRejectionAt = r.lastRejectionAt
+	status.SanitizedPayloads = r.sanitized
+	status.LastSanitization = r.lastSanitization
+}
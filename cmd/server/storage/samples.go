@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"github.com/patrickmn/go-cache"
+)
+
+// DefaultSampleRetention is how long the node samples are kept
+const DefaultSampleRetention = 15 * time.Minute
+
+// sampleSeries holds the samples of a node ordered by timestamp
+type sampleSeries struct {
+	samples []types.NodeSample
+	mu      sync.Mutex
+}
+
+// SetSampleRetention changes how long the node samples are kept, the stored samples are dropped
+func (s *CacheStore) SetSampleRetention(retention time.Duration) {
+	s.sampleRetention = retention
+	s.samples = cache.New(retention, time.Minute)
+}
+
+// storeSamples records the samples of a payload under their own timestamp, a payload without
+// samples is recorded as one sample. Backfilled samples fill the gap of the outage
+func (s *CacheStore) storeSamples(stats types.NodeStatsPayload) {
+	samples := stats.Samples
+	if len(samples) == 0 {
+		samples = []types.NodeSample{{
+			Timestamp: stats.Timestamp,
+			CPU:       stats.Metrics.CPU,
+			Memory:    stats.Metrics.Memory,
+			Network:   stats.Metrics.Network,
+			Disk:      stats.Metrics.Disk,
+		}}
+	}
+
+	series := s.series(stats.NodeName)
+
+	series.mu.Lock()
+	for _, sample := range samples {
+		series.insert(sample)
+	}
+	series.trim(time.Now().Add(-s.sampleRetention))
+	series.mu.Unlock()
+
+	// Series of nodes that stopped sending expire after the retention
+	s.samples.SetDefault(stats.NodeName, series)
+}
+
+// series returns the sample series of a node, created on its first payload
+func (s *CacheStore) series(nodeName string) *sampleSeries {
+	if item, found := s.samples.Get(nodeName); found {
+		return item.(*sampleSeries)
+	}
+	series := &sampleSeries{}
+	if err := s.samples.Add(nodeName, series, cache.DefaultExpiration); err != nil {
+		// Created concurrently by another stream of the node
+		if item, found := s.samples.Get(nodeName); found {
+			return item.(*sampleSeries)
+		}
+	}
+	return series
+}
+
+// insert adds a sample in timestamp order, a sample with the timestamp of a stored one is ignored
+func (ss *sampleSeries) insert(sample types.NodeSample) {
+	count := len(ss.samples)
+	if count == 0 || ss.samples[count-1].Timestamp.Before(sample.Timestamp) {
+		ss.samples = append(ss.samples, sample)
+		return
+	}
+
+	i := sort.Search(count, func(i int) bool {
+		return !ss.samples[i].Timestamp.Before(sample.Timestamp)
+	})
+	if i < count && ss.samples[i].Timestamp.Equal(sample.Timestamp) {
+		return
+	}
+	ss.samples = append(ss.samples, types.NodeSample{})
+	copy(ss.samples[i+1:], ss.samples[i:])
+	ss.samples[i] = sample
+}
+
+// trim drops the samples older than cutoff
+func (ss *sampleSeries) trim(cutoff time.Time) {
+	i := sort.Search(len(ss.samples), func(i int) bool {
+		return !ss.samples[i].Timestamp.Before(cutoff)
+	})
+	if i > 0 {
+		ss.samples = append([]types.NodeSample(nil), ss.samples[i:]...)
+	}
+}
+
+// GetNodeSamples returns the samples of a node taken after since, oldest first, false if the
+// node sent no payload during the retention
+func (s *CacheStore) GetNodeSamples(nodeName string, since time.Time) ([]types.NodeSample, bool) {
+	item, found := s.samples.Get(nodeName)
+	if !found {
+		return nil, false
+	}
+	series := item.(*sampleSeries)
+
+	series.mu.Lock()
+	defer series.mu.Unlock()
+
+	i := sort.Search(len(series.samples), func(i int) bool {
+		return series.samples[i].Timestamp.After(since)
+	})
+	return append([]types.NodeSample{}, series.samples[i:]...), true
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/ThomasCardin/gobservability/proto"
+	sharedGrpc "github.com/ThomasCardin/gobservability/shared/grpc"
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip" // Registers the gzip compressor
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Version reported by the virtual agents in their hello
+const simulatorVersion = "simulator"
+
+// Reconnection backoff of the virtual agents
+const (
+	minBackoff = 1 * time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// agentOptions are shared by all the virtual agents
+type agentOptions struct {
+	server        string
+	interval      time.Duration
+	samples       int // Samples per payload, 0 to send none
+	compression   string
+	delta         bool
+	snapshotEvery int
+}
+
+// virtualAgent streams the payloads of a virtual node to the server like a real agent
+type virtualAgent struct {
+	node     *virtualNode
+	options  agentOptions
+	recorder *recorder
+
+	delta   *sharedGrpc.DeltaEncoder // nil unless delta payloads are enabled
+	sendMu  sync.Mutex
+	pingID  uint64
+	pings   map[uint64]time.Time // Ping id -> sending time of the payload before it
+	pingsMu sync.Mutex
+}
+
+func newVirtualAgent(node *virtualNode, options agentOptions, recorder *recorder) *virtualAgent {
+	a := &virtualAgent{
+		node:     node,
+		options:  options,
+		recorder: recorder,
+		pings:    make(map[uint64]time.Time),
+	}
+	if options.delta {
+		a.delta = sharedGrpc.NewDeltaEncoder(options.snapshotEvery)
+	}
+	return a
+}
+
+// run streams payloads until ctx is done, reconnecting with a backoff when the stream fails
+func (a *virtualAgent) run(ctx context.Context) {
+	backoff := minBackoff
+	for ctx.Err() == nil {
+		connectedAt := time.Now()
+		err := a.stream(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		a.recorder.streamError()
+		slog.Warn("stream failed, reconnecting", "component", "simulate", "node", a.node.name, "error", err, "backoff", backoff)
+		// A stream that stayed up resets the backoff
+		if time.Since(connectedAt) > maxBackoff {
+			backoff = minBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// stream opens a stream, says hello and sends a payload every interval until ctx is done
+func (a *virtualAgent) stream(ctx context.Context) error {
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if a.options.compression != "" {
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(grpc.UseCompressor(a.options.compression)))
+	}
+	conn, err := grpc.NewClient(a.options.server, dialOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewNodeServiceClient(conn).AgentStream(streamCtx)
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %v", err)
+	}
+
+	hello := &pb.AgentMessage{
+		Message: &pb.AgentMessage_Hello{
+			Hello: &pb.AgentHello{
+				NodeName:        a.node.name,
+				AgentVersion:    simulatorVersion,
+				ProtocolVersion: types.ProtocolVersion,
+				Features: []*pb.AgentFeature{
+					{Name: types.FeatureCollect, Available: true},
+				},
+			},
+		},
+	}
+	if err := a.send(stream, hello); err != nil {
+		return fmt.Errorf("failed to send hello: %v", err)
+	}
+	if a.delta != nil {
+		a.delta.Reset()
+	}
+	a.pingsMu.Lock()
+	clear(a.pings)
+	a.pingsMu.Unlock()
+
+	a.recorder.connected.Add(1)
+	defer a.recorder.connected.Add(-1)
+
+	var serverProtocol atomic.Uint32
+	collect := make(chan struct{}, 1)
+	received := make(chan error, 1)
+	go func() {
+		received <- a.receive(stream, &serverProtocol, collect)
+	}()
+
+	ticker := time.NewTicker(a.options.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			goodbye := &pb.AgentMessage{
+				Message: &pb.AgentMessage_Goodbye{
+					Goodbye: &pb.AgentGoodbye{Reason: "simulation finished"},
+				},
+			}
+			if err := a.send(stream, goodbye); err == nil {
+				stream.CloseSend()
+				// The server ends the stream once it recorded the goodbye
+				select {
+				case <-received:
+				case <-time.After(5 * time.Second):
+				}
+			}
+			return nil
+		case err := <-received:
+			return err
+		case <-ticker.C:
+		case <-collect:
+		}
+
+		if err := a.sendStats(stream, serverProtocol.Load()); err != nil {
+			return err
+		}
+	}
+}
+
+// sendStats sends the next payload of the node, followed by a ping measuring its ingestion if the
+// server answers pings
+func (a *virtualAgent) sendStats(stream pb.NodeService_AgentStreamClient, serverProtocol uint32) error {
+	request := a.node.next(time.Now(), a.options.interval, a.options.samples)
+	pods := len(request.Metrics.Pods)
+	if a.delta != nil && serverProtocol >= types.ProtocolVersionDelta {
+		live := &pb.NodeStatsRequest{
+			NodeName:  request.NodeName,
+			Timestamp: request.Timestamp,
+			Samples:   request.Samples,
+		}
+		a.delta.Encode(live, request.Metrics)
+		request = live
+	}
+
+	sentAt := time.Now()
+	stats := &pb.AgentMessage{
+		Message: &pb.AgentMessage_Stats{Stats: request},
+	}
+	if err := a.send(stream, stats); err != nil {
+		return fmt.Errorf("failed to send stats: %v", err)
+	}
+	a.recorder.sent(proto.Size(request), pods)
+
+	if serverProtocol < types.ProtocolVersionServerPing {
+		return nil
+	}
+	a.pingsMu.Lock()
+	a.pingID++
+	id := a.pingID
+	a.pings[id] = sentAt
+	a.pingsMu.Unlock()
+
+	ping := &pb.AgentMessage{
+		Message: &pb.AgentMessage_Ping{Ping: &pb.Ping{Id: id}},
+	}
+	if err := a.send(stream, ping); err != nil {
+		return fmt.Errorf("failed to send ping: %v", err)
+	}
+	return nil
+}
+
+// receive handles the messages of the server until the stream ends
+func (a *virtualAgent) receive(stream pb.NodeService_AgentStreamClient, serverProtocol *atomic.Uint32, collect chan struct{}) error {
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("stream closed by the server")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch m := msg.Message.(type) {
+		case *pb.ServerMessage_Ack:
+			serverProtocol.Store(m.Ack.ProtocolVersion)
+
+		case *pb.ServerMessage_Pong:
+			a.pingsMu.Lock()
+			sentAt, found := a.pings[m.Pong.Id]
+			delete(a.pings, m.Pong.Id)
+			a.pingsMu.Unlock()
+			if found {
+				a.recorder.ingested(time.Since(sentAt))
+			}
+
+		case *pb.ServerMessage_Ping:
+			pong := &pb.AgentMessage{
+				Message: &pb.AgentMessage_Pong{
+					Pong: &pb.Pong{Id: m.Ping.Id, AgentTime: timestamppb.Now()},
+				},
+			}
+			if err := a.send(stream, pong); err != nil {
+				return err
+			}
+
+		case *pb.ServerMessage_Collect:
+			select {
+			case collect <- struct{}{}:
+			default:
+			}
+
+		case *pb.ServerMessage_Resync:
+			// The next payload is a snapshot
+			if a.delta != nil {
+				a.delta.Reset()
+			}
+		}
+	}
+}
+
+// send serializes the messages of the sending loop and of the receive loop
+func (a *virtualAgent) send(stream pb.NodeService_AgentStreamClient, msg *pb.AgentMessage) error {
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+	return stream.Send(msg)
+}
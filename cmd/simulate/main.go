@@ -0,0 +1,142 @@
+// Command simulate runs virtual agents streaming scripted metrics to a server over gRPC, and reports
+// the ingest latency and throughput of the server to size it for a cluster
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	grpcAddr       = flag.String("grpc-server", "localhost:9090", "Server gRPC address, the server must run without TLS and agent tokens")
+	agentCount     = flag.Int("agents", 10, "Number of virtual agents, each one streams the payloads of a node")
+	podRange       = flag.String("pods", "30", "Pods per node, or a range such as 10-80 drawn for every node")
+	patternList    = flag.String("patterns", string(patternSteady), "Comma separated metric patterns assigned to the nodes in turn: steady, spikes, leaks, flapping")
+	interval       = flag.Duration("interval", 10*time.Second, "Interval between two payloads of an agent")
+	samples        = flag.Int("samples", 0, "Node level samples sent in a batch with every payload (disabled if 0)")
+	duration       = flag.Duration("duration", 0, "Duration of the simulation (until interrupted if 0)")
+	rampUp         = flag.Duration("ramp-up", 10*time.Second, "Time over which the agents are started")
+	reportInterval = flag.Duration("report", 10*time.Second, "Interval of the progress reports")
+	prefix         = flag.String("prefix", "sim-node", "Prefix of the node names, nodes are named prefix-1 to prefix-N")
+	seed           = flag.Int64("seed", 1, "Seed of the generated pods and metrics, the same seed replays the same simulation")
+	compression    = flag.String("compression", "", "Compression of the streams to the server: gzip or empty for none")
+	deltaPayloads  = flag.Bool("delta", false, "Send stats as deltas against the previous payload, servers of protocol version 2 rebuild them")
+	deltaSnapshots = flag.Int("delta-snapshot-every", 30, "Payloads between two full snapshots in delta mode")
+)
+
+// parsePods parses a pod count or a min-max range
+func parsePods(value string) (lowest, highest int, err error) {
+	low, high, isRange := strings.Cut(value, "-")
+	if lowest, err = strconv.Atoi(strings.TrimSpace(low)); err != nil {
+		return 0, 0, fmt.Errorf("invalid pod count %q", value)
+	}
+	highest = lowest
+	if isRange {
+		if highest, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+			return 0, 0, fmt.Errorf("invalid pod range %q", value)
+		}
+	}
+	if lowest < 0 || highest < lowest {
+		return 0, 0, fmt.Errorf("invalid pod range %q", value)
+	}
+	return lowest, highest, nil
+}
+
+func main() {
+	flag.Parse()
+
+	if *agentCount <= 0 || *interval <= 0 || *reportInterval <= 0 {
+		slog.Error("agents, interval and report must be positive", "component", "simulate", "agents", *agentCount, "interval", *interval, "report", *reportInterval)
+		os.Exit(1)
+	}
+	minPods, maxPods, err := parsePods(*podRange)
+	if err != nil {
+		slog.Error("invalid pods", "component", "simulate", "error", err)
+		os.Exit(1)
+	}
+	nodePatterns, err := parsePatterns(*patternList)
+	if err != nil {
+		slog.Error("invalid patterns", "component", "simulate", "error", err)
+		os.Exit(1)
+	}
+	if *compression != "" && *compression != "gzip" {
+		slog.Error("invalid compression, expected gzip", "component", "simulate", "compression", *compression)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	options := agentOptions{
+		server:        *grpcAddr,
+		interval:      *interval,
+		samples:       *samples,
+		compression:   *compression,
+		delta:         *deltaPayloads,
+		snapshotEvery: *deltaSnapshots,
+	}
+	recorder := newRecorder()
+
+	slog.Info("starting simulation", "component", "simulate", "server", *grpcAddr, "agents", *agentCount,
+		"pods", *podRange, "patterns", *patternList, "interval", *interval, "duration", *duration)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Agents are started evenly over the ramp-up so that their payloads do not all arrive at once
+		step := *rampUp / time.Duration(*agentCount)
+		rnd := rand.New(rand.NewSource(*seed))
+		for i := 0; i < *agentCount; i++ {
+			nodeSeed := *seed*100000 + int64(i)
+			pods := minPods
+			if maxPods > minPods {
+				pods += rnd.Intn(maxPods - minPods + 1)
+			}
+			node := newVirtualNode(fmt.Sprintf("%s-%d", *prefix, i+1), nodePatterns[i%len(nodePatterns)], pods, nodeSeed)
+			agent := newVirtualAgent(node, options, recorder)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				agent.run(ctx)
+			}()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(step):
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(*reportInterval)
+	defer ticker.Stop()
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-ticker.C:
+			recorder.report()
+		}
+	}
+
+	slog.Info("stopping the virtual agents", "component", "simulate")
+	wg.Wait()
+	recorder.summary(os.Stdout, *agentCount)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	pb "github.com/ThomasCardin/gobservability/proto"
+	sharedGrpc "github.com/ThomasCardin/gobservability/shared/grpc"
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// pattern scripts the cpu and memory usage of a virtual node over time
+type pattern string
+
+const (
+	patternSteady   pattern = "steady"   // Constant usage with noise
+	patternSpikes   pattern = "spikes"   // CPU spikes to 95% for 2 payloads every 12 payloads
+	patternLeaks    pattern = "leaks"    // Memory grows to 95% over 60 payloads then drops as if the pods restarted
+	patternFlapping pattern = "flapping" // CPU and memory cross 80% on every payload
+)
+
+var patterns = []pattern{patternSteady, patternSpikes, patternLeaks, patternFlapping}
+
+// parsePatterns parses a comma separated list of patterns
+func parsePatterns(value string) ([]pattern, error) {
+	var parsed []pattern
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		valid := false
+		for _, p := range patterns {
+			if pattern(name) == p {
+				valid = true
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown pattern %q, expected steady, spikes, leaks or flapping", name)
+		}
+		parsed = append(parsed, pattern(name))
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("no pattern")
+	}
+	return parsed, nil
+}
+
+// load returns the cpu and memory usage between 0 and 1 at tick, ticks are payloads
+func (p pattern) load(tick float64, rnd *rand.Rand) (cpu, memory float64) {
+	noise := func(amplitude float64) float64 {
+		return (rnd.Float64()*2 - 1) * amplitude
+	}
+
+	switch p {
+	case patternSpikes:
+		cpu = 0.2 + noise(0.05)
+		if math.Mod(tick, 12) >= 10 {
+			cpu = 0.95 + noise(0.03)
+		}
+		memory = 0.5 + noise(0.02)
+	case patternLeaks:
+		cpu = 0.3 + noise(0.05)
+		memory = 0.2 + 0.75*math.Mod(tick, 60)/60
+	case patternFlapping:
+		cpu, memory = 0.7, 0.75
+		if int(tick)%2 == 1 {
+			cpu, memory = 0.9, 0.85
+		}
+		cpu += noise(0.03)
+		memory += noise(0.02)
+	default:
+		cpu = 0.3 + noise(0.05)
+		memory = 0.5 + noise(0.02)
+	}
+	return clamp(cpu), clamp(memory)
+}
+
+func clamp(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
+
+// Size of a virtual node
+const (
+	nodeCores       = 8
+	nodeMemoryKB    = 32 * 1024 * 1024
+	jiffiesPerCore  = 100 // Jiffies per second and core
+	networkMBPerSec = 20.0
+	diskMBPerSec    = 10.0
+)
+
+// virtualNode generates the payloads of a simulated node, its counters grow like the counters of
+// a real node
+type virtualNode struct {
+	name    string
+	pattern pattern
+	pods    []*types.Pod
+	rnd     *rand.Rand
+	tick    float64
+
+	cpu     types.CPUStats
+	network types.NetworkStats
+	disk    types.DiskStats
+}
+
+// newVirtualNode creates a node with its pods, the seed makes the node reproducible
+func newVirtualNode(name string, p pattern, pods int, seed int64) *virtualNode {
+	n := &virtualNode{
+		name:    name,
+		pattern: p,
+		rnd:     rand.New(rand.NewSource(seed)),
+	}
+	for i := 0; i < pods; i++ {
+		n.pods = append(n.pods, &types.Pod{
+			Name:        fmt.Sprintf("%s-app-%d-%05x", p, i, n.rnd.Intn(1<<20)),
+			Namespace:   fmt.Sprintf("sim-%d", i%5),
+			UID:         fmt.Sprintf("%08x-0000-4000-8000-%012x", seed, i),
+			IP:          fmt.Sprintf("10.%d.%d.%d", seed%250, i/250, i%250+1),
+			ContainerID: fmt.Sprintf("containerd://%016x%016x", seed, i),
+			PID:         1000 + i,
+			ResourceLimits: types.ResourceInfo{
+				CPU:    "500m",
+				Memory: "512Mi",
+			},
+			ResourceRequests: types.ResourceInfo{
+				CPU:    "100m",
+				Memory: "128Mi",
+			},
+			PidDetails: types.PidDetails{
+				Name:    fmt.Sprintf("app-%d", i),
+				State:   "S",
+				Threads: 8,
+			},
+		})
+	}
+	return n
+}
+
+// advance moves the counters of the node by elapsed at the given usage
+func (n *virtualNode) advance(elapsed time.Duration, cpuLoad, memoryLoad float64) types.NodeSample {
+	seconds := elapsed.Seconds()
+	jiffies := int(seconds * nodeCores * jiffiesPerCore)
+	busy := int(float64(jiffies) * cpuLoad)
+	n.cpu.User += busy * 7 / 10
+	n.cpu.System += busy * 2 / 10
+	n.cpu.SoftIRQ += busy / 10
+	n.cpu.Idle += jiffies - busy
+	n.cpu.Total += jiffies
+	n.cpu.CPUPercent = cpuLoad * 100
+
+	rx := uint64(seconds * networkMBPerSec * cpuLoad * 1024 * 1024)
+	tx := rx / 2
+	n.network.BytesReceived += rx
+	n.network.BytesTransmitted += tx
+	n.network.PacketsReceived += rx / 1500
+	n.network.PacketsTransmitted += tx / 1500
+	n.network.RxRate = float64(rx) / seconds / 1024 / 1024
+	n.network.TxRate = float64(tx) / seconds / 1024 / 1024
+	n.network.TotalRate = n.network.RxRate + n.network.TxRate
+
+	read := uint64(seconds * diskMBPerSec * cpuLoad * 1024 * 1024)
+	written := read / 2
+	n.disk.ReadsCompleted += read / 4096
+	n.disk.SectorsRead += read / 512
+	n.disk.WritesCompleted += written / 4096
+	n.disk.SectorsWritten += written / 512
+	n.disk.ReadRate = float64(read) / seconds / 1024 / 1024
+	n.disk.WriteRate = float64(written) / seconds / 1024 / 1024
+	n.disk.TotalRate = n.disk.ReadRate + n.disk.WriteRate
+
+	cpu := n.cpu
+	network := n.network
+	disk := n.disk
+	available := int(nodeMemoryKB * (1 - memoryLoad))
+	return types.NodeSample{
+		CPU: &cpu,
+		Memory: &types.MemoryStats{
+			MemTotal:      nodeMemoryKB,
+			MemFree:       available / 2,
+			MemAvailable:  available,
+			Cached:        available / 3,
+			MemoryPercent: memoryLoad * 100,
+		},
+		Network: &network,
+		Disk:    &disk,
+	}
+}
+
+// next returns the payload of the next tick with its samples taken every interval / samples
+func (n *virtualNode) next(now time.Time, interval time.Duration, samples int) *pb.NodeStatsRequest {
+	var batch []types.NodeSample
+	step := interval
+	if samples > 0 {
+		step = interval / time.Duration(samples)
+	}
+	for i := 1; i < samples; i++ {
+		cpuLoad, memoryLoad := n.pattern.load(n.tick+float64(i)/float64(samples), n.rnd)
+		sample := n.advance(step, cpuLoad, memoryLoad)
+		sample.Timestamp = now.Add(-interval + time.Duration(i)*step)
+		batch = append(batch, sample)
+	}
+	n.tick++
+	cpuLoad, memoryLoad := n.pattern.load(n.tick, n.rnd)
+	current := n.advance(step, cpuLoad, memoryLoad)
+	if samples > 0 {
+		current.Timestamp = now
+		batch = append(batch, current)
+	}
+
+	metrics := types.NodeMetrics{
+		CPU:     current.CPU,
+		Memory:  current.Memory,
+		Network: current.Network,
+		Disk:    current.Disk,
+		Pods:    n.podMetrics(interval, cpuLoad, memoryLoad),
+	}
+	return &pb.NodeStatsRequest{
+		NodeName:  n.name,
+		Timestamp: timestamppb.New(now),
+		Metrics:   sharedGrpc.ConvertToGRPCMetrics(metrics),
+		Samples:   sharedGrpc.ConvertToGRPCNodeSamples(batch),
+	}
+}
+
+// podMetrics shares the usage of the node between its pods
+func (n *virtualNode) podMetrics(interval time.Duration, cpuLoad, memoryLoad float64) []*types.Pod {
+	if len(n.pods) == 0 {
+		return nil
+	}
+	share := 1 / float64(len(n.pods))
+	for i, pod := range n.pods {
+		weight := 0.5 + float64(i%4)/4 // Pods do not all use the same share
+		cpuPercent := cpuLoad * nodeCores * 100 * share * weight
+		memoryPercent := memoryLoad * 100 * share * weight
+		jiffies := uint64(cpuPercent * interval.Seconds())
+
+		metrics := &pod.PodMetrics
+		metrics.CPU.UTime += jiffies * 8 / 10
+		metrics.CPU.STime += jiffies * 2 / 10
+		metrics.CPU.CPUPercent = cpuPercent
+		metrics.Memory.VmRSS = uint64(memoryPercent / 100 * nodeMemoryKB)
+		metrics.Memory.VmSize = metrics.Memory.VmRSS * 3
+		metrics.Memory.MemPercent = memoryPercent
+		metrics.Network.BytesReceived += jiffies * 1024
+		metrics.Network.BytesTransmitted += jiffies * 512
+		metrics.Disk.ReadBytes += jiffies * 256
+		metrics.Disk.WriteBytes += jiffies * 128
+	}
+	return n.pods
+}
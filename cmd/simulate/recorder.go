@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// counters are the measures of a report window or of the whole simulation
+type counters struct {
+	payloads  uint64
+	bytes     uint64 // Encoded size of the payloads, before compression
+	pods      uint64
+	latencies []time.Duration // Ingestion latency of the payloads, from their sending to the pong
+	errors    uint64          // Streams ended by an error
+}
+
+// recorder aggregates the measures of the virtual agents
+type recorder struct {
+	mu          sync.Mutex
+	window      counters
+	total       counters
+	windowStart time.Time
+	start       time.Time
+
+	connected atomic.Int64 // Agents with an open stream
+}
+
+func newRecorder() *recorder {
+	now := time.Now()
+	return &recorder{start: now, windowStart: now}
+}
+
+// sent counts a payload sent on a stream
+func (r *recorder) sent(bytes, pods int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range []*counters{&r.window, &r.total} {
+		c.payloads++
+		c.bytes += uint64(bytes)
+		c.pods += uint64(pods)
+	}
+}
+
+// ingested records the time the server took to store a payload
+func (r *recorder) ingested(latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.window.latencies = append(r.window.latencies, latency)
+	r.total.latencies = append(r.total.latencies, latency)
+}
+
+// streamError counts a stream ended by an error, the agent reconnects
+func (r *recorder) streamError() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.window.errors++
+	r.total.errors++
+}
+
+// report logs the measures of the window since the previous report
+func (r *recorder) report() {
+	r.mu.Lock()
+	window := r.window
+	elapsed := time.Since(r.windowStart)
+	r.window = counters{}
+	r.windowStart = time.Now()
+	r.mu.Unlock()
+
+	p50, p95, p99, slowest := percentiles(window.latencies)
+	seconds := elapsed.Seconds()
+	slog.Info("simulation progress", "component", "simulate",
+		"agents", r.connected.Load(),
+		"payloads_per_s", fmt.Sprintf("%.1f", float64(window.payloads)/seconds),
+		"pods_per_s", fmt.Sprintf("%.0f", float64(window.pods)/seconds),
+		"mb_per_s", fmt.Sprintf("%.2f", float64(window.bytes)/seconds/1024/1024),
+		"latency_p50", p50, "latency_p95", p95, "latency_p99", p99, "latency_max", slowest,
+		"errors", window.errors)
+}
+
+// summary writes the measures of the whole simulation
+func (r *recorder) summary(w io.Writer, agents int) {
+	r.mu.Lock()
+	total := r.total
+	total.latencies = append([]time.Duration(nil), r.total.latencies...)
+	elapsed := time.Since(r.start)
+	r.mu.Unlock()
+
+	p50, p95, p99, slowest := percentiles(total.latencies)
+	seconds := elapsed.Seconds()
+	unanswered := int64(total.payloads) - int64(len(total.latencies))
+
+	fmt.Fprintf(w, "\nSimulation of %d agents during %s\n", agents, elapsed.Round(time.Second))
+	fmt.Fprintf(w, "  Payloads sent      %d (%.1f/s)\n", total.payloads, float64(total.payloads)/seconds)
+	fmt.Fprintf(w, "  Pods ingested      %d (%.0f/s)\n", total.pods, float64(total.pods)/seconds)
+	fmt.Fprintf(w, "  Volume             %.1f MB (%.2f MB/s, before compression)\n", float64(total.bytes)/1024/1024, float64(total.bytes)/seconds/1024/1024)
+	fmt.Fprintf(w, "  Ingest latency     p50 %s  p95 %s  p99 %s  max %s\n", p50, p95, p99, slowest)
+	fmt.Fprintf(w, "  Unconfirmed        %d payloads (no pong, stream lost or server before protocol 4)\n", max(unanswered, 0))
+	fmt.Fprintf(w, "  Stream errors      %d\n", total.errors)
+}
+
+// percentiles returns the p50, p95, p99 and max of latencies rounded to 0.1ms, 0 if empty
+func percentiles(latencies []time.Duration) (p50, p95, p99, slowest time.Duration) {
+	if len(latencies) == 0 {
+		return 0, 0, 0, 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	at := func(q float64) time.Duration {
+		return latencies[int(q*float64(len(latencies)-1))].Round(100 * time.Microsecond)
+	}
+	return at(0.50), at(0.95), at(0.99), at(1)
+}
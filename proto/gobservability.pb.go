@@ -27,6 +27,11 @@ type NodeStatsRequest struct {
 	NodeName      string                 `protobuf:"bytes,1,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
 	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	Metrics       *NodeMetrics           `protobuf:"bytes,3,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	Backfill      bool                   `protobuf:"varint,4,opt,name=backfill,proto3" json:"backfill,omitempty"`                              // Buffered while the server was unreachable, replayed after reconnecting
+	Sequence      uint64                 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`                              // Live payload sequence in delta mode, 0 otherwise
+	Delta         *NodeMetricsDelta      `protobuf:"bytes,6,opt,name=delta,proto3" json:"delta,omitempty"`                                     // Set instead of metrics, changes since the payload of the previous sequence
+	Samples       []*NodeSample          `protobuf:"bytes,7,rep,name=samples,proto3" json:"samples,omitempty"`                                 // High resolution samples taken since the previous payload
+	CollectionMs  float64                `protobuf:"fixed64,8,opt,name=collection_ms,json=collectionMs,proto3" json:"collection_ms,omitempty"` // Duration of the collection that produced the payload
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -82,6 +87,248 @@ func (x *NodeStatsRequest) GetMetrics() *NodeMetrics {
 	return nil
 }
 
+func (x *NodeStatsRequest) GetBackfill() bool {
+	if x != nil {
+		return x.Backfill
+	}
+	return false
+}
+
+func (x *NodeStatsRequest) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *NodeStatsRequest) GetDelta() *NodeMetricsDelta {
+	if x != nil {
+		return x.Delta
+	}
+	return nil
+}
+
+func (x *NodeStatsRequest) GetSamples() []*NodeSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+func (x *NodeStatsRequest) GetCollectionMs() float64 {
+	if x != nil {
+		return x.CollectionMs
+	}
+	return 0
+}
+
+// Node level metrics of one high resolution sample, batched with the next payload
+type NodeSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Cpu           *CPUStats              `protobuf:"bytes,2,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory        *MemoryStats           `protobuf:"bytes,3,opt,name=memory,proto3" json:"memory,omitempty"`
+	Network       *NetworkStats          `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	Disk          *DiskStats             `protobuf:"bytes,5,opt,name=disk,proto3" json:"disk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeSample) Reset() {
+	*x = NodeSample{}
+	mi := &file_proto_gobservability_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeSample) ProtoMessage() {}
+
+func (x *NodeSample) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeSample.ProtoReflect.Descriptor instead.
+func (*NodeSample) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NodeSample) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *NodeSample) GetCpu() *CPUStats {
+	if x != nil {
+		return x.Cpu
+	}
+	return nil
+}
+
+func (x *NodeSample) GetMemory() *MemoryStats {
+	if x != nil {
+		return x.Memory
+	}
+	return nil
+}
+
+func (x *NodeSample) GetNetwork() *NetworkStats {
+	if x != nil {
+		return x.Network
+	}
+	return nil
+}
+
+func (x *NodeSample) GetDisk() *DiskStats {
+	if x != nil {
+		return x.Disk
+	}
+	return nil
+}
+
+// Changes of the node metrics since the previous payload of the stream, the server rebuilds the
+// full metrics from the last snapshot. Agents send deltas only to servers of protocol version 2
+type NodeMetricsDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changed       *NodeMetrics           `protobuf:"bytes,1,opt,name=changed,proto3" json:"changed,omitempty"`                            // Fields that changed, pods excluded
+	Cleared       []string               `protobuf:"bytes,2,rep,name=cleared,proto3" json:"cleared,omitempty"`                            // Paths of the fields reset to their zero value, e.g. memory.swap_used
+	Pods          []*PodDelta            `protobuf:"bytes,3,rep,name=pods,proto3" json:"pods,omitempty"`                                  // Changed and new pods
+	RemovedPods   []string               `protobuf:"bytes,4,rep,name=removed_pods,json=removedPods,proto3" json:"removed_pods,omitempty"` // Keys of the pods gone
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeMetricsDelta) Reset() {
+	*x = NodeMetricsDelta{}
+	mi := &file_proto_gobservability_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeMetricsDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeMetricsDelta) ProtoMessage() {}
+
+func (x *NodeMetricsDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeMetricsDelta.ProtoReflect.Descriptor instead.
+func (*NodeMetricsDelta) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NodeMetricsDelta) GetChanged() *NodeMetrics {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+func (x *NodeMetricsDelta) GetCleared() []string {
+	if x != nil {
+		return x.Cleared
+	}
+	return nil
+}
+
+func (x *NodeMetricsDelta) GetPods() []*PodDelta {
+	if x != nil {
+		return x.Pods
+	}
+	return nil
+}
+
+func (x *NodeMetricsDelta) GetRemovedPods() []string {
+	if x != nil {
+		return x.RemovedPods
+	}
+	return nil
+}
+
+type PodDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`         // namespace/name/container_id
+	Changed       *Pod                   `protobuf:"bytes,2,opt,name=changed,proto3" json:"changed,omitempty"` // Fields that changed, the whole pod if it is new
+	Cleared       []string               `protobuf:"bytes,3,rep,name=cleared,proto3" json:"cleared,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PodDelta) Reset() {
+	*x = PodDelta{}
+	mi := &file_proto_gobservability_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodDelta) ProtoMessage() {}
+
+func (x *PodDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodDelta.ProtoReflect.Descriptor instead.
+func (*PodDelta) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PodDelta) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PodDelta) GetChanged() *Pod {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+func (x *PodDelta) GetCleared() []string {
+	if x != nil {
+		return x.Cleared
+	}
+	return nil
+}
+
 type StatsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
@@ -91,7 +338,7 @@ type StatsResponse struct {
 
 func (x *StatsResponse) Reset() {
 	*x = StatsResponse{}
-	mi := &file_proto_gobservability_proto_msgTypes[1]
+	mi := &file_proto_gobservability_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -103,7 +350,7 @@ func (x *StatsResponse) String() string {
 func (*StatsResponse) ProtoMessage() {}
 
 func (x *StatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[1]
+	mi := &file_proto_gobservability_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -116,7 +363,7 @@ func (x *StatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
 func (*StatsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{1}
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *StatsResponse) GetStatus() string {
@@ -140,7 +387,7 @@ type FlamegraphRequest struct {
 
 func (x *FlamegraphRequest) Reset() {
 	*x = FlamegraphRequest{}
-	mi := &file_proto_gobservability_proto_msgTypes[2]
+	mi := &file_proto_gobservability_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -152,7 +399,7 @@ func (x *FlamegraphRequest) String() string {
 func (*FlamegraphRequest) ProtoMessage() {}
 
 func (x *FlamegraphRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[2]
+	mi := &file_proto_gobservability_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -165,7 +412,7 @@ func (x *FlamegraphRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FlamegraphRequest.ProtoReflect.Descriptor instead.
 func (*FlamegraphRequest) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{2}
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *FlamegraphRequest) GetNodeName() string {
@@ -216,7 +463,7 @@ type FlamegraphResponse struct {
 
 func (x *FlamegraphResponse) Reset() {
 	*x = FlamegraphResponse{}
-	mi := &file_proto_gobservability_proto_msgTypes[3]
+	mi := &file_proto_gobservability_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -228,7 +475,7 @@ func (x *FlamegraphResponse) String() string {
 func (*FlamegraphResponse) ProtoMessage() {}
 
 func (x *FlamegraphResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[3]
+	mi := &file_proto_gobservability_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -241,7 +488,7 @@ func (x *FlamegraphResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FlamegraphResponse.ProtoReflect.Descriptor instead.
 func (*FlamegraphResponse) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{3}
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *FlamegraphResponse) GetFlamegraphData() []byte {
@@ -280,13 +527,19 @@ type NodeMetrics struct {
 	Network       *NetworkStats          `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
 	Disk          *DiskStats             `protobuf:"bytes,4,opt,name=disk,proto3" json:"disk,omitempty"`
 	Pods          []*Pod                 `protobuf:"bytes,5,rep,name=pods,proto3" json:"pods,omitempty"`
+	LogPatterns   []*LogPatternCount     `protobuf:"bytes,6,rep,name=log_patterns,json=logPatterns,proto3" json:"log_patterns,omitempty"`       // Match counts of log pattern alert rules
+	CustomMetrics []*CustomMetrics       `protobuf:"bytes,7,rep,name=custom_metrics,json=customMetrics,proto3" json:"custom_metrics,omitempty"` // Textfile and exec custom collectors
+	Pushed        []*PushedMetrics       `protobuf:"bytes,8,rep,name=pushed,proto3" json:"pushed,omitempty"`                                    // StatsD and OTLP metrics received by the agent
+	Collectors    []*CollectorStatus     `protobuf:"bytes,9,rep,name=collectors,proto3" json:"collectors,omitempty"`                            // Status of the agent collectors
+	Buffer        *BufferStatus          `protobuf:"bytes,10,opt,name=buffer,proto3" json:"buffer,omitempty"`                                   // Offline buffer of the agent
+	Connection    *ConnectionStatus      `protobuf:"bytes,11,opt,name=connection,proto3" json:"connection,omitempty"`                           // Connection of the agent to the server
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NodeMetrics) Reset() {
 	*x = NodeMetrics{}
-	mi := &file_proto_gobservability_proto_msgTypes[4]
+	mi := &file_proto_gobservability_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -298,7 +551,7 @@ func (x *NodeMetrics) String() string {
 func (*NodeMetrics) ProtoMessage() {}
 
 func (x *NodeMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[4]
+	mi := &file_proto_gobservability_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -311,7 +564,7 @@ func (x *NodeMetrics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeMetrics.ProtoReflect.Descriptor instead.
 func (*NodeMetrics) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{4}
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *NodeMetrics) GetCpu() *CPUStats {
@@ -349,154 +602,180 @@ func (x *NodeMetrics) GetPods() []*Pod {
 	return nil
 }
 
-type CPUStats struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	User          int64                  `protobuf:"varint,1,opt,name=user,proto3" json:"user,omitempty"`
-	Nice          int64                  `protobuf:"varint,2,opt,name=nice,proto3" json:"nice,omitempty"`
-	System        int64                  `protobuf:"varint,3,opt,name=system,proto3" json:"system,omitempty"`
-	Idle          int64                  `protobuf:"varint,4,opt,name=idle,proto3" json:"idle,omitempty"`
-	Iowait        int64                  `protobuf:"varint,5,opt,name=iowait,proto3" json:"iowait,omitempty"`
-	Irq           int64                  `protobuf:"varint,6,opt,name=irq,proto3" json:"irq,omitempty"`
-	Softirq       int64                  `protobuf:"varint,7,opt,name=softirq,proto3" json:"softirq,omitempty"`
-	Steal         int64                  `protobuf:"varint,8,opt,name=steal,proto3" json:"steal,omitempty"`
-	Total         int64                  `protobuf:"varint,9,opt,name=total,proto3" json:"total,omitempty"`
-	CpuPercent    float64                `protobuf:"fixed64,10,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *CPUStats) Reset() {
-	*x = CPUStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[5]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *CPUStats) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*CPUStats) ProtoMessage() {}
-
-func (x *CPUStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[5]
+func (x *NodeMetrics) GetLogPatterns() []*LogPatternCount {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.LogPatterns
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use CPUStats.ProtoReflect.Descriptor instead.
-func (*CPUStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{5}
+func (x *NodeMetrics) GetCustomMetrics() []*CustomMetrics {
+	if x != nil {
+		return x.CustomMetrics
+	}
+	return nil
 }
 
-func (x *CPUStats) GetUser() int64 {
+func (x *NodeMetrics) GetPushed() []*PushedMetrics {
 	if x != nil {
-		return x.User
+		return x.Pushed
 	}
-	return 0
+	return nil
 }
 
-func (x *CPUStats) GetNice() int64 {
+func (x *NodeMetrics) GetCollectors() []*CollectorStatus {
 	if x != nil {
-		return x.Nice
+		return x.Collectors
 	}
-	return 0
+	return nil
 }
 
-func (x *CPUStats) GetSystem() int64 {
+func (x *NodeMetrics) GetBuffer() *BufferStatus {
 	if x != nil {
-		return x.System
+		return x.Buffer
 	}
-	return 0
+	return nil
 }
 
-func (x *CPUStats) GetIdle() int64 {
+func (x *NodeMetrics) GetConnection() *ConnectionStatus {
 	if x != nil {
-		return x.Idle
+		return x.Connection
 	}
-	return 0
+	return nil
 }
 
-func (x *CPUStats) GetIowait() int64 {
+// Connection of the agent to the server
+type ConnectionStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Endpoint       string                 `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`      // Server endpoint in use
+	Connected      bool                   `protobuf:"varint,2,opt,name=connected,proto3" json:"connected,omitempty"`   // False for stats buffered while disconnected
+	Reconnects     uint64                 `protobuf:"varint,3,opt,name=reconnects,proto3" json:"reconnects,omitempty"` // Connections re-established since the agent started
+	LastError      string                 `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastErrorAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_error_at,json=lastErrorAt,proto3" json:"last_error_at,omitempty"`
+	ConnectedSince *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=connected_since,json=connectedSince,proto3" json:"connected_since,omitempty"`
+	LastOutageMs   int64                  `protobuf:"varint,7,opt,name=last_outage_ms,json=lastOutageMs,proto3" json:"last_outage_ms,omitempty"`     // Duration of the last disconnection
+	DisconnectedMs int64                  `protobuf:"varint,8,opt,name=disconnected_ms,json=disconnectedMs,proto3" json:"disconnected_ms,omitempty"` // Time disconnected since the agent started
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ConnectionStatus) Reset() {
+	*x = ConnectionStatus{}
+	mi := &file_proto_gobservability_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectionStatus) ProtoMessage() {}
+
+func (x *ConnectionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[8]
 	if x != nil {
-		return x.Iowait
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *CPUStats) GetIrq() int64 {
+// Deprecated: Use ConnectionStatus.ProtoReflect.Descriptor instead.
+func (*ConnectionStatus) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ConnectionStatus) GetEndpoint() string {
 	if x != nil {
-		return x.Irq
+		return x.Endpoint
 	}
-	return 0
+	return ""
 }
 
-func (x *CPUStats) GetSoftirq() int64 {
+func (x *ConnectionStatus) GetConnected() bool {
 	if x != nil {
-		return x.Softirq
+		return x.Connected
 	}
-	return 0
+	return false
 }
 
-func (x *CPUStats) GetSteal() int64 {
+func (x *ConnectionStatus) GetReconnects() uint64 {
 	if x != nil {
-		return x.Steal
+		return x.Reconnects
 	}
 	return 0
 }
 
-func (x *CPUStats) GetTotal() int64 {
+func (x *ConnectionStatus) GetLastError() string {
 	if x != nil {
-		return x.Total
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *ConnectionStatus) GetLastErrorAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastErrorAt
+	}
+	return nil
+}
+
+func (x *ConnectionStatus) GetConnectedSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ConnectedSince
+	}
+	return nil
+}
+
+func (x *ConnectionStatus) GetLastOutageMs() int64 {
+	if x != nil {
+		return x.LastOutageMs
 	}
 	return 0
 }
 
-func (x *CPUStats) GetCpuPercent() float64 {
+func (x *ConnectionStatus) GetDisconnectedMs() int64 {
 	if x != nil {
-		return x.CpuPercent
+		return x.DisconnectedMs
 	}
 	return 0
 }
 
-type MemoryStats struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Raw values from /proc/meminfo - exactly like types.MemoryStats
-	MemTotal     int64 `protobuf:"varint,1,opt,name=mem_total,json=memTotal,proto3" json:"mem_total,omitempty"`
-	MemFree      int64 `protobuf:"varint,2,opt,name=mem_free,json=memFree,proto3" json:"mem_free,omitempty"`
-	MemAvailable int64 `protobuf:"varint,3,opt,name=mem_available,json=memAvailable,proto3" json:"mem_available,omitempty"`
-	Buffers      int64 `protobuf:"varint,4,opt,name=buffers,proto3" json:"buffers,omitempty"`
-	Cached       int64 `protobuf:"varint,5,opt,name=cached,proto3" json:"cached,omitempty"`
-	SwapCached   int64 `protobuf:"varint,6,opt,name=swap_cached,json=swapCached,proto3" json:"swap_cached,omitempty"`
-	SwapTotal    int64 `protobuf:"varint,7,opt,name=swap_total,json=swapTotal,proto3" json:"swap_total,omitempty"`
-	SwapFree     int64 `protobuf:"varint,8,opt,name=swap_free,json=swapFree,proto3" json:"swap_free,omitempty"`
-	// Calculated values by agent
-	MemoryPercent float64 `protobuf:"fixed64,9,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"` // Memory usage percentage
+// Payloads buffered by the agent while the server is unreachable
+type BufferStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payloads      int32                  `protobuf:"varint,1,opt,name=payloads,proto3" json:"payloads,omitempty"`                    // Payloads waiting to be replayed (memory and disk)
+	Bytes         int64                  `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`                          // Size of the payloads kept in memory
+	DiskBytes     int64                  `protobuf:"varint,3,opt,name=disk_bytes,json=diskBytes,proto3" json:"disk_bytes,omitempty"` // Size of the write-ahead log
+	MaxPayloads   int32                  `protobuf:"varint,4,opt,name=max_payloads,json=maxPayloads,proto3" json:"max_payloads,omitempty"`
+	MaxBytes      int64                  `protobuf:"varint,5,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MaxDiskBytes  int64                  `protobuf:"varint,6,opt,name=max_disk_bytes,json=maxDiskBytes,proto3" json:"max_disk_bytes,omitempty"` // 0 if payloads are only kept in memory
+	Dropped       uint64                 `protobuf:"varint,7,opt,name=dropped,proto3" json:"dropped,omitempty"`                                 // Payloads dropped because a limit was reached
+	Spilled       uint64                 `protobuf:"varint,8,opt,name=spilled,proto3" json:"spilled,omitempty"`                                 // Payloads moved from memory to the write-ahead log
+	Replayed      uint64                 `protobuf:"varint,9,opt,name=replayed,proto3" json:"replayed,omitempty"`                               // Payloads replayed after reconnecting
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MemoryStats) Reset() {
-	*x = MemoryStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[6]
+func (x *BufferStatus) Reset() {
+	*x = BufferStatus{}
+	mi := &file_proto_gobservability_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MemoryStats) String() string {
+func (x *BufferStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MemoryStats) ProtoMessage() {}
+func (*BufferStatus) ProtoMessage() {}
 
-func (x *MemoryStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[6]
+func (x *BufferStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -507,106 +786,103 @@ func (x *MemoryStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MemoryStats.ProtoReflect.Descriptor instead.
-func (*MemoryStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use BufferStatus.ProtoReflect.Descriptor instead.
+func (*BufferStatus) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *MemoryStats) GetMemTotal() int64 {
+func (x *BufferStatus) GetPayloads() int32 {
 	if x != nil {
-		return x.MemTotal
+		return x.Payloads
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetMemFree() int64 {
+func (x *BufferStatus) GetBytes() int64 {
 	if x != nil {
-		return x.MemFree
+		return x.Bytes
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetMemAvailable() int64 {
+func (x *BufferStatus) GetDiskBytes() int64 {
 	if x != nil {
-		return x.MemAvailable
+		return x.DiskBytes
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetBuffers() int64 {
+func (x *BufferStatus) GetMaxPayloads() int32 {
 	if x != nil {
-		return x.Buffers
+		return x.MaxPayloads
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetCached() int64 {
+func (x *BufferStatus) GetMaxBytes() int64 {
 	if x != nil {
-		return x.Cached
+		return x.MaxBytes
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetSwapCached() int64 {
+func (x *BufferStatus) GetMaxDiskBytes() int64 {
 	if x != nil {
-		return x.SwapCached
+		return x.MaxDiskBytes
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetSwapTotal() int64 {
+func (x *BufferStatus) GetDropped() uint64 {
 	if x != nil {
-		return x.SwapTotal
+		return x.Dropped
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetSwapFree() int64 {
+func (x *BufferStatus) GetSpilled() uint64 {
 	if x != nil {
-		return x.SwapFree
+		return x.Spilled
 	}
 	return 0
 }
 
-func (x *MemoryStats) GetMemoryPercent() float64 {
+func (x *BufferStatus) GetReplayed() uint64 {
 	if x != nil {
-		return x.MemoryPercent
+		return x.Replayed
 	}
 	return 0
 }
 
-type NetworkStats struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Raw values from /proc/net/dev - exactly like types.NetworkStats
-	BytesReceived      uint64 `protobuf:"varint,1,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
-	BytesTransmitted   uint64 `protobuf:"varint,2,opt,name=bytes_transmitted,json=bytesTransmitted,proto3" json:"bytes_transmitted,omitempty"`
-	PacketsReceived    uint64 `protobuf:"varint,3,opt,name=packets_received,json=packetsReceived,proto3" json:"packets_received,omitempty"`
-	PacketsTransmitted uint64 `protobuf:"varint,4,opt,name=packets_transmitted,json=packetsTransmitted,proto3" json:"packets_transmitted,omitempty"`
-	ErrorsReceived     uint64 `protobuf:"varint,5,opt,name=errors_received,json=errorsReceived,proto3" json:"errors_received,omitempty"`
-	ErrorsTransmitted  uint64 `protobuf:"varint,6,opt,name=errors_transmitted,json=errorsTransmitted,proto3" json:"errors_transmitted,omitempty"`
-	// Calculated rates by agent (MB/s)
-	RxRate        float64 `protobuf:"fixed64,7,opt,name=rx_rate,json=rxRate,proto3" json:"rx_rate,omitempty"`          // Receive rate in MB/s
-	TxRate        float64 `protobuf:"fixed64,8,opt,name=tx_rate,json=txRate,proto3" json:"tx_rate,omitempty"`          // Transmit rate in MB/s
-	TotalRate     float64 `protobuf:"fixed64,9,opt,name=total_rate,json=totalRate,proto3" json:"total_rate,omitempty"` // Total rate in MB/s
+// Configuration and last run of an agent collector
+type CollectorStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	IntervalMs    int64                  `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	LastRun       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"`
+	DurationMs    float64                `protobuf:"fixed64,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"` // Error of the last run (the previous result is kept)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkStats) Reset() {
-	*x = NetworkStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[7]
+func (x *CollectorStatus) Reset() {
+	*x = CollectorStatus{}
+	mi := &file_proto_gobservability_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkStats) String() string {
+func (x *CollectorStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkStats) ProtoMessage() {}
+func (*CollectorStatus) ProtoMessage() {}
 
-func (x *NetworkStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[7]
+func (x *CollectorStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -617,108 +893,91 @@ func (x *NetworkStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkStats.ProtoReflect.Descriptor instead.
-func (*NetworkStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *NetworkStats) GetBytesReceived() uint64 {
-	if x != nil {
-		return x.BytesReceived
-	}
-	return 0
-}
-
-func (x *NetworkStats) GetBytesTransmitted() uint64 {
-	if x != nil {
-		return x.BytesTransmitted
-	}
-	return 0
+// Deprecated: Use CollectorStatus.ProtoReflect.Descriptor instead.
+func (*CollectorStatus) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *NetworkStats) GetPacketsReceived() uint64 {
+func (x *CollectorStatus) GetName() string {
 	if x != nil {
-		return x.PacketsReceived
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-func (x *NetworkStats) GetPacketsTransmitted() uint64 {
+func (x *CollectorStatus) GetEnabled() bool {
 	if x != nil {
-		return x.PacketsTransmitted
+		return x.Enabled
 	}
-	return 0
+	return false
 }
 
-func (x *NetworkStats) GetErrorsReceived() uint64 {
+func (x *CollectorStatus) GetIntervalMs() int64 {
 	if x != nil {
-		return x.ErrorsReceived
+		return x.IntervalMs
 	}
 	return 0
 }
 
-func (x *NetworkStats) GetErrorsTransmitted() uint64 {
+func (x *CollectorStatus) GetCapabilities() []string {
 	if x != nil {
-		return x.ErrorsTransmitted
+		return x.Capabilities
 	}
-	return 0
+	return nil
 }
 
-func (x *NetworkStats) GetRxRate() float64 {
+func (x *CollectorStatus) GetLastRun() *timestamppb.Timestamp {
 	if x != nil {
-		return x.RxRate
+		return x.LastRun
 	}
-	return 0
+	return nil
 }
 
-func (x *NetworkStats) GetTxRate() float64 {
+func (x *CollectorStatus) GetDurationMs() float64 {
 	if x != nil {
-		return x.TxRate
+		return x.DurationMs
 	}
 	return 0
 }
 
-func (x *NetworkStats) GetTotalRate() float64 {
+func (x *CollectorStatus) GetError() string {
 	if x != nil {
-		return x.TotalRate
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-type DiskStats struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Raw values from /proc/diskstats - exactly like types.DiskStats
-	ReadsCompleted  uint64 `protobuf:"varint,1,opt,name=reads_completed,json=readsCompleted,proto3" json:"reads_completed,omitempty"`
-	ReadsMerged     uint64 `protobuf:"varint,2,opt,name=reads_merged,json=readsMerged,proto3" json:"reads_merged,omitempty"`
-	SectorsRead     uint64 `protobuf:"varint,3,opt,name=sectors_read,json=sectorsRead,proto3" json:"sectors_read,omitempty"`
-	TimeReading     uint64 `protobuf:"varint,4,opt,name=time_reading,json=timeReading,proto3" json:"time_reading,omitempty"`
-	WritesCompleted uint64 `protobuf:"varint,5,opt,name=writes_completed,json=writesCompleted,proto3" json:"writes_completed,omitempty"`
-	WritesMerged    uint64 `protobuf:"varint,6,opt,name=writes_merged,json=writesMerged,proto3" json:"writes_merged,omitempty"`
-	SectorsWritten  uint64 `protobuf:"varint,7,opt,name=sectors_written,json=sectorsWritten,proto3" json:"sectors_written,omitempty"`
-	TimeWriting     uint64 `protobuf:"varint,8,opt,name=time_writing,json=timeWriting,proto3" json:"time_writing,omitempty"`
-	// Calculated rates by agent (MB/s)
-	ReadRate      float64 `protobuf:"fixed64,9,opt,name=read_rate,json=readRate,proto3" json:"read_rate,omitempty"`     // Read rate in MB/s
-	WriteRate     float64 `protobuf:"fixed64,10,opt,name=write_rate,json=writeRate,proto3" json:"write_rate,omitempty"` // Write rate in MB/s
-	TotalRate     float64 `protobuf:"fixed64,11,opt,name=total_rate,json=totalRate,proto3" json:"total_rate,omitempty"` // Total I/O rate in MB/s
+type CPUStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          int64                  `protobuf:"varint,1,opt,name=user,proto3" json:"user,omitempty"`
+	Nice          int64                  `protobuf:"varint,2,opt,name=nice,proto3" json:"nice,omitempty"`
+	System        int64                  `protobuf:"varint,3,opt,name=system,proto3" json:"system,omitempty"`
+	Idle          int64                  `protobuf:"varint,4,opt,name=idle,proto3" json:"idle,omitempty"`
+	Iowait        int64                  `protobuf:"varint,5,opt,name=iowait,proto3" json:"iowait,omitempty"`
+	Irq           int64                  `protobuf:"varint,6,opt,name=irq,proto3" json:"irq,omitempty"`
+	Softirq       int64                  `protobuf:"varint,7,opt,name=softirq,proto3" json:"softirq,omitempty"`
+	Steal         int64                  `protobuf:"varint,8,opt,name=steal,proto3" json:"steal,omitempty"`
+	Total         int64                  `protobuf:"varint,9,opt,name=total,proto3" json:"total,omitempty"`
+	CpuPercent    float64                `protobuf:"fixed64,10,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiskStats) Reset() {
-	*x = DiskStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[8]
+func (x *CPUStats) Reset() {
+	*x = CPUStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiskStats) String() string {
+func (x *CPUStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiskStats) ProtoMessage() {}
+func (*CPUStats) ProtoMessage() {}
 
-func (x *DiskStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[8]
+func (x *CPUStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -729,116 +988,459 @@ func (x *DiskStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiskStats.ProtoReflect.Descriptor instead.
-func (*DiskStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use CPUStats.ProtoReflect.Descriptor instead.
+func (*CPUStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *DiskStats) GetReadsCompleted() uint64 {
+func (x *CPUStats) GetUser() int64 {
 	if x != nil {
-		return x.ReadsCompleted
+		return x.User
 	}
 	return 0
 }
 
-func (x *DiskStats) GetReadsMerged() uint64 {
+func (x *CPUStats) GetNice() int64 {
 	if x != nil {
-		return x.ReadsMerged
+		return x.Nice
 	}
 	return 0
 }
 
-func (x *DiskStats) GetSectorsRead() uint64 {
+func (x *CPUStats) GetSystem() int64 {
 	if x != nil {
-		return x.SectorsRead
+		return x.System
 	}
 	return 0
 }
 
-func (x *DiskStats) GetTimeReading() uint64 {
+func (x *CPUStats) GetIdle() int64 {
 	if x != nil {
-		return x.TimeReading
+		return x.Idle
 	}
 	return 0
 }
 
-func (x *DiskStats) GetWritesCompleted() uint64 {
+func (x *CPUStats) GetIowait() int64 {
 	if x != nil {
-		return x.WritesCompleted
+		return x.Iowait
 	}
 	return 0
 }
 
-func (x *DiskStats) GetWritesMerged() uint64 {
+func (x *CPUStats) GetIrq() int64 {
 	if x != nil {
-		return x.WritesMerged
+		return x.Irq
 	}
 	return 0
 }
 
-func (x *DiskStats) GetSectorsWritten() uint64 {
+func (x *CPUStats) GetSoftirq() int64 {
 	if x != nil {
-		return x.SectorsWritten
+		return x.Softirq
 	}
 	return 0
 }
 
-func (x *DiskStats) GetTimeWriting() uint64 {
+func (x *CPUStats) GetSteal() int64 {
 	if x != nil {
-		return x.TimeWriting
+		return x.Steal
 	}
 	return 0
 }
 
-func (x *DiskStats) GetReadRate() float64 {
+func (x *CPUStats) GetTotal() int64 {
 	if x != nil {
-		return x.ReadRate
+		return x.Total
 	}
 	return 0
 }
 
-func (x *DiskStats) GetWriteRate() float64 {
+func (x *CPUStats) GetCpuPercent() float64 {
 	if x != nil {
-		return x.WriteRate
+		return x.CpuPercent
 	}
 	return 0
 }
 
-func (x *DiskStats) GetTotalRate() float64 {
+type MemoryStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raw values from /proc/meminfo - exactly like types.MemoryStats
+	MemTotal     int64 `protobuf:"varint,1,opt,name=mem_total,json=memTotal,proto3" json:"mem_total,omitempty"`
+	MemFree      int64 `protobuf:"varint,2,opt,name=mem_free,json=memFree,proto3" json:"mem_free,omitempty"`
+	MemAvailable int64 `protobuf:"varint,3,opt,name=mem_available,json=memAvailable,proto3" json:"mem_available,omitempty"`
+	Buffers      int64 `protobuf:"varint,4,opt,name=buffers,proto3" json:"buffers,omitempty"`
+	Cached       int64 `protobuf:"varint,5,opt,name=cached,proto3" json:"cached,omitempty"`
+	SwapCached   int64 `protobuf:"varint,6,opt,name=swap_cached,json=swapCached,proto3" json:"swap_cached,omitempty"`
+	SwapTotal    int64 `protobuf:"varint,7,opt,name=swap_total,json=swapTotal,proto3" json:"swap_total,omitempty"`
+	SwapFree     int64 `protobuf:"varint,8,opt,name=swap_free,json=swapFree,proto3" json:"swap_free,omitempty"`
+	// Calculated values by agent
+	MemoryPercent float64 `protobuf:"fixed64,9,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"` // Memory usage percentage
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemoryStats) Reset() {
+	*x = MemoryStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryStats) ProtoMessage() {}
+
+func (x *MemoryStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryStats.ProtoReflect.Descriptor instead.
+func (*MemoryStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MemoryStats) GetMemTotal() int64 {
+	if x != nil {
+		return x.MemTotal
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetMemFree() int64 {
+	if x != nil {
+		return x.MemFree
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetMemAvailable() int64 {
+	if x != nil {
+		return x.MemAvailable
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetBuffers() int64 {
+	if x != nil {
+		return x.Buffers
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetCached() int64 {
+	if x != nil {
+		return x.Cached
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetSwapCached() int64 {
+	if x != nil {
+		return x.SwapCached
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetSwapTotal() int64 {
+	if x != nil {
+		return x.SwapTotal
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetSwapFree() int64 {
+	if x != nil {
+		return x.SwapFree
+	}
+	return 0
+}
+
+func (x *MemoryStats) GetMemoryPercent() float64 {
+	if x != nil {
+		return x.MemoryPercent
+	}
+	return 0
+}
+
+type NetworkStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raw values from /proc/net/dev - exactly like types.NetworkStats
+	BytesReceived      uint64 `protobuf:"varint,1,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	BytesTransmitted   uint64 `protobuf:"varint,2,opt,name=bytes_transmitted,json=bytesTransmitted,proto3" json:"bytes_transmitted,omitempty"`
+	PacketsReceived    uint64 `protobuf:"varint,3,opt,name=packets_received,json=packetsReceived,proto3" json:"packets_received,omitempty"`
+	PacketsTransmitted uint64 `protobuf:"varint,4,opt,name=packets_transmitted,json=packetsTransmitted,proto3" json:"packets_transmitted,omitempty"`
+	ErrorsReceived     uint64 `protobuf:"varint,5,opt,name=errors_received,json=errorsReceived,proto3" json:"errors_received,omitempty"`
+	ErrorsTransmitted  uint64 `protobuf:"varint,6,opt,name=errors_transmitted,json=errorsTransmitted,proto3" json:"errors_transmitted,omitempty"`
+	// Calculated rates by agent (MB/s)
+	RxRate        float64 `protobuf:"fixed64,7,opt,name=rx_rate,json=rxRate,proto3" json:"rx_rate,omitempty"`          // Receive rate in MB/s
+	TxRate        float64 `protobuf:"fixed64,8,opt,name=tx_rate,json=txRate,proto3" json:"tx_rate,omitempty"`          // Transmit rate in MB/s
+	TotalRate     float64 `protobuf:"fixed64,9,opt,name=total_rate,json=totalRate,proto3" json:"total_rate,omitempty"` // Total rate in MB/s
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkStats) Reset() {
+	*x = NetworkStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkStats) ProtoMessage() {}
+
+func (x *NetworkStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkStats.ProtoReflect.Descriptor instead.
+func (*NetworkStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *NetworkStats) GetBytesReceived() uint64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetBytesTransmitted() uint64 {
+	if x != nil {
+		return x.BytesTransmitted
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetPacketsReceived() uint64 {
+	if x != nil {
+		return x.PacketsReceived
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetPacketsTransmitted() uint64 {
+	if x != nil {
+		return x.PacketsTransmitted
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetErrorsReceived() uint64 {
+	if x != nil {
+		return x.ErrorsReceived
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetErrorsTransmitted() uint64 {
+	if x != nil {
+		return x.ErrorsTransmitted
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetRxRate() float64 {
+	if x != nil {
+		return x.RxRate
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetTxRate() float64 {
+	if x != nil {
+		return x.TxRate
+	}
+	return 0
+}
+
+func (x *NetworkStats) GetTotalRate() float64 {
 	if x != nil {
 		return x.TotalRate
 	}
 	return 0
 }
 
-type Pod struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	ContainerId      string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	Pid              int64                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
-	PodMetrics       *PodMetrics            `protobuf:"bytes,4,opt,name=pod_metrics,json=podMetrics,proto3" json:"pod_metrics,omitempty"`
-	PidDetails       *PidDetails            `protobuf:"bytes,5,opt,name=pid_details,json=pidDetails,proto3" json:"pid_details,omitempty"`
-	ResourceLimits   *ResourceInfo          `protobuf:"bytes,6,opt,name=resource_limits,json=resourceLimits,proto3" json:"resource_limits,omitempty"`
-	ResourceRequests *ResourceInfo          `protobuf:"bytes,7,opt,name=resource_requests,json=resourceRequests,proto3" json:"resource_requests,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+type DiskStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raw values from /proc/diskstats - exactly like types.DiskStats
+	ReadsCompleted  uint64 `protobuf:"varint,1,opt,name=reads_completed,json=readsCompleted,proto3" json:"reads_completed,omitempty"`
+	ReadsMerged     uint64 `protobuf:"varint,2,opt,name=reads_merged,json=readsMerged,proto3" json:"reads_merged,omitempty"`
+	SectorsRead     uint64 `protobuf:"varint,3,opt,name=sectors_read,json=sectorsRead,proto3" json:"sectors_read,omitempty"`
+	TimeReading     uint64 `protobuf:"varint,4,opt,name=time_reading,json=timeReading,proto3" json:"time_reading,omitempty"`
+	WritesCompleted uint64 `protobuf:"varint,5,opt,name=writes_completed,json=writesCompleted,proto3" json:"writes_completed,omitempty"`
+	WritesMerged    uint64 `protobuf:"varint,6,opt,name=writes_merged,json=writesMerged,proto3" json:"writes_merged,omitempty"`
+	SectorsWritten  uint64 `protobuf:"varint,7,opt,name=sectors_written,json=sectorsWritten,proto3" json:"sectors_written,omitempty"`
+	TimeWriting     uint64 `protobuf:"varint,8,opt,name=time_writing,json=timeWriting,proto3" json:"time_writing,omitempty"`
+	// Calculated rates by agent (MB/s)
+	ReadRate      float64 `protobuf:"fixed64,9,opt,name=read_rate,json=readRate,proto3" json:"read_rate,omitempty"`     // Read rate in MB/s
+	WriteRate     float64 `protobuf:"fixed64,10,opt,name=write_rate,json=writeRate,proto3" json:"write_rate,omitempty"` // Write rate in MB/s
+	TotalRate     float64 `protobuf:"fixed64,11,opt,name=total_rate,json=totalRate,proto3" json:"total_rate,omitempty"` // Total I/O rate in MB/s
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Pod) Reset() {
-	*x = Pod{}
-	mi := &file_proto_gobservability_proto_msgTypes[9]
+func (x *DiskStats) Reset() {
+	*x = DiskStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Pod) String() string {
+func (x *DiskStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Pod) ProtoMessage() {}
+func (*DiskStats) ProtoMessage() {}
 
-func (x *Pod) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[9]
+func (x *DiskStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskStats.ProtoReflect.Descriptor instead.
+func (*DiskStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DiskStats) GetReadsCompleted() uint64 {
+	if x != nil {
+		return x.ReadsCompleted
+	}
+	return 0
+}
+
+func (x *DiskStats) GetReadsMerged() uint64 {
+	if x != nil {
+		return x.ReadsMerged
+	}
+	return 0
+}
+
+func (x *DiskStats) GetSectorsRead() uint64 {
+	if x != nil {
+		return x.SectorsRead
+	}
+	return 0
+}
+
+func (x *DiskStats) GetTimeReading() uint64 {
+	if x != nil {
+		return x.TimeReading
+	}
+	return 0
+}
+
+func (x *DiskStats) GetWritesCompleted() uint64 {
+	if x != nil {
+		return x.WritesCompleted
+	}
+	return 0
+}
+
+func (x *DiskStats) GetWritesMerged() uint64 {
+	if x != nil {
+		return x.WritesMerged
+	}
+	return 0
+}
+
+func (x *DiskStats) GetSectorsWritten() uint64 {
+	if x != nil {
+		return x.SectorsWritten
+	}
+	return 0
+}
+
+func (x *DiskStats) GetTimeWriting() uint64 {
+	if x != nil {
+		return x.TimeWriting
+	}
+	return 0
+}
+
+func (x *DiskStats) GetReadRate() float64 {
+	if x != nil {
+		return x.ReadRate
+	}
+	return 0
+}
+
+func (x *DiskStats) GetWriteRate() float64 {
+	if x != nil {
+		return x.WriteRate
+	}
+	return 0
+}
+
+func (x *DiskStats) GetTotalRate() float64 {
+	if x != nil {
+		return x.TotalRate
+	}
+	return 0
+}
+
+type Pod struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ContainerId      string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Pid              int64                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	PodMetrics       *PodMetrics            `protobuf:"bytes,4,opt,name=pod_metrics,json=podMetrics,proto3" json:"pod_metrics,omitempty"`
+	PidDetails       *PidDetails            `protobuf:"bytes,5,opt,name=pid_details,json=pidDetails,proto3" json:"pid_details,omitempty"`
+	ResourceLimits   *ResourceInfo          `protobuf:"bytes,6,opt,name=resource_limits,json=resourceLimits,proto3" json:"resource_limits,omitempty"`
+	ResourceRequests *ResourceInfo          `protobuf:"bytes,7,opt,name=resource_requests,json=resourceRequests,proto3" json:"resource_requests,omitempty"`
+	Namespace        string                 `protobuf:"bytes,8,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Uid              string                 `protobuf:"bytes,9,opt,name=uid,proto3" json:"uid,omitempty"`
+	Ip               string                 `protobuf:"bytes,10,opt,name=ip,proto3" json:"ip,omitempty"`
+	Partial          bool                   `protobuf:"varint,11,opt,name=partial,proto3" json:"partial,omitempty"` // Metrics collection timed out on the agent, pod metrics are not set
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Pod) Reset() {
+	*x = Pod{}
+	mi := &file_proto_gobservability_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pod) ProtoMessage() {}
+
+func (x *Pod) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -851,83 +1453,1692 @@ func (x *Pod) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Pod.ProtoReflect.Descriptor instead.
 func (*Pod) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{9}
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Pod) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Pod) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *Pod) GetPid() int64 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *Pod) GetPodMetrics() *PodMetrics {
+	if x != nil {
+		return x.PodMetrics
+	}
+	return nil
+}
+
+func (x *Pod) GetPidDetails() *PidDetails {
+	if x != nil {
+		return x.PidDetails
+	}
+	return nil
+}
+
+func (x *Pod) GetResourceLimits() *ResourceInfo {
+	if x != nil {
+		return x.ResourceLimits
+	}
+	return nil
+}
+
+func (x *Pod) GetResourceRequests() *ResourceInfo {
+	if x != nil {
+		return x.ResourceRequests
+	}
+	return nil
+}
+
+func (x *Pod) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Pod) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *Pod) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Pod) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+type PodMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cpu           *PodCPUStats           `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory        *PodMemoryStats        `protobuf:"bytes,2,opt,name=memory,proto3" json:"memory,omitempty"`
+	Network       *PodNetworkStats       `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	Disk          *PodDiskStats          `protobuf:"bytes,4,opt,name=disk,proto3" json:"disk,omitempty"`
+	Storage       *PodStorageStats       `protobuf:"bytes,5,opt,name=storage,proto3" json:"storage,omitempty"`
+	Scrape        *ScrapeResult          `protobuf:"bytes,6,opt,name=scrape,proto3" json:"scrape,omitempty"` // Prometheus endpoint of the pod (unset if not annotated)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PodMetrics) Reset() {
+	*x = PodMetrics{}
+	mi := &file_proto_gobservability_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodMetrics) ProtoMessage() {}
+
+func (x *PodMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodMetrics.ProtoReflect.Descriptor instead.
+func (*PodMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PodMetrics) GetCpu() *PodCPUStats {
+	if x != nil {
+		return x.Cpu
+	}
+	return nil
+}
+
+func (x *PodMetrics) GetMemory() *PodMemoryStats {
+	if x != nil {
+		return x.Memory
+	}
+	return nil
+}
+
+func (x *PodMetrics) GetNetwork() *PodNetworkStats {
+	if x != nil {
+		return x.Network
+	}
+	return nil
+}
+
+func (x *PodMetrics) GetDisk() *PodDiskStats {
+	if x != nil {
+		return x.Disk
+	}
+	return nil
+}
+
+func (x *PodMetrics) GetStorage() *PodStorageStats {
+	if x != nil {
+		return x.Storage
+	}
+	return nil
+}
+
+func (x *PodMetrics) GetScrape() *ScrapeResult {
+	if x != nil {
+		return x.Scrape
+	}
+	return nil
+}
+
+// Series scraped from a Prometheus endpoint or produced by a custom collector
+type MetricSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"` // counter, gauge, histogram, summary or untyped
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricSample) Reset() {
+	*x = MetricSample{}
+	mi := &file_proto_gobservability_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricSample) ProtoMessage() {}
+
+func (x *MetricSample) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricSample.ProtoReflect.Descriptor instead.
+func (*MetricSample) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MetricSample) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MetricSample) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *MetricSample) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *MetricSample) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type ScrapeResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Samples       []*MetricSample        `protobuf:"bytes,2,rep,name=samples,proto3" json:"samples,omitempty"`
+	DurationMs    float64                `protobuf:"fixed64,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrapeResult) Reset() {
+	*x = ScrapeResult{}
+	mi := &file_proto_gobservability_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrapeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrapeResult) ProtoMessage() {}
+
+func (x *ScrapeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrapeResult.ProtoReflect.Descriptor instead.
+func (*ScrapeResult) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ScrapeResult) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ScrapeResult) GetSamples() []*MetricSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+func (x *ScrapeResult) GetDurationMs() float64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ScrapeResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Series pushed over StatsD or OTLP from one source IP during the last flush interval
+type PushedMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PodName       string                 `protobuf:"bytes,1,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"` // Empty if the source IP does not belong to a pod of the node
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	SourceIp      string                 `protobuf:"bytes,3,opt,name=source_ip,json=sourceIp,proto3" json:"source_ip,omitempty"`
+	Samples       []*MetricSample        `protobuf:"bytes,4,rep,name=samples,proto3" json:"samples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushedMetrics) Reset() {
+	*x = PushedMetrics{}
+	mi := &file_proto_gobservability_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushedMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushedMetrics) ProtoMessage() {}
+
+func (x *PushedMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushedMetrics.ProtoReflect.Descriptor instead.
+func (*PushedMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PushedMetrics) GetPodName() string {
+	if x != nil {
+		return x.PodName
+	}
+	return ""
+}
+
+func (x *PushedMetrics) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *PushedMetrics) GetSourceIp() string {
+	if x != nil {
+		return x.SourceIp
+	}
+	return ""
+}
+
+func (x *PushedMetrics) GetSamples() []*MetricSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+// Samples produced by one custom collector source (a .prom file or a command)
+type CustomMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // "textfile:<file>" or "exec:<name>"
+	Samples       []*MetricSample        `protobuf:"bytes,2,rep,name=samples,proto3" json:"samples,omitempty"`
+	DurationMs    float64                `protobuf:"fixed64,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomMetrics) Reset() {
+	*x = CustomMetrics{}
+	mi := &file_proto_gobservability_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomMetrics) ProtoMessage() {}
+
+func (x *CustomMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomMetrics.ProtoReflect.Descriptor instead.
+func (*CustomMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CustomMetrics) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CustomMetrics) GetSamples() []*MetricSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+func (x *CustomMetrics) GetDurationMs() float64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *CustomMetrics) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PodCPUStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Utime         uint64                 `protobuf:"varint,1,opt,name=utime,proto3" json:"utime,omitempty"`                              // User mode jiffies
+	Stime         uint64                 `protobuf:"varint,2,opt,name=stime,proto3" json:"stime,omitempty"`                              // Kernel mode jiffies
+	CpuPercent    float64                `protobuf:"fixed64,3,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"` // Calculated CPU %
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PodCPUStats) Reset() {
+	*x = PodCPUStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodCPUStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodCPUStats) ProtoMessage() {}
+
+func (x *PodCPUStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodCPUStats.ProtoReflect.Descriptor instead.
+func (*PodCPUStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PodCPUStats) GetUtime() uint64 {
+	if x != nil {
+		return x.Utime
+	}
+	return 0
+}
+
+func (x *PodCPUStats) GetStime() uint64 {
+	if x != nil {
+		return x.Stime
+	}
+	return 0
+}
+
+func (x *PodCPUStats) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+type PodMemoryStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VmSize        uint64                 `protobuf:"varint,1,opt,name=vm_size,json=vmSize,proto3" json:"vm_size,omitempty"`              // Virtual memory size (KB)
+	VmRss         uint64                 `protobuf:"varint,2,opt,name=vm_rss,json=vmRss,proto3" json:"vm_rss,omitempty"`                 // Resident memory size (KB)
+	MemPercent    float64                `protobuf:"fixed64,3,opt,name=mem_percent,json=memPercent,proto3" json:"mem_percent,omitempty"` // % of total node memory
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PodMemoryStats) Reset() {
+	*x = PodMemoryStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodMemoryStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodMemoryStats) ProtoMessage() {}
+
+func (x *PodMemoryStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodMemoryStats.ProtoReflect.Descriptor instead.
+func (*PodMemoryStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PodMemoryStats) GetVmSize() uint64 {
+	if x != nil {
+		return x.VmSize
+	}
+	return 0
+}
+
+func (x *PodMemoryStats) GetVmRss() uint64 {
+	if x != nil {
+		return x.VmRss
+	}
+	return 0
+}
+
+func (x *PodMemoryStats) GetMemPercent() float64 {
+	if x != nil {
+		return x.MemPercent
+	}
+	return 0
+}
+
+type PodNetworkStats struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	BytesReceived    uint64                 `protobuf:"varint,1,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	BytesTransmitted uint64                 `protobuf:"varint,2,opt,name=bytes_transmitted,json=bytesTransmitted,proto3" json:"bytes_transmitted,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PodNetworkStats) Reset() {
+	*x = PodNetworkStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodNetworkStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodNetworkStats) ProtoMessage() {}
+
+func (x *PodNetworkStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodNetworkStats.ProtoReflect.Descriptor instead.
+func (*PodNetworkStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PodNetworkStats) GetBytesReceived() uint64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+func (x *PodNetworkStats) GetBytesTransmitted() uint64 {
+	if x != nil {
+		return x.BytesTransmitted
+	}
+	return 0
+}
+
+type PodDiskStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReadBytes     uint64                 `protobuf:"varint,1,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`    // Bytes read from disk
+	WriteBytes    uint64                 `protobuf:"varint,2,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"` // Bytes written to disk
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PodDiskStats) Reset() {
+	*x = PodDiskStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodDiskStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodDiskStats) ProtoMessage() {}
+
+func (x *PodDiskStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodDiskStats.ProtoReflect.Descriptor instead.
+func (*PodDiskStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PodDiskStats) GetReadBytes() uint64 {
+	if x != nil {
+		return x.ReadBytes
+	}
+	return 0
+}
+
+func (x *PodDiskStats) GetWriteBytes() uint64 {
+	if x != nil {
+		return x.WriteBytes
+	}
+	return 0
+}
+
+type PodStorageStats struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Volumes             []*VolumeStats         `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	WritableLayerBytes  uint64                 `protobuf:"varint,2,opt,name=writable_layer_bytes,json=writableLayerBytes,proto3" json:"writable_layer_bytes,omitempty"` // Container writable layer (overlay upperdir)
+	WritableLayerInodes uint64                 `protobuf:"varint,3,opt,name=writable_layer_inodes,json=writableLayerInodes,proto3" json:"writable_layer_inodes,omitempty"`
+	EphemeralUsedBytes  uint64                 `protobuf:"varint,4,opt,name=ephemeral_used_bytes,json=ephemeralUsedBytes,proto3" json:"ephemeral_used_bytes,omitempty"`    // emptyDir volumes + writable layers of the pod
+	EphemeralLimitBytes uint64                 `protobuf:"varint,5,opt,name=ephemeral_limit_bytes,json=ephemeralLimitBytes,proto3" json:"ephemeral_limit_bytes,omitempty"` // Sum of ephemeral-storage limits (0 if unlimited)
+	EphemeralPercent    float64                `protobuf:"fixed64,6,opt,name=ephemeral_percent,json=ephemeralPercent,proto3" json:"ephemeral_percent,omitempty"`           // Calculated by agent
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *PodStorageStats) Reset() {
+	*x = PodStorageStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodStorageStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodStorageStats) ProtoMessage() {}
+
+func (x *PodStorageStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodStorageStats.ProtoReflect.Descriptor instead.
+func (*PodStorageStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PodStorageStats) GetVolumes() []*VolumeStats {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+func (x *PodStorageStats) GetWritableLayerBytes() uint64 {
+	if x != nil {
+		return x.WritableLayerBytes
+	}
+	return 0
+}
+
+func (x *PodStorageStats) GetWritableLayerInodes() uint64 {
+	if x != nil {
+		return x.WritableLayerInodes
+	}
+	return 0
+}
+
+func (x *PodStorageStats) GetEphemeralUsedBytes() uint64 {
+	if x != nil {
+		return x.EphemeralUsedBytes
+	}
+	return 0
+}
+
+func (x *PodStorageStats) GetEphemeralLimitBytes() uint64 {
+	if x != nil {
+		return x.EphemeralLimitBytes
+	}
+	return 0
+}
+
+func (x *PodStorageStats) GetEphemeralPercent() float64 {
+	if x != nil {
+		return x.EphemeralPercent
+	}
+	return 0
+}
+
+type VolumeStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // Volume plugin (empty-dir, csi, configmap, ...)
+	UsedBytes     uint64                 `protobuf:"varint,3,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	UsedInodes    uint64                 `protobuf:"varint,4,opt,name=used_inodes,json=usedInodes,proto3" json:"used_inodes,omitempty"`
+	CapacityBytes uint64                 `protobuf:"varint,5,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"` // Size limit or filesystem capacity (0 if unknown)
+	UsagePercent  float64                `protobuf:"fixed64,6,opt,name=usage_percent,json=usagePercent,proto3" json:"usage_percent,omitempty"`   // Calculated by agent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VolumeStats) Reset() {
+	*x = VolumeStats{}
+	mi := &file_proto_gobservability_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeStats) ProtoMessage() {}
+
+func (x *VolumeStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeStats.ProtoReflect.Descriptor instead.
+func (*VolumeStats) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *VolumeStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VolumeStats) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VolumeStats) GetUsedBytes() uint64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *VolumeStats) GetUsedInodes() uint64 {
+	if x != nil {
+		return x.UsedInodes
+	}
+	return 0
+}
+
+func (x *VolumeStats) GetCapacityBytes() uint64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *VolumeStats) GetUsagePercent() float64 {
+	if x != nil {
+		return x.UsagePercent
+	}
+	return 0
+}
+
+type ResourceInfo struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Cpu              string                 `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`                                                   // CPU in millicores (e.g., "100m", "2")
+	Memory           string                 `protobuf:"bytes,2,opt,name=memory,proto3" json:"memory,omitempty"`                                             // Memory in bytes (e.g., "128Mi", "1Gi")
+	EphemeralStorage string                 `protobuf:"bytes,3,opt,name=ephemeral_storage,json=ephemeralStorage,proto3" json:"ephemeral_storage,omitempty"` // Ephemeral storage in bytes (e.g., "1Gi")
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ResourceInfo) Reset() {
+	*x = ResourceInfo{}
+	mi := &file_proto_gobservability_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceInfo) ProtoMessage() {}
+
+func (x *ResourceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceInfo.ProtoReflect.Descriptor instead.
+func (*ResourceInfo) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ResourceInfo) GetCpu() string {
+	if x != nil {
+		return x.Cpu
+	}
+	return ""
+}
+
+func (x *ResourceInfo) GetMemory() string {
+	if x != nil {
+		return x.Memory
+	}
+	return ""
+}
+
+func (x *ResourceInfo) GetEphemeralStorage() string {
+	if x != nil {
+		return x.EphemeralStorage
+	}
+	return ""
+}
+
+type PidDetails struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// From /proc/{PID}/stat - process basics
+	Name             string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                                  // Filename of executable
+	State            string `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`                                                // Process state
+	Priority         int32  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`                                         // Priority level
+	Nice             int32  `protobuf:"varint,4,opt,name=nice,proto3" json:"nice,omitempty"`                                                 // Nice level
+	Threads          int32  `protobuf:"varint,5,opt,name=threads,proto3" json:"threads,omitempty"`                                           // Number of threads
+	StartTime        uint64 `protobuf:"varint,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`                      // Start time after boot
+	RealtimePriority int32  `protobuf:"varint,7,opt,name=realtime_priority,json=realtimePriority,proto3" json:"realtime_priority,omitempty"` // Realtime priority
+	Cutime           uint64 `protobuf:"varint,8,opt,name=cutime,proto3" json:"cutime,omitempty"`                                             // Children user time
+	Cstime           uint64 `protobuf:"varint,9,opt,name=cstime,proto3" json:"cstime,omitempty"`                                             // Children system time
+	TaskCpu          int32  `protobuf:"varint,10,opt,name=task_cpu,json=taskCpu,proto3" json:"task_cpu,omitempty"`                           // Which CPU the task is scheduled on
+	// From /proc/{PID}/status - security and scheduling
+	Kthread                   int32  `protobuf:"varint,11,opt,name=kthread,proto3" json:"kthread,omitempty"` // Kernel thread flag
+	Seccomp                   int32  `protobuf:"varint,12,opt,name=seccomp,proto3" json:"seccomp,omitempty"` // Seccomp mode
+	SpeculationIndirectBranch string `protobuf:"bytes,13,opt,name=speculation_indirect_branch,json=speculationIndirectBranch,proto3" json:"speculation_indirect_branch,omitempty"`
+	CpusAllowedList           string `protobuf:"bytes,14,opt,name=cpus_allowed_list,json=cpusAllowedList,proto3" json:"cpus_allowed_list,omitempty"`
+	MemsAllowedList           string `protobuf:"bytes,15,opt,name=mems_allowed_list,json=memsAllowedList,proto3" json:"mems_allowed_list,omitempty"`
+	VoluntaryCtxtSwitches     uint64 `protobuf:"varint,16,opt,name=voluntary_ctxt_switches,json=voluntaryCtxtSwitches,proto3" json:"voluntary_ctxt_switches,omitempty"`
+	NonvoluntaryCtxtSwitches  uint64 `protobuf:"varint,17,opt,name=nonvoluntary_ctxt_switches,json=nonvoluntaryCtxtSwitches,proto3" json:"nonvoluntary_ctxt_switches,omitempty"`
+	VmPeak                    uint64 `protobuf:"varint,18,opt,name=vm_peak,json=vmPeak,proto3" json:"vm_peak,omitempty"` // Peak virtual memory size (KB)
+	VmLck                     uint64 `protobuf:"varint,19,opt,name=vm_lck,json=vmLck,proto3" json:"vm_lck,omitempty"`    // Locked memory size (KB)
+	VmPin                     uint64 `protobuf:"varint,20,opt,name=vm_pin,json=vmPin,proto3" json:"vm_pin,omitempty"`    // Pinned memory size (KB)
+	// From /proc/{PID}/net - network
+	PacketsReceived    uint64 `protobuf:"varint,21,opt,name=packets_received,json=packetsReceived,proto3" json:"packets_received,omitempty"`
+	PacketsTransmitted uint64 `protobuf:"varint,22,opt,name=packets_transmitted,json=packetsTransmitted,proto3" json:"packets_transmitted,omitempty"`
+	ErrorsReceived     uint64 `protobuf:"varint,23,opt,name=errors_received,json=errorsReceived,proto3" json:"errors_received,omitempty"`
+	ErrorsTransmitted  uint64 `protobuf:"varint,24,opt,name=errors_transmitted,json=errorsTransmitted,proto3" json:"errors_transmitted,omitempty"`
+	// From /proc/{PID}/io
+	CancelledWrites uint64 `protobuf:"varint,25,opt,name=cancelled_writes,json=cancelledWrites,proto3" json:"cancelled_writes,omitempty"`
+	// Additional process information
+	Cmdline       string   `protobuf:"bytes,26,opt,name=cmdline,proto3" json:"cmdline,omitempty"`                 // Command line with arguments
+	Stack         []string `protobuf:"bytes,27,rep,name=stack,proto3" json:"stack,omitempty"`                     // Kernel stack trace
+	OpenFds       int32    `protobuf:"varint,28,opt,name=open_fds,json=openFds,proto3" json:"open_fds,omitempty"` // Number of open file descriptors
+	MaxFds        uint64   `protobuf:"varint,29,opt,name=max_fds,json=maxFds,proto3" json:"max_fds,omitempty"`    // Maximum file descriptors allowed
+	Cgroup        []string `protobuf:"bytes,30,rep,name=cgroup,proto3" json:"cgroup,omitempty"`                   // Control groups
+	VmData        uint64   `protobuf:"varint,31,opt,name=vm_data,json=vmData,proto3" json:"vm_data,omitempty"`    // Data segment size (KB)
+	VmStk         uint64   `protobuf:"varint,32,opt,name=vm_stk,json=vmStk,proto3" json:"vm_stk,omitempty"`       // Stack segment size (KB)
+	VmExe         uint64   `protobuf:"varint,33,opt,name=vm_exe,json=vmExe,proto3" json:"vm_exe,omitempty"`       // Text segment size (KB)
+	VmLib         uint64   `protobuf:"varint,34,opt,name=vm_lib,json=vmLib,proto3" json:"vm_lib,omitempty"`       // Shared library size (KB)
+	VmSwap        uint64   `protobuf:"varint,35,opt,name=vm_swap,json=vmSwap,proto3" json:"vm_swap,omitempty"`    // Swap usage (KB)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PidDetails) Reset() {
+	*x = PidDetails{}
+	mi := &file_proto_gobservability_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PidDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PidDetails) ProtoMessage() {}
+
+func (x *PidDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PidDetails.ProtoReflect.Descriptor instead.
+func (*PidDetails) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *PidDetails) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PidDetails) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *PidDetails) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *PidDetails) GetNice() int32 {
+	if x != nil {
+		return x.Nice
+	}
+	return 0
+}
+
+func (x *PidDetails) GetThreads() int32 {
+	if x != nil {
+		return x.Threads
+	}
+	return 0
+}
+
+func (x *PidDetails) GetStartTime() uint64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *PidDetails) GetRealtimePriority() int32 {
+	if x != nil {
+		return x.RealtimePriority
+	}
+	return 0
+}
+
+func (x *PidDetails) GetCutime() uint64 {
+	if x != nil {
+		return x.Cutime
+	}
+	return 0
+}
+
+func (x *PidDetails) GetCstime() uint64 {
+	if x != nil {
+		return x.Cstime
+	}
+	return 0
+}
+
+func (x *PidDetails) GetTaskCpu() int32 {
+	if x != nil {
+		return x.TaskCpu
+	}
+	return 0
+}
+
+func (x *PidDetails) GetKthread() int32 {
+	if x != nil {
+		return x.Kthread
+	}
+	return 0
+}
+
+func (x *PidDetails) GetSeccomp() int32 {
+	if x != nil {
+		return x.Seccomp
+	}
+	return 0
+}
+
+func (x *PidDetails) GetSpeculationIndirectBranch() string {
+	if x != nil {
+		return x.SpeculationIndirectBranch
+	}
+	return ""
+}
+
+func (x *PidDetails) GetCpusAllowedList() string {
+	if x != nil {
+		return x.CpusAllowedList
+	}
+	return ""
+}
+
+func (x *PidDetails) GetMemsAllowedList() string {
+	if x != nil {
+		return x.MemsAllowedList
+	}
+	return ""
+}
+
+func (x *PidDetails) GetVoluntaryCtxtSwitches() uint64 {
+	if x != nil {
+		return x.VoluntaryCtxtSwitches
+	}
+	return 0
+}
+
+func (x *PidDetails) GetNonvoluntaryCtxtSwitches() uint64 {
+	if x != nil {
+		return x.NonvoluntaryCtxtSwitches
+	}
+	return 0
+}
+
+func (x *PidDetails) GetVmPeak() uint64 {
+	if x != nil {
+		return x.VmPeak
+	}
+	return 0
+}
+
+func (x *PidDetails) GetVmLck() uint64 {
+	if x != nil {
+		return x.VmLck
+	}
+	return 0
+}
+
+func (x *PidDetails) GetVmPin() uint64 {
+	if x != nil {
+		return x.VmPin
+	}
+	return 0
+}
+
+func (x *PidDetails) GetPacketsReceived() uint64 {
+	if x != nil {
+		return x.PacketsReceived
+	}
+	return 0
+}
+
+func (x *PidDetails) GetPacketsTransmitted() uint64 {
+	if x != nil {
+		return x.PacketsTransmitted
+	}
+	return 0
+}
+
+func (x *PidDetails) GetErrorsReceived() uint64 {
+	if x != nil {
+		return x.ErrorsReceived
+	}
+	return 0
+}
+
+func (x *PidDetails) GetErrorsTransmitted() uint64 {
+	if x != nil {
+		return x.ErrorsTransmitted
+	}
+	return 0
+}
+
+func (x *PidDetails) GetCancelledWrites() uint64 {
+	if x != nil {
+		return x.CancelledWrites
+	}
+	return 0
+}
+
+func (x *PidDetails) GetCmdline() string {
+	if x != nil {
+		return x.Cmdline
+	}
+	return ""
+}
+
+func (x *PidDetails) GetStack() []string {
+	if x != nil {
+		return x.Stack
+	}
+	return nil
+}
+
+func (x *PidDetails) GetOpenFds() int32 {
+	if x != nil {
+		return x.OpenFds
+	}
+	return 0
+}
+
+func (x *PidDetails) GetMaxFds() uint64 {
+	if x != nil {
+		return x.MaxFds
+	}
+	return 0
+}
+
+func (x *PidDetails) GetCgroup() []string {
+	if x != nil {
+		return x.Cgroup
+	}
+	return nil
+}
+
+func (x *PidDetails) GetVmData() uint64 {
+	if x != nil {
+		return x.VmData
+	}
+	return 0
+}
+
+func (x *PidDetails) GetVmStk() uint64 {
+	if x != nil {
+		return x.VmStk
+	}
+	return 0
+}
+
+func (x *PidDetails) GetVmExe() uint64 {
+	if x != nil {
+		return x.VmExe
+	}
+	return 0
+}
+
+func (x *PidDetails) GetVmLib() uint64 {
+	if x != nil {
+		return x.VmLib
+	}
+	return 0
+}
+
+func (x *PidDetails) GetVmSwap() uint64 {
+	if x != nil {
+		return x.VmSwap
+	}
+	return 0
+}
+
+// Bidirectional streaming messages for agent-server communication
+type AgentMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*AgentMessage_Hello
+	//	*AgentMessage_Stats
+	//	*AgentMessage_FlamegraphResponse
+	//	*AgentMessage_LogChunk
+	//	*AgentMessage_PodDetailsResponse
+	//	*AgentMessage_AgentConfigAck
+	//	*AgentMessage_Goodbye
+	//	*AgentMessage_Pong
+	//	*AgentMessage_Ping
+	Message       isAgentMessage_Message `protobuf_oneof:"message"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentMessage) Reset() {
+	*x = AgentMessage{}
+	mi := &file_proto_gobservability_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentMessage) ProtoMessage() {}
+
+func (x *AgentMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentMessage.ProtoReflect.Descriptor instead.
+func (*AgentMessage) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AgentMessage) GetMessage() isAgentMessage_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetHello() *AgentHello {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_Hello); ok {
+			return x.Hello
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetStats() *NodeStatsRequest {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_Stats); ok {
+			return x.Stats
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetFlamegraphResponse() *FlamegraphResponse {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_FlamegraphResponse); ok {
+			return x.FlamegraphResponse
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetLogChunk() *LogChunk {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_LogChunk); ok {
+			return x.LogChunk
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetPodDetailsResponse() *PodDetailsResponse {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_PodDetailsResponse); ok {
+			return x.PodDetailsResponse
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetAgentConfigAck() *AgentConfigAck {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_AgentConfigAck); ok {
+			return x.AgentConfigAck
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetGoodbye() *AgentGoodbye {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_Goodbye); ok {
+			return x.Goodbye
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetPong() *Pong {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_Pong); ok {
+			return x.Pong
+		}
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetPing() *Ping {
+	if x != nil {
+		if x, ok := x.Message.(*AgentMessage_Ping); ok {
+			return x.Ping
+		}
+	}
+	return nil
+}
+
+type isAgentMessage_Message interface {
+	isAgentMessage_Message()
+}
+
+type AgentMessage_Hello struct {
+	Hello *AgentHello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type AgentMessage_Stats struct {
+	Stats *NodeStatsRequest `protobuf:"bytes,2,opt,name=stats,proto3,oneof"`
+}
+
+type AgentMessage_FlamegraphResponse struct {
+	FlamegraphResponse *FlamegraphResponse `protobuf:"bytes,3,opt,name=flamegraph_response,json=flamegraphResponse,proto3,oneof"`
+}
+
+type AgentMessage_LogChunk struct {
+	LogChunk *LogChunk `protobuf:"bytes,4,opt,name=log_chunk,json=logChunk,proto3,oneof"`
+}
+
+type AgentMessage_PodDetailsResponse struct {
+	PodDetailsResponse *PodDetailsResponse `protobuf:"bytes,5,opt,name=pod_details_response,json=podDetailsResponse,proto3,oneof"`
+}
+
+type AgentMessage_AgentConfigAck struct {
+	AgentConfigAck *AgentConfigAck `protobuf:"bytes,6,opt,name=agent_config_ack,json=agentConfigAck,proto3,oneof"`
+}
+
+type AgentMessage_Goodbye struct {
+	Goodbye *AgentGoodbye `protobuf:"bytes,7,opt,name=goodbye,proto3,oneof"`
+}
+
+type AgentMessage_Pong struct {
+	Pong *Pong `protobuf:"bytes,8,opt,name=pong,proto3,oneof"`
+}
+
+type AgentMessage_Ping struct {
+	Ping *Ping `protobuf:"bytes,9,opt,name=ping,proto3,oneof"` // Answered once the messages sent before it are processed
+}
+
+func (*AgentMessage_Hello) isAgentMessage_Message() {}
+
+func (*AgentMessage_Stats) isAgentMessage_Message() {}
+
+func (*AgentMessage_FlamegraphResponse) isAgentMessage_Message() {}
+
+func (*AgentMessage_LogChunk) isAgentMessage_Message() {}
+
+func (*AgentMessage_PodDetailsResponse) isAgentMessage_Message() {}
+
+func (*AgentMessage_AgentConfigAck) isAgentMessage_Message() {}
+
+func (*AgentMessage_Goodbye) isAgentMessage_Message() {}
+
+func (*AgentMessage_Pong) isAgentMessage_Message() {}
+
+func (*AgentMessage_Ping) isAgentMessage_Message() {}
+
+// Sent by an agent stopping on purpose before it closes its stream
+type AgentGoodbye struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentGoodbye) Reset() {
+	*x = AgentGoodbye{}
+	mi := &file_proto_gobservability_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentGoodbye) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentGoodbye) ProtoMessage() {}
+
+func (x *AgentGoodbye) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentGoodbye.ProtoReflect.Descriptor instead.
+func (*AgentGoodbye) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AgentGoodbye) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ServerMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*ServerMessage_Ack
+	//	*ServerMessage_FlamegraphRequest
+	//	*ServerMessage_LogRequest
+	//	*ServerMessage_LogPatternRules
+	//	*ServerMessage_PodDetailsRequest
+	//	*ServerMessage_AgentConfig
+	//	*ServerMessage_Resync
+	//	*ServerMessage_Ping
+	//	*ServerMessage_Collect
+	//	*ServerMessage_Pong
+	Message       isServerMessage_Message `protobuf_oneof:"message"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerMessage) Reset() {
+	*x = ServerMessage{}
+	mi := &file_proto_gobservability_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage) ProtoMessage() {}
+
+func (x *ServerMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
+func (*ServerMessage) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ServerMessage) GetMessage() isServerMessage_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetAck() *ServerAck {
+	if x != nil {
+		if x, ok := x.Message.(*ServerMessage_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetFlamegraphRequest() *FlamegraphRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ServerMessage_FlamegraphRequest); ok {
+			return x.FlamegraphRequest
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetLogRequest() *LogRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ServerMessage_LogRequest); ok {
+			return x.LogRequest
+		}
+	}
+	return nil
 }
 
-func (x *Pod) GetName() string {
+func (x *ServerMessage) GetLogPatternRules() *LogPatternRules {
 	if x != nil {
-		return x.Name
+		if x, ok := x.Message.(*ServerMessage_LogPatternRules); ok {
+			return x.LogPatternRules
+		}
 	}
-	return ""
+	return nil
 }
 
-func (x *Pod) GetContainerId() string {
+func (x *ServerMessage) GetPodDetailsRequest() *PodDetailsRequest {
 	if x != nil {
-		return x.ContainerId
+		if x, ok := x.Message.(*ServerMessage_PodDetailsRequest); ok {
+			return x.PodDetailsRequest
+		}
 	}
-	return ""
+	return nil
 }
 
-func (x *Pod) GetPid() int64 {
+func (x *ServerMessage) GetAgentConfig() *AgentConfig {
 	if x != nil {
-		return x.Pid
+		if x, ok := x.Message.(*ServerMessage_AgentConfig); ok {
+			return x.AgentConfig
+		}
 	}
-	return 0
+	return nil
 }
 
-func (x *Pod) GetPodMetrics() *PodMetrics {
+func (x *ServerMessage) GetResync() *ResyncRequest {
 	if x != nil {
-		return x.PodMetrics
+		if x, ok := x.Message.(*ServerMessage_Resync); ok {
+			return x.Resync
+		}
 	}
 	return nil
 }
 
-func (x *Pod) GetPidDetails() *PidDetails {
+func (x *ServerMessage) GetPing() *Ping {
 	if x != nil {
-		return x.PidDetails
+		if x, ok := x.Message.(*ServerMessage_Ping); ok {
+			return x.Ping
+		}
 	}
 	return nil
 }
 
-func (x *Pod) GetResourceLimits() *ResourceInfo {
+func (x *ServerMessage) GetCollect() *CollectRequest {
 	if x != nil {
-		return x.ResourceLimits
+		if x, ok := x.Message.(*ServerMessage_Collect); ok {
+			return x.Collect
+		}
 	}
 	return nil
 }
 
-func (x *Pod) GetResourceRequests() *ResourceInfo {
+func (x *ServerMessage) GetPong() *Pong {
 	if x != nil {
-		return x.ResourceRequests
+		if x, ok := x.Message.(*ServerMessage_Pong); ok {
+			return x.Pong
+		}
 	}
 	return nil
 }
 
-type PodMetrics struct {
+type isServerMessage_Message interface {
+	isServerMessage_Message()
+}
+
+type ServerMessage_Ack struct {
+	Ack *ServerAck `protobuf:"bytes,1,opt,name=ack,proto3,oneof"`
+}
+
+type ServerMessage_FlamegraphRequest struct {
+	FlamegraphRequest *FlamegraphRequest `protobuf:"bytes,2,opt,name=flamegraph_request,json=flamegraphRequest,proto3,oneof"`
+}
+
+type ServerMessage_LogRequest struct {
+	LogRequest *LogRequest `protobuf:"bytes,3,opt,name=log_request,json=logRequest,proto3,oneof"`
+}
+
+type ServerMessage_LogPatternRules struct {
+	LogPatternRules *LogPatternRules `protobuf:"bytes,4,opt,name=log_pattern_rules,json=logPatternRules,proto3,oneof"`
+}
+
+type ServerMessage_PodDetailsRequest struct {
+	PodDetailsRequest *PodDetailsRequest `protobuf:"bytes,5,opt,name=pod_details_request,json=podDetailsRequest,proto3,oneof"`
+}
+
+type ServerMessage_AgentConfig struct {
+	AgentConfig *AgentConfig `protobuf:"bytes,6,opt,name=agent_config,json=agentConfig,proto3,oneof"`
+}
+
+type ServerMessage_Resync struct {
+	Resync *ResyncRequest `protobuf:"bytes,7,opt,name=resync,proto3,oneof"`
+}
+
+type ServerMessage_Ping struct {
+	Ping *Ping `protobuf:"bytes,8,opt,name=ping,proto3,oneof"`
+}
+
+type ServerMessage_Collect struct {
+	Collect *CollectRequest `protobuf:"bytes,9,opt,name=collect,proto3,oneof"`
+}
+
+type ServerMessage_Pong struct {
+	Pong *Pong `protobuf:"bytes,10,opt,name=pong,proto3,oneof"`
+}
+
+func (*ServerMessage_Ack) isServerMessage_Message() {}
+
+func (*ServerMessage_FlamegraphRequest) isServerMessage_Message() {}
+
+func (*ServerMessage_LogRequest) isServerMessage_Message() {}
+
+func (*ServerMessage_LogPatternRules) isServerMessage_Message() {}
+
+func (*ServerMessage_PodDetailsRequest) isServerMessage_Message() {}
+
+func (*ServerMessage_AgentConfig) isServerMessage_Message() {}
+
+func (*ServerMessage_Resync) isServerMessage_Message() {}
+
+func (*ServerMessage_Ping) isServerMessage_Message() {}
+
+func (*ServerMessage_Collect) isServerMessage_Message() {}
+
+func (*ServerMessage_Pong) isServerMessage_Message() {}
+
+// Sent periodically to measure the round-trip latency of the stream, answered with a pong. Agents
+// may ping the server after their stats to measure the ingestion latency
+type Ping struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Cpu           *PodCPUStats           `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
-	Memory        *PodMemoryStats        `protobuf:"bytes,2,opt,name=memory,proto3" json:"memory,omitempty"`
-	Network       *PodNetworkStats       `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
-	Disk          *PodDiskStats          `protobuf:"bytes,4,opt,name=disk,proto3" json:"disk,omitempty"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PodMetrics) Reset() {
-	*x = PodMetrics{}
-	mi := &file_proto_gobservability_proto_msgTypes[10]
+func (x *Ping) Reset() {
+	*x = Ping{}
+	mi := &file_proto_gobservability_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PodMetrics) String() string {
+func (x *Ping) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PodMetrics) ProtoMessage() {}
+func (*Ping) ProtoMessage() {}
 
-func (x *PodMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[10]
+func (x *Ping) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -938,63 +3149,93 @@ func (x *PodMetrics) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PodMetrics.ProtoReflect.Descriptor instead.
-func (*PodMetrics) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use Ping.ProtoReflect.Descriptor instead.
+func (*Ping) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *PodMetrics) GetCpu() *PodCPUStats {
+func (x *Ping) GetId() uint64 {
 	if x != nil {
-		return x.Cpu
+		return x.Id
 	}
-	return nil
+	return 0
 }
 
-func (x *PodMetrics) GetMemory() *PodMemoryStats {
+type Pong struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                               // Id of the ping
+	AgentTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=agent_time,json=agentTime,proto3" json:"agent_time,omitempty"` // Clock of the agent when answering, to measure its skew
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pong) Reset() {
+	*x = Pong{}
+	mi := &file_proto_gobservability_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pong) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pong) ProtoMessage() {}
+
+func (x *Pong) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[33]
 	if x != nil {
-		return x.Memory
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *PodMetrics) GetNetwork() *PodNetworkStats {
+// Deprecated: Use Pong.ProtoReflect.Descriptor instead.
+func (*Pong) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Pong) GetId() uint64 {
 	if x != nil {
-		return x.Network
+		return x.Id
 	}
-	return nil
+	return 0
 }
 
-func (x *PodMetrics) GetDisk() *PodDiskStats {
+func (x *Pong) GetAgentTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Disk
+		return x.AgentTime
 	}
 	return nil
 }
 
-type PodCPUStats struct {
+// Asks the agent to collect and send a payload right away
+type CollectRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Utime         uint64                 `protobuf:"varint,1,opt,name=utime,proto3" json:"utime,omitempty"`                              // User mode jiffies
-	Stime         uint64                 `protobuf:"varint,2,opt,name=stime,proto3" json:"stime,omitempty"`                              // Kernel mode jiffies
-	CpuPercent    float64                `protobuf:"fixed64,3,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"` // Calculated CPU %
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PodCPUStats) Reset() {
-	*x = PodCPUStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[11]
+func (x *CollectRequest) Reset() {
+	*x = CollectRequest{}
+	mi := &file_proto_gobservability_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PodCPUStats) String() string {
+func (x *CollectRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PodCPUStats) ProtoMessage() {}
+func (*CollectRequest) ProtoMessage() {}
 
-func (x *PodCPUStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[11]
+func (x *CollectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1005,56 +3246,92 @@ func (x *PodCPUStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PodCPUStats.ProtoReflect.Descriptor instead.
-func (*PodCPUStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use CollectRequest.ProtoReflect.Descriptor instead.
+func (*CollectRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *PodCPUStats) GetUtime() uint64 {
+func (x *CollectRequest) GetReason() string {
 	if x != nil {
-		return x.Utime
+		return x.Reason
 	}
-	return 0
+	return ""
 }
 
-func (x *PodCPUStats) GetStime() uint64 {
+// Sent when a delta cannot be applied (sequence gap), the next payload of the agent is a snapshot
+type ResyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResyncRequest) Reset() {
+	*x = ResyncRequest{}
+	mi := &file_proto_gobservability_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResyncRequest) ProtoMessage() {}
+
+func (x *ResyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[35]
 	if x != nil {
-		return x.Stime
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *PodCPUStats) GetCpuPercent() float64 {
+// Deprecated: Use ResyncRequest.ProtoReflect.Descriptor instead.
+func (*ResyncRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ResyncRequest) GetReason() string {
 	if x != nil {
-		return x.CpuPercent
+		return x.Reason
 	}
-	return 0
+	return ""
 }
 
-type PodMemoryStats struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	VmSize        uint64                 `protobuf:"varint,1,opt,name=vm_size,json=vmSize,proto3" json:"vm_size,omitempty"`              // Virtual memory size (KB)
-	VmRss         uint64                 `protobuf:"varint,2,opt,name=vm_rss,json=vmRss,proto3" json:"vm_rss,omitempty"`                 // Resident memory size (KB)
-	MemPercent    float64                `protobuf:"fixed64,3,opt,name=mem_percent,json=memPercent,proto3" json:"mem_percent,omitempty"` // % of total node memory
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// Collection settings pushed by the server, they override the agent config file and flags
+// An empty config reverts the agent to its own settings
+type AgentConfig struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Version              int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`                         // Incremented on every push, acknowledged by the agent
+	IntervalMs           int64                  `protobuf:"varint,2,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"` // Collection tick, 0 keeps the agent interval
+	EnabledCollectors    []string               `protobuf:"bytes,3,rep,name=enabled_collectors,json=enabledCollectors,proto3" json:"enabled_collectors,omitempty"`
+	DisabledCollectors   []string               `protobuf:"bytes,4,rep,name=disabled_collectors,json=disabledCollectors,proto3" json:"disabled_collectors,omitempty"`
+	CollectorIntervalsMs map[string]int64       `protobuf:"bytes,5,rep,name=collector_intervals_ms,json=collectorIntervalsMs,proto3" json:"collector_intervals_ms,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Filters              *AgentFilters          `protobuf:"bytes,6,opt,name=filters,proto3" json:"filters,omitempty"` // Empty rules keep the agent filters
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
-func (x *PodMemoryStats) Reset() {
-	*x = PodMemoryStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[12]
+func (x *AgentConfig) Reset() {
+	*x = AgentConfig{}
+	mi := &file_proto_gobservability_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PodMemoryStats) String() string {
+func (x *AgentConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PodMemoryStats) ProtoMessage() {}
+func (*AgentConfig) ProtoMessage() {}
 
-func (x *PodMemoryStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[12]
+func (x *AgentConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1065,55 +3342,78 @@ func (x *PodMemoryStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PodMemoryStats.ProtoReflect.Descriptor instead.
-func (*PodMemoryStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use AgentConfig.ProtoReflect.Descriptor instead.
+func (*AgentConfig) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *PodMemoryStats) GetVmSize() uint64 {
+func (x *AgentConfig) GetVersion() int64 {
 	if x != nil {
-		return x.VmSize
+		return x.Version
 	}
 	return 0
 }
 
-func (x *PodMemoryStats) GetVmRss() uint64 {
+func (x *AgentConfig) GetIntervalMs() int64 {
 	if x != nil {
-		return x.VmRss
+		return x.IntervalMs
 	}
 	return 0
 }
 
-func (x *PodMemoryStats) GetMemPercent() float64 {
+func (x *AgentConfig) GetEnabledCollectors() []string {
 	if x != nil {
-		return x.MemPercent
+		return x.EnabledCollectors
 	}
-	return 0
+	return nil
 }
 
-type PodNetworkStats struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	BytesReceived    uint64                 `protobuf:"varint,1,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
-	BytesTransmitted uint64                 `protobuf:"varint,2,opt,name=bytes_transmitted,json=bytesTransmitted,proto3" json:"bytes_transmitted,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+func (x *AgentConfig) GetDisabledCollectors() []string {
+	if x != nil {
+		return x.DisabledCollectors
+	}
+	return nil
 }
 
-func (x *PodNetworkStats) Reset() {
-	*x = PodNetworkStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[13]
+func (x *AgentConfig) GetCollectorIntervalsMs() map[string]int64 {
+	if x != nil {
+		return x.CollectorIntervalsMs
+	}
+	return nil
+}
+
+func (x *AgentConfig) GetFilters() *AgentFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type AgentFilters struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespaces    *FilterRule            `protobuf:"bytes,1,opt,name=namespaces,proto3" json:"namespaces,omitempty"`
+	Pods          *FilterRule            `protobuf:"bytes,2,opt,name=pods,proto3" json:"pods,omitempty"`
+	Interfaces    *FilterRule            `protobuf:"bytes,3,opt,name=interfaces,proto3" json:"interfaces,omitempty"`
+	Devices       *FilterRule            `protobuf:"bytes,4,opt,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentFilters) Reset() {
+	*x = AgentFilters{}
+	mi := &file_proto_gobservability_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PodNetworkStats) String() string {
+func (x *AgentFilters) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PodNetworkStats) ProtoMessage() {}
+func (*AgentFilters) ProtoMessage() {}
 
-func (x *PodNetworkStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[13]
+func (x *AgentFilters) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1124,48 +3424,63 @@ func (x *PodNetworkStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PodNetworkStats.ProtoReflect.Descriptor instead.
-func (*PodNetworkStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use AgentFilters.ProtoReflect.Descriptor instead.
+func (*AgentFilters) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *PodNetworkStats) GetBytesReceived() uint64 {
+func (x *AgentFilters) GetNamespaces() *FilterRule {
 	if x != nil {
-		return x.BytesReceived
+		return x.Namespaces
 	}
-	return 0
+	return nil
 }
 
-func (x *PodNetworkStats) GetBytesTransmitted() uint64 {
+func (x *AgentFilters) GetPods() *FilterRule {
 	if x != nil {
-		return x.BytesTransmitted
+		return x.Pods
 	}
-	return 0
+	return nil
 }
 
-type PodDiskStats struct {
+func (x *AgentFilters) GetInterfaces() *FilterRule {
+	if x != nil {
+		return x.Interfaces
+	}
+	return nil
+}
+
+func (x *AgentFilters) GetDevices() *FilterRule {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// Include and exclude regexes matching the whole name
+type FilterRule struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ReadBytes     uint64                 `protobuf:"varint,1,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`    // Bytes read from disk
-	WriteBytes    uint64                 `protobuf:"varint,2,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"` // Bytes written to disk
+	Include       []string               `protobuf:"bytes,1,rep,name=include,proto3" json:"include,omitempty"`
+	Exclude       []string               `protobuf:"bytes,2,rep,name=exclude,proto3" json:"exclude,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PodDiskStats) Reset() {
-	*x = PodDiskStats{}
-	mi := &file_proto_gobservability_proto_msgTypes[14]
+func (x *FilterRule) Reset() {
+	*x = FilterRule{}
+	mi := &file_proto_gobservability_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PodDiskStats) String() string {
+func (x *FilterRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PodDiskStats) ProtoMessage() {}
+func (*FilterRule) ProtoMessage() {}
 
-func (x *PodDiskStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[14]
+func (x *FilterRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1176,48 +3491,50 @@ func (x *PodDiskStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PodDiskStats.ProtoReflect.Descriptor instead.
-func (*PodDiskStats) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use FilterRule.ProtoReflect.Descriptor instead.
+func (*FilterRule) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *PodDiskStats) GetReadBytes() uint64 {
+func (x *FilterRule) GetInclude() []string {
 	if x != nil {
-		return x.ReadBytes
+		return x.Include
 	}
-	return 0
+	return nil
 }
 
-func (x *PodDiskStats) GetWriteBytes() uint64 {
+func (x *FilterRule) GetExclude() []string {
 	if x != nil {
-		return x.WriteBytes
+		return x.Exclude
 	}
-	return 0
+	return nil
 }
 
-type ResourceInfo struct {
+// Sent by the agent once an AgentConfig is applied or rejected
+type AgentConfigAck struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Cpu           string                 `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`       // CPU in millicores (e.g., "100m", "2")
-	Memory        string                 `protobuf:"bytes,2,opt,name=memory,proto3" json:"memory,omitempty"` // Memory in bytes (e.g., "128Mi", "1Gi")
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Applied       bool                   `protobuf:"varint,2,opt,name=applied,proto3" json:"applied,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"` // Why the config was rejected (unknown collector, invalid regex...)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResourceInfo) Reset() {
-	*x = ResourceInfo{}
-	mi := &file_proto_gobservability_proto_msgTypes[15]
+func (x *AgentConfigAck) Reset() {
+	*x = AgentConfigAck{}
+	mi := &file_proto_gobservability_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResourceInfo) String() string {
+func (x *AgentConfigAck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResourceInfo) ProtoMessage() {}
+func (*AgentConfigAck) ProtoMessage() {}
 
-func (x *ResourceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[15]
+func (x *AgentConfigAck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1228,86 +3545,57 @@ func (x *ResourceInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResourceInfo.ProtoReflect.Descriptor instead.
-func (*ResourceInfo) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use AgentConfigAck.ProtoReflect.Descriptor instead.
+func (*AgentConfigAck) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *ResourceInfo) GetCpu() string {
+func (x *AgentConfigAck) GetVersion() int64 {
 	if x != nil {
-		return x.Cpu
+		return x.Version
 	}
-	return ""
+	return 0
 }
 
-func (x *ResourceInfo) GetMemory() string {
+func (x *AgentConfigAck) GetApplied() bool {
 	if x != nil {
-		return x.Memory
+		return x.Applied
+	}
+	return false
+}
+
+func (x *AgentConfigAck) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type PidDetails struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// From /proc/{PID}/stat - process basics
-	Name             string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                                  // Filename of executable
-	State            string `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`                                                // Process state
-	Priority         int32  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`                                         // Priority level
-	Nice             int32  `protobuf:"varint,4,opt,name=nice,proto3" json:"nice,omitempty"`                                                 // Nice level
-	Threads          int32  `protobuf:"varint,5,opt,name=threads,proto3" json:"threads,omitempty"`                                           // Number of threads
-	StartTime        uint64 `protobuf:"varint,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`                      // Start time after boot
-	RealtimePriority int32  `protobuf:"varint,7,opt,name=realtime_priority,json=realtimePriority,proto3" json:"realtime_priority,omitempty"` // Realtime priority
-	Cutime           uint64 `protobuf:"varint,8,opt,name=cutime,proto3" json:"cutime,omitempty"`                                             // Children user time
-	Cstime           uint64 `protobuf:"varint,9,opt,name=cstime,proto3" json:"cstime,omitempty"`                                             // Children system time
-	TaskCpu          int32  `protobuf:"varint,10,opt,name=task_cpu,json=taskCpu,proto3" json:"task_cpu,omitempty"`                           // Which CPU the task is scheduled on
-	// From /proc/{PID}/status - security and scheduling
-	Kthread                   int32  `protobuf:"varint,11,opt,name=kthread,proto3" json:"kthread,omitempty"` // Kernel thread flag
-	Seccomp                   int32  `protobuf:"varint,12,opt,name=seccomp,proto3" json:"seccomp,omitempty"` // Seccomp mode
-	SpeculationIndirectBranch string `protobuf:"bytes,13,opt,name=speculation_indirect_branch,json=speculationIndirectBranch,proto3" json:"speculation_indirect_branch,omitempty"`
-	CpusAllowedList           string `protobuf:"bytes,14,opt,name=cpus_allowed_list,json=cpusAllowedList,proto3" json:"cpus_allowed_list,omitempty"`
-	MemsAllowedList           string `protobuf:"bytes,15,opt,name=mems_allowed_list,json=memsAllowedList,proto3" json:"mems_allowed_list,omitempty"`
-	VoluntaryCtxtSwitches     uint64 `protobuf:"varint,16,opt,name=voluntary_ctxt_switches,json=voluntaryCtxtSwitches,proto3" json:"voluntary_ctxt_switches,omitempty"`
-	NonvoluntaryCtxtSwitches  uint64 `protobuf:"varint,17,opt,name=nonvoluntary_ctxt_switches,json=nonvoluntaryCtxtSwitches,proto3" json:"nonvoluntary_ctxt_switches,omitempty"`
-	VmPeak                    uint64 `protobuf:"varint,18,opt,name=vm_peak,json=vmPeak,proto3" json:"vm_peak,omitempty"` // Peak virtual memory size (KB)
-	VmLck                     uint64 `protobuf:"varint,19,opt,name=vm_lck,json=vmLck,proto3" json:"vm_lck,omitempty"`    // Locked memory size (KB)
-	VmPin                     uint64 `protobuf:"varint,20,opt,name=vm_pin,json=vmPin,proto3" json:"vm_pin,omitempty"`    // Pinned memory size (KB)
-	// From /proc/{PID}/net - network
-	PacketsReceived    uint64 `protobuf:"varint,21,opt,name=packets_received,json=packetsReceived,proto3" json:"packets_received,omitempty"`
-	PacketsTransmitted uint64 `protobuf:"varint,22,opt,name=packets_transmitted,json=packetsTransmitted,proto3" json:"packets_transmitted,omitempty"`
-	ErrorsReceived     uint64 `protobuf:"varint,23,opt,name=errors_received,json=errorsReceived,proto3" json:"errors_received,omitempty"`
-	ErrorsTransmitted  uint64 `protobuf:"varint,24,opt,name=errors_transmitted,json=errorsTransmitted,proto3" json:"errors_transmitted,omitempty"`
-	// From /proc/{PID}/io
-	CancelledWrites uint64 `protobuf:"varint,25,opt,name=cancelled_writes,json=cancelledWrites,proto3" json:"cancelled_writes,omitempty"`
-	// Additional process information
-	Cmdline       string   `protobuf:"bytes,26,opt,name=cmdline,proto3" json:"cmdline,omitempty"`                 // Command line with arguments
-	Stack         []string `protobuf:"bytes,27,rep,name=stack,proto3" json:"stack,omitempty"`                     // Kernel stack trace
-	OpenFds       int32    `protobuf:"varint,28,opt,name=open_fds,json=openFds,proto3" json:"open_fds,omitempty"` // Number of open file descriptors
-	MaxFds        uint64   `protobuf:"varint,29,opt,name=max_fds,json=maxFds,proto3" json:"max_fds,omitempty"`    // Maximum file descriptors allowed
-	Cgroup        []string `protobuf:"bytes,30,rep,name=cgroup,proto3" json:"cgroup,omitempty"`                   // Control groups
-	VmData        uint64   `protobuf:"varint,31,opt,name=vm_data,json=vmData,proto3" json:"vm_data,omitempty"`    // Data segment size (KB)
-	VmStk         uint64   `protobuf:"varint,32,opt,name=vm_stk,json=vmStk,proto3" json:"vm_stk,omitempty"`       // Stack segment size (KB)
-	VmExe         uint64   `protobuf:"varint,33,opt,name=vm_exe,json=vmExe,proto3" json:"vm_exe,omitempty"`       // Text segment size (KB)
-	VmLib         uint64   `protobuf:"varint,34,opt,name=vm_lib,json=vmLib,proto3" json:"vm_lib,omitempty"`       // Shared library size (KB)
-	VmSwap        uint64   `protobuf:"varint,35,opt,name=vm_swap,json=vmSwap,proto3" json:"vm_swap,omitempty"`    // Swap usage (KB)
+// Request for the detail tier of a pod process (only the summary tier is sent with the stats)
+type PodDetailsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // Unique request ID for matching the response
+	NodeName      string                 `protobuf:"bytes,2,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	PodName       string                 `protobuf:"bytes,3,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PidDetails) Reset() {
-	*x = PidDetails{}
-	mi := &file_proto_gobservability_proto_msgTypes[16]
+func (x *PodDetailsRequest) Reset() {
+	*x = PodDetailsRequest{}
+	mi := &file_proto_gobservability_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PidDetails) String() string {
+func (x *PodDetailsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PidDetails) ProtoMessage() {}
+func (*PodDetailsRequest) ProtoMessage() {}
 
-func (x *PidDetails) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[16]
+func (x *PodDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1318,284 +3606,280 @@ func (x *PidDetails) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PidDetails.ProtoReflect.Descriptor instead.
-func (*PidDetails) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use PodDetailsRequest.ProtoReflect.Descriptor instead.
+func (*PodDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *PidDetails) GetName() string {
+func (x *PodDetailsRequest) GetRequestId() string {
 	if x != nil {
-		return x.Name
+		return x.RequestId
 	}
 	return ""
 }
 
-func (x *PidDetails) GetState() string {
+func (x *PodDetailsRequest) GetNodeName() string {
 	if x != nil {
-		return x.State
+		return x.NodeName
 	}
 	return ""
 }
 
-func (x *PidDetails) GetPriority() int32 {
-	if x != nil {
-		return x.Priority
-	}
-	return 0
-}
-
-func (x *PidDetails) GetNice() int32 {
+func (x *PodDetailsRequest) GetPodName() string {
 	if x != nil {
-		return x.Nice
+		return x.PodName
 	}
-	return 0
+	return ""
 }
 
-func (x *PidDetails) GetThreads() int32 {
-	if x != nil {
-		return x.Threads
-	}
-	return 0
+// All the process details of a pod, read when requested
+type PodDetailsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Details       *PidDetails            `protobuf:"bytes,2,opt,name=details,proto3" json:"details,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"` // Error message if the process could not be read
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PidDetails) GetStartTime() uint64 {
-	if x != nil {
-		return x.StartTime
-	}
-	return 0
+func (x *PodDetailsResponse) Reset() {
+	*x = PodDetailsResponse{}
+	mi := &file_proto_gobservability_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *PidDetails) GetRealtimePriority() int32 {
-	if x != nil {
-		return x.RealtimePriority
-	}
-	return 0
+func (x *PodDetailsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *PidDetails) GetCutime() uint64 {
-	if x != nil {
-		return x.Cutime
-	}
-	return 0
-}
+func (*PodDetailsResponse) ProtoMessage() {}
 
-func (x *PidDetails) GetCstime() uint64 {
+func (x *PodDetailsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[41]
 	if x != nil {
-		return x.Cstime
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *PidDetails) GetTaskCpu() int32 {
-	if x != nil {
-		return x.TaskCpu
-	}
-	return 0
+// Deprecated: Use PodDetailsResponse.ProtoReflect.Descriptor instead.
+func (*PodDetailsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *PidDetails) GetKthread() int32 {
+func (x *PodDetailsResponse) GetRequestId() string {
 	if x != nil {
-		return x.Kthread
+		return x.RequestId
 	}
-	return 0
+	return ""
 }
 
-func (x *PidDetails) GetSeccomp() int32 {
+func (x *PodDetailsResponse) GetDetails() *PidDetails {
 	if x != nil {
-		return x.Seccomp
+		return x.Details
 	}
-	return 0
+	return nil
 }
 
-func (x *PidDetails) GetSpeculationIndirectBranch() string {
+func (x *PodDetailsResponse) GetError() string {
 	if x != nil {
-		return x.SpeculationIndirectBranch
+		return x.Error
 	}
 	return ""
 }
 
-func (x *PidDetails) GetCpusAllowedList() string {
-	if x != nil {
-		return x.CpusAllowedList
-	}
-	return ""
+// Container log tailing request (CRI log files under /var/log/pods)
+type LogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // Unique request ID for matching chunks
+	NodeName      string                 `protobuf:"bytes,2,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	PodName       string                 `protobuf:"bytes,3,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	Container     string                 `protobuf:"bytes,4,opt,name=container,proto3" json:"container,omitempty"`                   // Optional - if empty, all containers of the pod
+	TailLines     int32                  `protobuf:"varint,5,opt,name=tail_lines,json=tailLines,proto3" json:"tail_lines,omitempty"` // Number of lines to return from the end of the log
+	Follow        bool                   `protobuf:"varint,6,opt,name=follow,proto3" json:"follow,omitempty"`                        // Keep streaming new lines until stopped
+	Stop          bool                   `protobuf:"varint,7,opt,name=stop,proto3" json:"stop,omitempty"`                            // Stop a running follow for request_id
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PidDetails) GetMemsAllowedList() string {
-	if x != nil {
-		return x.MemsAllowedList
-	}
-	return ""
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	mi := &file_proto_gobservability_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *PidDetails) GetVoluntaryCtxtSwitches() uint64 {
-	if x != nil {
-		return x.VoluntaryCtxtSwitches
-	}
-	return 0
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *PidDetails) GetNonvoluntaryCtxtSwitches() uint64 {
-	if x != nil {
-		return x.NonvoluntaryCtxtSwitches
-	}
-	return 0
-}
+func (*LogRequest) ProtoMessage() {}
 
-func (x *PidDetails) GetVmPeak() uint64 {
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[42]
 	if x != nil {
-		return x.VmPeak
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *PidDetails) GetVmLck() uint64 {
-	if x != nil {
-		return x.VmLck
-	}
-	return 0
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *PidDetails) GetVmPin() uint64 {
+func (x *LogRequest) GetRequestId() string {
 	if x != nil {
-		return x.VmPin
+		return x.RequestId
 	}
-	return 0
+	return ""
 }
 
-func (x *PidDetails) GetPacketsReceived() uint64 {
+func (x *LogRequest) GetNodeName() string {
 	if x != nil {
-		return x.PacketsReceived
+		return x.NodeName
 	}
-	return 0
+	return ""
 }
 
-func (x *PidDetails) GetPacketsTransmitted() uint64 {
+func (x *LogRequest) GetPodName() string {
 	if x != nil {
-		return x.PacketsTransmitted
+		return x.PodName
 	}
-	return 0
+	return ""
 }
 
-func (x *PidDetails) GetErrorsReceived() uint64 {
+func (x *LogRequest) GetContainer() string {
 	if x != nil {
-		return x.ErrorsReceived
+		return x.Container
 	}
-	return 0
+	return ""
 }
 
-func (x *PidDetails) GetErrorsTransmitted() uint64 {
+func (x *LogRequest) GetTailLines() int32 {
 	if x != nil {
-		return x.ErrorsTransmitted
+		return x.TailLines
 	}
 	return 0
 }
 
-func (x *PidDetails) GetCancelledWrites() uint64 {
+func (x *LogRequest) GetFollow() bool {
 	if x != nil {
-		return x.CancelledWrites
+		return x.Follow
 	}
-	return 0
+	return false
 }
 
-func (x *PidDetails) GetCmdline() string {
+func (x *LogRequest) GetStop() bool {
 	if x != nil {
-		return x.Cmdline
+		return x.Stop
 	}
-	return ""
+	return false
 }
 
-func (x *PidDetails) GetStack() []string {
-	if x != nil {
-		return x.Stack
-	}
-	return nil
+// Chunk of log lines sent back for a LogRequest
+type LogChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Lines         []*LogLine             `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`
+	Eof           bool                   `protobuf:"varint,3,opt,name=eof,proto3" json:"eof,omitempty"`    // No more chunks will be sent for this request
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"` // Error message if tailing failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PidDetails) GetOpenFds() int32 {
-	if x != nil {
-		return x.OpenFds
-	}
-	return 0
+func (x *LogChunk) Reset() {
+	*x = LogChunk{}
+	mi := &file_proto_gobservability_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *PidDetails) GetMaxFds() uint64 {
-	if x != nil {
-		return x.MaxFds
-	}
-	return 0
+func (x *LogChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *PidDetails) GetCgroup() []string {
-	if x != nil {
-		return x.Cgroup
-	}
-	return nil
-}
+func (*LogChunk) ProtoMessage() {}
 
-func (x *PidDetails) GetVmData() uint64 {
+func (x *LogChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[43]
 	if x != nil {
-		return x.VmData
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogChunk.ProtoReflect.Descriptor instead.
+func (*LogChunk) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *PidDetails) GetVmStk() uint64 {
+func (x *LogChunk) GetRequestId() string {
 	if x != nil {
-		return x.VmStk
+		return x.RequestId
 	}
-	return 0
+	return ""
 }
 
-func (x *PidDetails) GetVmExe() uint64 {
+func (x *LogChunk) GetLines() []*LogLine {
 	if x != nil {
-		return x.VmExe
+		return x.Lines
 	}
-	return 0
+	return nil
 }
 
-func (x *PidDetails) GetVmLib() uint64 {
+func (x *LogChunk) GetEof() bool {
 	if x != nil {
-		return x.VmLib
+		return x.Eof
 	}
-	return 0
+	return false
 }
 
-func (x *PidDetails) GetVmSwap() uint64 {
+func (x *LogChunk) GetError() string {
 	if x != nil {
-		return x.VmSwap
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-// Bidirectional streaming messages for agent-server communication
-type AgentMessage struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Message:
-	//
-	//	*AgentMessage_Hello
-	//	*AgentMessage_Stats
-	//	*AgentMessage_FlamegraphResponse
-	Message       isAgentMessage_Message `protobuf_oneof:"message"`
+type LogLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Container     string                 `protobuf:"bytes,2,opt,name=container,proto3" json:"container,omitempty"`
+	Stream        string                 `protobuf:"bytes,3,opt,name=stream,proto3" json:"stream,omitempty"` // stdout or stderr
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentMessage) Reset() {
-	*x = AgentMessage{}
-	mi := &file_proto_gobservability_proto_msgTypes[17]
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	mi := &file_proto_gobservability_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentMessage) String() string {
+func (x *LogLine) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentMessage) ProtoMessage() {}
+func (*LogLine) ProtoMessage() {}
 
-func (x *AgentMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[17]
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1606,93 +3890,108 @@ func (x *AgentMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentMessage.ProtoReflect.Descriptor instead.
-func (*AgentMessage) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *AgentMessage) GetMessage() isAgentMessage_Message {
+func (x *LogLine) GetTimestamp() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Message
+		return x.Timestamp
 	}
 	return nil
 }
 
-func (x *AgentMessage) GetHello() *AgentHello {
+func (x *LogLine) GetContainer() string {
 	if x != nil {
-		if x, ok := x.Message.(*AgentMessage_Hello); ok {
-			return x.Hello
-		}
+		return x.Container
 	}
-	return nil
+	return ""
 }
 
-func (x *AgentMessage) GetStats() *NodeStatsRequest {
+func (x *LogLine) GetStream() string {
 	if x != nil {
-		if x, ok := x.Message.(*AgentMessage_Stats); ok {
-			return x.Stats
-		}
+		return x.Stream
 	}
-	return nil
+	return ""
 }
 
-func (x *AgentMessage) GetFlamegraphResponse() *FlamegraphResponse {
+func (x *LogLine) GetMessage() string {
 	if x != nil {
-		if x, ok := x.Message.(*AgentMessage_FlamegraphResponse); ok {
-			return x.FlamegraphResponse
-		}
+		return x.Message
 	}
-	return nil
+	return ""
 }
 
-type isAgentMessage_Message interface {
-	isAgentMessage_Message()
+// Log pattern alert rules of a node (replaces the full set on the agent)
+type LogPatternRules struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*LogPatternRule      `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type AgentMessage_Hello struct {
-	Hello *AgentHello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+func (x *LogPatternRules) Reset() {
+	*x = LogPatternRules{}
+	mi := &file_proto_gobservability_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type AgentMessage_Stats struct {
-	Stats *NodeStatsRequest `protobuf:"bytes,2,opt,name=stats,proto3,oneof"`
+func (x *LogPatternRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type AgentMessage_FlamegraphResponse struct {
-	FlamegraphResponse *FlamegraphResponse `protobuf:"bytes,3,opt,name=flamegraph_response,json=flamegraphResponse,proto3,oneof"`
-}
+func (*LogPatternRules) ProtoMessage() {}
 
-func (*AgentMessage_Hello) isAgentMessage_Message() {}
+func (x *LogPatternRules) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-func (*AgentMessage_Stats) isAgentMessage_Message() {}
+// Deprecated: Use LogPatternRules.ProtoReflect.Descriptor instead.
+func (*LogPatternRules) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{45}
+}
 
-func (*AgentMessage_FlamegraphResponse) isAgentMessage_Message() {}
+func (x *LogPatternRules) GetRules() []*LogPatternRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
 
-type ServerMessage struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Message:
-	//
-	//	*ServerMessage_Ack
-	//	*ServerMessage_FlamegraphRequest
-	Message       isServerMessage_Message `protobuf_oneof:"message"`
+type LogPatternRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	PodName       string                 `protobuf:"bytes,2,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	Pattern       string                 `protobuf:"bytes,3,opt,name=pattern,proto3" json:"pattern,omitempty"` // Go regular expression (RE2 syntax)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServerMessage) Reset() {
-	*x = ServerMessage{}
-	mi := &file_proto_gobservability_proto_msgTypes[18]
+func (x *LogPatternRule) Reset() {
+	*x = LogPatternRule{}
+	mi := &file_proto_gobservability_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServerMessage) String() string {
+func (x *LogPatternRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServerMessage) ProtoMessage() {}
+func (*LogPatternRule) ProtoMessage() {}
 
-func (x *ServerMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[18]
+func (x *LogPatternRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1703,63 +4002,122 @@ func (x *ServerMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
-func (*ServerMessage) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use LogPatternRule.ProtoReflect.Descriptor instead.
+func (*LogPatternRule) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *ServerMessage) GetMessage() isServerMessage_Message {
+func (x *LogPatternRule) GetRuleId() string {
 	if x != nil {
-		return x.Message
+		return x.RuleId
 	}
-	return nil
+	return ""
 }
 
-func (x *ServerMessage) GetAck() *ServerAck {
+func (x *LogPatternRule) GetPodName() string {
 	if x != nil {
-		if x, ok := x.Message.(*ServerMessage_Ack); ok {
-			return x.Ack
-		}
+		return x.PodName
 	}
-	return nil
+	return ""
 }
 
-func (x *ServerMessage) GetFlamegraphRequest() *FlamegraphRequest {
+func (x *LogPatternRule) GetPattern() string {
 	if x != nil {
-		if x, ok := x.Message.(*ServerMessage_FlamegraphRequest); ok {
-			return x.FlamegraphRequest
+		return x.Pattern
+	}
+	return ""
+}
+
+// Regex match counts reported by the agent for a log pattern rule
+type LogPatternCount struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	RuleId            string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	PodName           string                 `protobuf:"bytes,2,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	MatchesLastMinute uint64                 `protobuf:"varint,3,opt,name=matches_last_minute,json=matchesLastMinute,proto3" json:"matches_last_minute,omitempty"` // Matching lines over the last 60 seconds
+	TotalMatches      uint64                 `protobuf:"varint,4,opt,name=total_matches,json=totalMatches,proto3" json:"total_matches,omitempty"`                  // Matching lines since the rule was received
+	Error             string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`                                                     // Error tailing the logs (pod not found, invalid pattern...)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *LogPatternCount) Reset() {
+	*x = LogPatternCount{}
+	mi := &file_proto_gobservability_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogPatternCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogPatternCount) ProtoMessage() {}
+
+func (x *LogPatternCount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type isServerMessage_Message interface {
-	isServerMessage_Message()
+// Deprecated: Use LogPatternCount.ProtoReflect.Descriptor instead.
+func (*LogPatternCount) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{47}
 }
 
-type ServerMessage_Ack struct {
-	Ack *ServerAck `protobuf:"bytes,1,opt,name=ack,proto3,oneof"`
+func (x *LogPatternCount) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
 }
 
-type ServerMessage_FlamegraphRequest struct {
-	FlamegraphRequest *FlamegraphRequest `protobuf:"bytes,2,opt,name=flamegraph_request,json=flamegraphRequest,proto3,oneof"`
+func (x *LogPatternCount) GetPodName() string {
+	if x != nil {
+		return x.PodName
+	}
+	return ""
 }
 
-func (*ServerMessage_Ack) isServerMessage_Message() {}
+func (x *LogPatternCount) GetMatchesLastMinute() uint64 {
+	if x != nil {
+		return x.MatchesLastMinute
+	}
+	return 0
+}
 
-func (*ServerMessage_FlamegraphRequest) isServerMessage_Message() {}
+func (x *LogPatternCount) GetTotalMatches() uint64 {
+	if x != nil {
+		return x.TotalMatches
+	}
+	return 0
+}
+
+func (x *LogPatternCount) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
 
 type AgentHello struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeName      string                 `protobuf:"bytes,1,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
-	AgentVersion  string                 `protobuf:"bytes,2,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	NodeName        string                 `protobuf:"bytes,1,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	AgentVersion    string                 `protobuf:"bytes,2,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	ProtocolVersion uint32                 `protobuf:"varint,3,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // 0 for agents that do not report their features
+	Features        []*AgentFeature        `protobuf:"bytes,4,rep,name=features,proto3" json:"features,omitempty"`                                       // Probed by the agent at startup
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *AgentHello) Reset() {
 	*x = AgentHello{}
-	mi := &file_proto_gobservability_proto_msgTypes[19]
+	mi := &file_proto_gobservability_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1771,7 +4129,7 @@ func (x *AgentHello) String() string {
 func (*AgentHello) ProtoMessage() {}
 
 func (x *AgentHello) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[19]
+	mi := &file_proto_gobservability_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1784,7 +4142,7 @@ func (x *AgentHello) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentHello.ProtoReflect.Descriptor instead.
 func (*AgentHello) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{19}
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{48}
 }
 
 func (x *AgentHello) GetNodeName() string {
@@ -1801,16 +4159,92 @@ func (x *AgentHello) GetAgentVersion() string {
 	return ""
 }
 
-type ServerAck struct {
+func (x *AgentHello) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *AgentHello) GetFeatures() []*AgentFeature {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+// Feature the server can request from an agent, the reason explains why it is unavailable
+type AgentFeature struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // flamegraph, kernel_stacks, logs, log_patterns, pod_details, agent_config
+	Available     bool                   `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *AgentFeature) Reset() {
+	*x = AgentFeature{}
+	mi := &file_proto_gobservability_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentFeature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentFeature) ProtoMessage() {}
+
+func (x *AgentFeature) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_gobservability_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentFeature.ProtoReflect.Descriptor instead.
+func (*AgentFeature) Descriptor() ([]byte, []int) {
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *AgentFeature) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AgentFeature) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *AgentFeature) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ServerAck struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Message         string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	ProtocolVersion uint32                 `protobuf:"varint,2,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // Protocol version of the server
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
 func (x *ServerAck) Reset() {
 	*x = ServerAck{}
-	mi := &file_proto_gobservability_proto_msgTypes[20]
+	mi := &file_proto_gobservability_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1822,7 +4256,7 @@ func (x *ServerAck) String() string {
 func (*ServerAck) ProtoMessage() {}
 
 func (x *ServerAck) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_gobservability_proto_msgTypes[20]
+	mi := &file_proto_gobservability_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1835,7 +4269,7 @@ func (x *ServerAck) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerAck.ProtoReflect.Descriptor instead.
 func (*ServerAck) Descriptor() ([]byte, []int) {
-	return file_proto_gobservability_proto_rawDescGZIP(), []int{20}
+	return file_proto_gobservability_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *ServerAck) GetMessage() string {
@@ -1845,15 +4279,43 @@ func (x *ServerAck) GetMessage() string {
 	return ""
 }
 
+func (x *ServerAck) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
 var File_proto_gobservability_proto protoreflect.FileDescriptor
 
 const file_proto_gobservability_proto_rawDesc = "" +
 	"\n" +
-	"\x1aproto/gobservability.proto\x12\x0egobservability\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa0\x01\n" +
+	"\x1aproto/gobservability.proto\x12\x0egobservability\x1a\x1fgoogle/protobuf/timestamp.proto\"\xeb\x02\n" +
 	"\x10NodeStatsRequest\x12\x1b\n" +
 	"\tnode_name\x18\x01 \x01(\tR\bnodeName\x128\n" +
 	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x125\n" +
-	"\ametrics\x18\x03 \x01(\v2\x1b.gobservability.NodeMetricsR\ametrics\"'\n" +
+	"\ametrics\x18\x03 \x01(\v2\x1b.gobservability.NodeMetricsR\ametrics\x12\x1a\n" +
+	"\bbackfill\x18\x04 \x01(\bR\bbackfill\x12\x1a\n" +
+	"\bsequence\x18\x05 \x01(\x04R\bsequence\x126\n" +
+	"\x05delta\x18\x06 \x01(\v2 .gobservability.NodeMetricsDeltaR\x05delta\x124\n" +
+	"\asamples\x18\a \x03(\v2\x1a.gobservability.NodeSampleR\asamples\x12#\n" +
+	"\rcollection_ms\x18\b \x01(\x01R\fcollectionMs\"\x8e\x02\n" +
+	"\n" +
+	"NodeSample\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12*\n" +
+	"\x03cpu\x18\x02 \x01(\v2\x18.gobservability.CPUStatsR\x03cpu\x123\n" +
+	"\x06memory\x18\x03 \x01(\v2\x1b.gobservability.MemoryStatsR\x06memory\x126\n" +
+	"\anetwork\x18\x04 \x01(\v2\x1c.gobservability.NetworkStatsR\anetwork\x12-\n" +
+	"\x04disk\x18\x05 \x01(\v2\x19.gobservability.DiskStatsR\x04disk\"\xb4\x01\n" +
+	"\x10NodeMetricsDelta\x125\n" +
+	"\achanged\x18\x01 \x01(\v2\x1b.gobservability.NodeMetricsR\achanged\x12\x18\n" +
+	"\acleared\x18\x02 \x03(\tR\acleared\x12,\n" +
+	"\x04pods\x18\x03 \x03(\v2\x18.gobservability.PodDeltaR\x04pods\x12!\n" +
+	"\fremoved_pods\x18\x04 \x03(\tR\vremovedPods\"e\n" +
+	"\bPodDelta\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\achanged\x18\x02 \x01(\v2\x13.gobservability.PodR\achanged\x12\x18\n" +
+	"\acleared\x18\x03 \x03(\tR\acleared\"'\n" +
 	"\rStatsResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\"\x9e\x01\n" +
 	"\x11FlamegraphRequest\x12\x1b\n" +
@@ -1868,13 +4330,57 @@ const file_proto_gobservability_proto_rawDesc = "" +
 	"\x06format\x18\x02 \x01(\tR\x06format\x12\x14\n" +
 	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1d\n" +
 	"\n" +
-	"request_id\x18\x04 \x01(\tR\trequestId\"\xfe\x01\n" +
+	"request_id\x18\x04 \x01(\tR\trequestId\"\xf8\x04\n" +
 	"\vNodeMetrics\x12*\n" +
 	"\x03cpu\x18\x01 \x01(\v2\x18.gobservability.CPUStatsR\x03cpu\x123\n" +
 	"\x06memory\x18\x02 \x01(\v2\x1b.gobservability.MemoryStatsR\x06memory\x126\n" +
 	"\anetwork\x18\x03 \x01(\v2\x1c.gobservability.NetworkStatsR\anetwork\x12-\n" +
 	"\x04disk\x18\x04 \x01(\v2\x19.gobservability.DiskStatsR\x04disk\x12'\n" +
-	"\x04pods\x18\x05 \x03(\v2\x13.gobservability.PodR\x04pods\"\xef\x01\n" +
+	"\x04pods\x18\x05 \x03(\v2\x13.gobservability.PodR\x04pods\x12B\n" +
+	"\flog_patterns\x18\x06 \x03(\v2\x1f.gobservability.LogPatternCountR\vlogPatterns\x12D\n" +
+	"\x0ecustom_metrics\x18\a \x03(\v2\x1d.gobservability.CustomMetricsR\rcustomMetrics\x125\n" +
+	"\x06pushed\x18\b \x03(\v2\x1d.gobservability.PushedMetricsR\x06pushed\x12?\n" +
+	"\n" +
+	"collectors\x18\t \x03(\v2\x1f.gobservability.CollectorStatusR\n" +
+	"collectors\x124\n" +
+	"\x06buffer\x18\n" +
+	" \x01(\v2\x1c.gobservability.BufferStatusR\x06buffer\x12@\n" +
+	"\n" +
+	"connection\x18\v \x01(\v2 .gobservability.ConnectionStatusR\n" +
+	"connection\"\xdf\x02\n" +
+	"\x10ConnectionStatus\x12\x1a\n" +
+	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12\x1c\n" +
+	"\tconnected\x18\x02 \x01(\bR\tconnected\x12\x1e\n" +
+	"\n" +
+	"reconnects\x18\x03 \x01(\x04R\n" +
+	"reconnects\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x04 \x01(\tR\tlastError\x12>\n" +
+	"\rlast_error_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vlastErrorAt\x12C\n" +
+	"\x0fconnected_since\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0econnectedSince\x12$\n" +
+	"\x0elast_outage_ms\x18\a \x01(\x03R\flastOutageMs\x12'\n" +
+	"\x0fdisconnected_ms\x18\b \x01(\x03R\x0edisconnectedMs\"\x95\x02\n" +
+	"\fBufferStatus\x12\x1a\n" +
+	"\bpayloads\x18\x01 \x01(\x05R\bpayloads\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\x12\x1d\n" +
+	"\n" +
+	"disk_bytes\x18\x03 \x01(\x03R\tdiskBytes\x12!\n" +
+	"\fmax_payloads\x18\x04 \x01(\x05R\vmaxPayloads\x12\x1b\n" +
+	"\tmax_bytes\x18\x05 \x01(\x03R\bmaxBytes\x12$\n" +
+	"\x0emax_disk_bytes\x18\x06 \x01(\x03R\fmaxDiskBytes\x12\x18\n" +
+	"\adropped\x18\a \x01(\x04R\adropped\x12\x18\n" +
+	"\aspilled\x18\b \x01(\x04R\aspilled\x12\x1a\n" +
+	"\breplayed\x18\t \x01(\x04R\breplayed\"\xf2\x01\n" +
+	"\x0fCollectorStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x12\x1f\n" +
+	"\vinterval_ms\x18\x03 \x01(\x03R\n" +
+	"intervalMs\x12\"\n" +
+	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\x125\n" +
+	"\blast_run\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\alastRun\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x01R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xef\x01\n" +
 	"\bCPUStats\x12\x12\n" +
 	"\x04user\x18\x01 \x01(\x03R\x04user\x12\x12\n" +
 	"\x04nice\x18\x02 \x01(\x03R\x04nice\x12\x16\n" +
@@ -1925,7 +4431,7 @@ const file_proto_gobservability_proto_rawDesc = "" +
 	"write_rate\x18\n" +
 	" \x01(\x01R\twriteRate\x12\x1d\n" +
 	"\n" +
-	"total_rate\x18\v \x01(\x01R\ttotalRate\"\xda\x02\n" +
+	"total_rate\x18\v \x01(\x01R\ttotalRate\"\xb4\x03\n" +
 	"\x03Pod\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
 	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\x12\x10\n" +
@@ -1935,13 +4441,45 @@ const file_proto_gobservability_proto_rawDesc = "" +
 	"\vpid_details\x18\x05 \x01(\v2\x1a.gobservability.PidDetailsR\n" +
 	"pidDetails\x12E\n" +
 	"\x0fresource_limits\x18\x06 \x01(\v2\x1c.gobservability.ResourceInfoR\x0eresourceLimits\x12I\n" +
-	"\x11resource_requests\x18\a \x01(\v2\x1c.gobservability.ResourceInfoR\x10resourceRequests\"\xe0\x01\n" +
+	"\x11resource_requests\x18\a \x01(\v2\x1c.gobservability.ResourceInfoR\x10resourceRequests\x12\x1c\n" +
+	"\tnamespace\x18\b \x01(\tR\tnamespace\x12\x10\n" +
+	"\x03uid\x18\t \x01(\tR\x03uid\x12\x0e\n" +
+	"\x02ip\x18\n" +
+	" \x01(\tR\x02ip\x12\x18\n" +
+	"\apartial\x18\v \x01(\bR\apartial\"\xd1\x02\n" +
 	"\n" +
 	"PodMetrics\x12-\n" +
 	"\x03cpu\x18\x01 \x01(\v2\x1b.gobservability.PodCPUStatsR\x03cpu\x126\n" +
 	"\x06memory\x18\x02 \x01(\v2\x1e.gobservability.PodMemoryStatsR\x06memory\x129\n" +
 	"\anetwork\x18\x03 \x01(\v2\x1f.gobservability.PodNetworkStatsR\anetwork\x120\n" +
-	"\x04disk\x18\x04 \x01(\v2\x1c.gobservability.PodDiskStatsR\x04disk\"Z\n" +
+	"\x04disk\x18\x04 \x01(\v2\x1c.gobservability.PodDiskStatsR\x04disk\x129\n" +
+	"\astorage\x18\x05 \x01(\v2\x1f.gobservability.PodStorageStatsR\astorage\x124\n" +
+	"\x06scrape\x18\x06 \x01(\v2\x1c.gobservability.ScrapeResultR\x06scrape\"\xc9\x01\n" +
+	"\fMetricSample\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12@\n" +
+	"\x06labels\x18\x02 \x03(\v2(.gobservability.MetricSample.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8f\x01\n" +
+	"\fScrapeResult\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x126\n" +
+	"\asamples\x18\x02 \x03(\v2\x1c.gobservability.MetricSampleR\asamples\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x01R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x9d\x01\n" +
+	"\rPushedMetrics\x12\x19\n" +
+	"\bpod_name\x18\x01 \x01(\tR\apodName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x1b\n" +
+	"\tsource_ip\x18\x03 \x01(\tR\bsourceIp\x126\n" +
+	"\asamples\x18\x04 \x03(\v2\x1c.gobservability.MetricSampleR\asamples\"\x96\x01\n" +
+	"\rCustomMetrics\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x126\n" +
+	"\asamples\x18\x02 \x03(\v2\x1c.gobservability.MetricSampleR\asamples\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x01R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"Z\n" +
 	"\vPodCPUStats\x12\x14\n" +
 	"\x05utime\x18\x01 \x01(\x04R\x05utime\x12\x14\n" +
 	"\x05stime\x18\x02 \x01(\x04R\x05stime\x12\x1f\n" +
@@ -1959,10 +4497,27 @@ const file_proto_gobservability_proto_rawDesc = "" +
 	"\n" +
 	"read_bytes\x18\x01 \x01(\x04R\treadBytes\x12\x1f\n" +
 	"\vwrite_bytes\x18\x02 \x01(\x04R\n" +
-	"writeBytes\"8\n" +
+	"writeBytes\"\xc1\x02\n" +
+	"\x0fPodStorageStats\x125\n" +
+	"\avolumes\x18\x01 \x03(\v2\x1b.gobservability.VolumeStatsR\avolumes\x120\n" +
+	"\x14writable_layer_bytes\x18\x02 \x01(\x04R\x12writableLayerBytes\x122\n" +
+	"\x15writable_layer_inodes\x18\x03 \x01(\x04R\x13writableLayerInodes\x120\n" +
+	"\x14ephemeral_used_bytes\x18\x04 \x01(\x04R\x12ephemeralUsedBytes\x122\n" +
+	"\x15ephemeral_limit_bytes\x18\x05 \x01(\x04R\x13ephemeralLimitBytes\x12+\n" +
+	"\x11ephemeral_percent\x18\x06 \x01(\x01R\x10ephemeralPercent\"\xc1\x01\n" +
+	"\vVolumeStats\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x03 \x01(\x04R\tusedBytes\x12\x1f\n" +
+	"\vused_inodes\x18\x04 \x01(\x04R\n" +
+	"usedInodes\x12%\n" +
+	"\x0ecapacity_bytes\x18\x05 \x01(\x04R\rcapacityBytes\x12#\n" +
+	"\rusage_percent\x18\x06 \x01(\x01R\fusagePercent\"e\n" +
 	"\fResourceInfo\x12\x10\n" +
 	"\x03cpu\x18\x01 \x01(\tR\x03cpu\x12\x16\n" +
-	"\x06memory\x18\x02 \x01(\tR\x06memory\"\xf2\b\n" +
+	"\x06memory\x18\x02 \x01(\tR\x06memory\x12+\n" +
+	"\x11ephemeral_storage\x18\x03 \x01(\tR\x10ephemeralStorage\"\xf2\b\n" +
 	"\n" +
 	"PidDetails\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
@@ -2001,22 +4556,129 @@ const file_proto_gobservability_proto_rawDesc = "" +
 	"\x06vm_stk\x18  \x01(\x04R\x05vmStk\x12\x15\n" +
 	"\x06vm_exe\x18! \x01(\x04R\x05vmExe\x12\x15\n" +
 	"\x06vm_lib\x18\" \x01(\x04R\x05vmLib\x12\x17\n" +
-	"\avm_swap\x18# \x01(\x04R\x06vmSwap\"\xde\x01\n" +
+	"\avm_swap\x18# \x01(\x04R\x06vmSwap\"\xcd\x04\n" +
 	"\fAgentMessage\x122\n" +
 	"\x05hello\x18\x01 \x01(\v2\x1a.gobservability.AgentHelloH\x00R\x05hello\x128\n" +
 	"\x05stats\x18\x02 \x01(\v2 .gobservability.NodeStatsRequestH\x00R\x05stats\x12U\n" +
-	"\x13flamegraph_response\x18\x03 \x01(\v2\".gobservability.FlamegraphResponseH\x00R\x12flamegraphResponseB\t\n" +
-	"\amessage\"\x9d\x01\n" +
+	"\x13flamegraph_response\x18\x03 \x01(\v2\".gobservability.FlamegraphResponseH\x00R\x12flamegraphResponse\x127\n" +
+	"\tlog_chunk\x18\x04 \x01(\v2\x18.gobservability.LogChunkH\x00R\blogChunk\x12V\n" +
+	"\x14pod_details_response\x18\x05 \x01(\v2\".gobservability.PodDetailsResponseH\x00R\x12podDetailsResponse\x12J\n" +
+	"\x10agent_config_ack\x18\x06 \x01(\v2\x1e.gobservability.AgentConfigAckH\x00R\x0eagentConfigAck\x128\n" +
+	"\agoodbye\x18\a \x01(\v2\x1c.gobservability.AgentGoodbyeH\x00R\agoodbye\x12*\n" +
+	"\x04pong\x18\b \x01(\v2\x14.gobservability.PongH\x00R\x04pong\x12*\n" +
+	"\x04ping\x18\t \x01(\v2\x14.gobservability.PingH\x00R\x04pingB\t\n" +
+	"\amessage\"&\n" +
+	"\fAgentGoodbye\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"\x8f\x05\n" +
 	"\rServerMessage\x12-\n" +
 	"\x03ack\x18\x01 \x01(\v2\x19.gobservability.ServerAckH\x00R\x03ack\x12R\n" +
-	"\x12flamegraph_request\x18\x02 \x01(\v2!.gobservability.FlamegraphRequestH\x00R\x11flamegraphRequestB\t\n" +
-	"\amessage\"N\n" +
+	"\x12flamegraph_request\x18\x02 \x01(\v2!.gobservability.FlamegraphRequestH\x00R\x11flamegraphRequest\x12=\n" +
+	"\vlog_request\x18\x03 \x01(\v2\x1a.gobservability.LogRequestH\x00R\n" +
+	"logRequest\x12M\n" +
+	"\x11log_pattern_rules\x18\x04 \x01(\v2\x1f.gobservability.LogPatternRulesH\x00R\x0flogPatternRules\x12S\n" +
+	"\x13pod_details_request\x18\x05 \x01(\v2!.gobservability.PodDetailsRequestH\x00R\x11podDetailsRequest\x12@\n" +
+	"\fagent_config\x18\x06 \x01(\v2\x1b.gobservability.AgentConfigH\x00R\vagentConfig\x127\n" +
+	"\x06resync\x18\a \x01(\v2\x1d.gobservability.ResyncRequestH\x00R\x06resync\x12*\n" +
+	"\x04ping\x18\b \x01(\v2\x14.gobservability.PingH\x00R\x04ping\x12:\n" +
+	"\acollect\x18\t \x01(\v2\x1e.gobservability.CollectRequestH\x00R\acollect\x12*\n" +
+	"\x04pong\x18\n" +
+	" \x01(\v2\x14.gobservability.PongH\x00R\x04pongB\t\n" +
+	"\amessage\"\x16\n" +
+	"\x04Ping\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"Q\n" +
+	"\x04Pong\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x129\n" +
+	"\n" +
+	"agent_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tagentTime\"(\n" +
+	"\x0eCollectRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"'\n" +
+	"\rResyncRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"\x96\x03\n" +
+	"\vAgentConfig\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x1f\n" +
+	"\vinterval_ms\x18\x02 \x01(\x03R\n" +
+	"intervalMs\x12-\n" +
+	"\x12enabled_collectors\x18\x03 \x03(\tR\x11enabledCollectors\x12/\n" +
+	"\x13disabled_collectors\x18\x04 \x03(\tR\x12disabledCollectors\x12k\n" +
+	"\x16collector_intervals_ms\x18\x05 \x03(\v25.gobservability.AgentConfig.CollectorIntervalsMsEntryR\x14collectorIntervalsMs\x126\n" +
+	"\afilters\x18\x06 \x01(\v2\x1c.gobservability.AgentFiltersR\afilters\x1aG\n" +
+	"\x19CollectorIntervalsMsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xec\x01\n" +
+	"\fAgentFilters\x12:\n" +
+	"\n" +
+	"namespaces\x18\x01 \x01(\v2\x1a.gobservability.FilterRuleR\n" +
+	"namespaces\x12.\n" +
+	"\x04pods\x18\x02 \x01(\v2\x1a.gobservability.FilterRuleR\x04pods\x12:\n" +
+	"\n" +
+	"interfaces\x18\x03 \x01(\v2\x1a.gobservability.FilterRuleR\n" +
+	"interfaces\x124\n" +
+	"\adevices\x18\x04 \x01(\v2\x1a.gobservability.FilterRuleR\adevices\"@\n" +
+	"\n" +
+	"FilterRule\x12\x18\n" +
+	"\ainclude\x18\x01 \x03(\tR\ainclude\x12\x18\n" +
+	"\aexclude\x18\x02 \x03(\tR\aexclude\"Z\n" +
+	"\x0eAgentConfigAck\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x18\n" +
+	"\aapplied\x18\x02 \x01(\bR\aapplied\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"j\n" +
+	"\x11PodDetailsRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
+	"\tnode_name\x18\x02 \x01(\tR\bnodeName\x12\x19\n" +
+	"\bpod_name\x18\x03 \x01(\tR\apodName\"\x7f\n" +
+	"\x12PodDetailsResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x124\n" +
+	"\adetails\x18\x02 \x01(\v2\x1a.gobservability.PidDetailsR\adetails\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xcc\x01\n" +
+	"\n" +
+	"LogRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
+	"\tnode_name\x18\x02 \x01(\tR\bnodeName\x12\x19\n" +
+	"\bpod_name\x18\x03 \x01(\tR\apodName\x12\x1c\n" +
+	"\tcontainer\x18\x04 \x01(\tR\tcontainer\x12\x1d\n" +
+	"\n" +
+	"tail_lines\x18\x05 \x01(\x05R\ttailLines\x12\x16\n" +
+	"\x06follow\x18\x06 \x01(\bR\x06follow\x12\x12\n" +
+	"\x04stop\x18\a \x01(\bR\x04stop\"\x80\x01\n" +
+	"\bLogChunk\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12-\n" +
+	"\x05lines\x18\x02 \x03(\v2\x17.gobservability.LogLineR\x05lines\x12\x10\n" +
+	"\x03eof\x18\x03 \x01(\bR\x03eof\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x93\x01\n" +
+	"\aLogLine\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1c\n" +
+	"\tcontainer\x18\x02 \x01(\tR\tcontainer\x12\x16\n" +
+	"\x06stream\x18\x03 \x01(\tR\x06stream\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"G\n" +
+	"\x0fLogPatternRules\x124\n" +
+	"\x05rules\x18\x01 \x03(\v2\x1e.gobservability.LogPatternRuleR\x05rules\"^\n" +
+	"\x0eLogPatternRule\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x19\n" +
+	"\bpod_name\x18\x02 \x01(\tR\apodName\x12\x18\n" +
+	"\apattern\x18\x03 \x01(\tR\apattern\"\xb0\x01\n" +
+	"\x0fLogPatternCount\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x19\n" +
+	"\bpod_name\x18\x02 \x01(\tR\apodName\x12.\n" +
+	"\x13matches_last_minute\x18\x03 \x01(\x04R\x11matchesLastMinute\x12#\n" +
+	"\rtotal_matches\x18\x04 \x01(\x04R\ftotalMatches\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\xb3\x01\n" +
 	"\n" +
 	"AgentHello\x12\x1b\n" +
 	"\tnode_name\x18\x01 \x01(\tR\bnodeName\x12#\n" +
-	"\ragent_version\x18\x02 \x01(\tR\fagentVersion\"%\n" +
+	"\ragent_version\x18\x02 \x01(\tR\fagentVersion\x12)\n" +
+	"\x10protocol_version\x18\x03 \x01(\rR\x0fprotocolVersion\x128\n" +
+	"\bfeatures\x18\x04 \x03(\v2\x1c.gobservability.AgentFeatureR\bfeatures\"X\n" +
+	"\fAgentFeature\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tavailable\x18\x02 \x01(\bR\tavailable\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"P\n" +
 	"\tServerAck\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2\x88\x02\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12)\n" +
+	"\x10protocol_version\x18\x02 \x01(\rR\x0fprotocolVersion2\x88\x02\n" +
 	"\vNodeService\x12L\n" +
 	"\tSendStats\x12 .gobservability.NodeStatsRequest\x1a\x1d.gobservability.StatsResponse\x12[\n" +
 	"\x12GenerateFlamegraph\x12!.gobservability.FlamegraphRequest\x1a\".gobservability.FlamegraphResponse\x12N\n" +
@@ -2034,63 +4696,147 @@ func file_proto_gobservability_proto_rawDescGZIP() []byte {
 	return file_proto_gobservability_proto_rawDescData
 }
 
-var file_proto_gobservability_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_proto_gobservability_proto_msgTypes = make([]protoimpl.MessageInfo, 53)
 var file_proto_gobservability_proto_goTypes = []any{
 	(*NodeStatsRequest)(nil),      // 0: gobservability.NodeStatsRequest
-	(*StatsResponse)(nil),         // 1: gobservability.StatsResponse
-	(*FlamegraphRequest)(nil),     // 2: gobservability.FlamegraphRequest
-	(*FlamegraphResponse)(nil),    // 3: gobservability.FlamegraphResponse
-	(*NodeMetrics)(nil),           // 4: gobservability.NodeMetrics
-	(*CPUStats)(nil),              // 5: gobservability.CPUStats
-	(*MemoryStats)(nil),           // 6: gobservability.MemoryStats
-	(*NetworkStats)(nil),          // 7: gobservability.NetworkStats
-	(*DiskStats)(nil),             // 8: gobservability.DiskStats
-	(*Pod)(nil),                   // 9: gobservability.Pod
-	(*PodMetrics)(nil),            // 10: gobservability.PodMetrics
-	(*PodCPUStats)(nil),           // 11: gobservability.PodCPUStats
-	(*PodMemoryStats)(nil),        // 12: gobservability.PodMemoryStats
-	(*PodNetworkStats)(nil),       // 13: gobservability.PodNetworkStats
-	(*PodDiskStats)(nil),          // 14: gobservability.PodDiskStats
-	(*ResourceInfo)(nil),          // 15: gobservability.ResourceInfo
-	(*PidDetails)(nil),            // 16: gobservability.PidDetails
-	(*AgentMessage)(nil),          // 17: gobservability.AgentMessage
-	(*ServerMessage)(nil),         // 18: gobservability.ServerMessage
-	(*AgentHello)(nil),            // 19: gobservability.AgentHello
-	(*ServerAck)(nil),             // 20: gobservability.ServerAck
-	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+	(*NodeSample)(nil),            // 1: gobservability.NodeSample
+	(*NodeMetricsDelta)(nil),      // 2: gobservability.NodeMetricsDelta
+	(*PodDelta)(nil),              // 3: gobservability.PodDelta
+	(*StatsResponse)(nil),         // 4: gobservability.StatsResponse
+	(*FlamegraphRequest)(nil),     // 5: gobservability.FlamegraphRequest
+	(*FlamegraphResponse)(nil),    // 6: gobservability.FlamegraphResponse
+	(*NodeMetrics)(nil),           // 7: gobservability.NodeMetrics
+	(*ConnectionStatus)(nil),      // 8: gobservability.ConnectionStatus
+	(*BufferStatus)(nil),          // 9: gobservability.BufferStatus
+	(*CollectorStatus)(nil),       // 10: gobservability.CollectorStatus
+	(*CPUStats)(nil),              // 11: gobservability.CPUStats
+	(*MemoryStats)(nil),           // 12: gobservability.MemoryStats
+	(*NetworkStats)(nil),          // 13: gobservability.NetworkStats
+	(*DiskStats)(nil),             // 14: gobservability.DiskStats
+	(*Pod)(nil),                   // 15: gobservability.Pod
+	(*PodMetrics)(nil),            // 16: gobservability.PodMetrics
+	(*MetricSample)(nil),          // 17: gobservability.MetricSample
+	(*ScrapeResult)(nil),          // 18: gobservability.ScrapeResult
+	(*PushedMetrics)(nil),         // 19: gobservability.PushedMetrics
+	(*CustomMetrics)(nil),         // 20: gobservability.CustomMetrics
+	(*PodCPUStats)(nil),           // 21: gobservability.PodCPUStats
+	(*PodMemoryStats)(nil),        // 22: gobservability.PodMemoryStats
+	(*PodNetworkStats)(nil),       // 23: gobservability.PodNetworkStats
+	(*PodDiskStats)(nil),          // 24: gobservability.PodDiskStats
+	(*PodStorageStats)(nil),       // 25: gobservability.PodStorageStats
+	(*VolumeStats)(nil),           // 26: gobservability.VolumeStats
+	(*ResourceInfo)(nil),          // 27: gobservability.ResourceInfo
+	(*PidDetails)(nil),            // 28: gobservability.PidDetails
+	(*AgentMessage)(nil),          // 29: gobservability.AgentMessage
+	(*AgentGoodbye)(nil),          // 30: gobservability.AgentGoodbye
+	(*ServerMessage)(nil),         // 31: gobservability.ServerMessage
+	(*Ping)(nil),                  // 32: gobservability.Ping
+	(*Pong)(nil),                  // 33: gobservability.Pong
+	(*CollectRequest)(nil),        // 34: gobservability.CollectRequest
+	(*ResyncRequest)(nil),         // 35: gobservability.ResyncRequest
+	(*AgentConfig)(nil),           // 36: gobservability.AgentConfig
+	(*AgentFilters)(nil),          // 37: gobservability.AgentFilters
+	(*FilterRule)(nil),            // 38: gobservability.FilterRule
+	(*AgentConfigAck)(nil),        // 39: gobservability.AgentConfigAck
+	(*PodDetailsRequest)(nil),     // 40: gobservability.PodDetailsRequest
+	(*PodDetailsResponse)(nil),    // 41: gobservability.PodDetailsResponse
+	(*LogRequest)(nil),            // 42: gobservability.LogRequest
+	(*LogChunk)(nil),              // 43: gobservability.LogChunk
+	(*LogLine)(nil),               // 44: gobservability.LogLine
+	(*LogPatternRules)(nil),       // 45: gobservability.LogPatternRules
+	(*LogPatternRule)(nil),        // 46: gobservability.LogPatternRule
+	(*LogPatternCount)(nil),       // 47: gobservability.LogPatternCount
+	(*AgentHello)(nil),            // 48: gobservability.AgentHello
+	(*AgentFeature)(nil),          // 49: gobservability.AgentFeature
+	(*ServerAck)(nil),             // 50: gobservability.ServerAck
+	nil,                           // 51: gobservability.MetricSample.LabelsEntry
+	nil,                           // 52: gobservability.AgentConfig.CollectorIntervalsMsEntry
+	(*timestamppb.Timestamp)(nil), // 53: google.protobuf.Timestamp
 }
 var file_proto_gobservability_proto_depIdxs = []int32{
-	21, // 0: gobservability.NodeStatsRequest.timestamp:type_name -> google.protobuf.Timestamp
-	4,  // 1: gobservability.NodeStatsRequest.metrics:type_name -> gobservability.NodeMetrics
-	5,  // 2: gobservability.NodeMetrics.cpu:type_name -> gobservability.CPUStats
-	6,  // 3: gobservability.NodeMetrics.memory:type_name -> gobservability.MemoryStats
-	7,  // 4: gobservability.NodeMetrics.network:type_name -> gobservability.NetworkStats
-	8,  // 5: gobservability.NodeMetrics.disk:type_name -> gobservability.DiskStats
-	9,  // 6: gobservability.NodeMetrics.pods:type_name -> gobservability.Pod
-	10, // 7: gobservability.Pod.pod_metrics:type_name -> gobservability.PodMetrics
-	16, // 8: gobservability.Pod.pid_details:type_name -> gobservability.PidDetails
-	15, // 9: gobservability.Pod.resource_limits:type_name -> gobservability.ResourceInfo
-	15, // 10: gobservability.Pod.resource_requests:type_name -> gobservability.ResourceInfo
-	11, // 11: gobservability.PodMetrics.cpu:type_name -> gobservability.PodCPUStats
-	12, // 12: gobservability.PodMetrics.memory:type_name -> gobservability.PodMemoryStats
-	13, // 13: gobservability.PodMetrics.network:type_name -> gobservability.PodNetworkStats
-	14, // 14: gobservability.PodMetrics.disk:type_name -> gobservability.PodDiskStats
-	19, // 15: gobservability.AgentMessage.hello:type_name -> gobservability.AgentHello
-	0,  // 16: gobservability.AgentMessage.stats:type_name -> gobservability.NodeStatsRequest
-	3,  // 17: gobservability.AgentMessage.flamegraph_response:type_name -> gobservability.FlamegraphResponse
-	20, // 18: gobservability.ServerMessage.ack:type_name -> gobservability.ServerAck
-	2,  // 19: gobservability.ServerMessage.flamegraph_request:type_name -> gobservability.FlamegraphRequest
-	0,  // 20: gobservability.NodeService.SendStats:input_type -> gobservability.NodeStatsRequest
-	2,  // 21: gobservability.NodeService.GenerateFlamegraph:input_type -> gobservability.FlamegraphRequest
-	17, // 22: gobservability.NodeService.AgentStream:input_type -> gobservability.AgentMessage
-	1,  // 23: gobservability.NodeService.SendStats:output_type -> gobservability.StatsResponse
-	3,  // 24: gobservability.NodeService.GenerateFlamegraph:output_type -> gobservability.FlamegraphResponse
-	18, // 25: gobservability.NodeService.AgentStream:output_type -> gobservability.ServerMessage
-	23, // [23:26] is the sub-list for method output_type
-	20, // [20:23] is the sub-list for method input_type
-	20, // [20:20] is the sub-list for extension type_name
-	20, // [20:20] is the sub-list for extension extendee
-	0,  // [0:20] is the sub-list for field type_name
+	53, // 0: gobservability.NodeStatsRequest.timestamp:type_name -> google.protobuf.Timestamp
+	7,  // 1: gobservability.NodeStatsRequest.metrics:type_name -> gobservability.NodeMetrics
+	2,  // 2: gobservability.NodeStatsRequest.delta:type_name -> gobservability.NodeMetricsDelta
+	1,  // 3: gobservability.NodeStatsRequest.samples:type_name -> gobservability.NodeSample
+	53, // 4: gobservability.NodeSample.timestamp:type_name -> google.protobuf.Timestamp
+	11, // 5: gobservability.NodeSample.cpu:type_name -> gobservability.CPUStats
+	12, // 6: gobservability.NodeSample.memory:type_name -> gobservability.MemoryStats
+	13, // 7: gobservability.NodeSample.network:type_name -> gobservability.NetworkStats
+	14, // 8: gobservability.NodeSample.disk:type_name -> gobservability.DiskStats
+	7,  // 9: gobservability.NodeMetricsDelta.changed:type_name -> gobservability.NodeMetrics
+	3,  // 10: gobservability.NodeMetricsDelta.pods:type_name -> gobservability.PodDelta
+	15, // 11: gobservability.PodDelta.changed:type_name -> gobservability.Pod
+	11, // 12: gobservability.NodeMetrics.cpu:type_name -> gobservability.CPUStats
+	12, // 13: gobservability.NodeMetrics.memory:type_name -> gobservability.MemoryStats
+	13, // 14: gobservability.NodeMetrics.network:type_name -> gobservability.NetworkStats
+	14, // 15: gobservability.NodeMetrics.disk:type_name -> gobservability.DiskStats
+	15, // 16: gobservability.NodeMetrics.pods:type_name -> gobservability.Pod
+	47, // 17: gobservability.NodeMetrics.log_patterns:type_name -> gobservability.LogPatternCount
+	20, // 18: gobservability.NodeMetrics.custom_metrics:type_name -> gobservability.CustomMetrics
+	19, // 19: gobservability.NodeMetrics.pushed:type_name -> gobservability.PushedMetrics
+	10, // 20: gobservability.NodeMetrics.collectors:type_name -> gobservability.CollectorStatus
+	9,  // 21: gobservability.NodeMetrics.buffer:type_name -> gobservability.BufferStatus
+	8,  // 22: gobservability.NodeMetrics.connection:type_name -> gobservability.ConnectionStatus
+	53, // 23: gobservability.ConnectionStatus.last_error_at:type_name -> google.protobuf.Timestamp
+	53, // 24: gobservability.ConnectionStatus.connected_since:type_name -> google.protobuf.Timestamp
+	53, // 25: gobservability.CollectorStatus.last_run:type_name -> google.protobuf.Timestamp
+	16, // 26: gobservability.Pod.pod_metrics:type_name -> gobservability.PodMetrics
+	28, // 27: gobservability.Pod.pid_details:type_name -> gobservability.PidDetails
+	27, // 28: gobservability.Pod.resource_limits:type_name -> gobservability.ResourceInfo
+	27, // 29: gobservability.Pod.resource_requests:type_name -> gobservability.ResourceInfo
+	21, // 30: gobservability.PodMetrics.cpu:type_name -> gobservability.PodCPUStats
+	22, // 31: gobservability.PodMetrics.memory:type_name -> gobservability.PodMemoryStats
+	23, // 32: gobservability.PodMetrics.network:type_name -> gobservability.PodNetworkStats
+	24, // 33: gobservability.PodMetrics.disk:type_name -> gobservability.PodDiskStats
+	25, // 34: gobservability.PodMetrics.storage:type_name -> gobservability.PodStorageStats
+	18, // 35: gobservability.PodMetrics.scrape:type_name -> gobservability.ScrapeResult
+	51, // 36: gobservability.MetricSample.labels:type_name -> gobservability.MetricSample.LabelsEntry
+	17, // 37: gobservability.ScrapeResult.samples:type_name -> gobservability.MetricSample
+	17, // 38: gobservability.PushedMetrics.samples:type_name -> gobservability.MetricSample
+	17, // 39: gobservability.CustomMetrics.samples:type_name -> gobservability.MetricSample
+	26, // 40: gobservability.PodStorageStats.volumes:type_name -> gobservability.VolumeStats
+	48, // 41: gobservability.AgentMessage.hello:type_name -> gobservability.AgentHello
+	0,  // 42: gobservability.AgentMessage.stats:type_name -> gobservability.NodeStatsRequest
+	6,  // 43: gobservability.AgentMessage.flamegraph_response:type_name -> gobservability.FlamegraphResponse
+	43, // 44: gobservability.AgentMessage.log_chunk:type_name -> gobservability.LogChunk
+	41, // 45: gobservability.AgentMessage.pod_details_response:type_name -> gobservability.PodDetailsResponse
+	39, // 46: gobservability.AgentMessage.agent_config_ack:type_name -> gobservability.AgentConfigAck
+	30, // 47: gobservability.AgentMessage.goodbye:type_name -> gobservability.AgentGoodbye
+	33, // 48: gobservability.AgentMessage.pong:type_name -> gobservability.Pong
+	32, // 49: gobservability.AgentMessage.ping:type_name -> gobservability.Ping
+	50, // 50: gobservability.ServerMessage.ack:type_name -> gobservability.ServerAck
+	5,  // 51: gobservability.ServerMessage.flamegraph_request:type_name -> gobservability.FlamegraphRequest
+	42, // 52: gobservability.ServerMessage.log_request:type_name -> gobservability.LogRequest
+	45, // 53: gobservability.ServerMessage.log_pattern_rules:type_name -> gobservability.LogPatternRules
+	40, // 54: gobservability.ServerMessage.pod_details_request:type_name -> gobservability.PodDetailsRequest
+	36, // 55: gobservability.ServerMessage.agent_config:type_name -> gobservability.AgentConfig
+	35, // 56: gobservability.ServerMessage.resync:type_name -> gobservability.ResyncRequest
+	32, // 57: gobservability.ServerMessage.ping:type_name -> gobservability.Ping
+	34, // 58: gobservability.ServerMessage.collect:type_name -> gobservability.CollectRequest
+	33, // 59: gobservability.ServerMessage.pong:type_name -> gobservability.Pong
+	53, // 60: gobservability.Pong.agent_time:type_name -> google.protobuf.Timestamp
+	52, // 61: gobservability.AgentConfig.collector_intervals_ms:type_name -> gobservability.AgentConfig.CollectorIntervalsMsEntry
+	37, // 62: gobservability.AgentConfig.filters:type_name -> gobservability.AgentFilters
+	38, // 63: gobservability.AgentFilters.namespaces:type_name -> gobservability.FilterRule
+	38, // 64: gobservability.AgentFilters.pods:type_name -> gobservability.FilterRule
+	38, // 65: gobservability.AgentFilters.interfaces:type_name -> gobservability.FilterRule
+	38, // 66: gobservability.AgentFilters.devices:type_name -> gobservability.FilterRule
+	28, // 67: gobservability.PodDetailsResponse.details:type_name -> gobservability.PidDetails
+	44, // 68: gobservability.LogChunk.lines:type_name -> gobservability.LogLine
+	53, // 69: gobservability.LogLine.timestamp:type_name -> google.protobuf.Timestamp
+	46, // 70: gobservability.LogPatternRules.rules:type_name -> gobservability.LogPatternRule
+	49, // 71: gobservability.AgentHello.features:type_name -> gobservability.AgentFeature
+	0,  // 72: gobservability.NodeService.SendStats:input_type -> gobservability.NodeStatsRequest
+	5,  // 73: gobservability.NodeService.GenerateFlamegraph:input_type -> gobservability.FlamegraphRequest
+	29, // 74: gobservability.NodeService.AgentStream:input_type -> gobservability.AgentMessage
+	4,  // 75: gobservability.NodeService.SendStats:output_type -> gobservability.StatsResponse
+	6,  // 76: gobservability.NodeService.GenerateFlamegraph:output_type -> gobservability.FlamegraphResponse
+	31, // 77: gobservability.NodeService.AgentStream:output_type -> gobservability.ServerMessage
+	75, // [75:78] is the sub-list for method output_type
+	72, // [72:75] is the sub-list for method input_type
+	72, // [72:72] is the sub-list for extension type_name
+	72, // [72:72] is the sub-list for extension extendee
+	0,  // [0:72] is the sub-list for field type_name
 }
 
 func init() { file_proto_gobservability_proto_init() }
@@ -2098,14 +4844,28 @@ func file_proto_gobservability_proto_init() {
 	if File_proto_gobservability_proto != nil {
 		return
 	}
-	file_proto_gobservability_proto_msgTypes[17].OneofWrappers = []any{
+	file_proto_gobservability_proto_msgTypes[29].OneofWrappers = []any{
 		(*AgentMessage_Hello)(nil),
 		(*AgentMessage_Stats)(nil),
 		(*AgentMessage_FlamegraphResponse)(nil),
-	}
-	file_proto_gobservability_proto_msgTypes[18].OneofWrappers = []any{
+		(*AgentMessage_LogChunk)(nil),
+		(*AgentMessage_PodDetailsResponse)(nil),
+		(*AgentMessage_AgentConfigAck)(nil),
+		(*AgentMessage_Goodbye)(nil),
+		(*AgentMessage_Pong)(nil),
+		(*AgentMessage_Ping)(nil),
+	}
+	file_proto_gobservability_proto_msgTypes[31].OneofWrappers = []any{
 		(*ServerMessage_Ack)(nil),
 		(*ServerMessage_FlamegraphRequest)(nil),
+		(*ServerMessage_LogRequest)(nil),
+		(*ServerMessage_LogPatternRules)(nil),
+		(*ServerMessage_PodDetailsRequest)(nil),
+		(*ServerMessage_AgentConfig)(nil),
+		(*ServerMessage_Resync)(nil),
+		(*ServerMessage_Ping)(nil),
+		(*ServerMessage_Collect)(nil),
+		(*ServerMessage_Pong)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -2113,7 +4873,7 @@ func file_proto_gobservability_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_gobservability_proto_rawDesc), len(file_proto_gobservability_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   21,
+			NumMessages:   53,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,128 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a certificate and a CA pool read from PEM files, the files are checked on every
+// TLS handshake and read again when they change (e.g. a rotated Kubernetes secret). A file that
+// fails to load is logged and the previous certificate or pool is kept
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	certStat fileStat
+	caStat   fileStat
+	mu       sync.Mutex
+}
+
+// fileStat identifies a version of the files, the key is not checked as it is rotated with the certificate
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the certificate and key (if certFile is set) and the CA bundle (if caFile is set)
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("tls certificate and key must be set together")
+	}
+
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if certFile != "" {
+		if err := r.loadCertificate(); err != nil {
+			return nil, err
+		}
+	}
+	if caFile != "" {
+		if err := r.loadCA(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Certificate returns the current certificate, nil if no certificate is configured
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.certFile != "" && r.changed(r.certFile, r.certStat) {
+		if err := r.loadCertificate(); err != nil {
+			slog.Error("failed to reload certificate, keeping the previous one", "component", "tls", "error", err)
+		} else {
+			slog.Info("certificate reloaded", "component", "tls", "file", r.certFile, "expires", r.cert.Leaf.NotAfter)
+		}
+	}
+	return r.cert
+}
+
+// CAPool returns the current CA pool, nil if no CA is configured
+func (r *Reloader) CAPool() *x509.CertPool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.caFile != "" && r.changed(r.caFile, r.caStat) {
+		if err := r.loadCA(); err != nil {
+			slog.Error("failed to reload CA, keeping the previous one", "component", "tls", "error", err)
+		} else {
+			slog.Info("CA reloaded", "component", "tls", "file", r.caFile)
+		}
+	}
+	return r.pool
+}
+
+func (r *Reloader) changed(path string, loaded fileStat) bool {
+	stat, err := statFile(path)
+	return err == nil && stat != loaded
+}
+
+func (r *Reloader) loadCertificate() error {
+	stat, err := statFile(r.certFile)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate %s: %v", r.certFile, err)
+	}
+	r.cert = &cert
+	r.certStat = stat
+	return nil
+}
+
+func (r *Reloader) loadCA() error {
+	stat, err := statFile(r.caFile)
+	if err != nil {
+		return err
+	}
+	pem, err := os.ReadFile(r.caFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tls CA %s: %v", r.caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("failed to parse tls CA %s", r.caFile)
+	}
+	r.pool = pool
+	r.caStat = stat
+	return nil
+}
+
+func statFile(path string) (fileStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}, fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+	return fileStat{modTime: info.ModTime(), size: info.Size()}, nil
+}
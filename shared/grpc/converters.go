@@ -3,17 +3,24 @@ package grpc
 import (
 	pb "github.com/ThomasCardin/gobservability/proto"
 	"github.com/ThomasCardin/gobservability/shared/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Conversions from Go types to gRPC protobuf (for agent -> server)
 
 func ConvertToGRPCMetrics(metrics types.NodeMetrics) *pb.NodeMetrics {
 	return &pb.NodeMetrics{
-		Cpu:     ConvertToGRPCCPUStats(metrics.CPU),
-		Memory:  ConvertToGRPCMemoryStats(metrics.Memory),
-		Network: ConvertToGRPCNetworkStats(metrics.Network),
-		Disk:    ConvertToGRPCDiskStats(metrics.Disk),
-		Pods:    ConvertToGRPCPods(metrics.Pods),
+		Cpu:           ConvertToGRPCCPUStats(metrics.CPU),
+		Memory:        ConvertToGRPCMemoryStats(metrics.Memory),
+		Network:       ConvertToGRPCNetworkStats(metrics.Network),
+		Disk:          ConvertToGRPCDiskStats(metrics.Disk),
+		Pods:          ConvertToGRPCPods(metrics.Pods),
+		LogPatterns:   ConvertToGRPCLogPatternCounts(metrics.LogPatterns),
+		CustomMetrics: ConvertToGRPCCustomMetrics(metrics.CustomMetrics),
+		Pushed:        ConvertToGRPCPushedMetrics(metrics.Pushed),
+		Collectors:    ConvertToGRPCCollectorStatuses(metrics.Collectors),
+		Buffer:        ConvertToGRPCBufferStatus(metrics.Buffer),
+		Connection:    ConvertToGRPCConnectionStatus(metrics.Connection),
 	}
 }
 
@@ -93,8 +100,12 @@ func ConvertToGRPCPods(pods []*types.Pod) []*pb.Pod {
 	for i, pod := range pods {
 		grpcPods[i] = &pb.Pod{
 			Name:             pod.Name,
+			Namespace:        pod.Namespace,
+			Uid:              pod.UID,
+			Ip:               pod.IP,
 			ContainerId:      pod.ContainerID,
 			Pid:              int64(pod.PID),
+			Partial:          pod.Partial,
 			PodMetrics:       ConvertToGRPCPodMetrics(pod.PodMetrics),
 			PidDetails:       ConvertToGRPCPidDetails(pod.PidDetails),
 			ResourceLimits:   ConvertToGRPCResourceInfo(pod.ResourceLimits),
@@ -110,6 +121,8 @@ func ConvertToGRPCPodMetrics(metrics types.PodMetrics) *pb.PodMetrics {
 		Memory:  ConvertToGRPCPodMemoryStats(metrics.Memory),
 		Network: ConvertToGRPCPodNetworkStats(metrics.Network),
 		Disk:    ConvertToGRPCPodDiskStats(metrics.Disk),
+		Storage: ConvertToGRPCPodStorageStats(metrics.Storage),
+		Scrape:  ConvertToGRPCScrapeResult(metrics.Scrape),
 	}
 }
 
@@ -143,10 +156,140 @@ func ConvertToGRPCPodDiskStats(disk types.PodDiskStats) *pb.PodDiskStats {
 	}
 }
 
+func ConvertToGRPCPodStorageStats(storage types.PodStorageStats) *pb.PodStorageStats {
+	volumes := make([]*pb.VolumeStats, len(storage.Volumes))
+	for i, volume := range storage.Volumes {
+		volumes[i] = &pb.VolumeStats{
+			Name:          volume.Name,
+			Type:          volume.Type,
+			UsedBytes:     volume.UsedBytes,
+			UsedInodes:    volume.UsedInodes,
+			CapacityBytes: volume.CapacityBytes,
+			UsagePercent:  volume.UsagePercent,
+		}
+	}
+	return &pb.PodStorageStats{
+		Volumes:             volumes,
+		WritableLayerBytes:  storage.WritableLayerBytes,
+		WritableLayerInodes: storage.WritableLayerInodes,
+		EphemeralUsedBytes:  storage.EphemeralUsedBytes,
+		EphemeralLimitBytes: storage.EphemeralLimitBytes,
+		EphemeralPercent:    storage.EphemeralPercent,
+	}
+}
+
+func ConvertToGRPCScrapeResult(scrape *types.ScrapeResult) *pb.ScrapeResult {
+	if scrape == nil {
+		return nil
+	}
+	return &pb.ScrapeResult{
+		Url:        scrape.URL,
+		Samples:    ConvertToGRPCMetricSamples(scrape.Samples),
+		DurationMs: scrape.DurationMs,
+		Error:      scrape.Error,
+	}
+}
+
+func ConvertToGRPCMetricSamples(samples []types.MetricSample) []*pb.MetricSample {
+	grpcSamples := make([]*pb.MetricSample, len(samples))
+	for i, sample := range samples {
+		grpcSamples[i] = &pb.MetricSample{
+			Name:   sample.Name,
+			Labels: sample.Labels,
+			Value:  sample.Value,
+			Type:   sample.Type,
+		}
+	}
+	return grpcSamples
+}
+
+func ConvertToGRPCCustomMetrics(custom []types.CustomMetrics) []*pb.CustomMetrics {
+	grpcCustom := make([]*pb.CustomMetrics, len(custom))
+	for i, source := range custom {
+		grpcCustom[i] = &pb.CustomMetrics{
+			Source:     source.Source,
+			Samples:    ConvertToGRPCMetricSamples(source.Samples),
+			DurationMs: source.DurationMs,
+			Error:      source.Error,
+		}
+	}
+	return grpcCustom
+}
+
+func ConvertToGRPCPushedMetrics(pushed []types.PushedMetrics) []*pb.PushedMetrics {
+	grpcPushed := make([]*pb.PushedMetrics, len(pushed))
+	for i, source := range pushed {
+		grpcPushed[i] = &pb.PushedMetrics{
+			PodName:   source.PodName,
+			Namespace: source.Namespace,
+			SourceIp:  source.SourceIP,
+			Samples:   ConvertToGRPCMetricSamples(source.Samples),
+		}
+	}
+	return grpcPushed
+}
+
+func ConvertToGRPCCollectorStatuses(statuses []types.CollectorStatus) []*pb.CollectorStatus {
+	grpcStatuses := make([]*pb.CollectorStatus, len(statuses))
+	for i, status := range statuses {
+		grpcStatuses[i] = &pb.CollectorStatus{
+			Name:         status.Name,
+			Enabled:      status.Enabled,
+			IntervalMs:   status.IntervalMs,
+			Capabilities: status.Capabilities,
+			DurationMs:   status.DurationMs,
+			Error:        status.Error,
+		}
+		if !status.LastRun.IsZero() {
+			grpcStatuses[i].LastRun = timestamppb.New(status.LastRun)
+		}
+	}
+	return grpcStatuses
+}
+
+func ConvertToGRPCBufferStatus(status *types.BufferStatus) *pb.BufferStatus {
+	if status == nil {
+		return nil
+	}
+	return &pb.BufferStatus{
+		Payloads:     int32(status.Payloads),
+		Bytes:        status.Bytes,
+		DiskBytes:    status.DiskBytes,
+		MaxPayloads:  int32(status.MaxPayloads),
+		MaxBytes:     status.MaxBytes,
+		MaxDiskBytes: status.MaxDiskBytes,
+		Dropped:      status.Dropped,
+		Spilled:      status.Spilled,
+		Replayed:     status.Replayed,
+	}
+}
+
+func ConvertToGRPCConnectionStatus(status *types.ConnectionStatus) *pb.ConnectionStatus {
+	if status == nil {
+		return nil
+	}
+	grpcStatus := &pb.ConnectionStatus{
+		Endpoint:       status.Endpoint,
+		Connected:      status.Connected,
+		Reconnects:     status.Reconnects,
+		LastError:      status.LastError,
+		LastOutageMs:   status.LastOutageMs,
+		DisconnectedMs: status.DisconnectedMs,
+	}
+	if !status.LastErrorAt.IsZero() {
+		grpcStatus.LastErrorAt = timestamppb.New(status.LastErrorAt)
+	}
+	if !status.ConnectedSince.IsZero() {
+		grpcStatus.ConnectedSince = timestamppb.New(status.ConnectedSince)
+	}
+	return grpcStatus
+}
+
 func ConvertToGRPCResourceInfo(resource types.ResourceInfo) *pb.ResourceInfo {
 	return &pb.ResourceInfo{
-		Cpu:    resource.CPU,
-		Memory: resource.Memory,
+		Cpu:              resource.CPU,
+		Memory:           resource.Memory,
+		EphemeralStorage: resource.EphemeralStorage,
 	}
 }
 
@@ -199,15 +342,109 @@ func ConvertToGRPCPidDetails(pid types.PidDetails) *pb.PidDetails {
 	}
 }
 
+func ConvertToGRPCLogLines(lines []types.LogLine) []*pb.LogLine {
+	grpcLines := make([]*pb.LogLine, len(lines))
+	for i, line := range lines {
+		grpcLines[i] = &pb.LogLine{
+			Timestamp: timestamppb.New(line.Timestamp),
+			Container: line.Container,
+			Stream:    line.Stream,
+			Message:   line.Message,
+		}
+	}
+	return grpcLines
+}
+
+func ConvertToGRPCLogPatternCounts(counts []types.LogPatternCount) []*pb.LogPatternCount {
+	grpcCounts := make([]*pb.LogPatternCount, len(counts))
+	for i, count := range counts {
+		grpcCounts[i] = &pb.LogPatternCount{
+			RuleId:            count.RuleID,
+			PodName:           count.PodName,
+			MatchesLastMinute: count.MatchesLastMinute,
+			TotalMatches:      count.TotalMatches,
+			Error:             count.Error,
+		}
+	}
+	return grpcCounts
+}
+
+func ConvertToGRPCLogPatternRules(rules []types.LogPatternRule) *pb.LogPatternRules {
+	grpcRules := make([]*pb.LogPatternRule, len(rules))
+	for i, rule := range rules {
+		grpcRules[i] = &pb.LogPatternRule{
+			RuleId:  rule.RuleID,
+			PodName: rule.PodName,
+			Pattern: rule.Pattern,
+		}
+	}
+	return &pb.LogPatternRules{Rules: grpcRules}
+}
+
+func ConvertToGRPCAgentConfig(config types.AgentConfig) *pb.AgentConfig {
+	return &pb.AgentConfig{
+		Version:              config.Version,
+		IntervalMs:           config.IntervalMs,
+		EnabledCollectors:    config.EnabledCollectors,
+		DisabledCollectors:   config.DisabledCollectors,
+		CollectorIntervalsMs: config.CollectorIntervalsMs,
+		Filters: &pb.AgentFilters{
+			Namespaces: ConvertToGRPCFilterRule(config.Filters.Namespaces),
+			Pods:       ConvertToGRPCFilterRule(config.Filters.Pods),
+			Interfaces: ConvertToGRPCFilterRule(config.Filters.Interfaces),
+			Devices:    ConvertToGRPCFilterRule(config.Filters.Devices),
+		},
+	}
+}
+
+func ConvertToGRPCFilterRule(rule types.FilterRule) *pb.FilterRule {
+	return &pb.FilterRule{
+		Include: rule.Include,
+		Exclude: rule.Exclude,
+	}
+}
+
+func ConvertToGRPCAgentFeatures(features []types.AgentFeature) []*pb.AgentFeature {
+	grpcFeatures := make([]*pb.AgentFeature, 0, len(features))
+	for _, feature := range features {
+		grpcFeatures = append(grpcFeatures, &pb.AgentFeature{
+			Name:      feature.Name,
+			Available: feature.Available,
+			Reason:    feature.Reason,
+		})
+	}
+	return grpcFeatures
+}
+
+func ConvertToGRPCNodeSamples(samples []types.NodeSample) []*pb.NodeSample {
+	grpcSamples := make([]*pb.NodeSample, 0, len(samples))
+	for _, sample := range samples {
+		grpcSamples = append(grpcSamples, &pb.NodeSample{
+			Timestamp: timestamppb.New(sample.Timestamp),
+			Cpu:       ConvertToGRPCCPUStats(sample.CPU),
+			Memory:    ConvertToGRPCMemoryStats(sample.Memory),
+			Network:   ConvertToGRPCNetworkStats(sample.Network),
+			Disk:      ConvertToGRPCDiskStats(sample.Disk),
+		})
+	}
+	return grpcSamples
+}
+
 // Conversions from gRPC protobuf to Go types (for server <- agent)
 
 func ConvertNodeMetrics(grpcMetrics *pb.NodeMetrics) types.NodeMetrics {
 	return types.NodeMetrics{
-		CPU:     ConvertCPUStats(grpcMetrics.Cpu),
-		Memory:  ConvertMemoryStats(grpcMetrics.Memory),
-		Network: ConvertNetworkStats(grpcMetrics.Network),
-		Disk:    ConvertDiskStats(grpcMetrics.Disk),
-		Pods:    ConvertPods(grpcMetrics.Pods),
+		CPU:           ConvertCPUStats(grpcMetrics.Cpu),
+		Memory:        ConvertMemoryStats(grpcMetrics.Memory),
+		Network:       ConvertNetworkStats(grpcMetrics.Network),
+		Disk:          ConvertDiskStats(grpcMetrics.Disk),
+		Pods:          ConvertPods(grpcMetrics.Pods),
+		LogPatterns:   ConvertLogPatternCounts(grpcMetrics.LogPatterns),
+		CustomMetrics: ConvertCustomMetrics(grpcMetrics.CustomMetrics),
+		Pushed:        ConvertPushedMetrics(grpcMetrics.Pushed),
+		Collectors:    ConvertCollectorStatuses(grpcMetrics.Collectors),
+		Buffer:        ConvertBufferStatus(grpcMetrics.Buffer),
+		Connection:    ConvertConnectionStatus(grpcMetrics.Connection),
 	}
 }
 
@@ -287,8 +524,12 @@ func ConvertPods(grpcPods []*pb.Pod) []*types.Pod {
 	for i, grpcPod := range grpcPods {
 		pods[i] = &types.Pod{
 			Name:             grpcPod.Name,
+			Namespace:        grpcPod.Namespace,
+			UID:              grpcPod.Uid,
+			IP:               grpcPod.Ip,
 			ContainerID:      grpcPod.ContainerId,
 			PID:              int(grpcPod.Pid),
+			Partial:          grpcPod.Partial,
 			PodMetrics:       ConvertPodMetrics(grpcPod.PodMetrics),
 			PidDetails:       ConvertPidDetails(grpcPod.PidDetails),
 			ResourceLimits:   ConvertResourceInfo(grpcPod.ResourceLimits),
@@ -307,6 +548,8 @@ func ConvertPodMetrics(grpc *pb.PodMetrics) types.PodMetrics {
 		Memory:  ConvertPodMemoryStats(grpc.Memory),
 		Network: ConvertPodNetworkStats(grpc.Network),
 		Disk:    ConvertPodDiskStats(grpc.Disk),
+		Storage: ConvertPodStorageStats(grpc.Storage),
+		Scrape:  ConvertScrapeResult(grpc.Scrape),
 	}
 }
 
@@ -352,13 +595,146 @@ func ConvertPodDiskStats(grpc *pb.PodDiskStats) types.PodDiskStats {
 	}
 }
 
+func ConvertPodStorageStats(grpc *pb.PodStorageStats) types.PodStorageStats {
+	if grpc == nil {
+		return types.PodStorageStats{}
+	}
+	volumes := make([]types.VolumeStats, len(grpc.Volumes))
+	for i, volume := range grpc.Volumes {
+		volumes[i] = types.VolumeStats{
+			Name:          volume.Name,
+			Type:          volume.Type,
+			UsedBytes:     volume.UsedBytes,
+			UsedInodes:    volume.UsedInodes,
+			CapacityBytes: volume.CapacityBytes,
+			UsagePercent:  volume.UsagePercent,
+		}
+	}
+	return types.PodStorageStats{
+		Volumes:             volumes,
+		WritableLayerBytes:  grpc.WritableLayerBytes,
+		WritableLayerInodes: grpc.WritableLayerInodes,
+		EphemeralUsedBytes:  grpc.EphemeralUsedBytes,
+		EphemeralLimitBytes: grpc.EphemeralLimitBytes,
+		EphemeralPercent:    grpc.EphemeralPercent,
+	}
+}
+
+func ConvertScrapeResult(grpc *pb.ScrapeResult) *types.ScrapeResult {
+	if grpc == nil {
+		return nil
+	}
+	return &types.ScrapeResult{
+		URL:        grpc.Url,
+		Samples:    ConvertMetricSamples(grpc.Samples),
+		DurationMs: grpc.DurationMs,
+		Error:      grpc.Error,
+	}
+}
+
+func ConvertMetricSamples(grpc []*pb.MetricSample) []types.MetricSample {
+	samples := make([]types.MetricSample, len(grpc))
+	for i, sample := range grpc {
+		samples[i] = types.MetricSample{
+			Name:   sample.Name,
+			Labels: sample.Labels,
+			Value:  sample.Value,
+			Type:   sample.Type,
+		}
+	}
+	return samples
+}
+
+func ConvertCustomMetrics(grpc []*pb.CustomMetrics) []types.CustomMetrics {
+	custom := make([]types.CustomMetrics, len(grpc))
+	for i, source := range grpc {
+		custom[i] = types.CustomMetrics{
+			Source:     source.Source,
+			Samples:    ConvertMetricSamples(source.Samples),
+			DurationMs: source.DurationMs,
+			Error:      source.Error,
+		}
+	}
+	return custom
+}
+
+func ConvertPushedMetrics(grpc []*pb.PushedMetrics) []types.PushedMetrics {
+	pushed := make([]types.PushedMetrics, len(grpc))
+	for i, source := range grpc {
+		pushed[i] = types.PushedMetrics{
+			PodName:   source.PodName,
+			Namespace: source.Namespace,
+			SourceIP:  source.SourceIp,
+			Samples:   ConvertMetricSamples(source.Samples),
+		}
+	}
+	return pushed
+}
+
+func ConvertCollectorStatuses(grpc []*pb.CollectorStatus) []types.CollectorStatus {
+	statuses := make([]types.CollectorStatus, len(grpc))
+	for i, status := range grpc {
+		statuses[i] = types.CollectorStatus{
+			Name:         status.Name,
+			Enabled:      status.Enabled,
+			IntervalMs:   status.IntervalMs,
+			Capabilities: status.Capabilities,
+			DurationMs:   status.DurationMs,
+			Error:        status.Error,
+		}
+		if status.LastRun != nil {
+			statuses[i].LastRun = status.LastRun.AsTime()
+		}
+	}
+	return statuses
+}
+
+func ConvertBufferStatus(grpc *pb.BufferStatus) *types.BufferStatus {
+	if grpc == nil {
+		return nil
+	}
+	return &types.BufferStatus{
+		Payloads:     int(grpc.Payloads),
+		Bytes:        grpc.Bytes,
+		DiskBytes:    grpc.DiskBytes,
+		MaxPayloads:  int(grpc.MaxPayloads),
+		MaxBytes:     grpc.MaxBytes,
+		MaxDiskBytes: grpc.MaxDiskBytes,
+		Dropped:      grpc.Dropped,
+		Spilled:      grpc.Spilled,
+		Replayed:     grpc.Replayed,
+	}
+}
+
+func ConvertConnectionStatus(grpc *pb.ConnectionStatus) *types.ConnectionStatus {
+	if grpc == nil {
+		return nil
+	}
+	status := &types.ConnectionStatus{
+		Endpoint:       grpc.Endpoint,
+		Connected:      grpc.Connected,
+		Reconnects:     grpc.Reconnects,
+		LastError:      grpc.LastError,
+		LastOutageMs:   grpc.LastOutageMs,
+		DisconnectedMs: grpc.DisconnectedMs,
+	}
+	if grpc.LastErrorAt != nil {
+		status.LastErrorAt = grpc.LastErrorAt.AsTime()
+	}
+	if grpc.ConnectedSince != nil {
+		status.ConnectedSince = grpc.ConnectedSince.AsTime()
+	}
+	return status
+}
+
 func ConvertResourceInfo(grpc *pb.ResourceInfo) types.ResourceInfo {
 	if grpc == nil {
 		return types.ResourceInfo{}
 	}
 	return types.ResourceInfo{
-		CPU:    grpc.Cpu,
-		Memory: grpc.Memory,
+		CPU:              grpc.Cpu,
+		Memory:           grpc.Memory,
+		EphemeralStorage: grpc.EphemeralStorage,
 	}
 }
 
@@ -413,3 +789,109 @@ func ConvertPidDetails(grpc *pb.PidDetails) types.PidDetails {
 		VmSwap:  grpc.VmSwap,
 	}
 }
+
+func ConvertLogLines(grpc []*pb.LogLine) []types.LogLine {
+	lines := make([]types.LogLine, len(grpc))
+	for i, line := range grpc {
+		lines[i] = types.LogLine{
+			Timestamp: line.Timestamp.AsTime(),
+			Container: line.Container,
+			Stream:    line.Stream,
+			Message:   line.Message,
+		}
+	}
+	return lines
+}
+
+func ConvertLogPatternCounts(grpc []*pb.LogPatternCount) []types.LogPatternCount {
+	counts := make([]types.LogPatternCount, len(grpc))
+	for i, count := range grpc {
+		counts[i] = types.LogPatternCount{
+			RuleID:            count.RuleId,
+			PodName:           count.PodName,
+			MatchesLastMinute: count.MatchesLastMinute,
+			TotalMatches:      count.TotalMatches,
+			Error:             count.Error,
+		}
+	}
+	return counts
+}
+
+func ConvertLogPatternRules(grpc *pb.LogPatternRules) []types.LogPatternRule {
+	if grpc == nil {
+		return nil
+	}
+	rules := make([]types.LogPatternRule, len(grpc.Rules))
+	for i, rule := range grpc.Rules {
+		rules[i] = types.LogPatternRule{
+			RuleID:  rule.RuleId,
+			PodName: rule.PodName,
+			Pattern: rule.Pattern,
+		}
+	}
+	return rules
+}
+
+func ConvertAgentConfig(grpc *pb.AgentConfig) types.AgentConfig {
+	if grpc == nil {
+		return types.AgentConfig{}
+	}
+	config := types.AgentConfig{
+		Version:              grpc.Version,
+		IntervalMs:           grpc.IntervalMs,
+		EnabledCollectors:    grpc.EnabledCollectors,
+		DisabledCollectors:   grpc.DisabledCollectors,
+		CollectorIntervalsMs: grpc.CollectorIntervalsMs,
+	}
+	if grpc.Filters != nil {
+		config.Filters = types.AgentFilters{
+			Namespaces: ConvertFilterRule(grpc.Filters.Namespaces),
+			Pods:       ConvertFilterRule(grpc.Filters.Pods),
+			Interfaces: ConvertFilterRule(grpc.Filters.Interfaces),
+			Devices:    ConvertFilterRule(grpc.Filters.Devices),
+		}
+	}
+	return config
+}
+
+func ConvertFilterRule(grpc *pb.FilterRule) types.FilterRule {
+	if grpc == nil {
+		return types.FilterRule{}
+	}
+	return types.FilterRule{
+		Include: grpc.Include,
+		Exclude: grpc.Exclude,
+	}
+}
+
+func ConvertAgentCapabilities(hello *pb.AgentHello) types.AgentCapabilities {
+	capabilities := types.AgentCapabilities{
+		AgentVersion:    hello.AgentVersion,
+		ProtocolVersion: hello.ProtocolVersion,
+	}
+	for _, feature := range hello.Features {
+		capabilities.Features = append(capabilities.Features, types.AgentFeature{
+			Name:      feature.Name,
+			Available: feature.Available,
+			Reason:    feature.Reason,
+		})
+	}
+	return capabilities
+}
+
+func ConvertNodeSamples(grpc []*pb.NodeSample) []types.NodeSample {
+	if len(grpc) == 0 {
+		return nil
+	}
+	samples := make([]types.NodeSample, 0, len(grpc))
+	for _, sample := range grpc {
+		samples = append(samples, types.NodeSample{
+			Timestamp: sample.Timestamp.AsTime(),
+			CPU:       ConvertCPUStats(sample.Cpu),
+			Memory:    ConvertMemoryStats(sample.Memory),
+			Network:   ConvertNetworkStats(sample.Network),
+			Disk:      ConvertDiskStats(sample.Disk),
+		})
+	}
+	return samples
+}
@@ -0,0 +1,303 @@
+package grpc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	pb "github.com/ThomasCardin/gobservability/proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// podsField is excluded from the field diff of the node metrics, pods are diffed by key
+const podsField protoreflect.Name = "pods"
+
+// PodKey identifies a pod entry across payloads, a pod has an entry per container
+func PodKey(pod *pb.Pod) string {
+	return pod.Namespace + "/" + pod.Name + "/" + pod.ContainerId
+}
+
+// DeltaEncoder turns the node metrics of consecutive payloads into deltas against the previous
+// payload, with a full snapshot every snapshotEvery payloads and after Reset
+type DeltaEncoder struct {
+	snapshotEvery int
+	sequence      uint64
+	sinceSnapshot int
+	last          *pb.NodeMetrics // nil forces a snapshot
+	lastPods      map[string]*pb.Pod
+	mu            sync.Mutex
+}
+
+// NewDeltaEncoder creates an encoder sending a snapshot every snapshotEvery payloads
+func NewDeltaEncoder(snapshotEvery int) *DeltaEncoder {
+	return &DeltaEncoder{snapshotEvery: max(snapshotEvery, 1)}
+}
+
+// Reset makes the next payload a snapshot, called when the stream or the server state is lost
+func (e *DeltaEncoder) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.last = nil
+	e.lastPods = nil
+}
+
+// Encode sets the metrics of request, or a delta against the previous payload, and its sequence
+// The metrics must not be modified afterwards as they are the base of the next delta
+func (e *DeltaEncoder) Encode(request *pb.NodeStatsRequest, metrics *pb.NodeMetrics) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sequence++
+	request.Sequence = e.sequence
+
+	pods := make(map[string]*pb.Pod, len(metrics.Pods))
+	for _, pod := range metrics.Pods {
+		pods[PodKey(pod)] = pod
+	}
+
+	// Duplicate keys cannot be diffed, the payload is sent whole
+	if e.last == nil || e.sinceSnapshot+1 >= e.snapshotEvery || len(pods) != len(metrics.Pods) {
+		request.Metrics = metrics
+		e.last, e.lastPods, e.sinceSnapshot = metrics, pods, 0
+		return
+	}
+
+	delta := &pb.NodeMetricsDelta{Changed: &pb.NodeMetrics{}}
+	diffMessage(e.last.ProtoReflect(), metrics.ProtoReflect(), delta.Changed.ProtoReflect(), "", podsField, &delta.Cleared)
+
+	for _, pod := range metrics.Pods {
+		key := PodKey(pod)
+		previous, found := e.lastPods[key]
+		if !found {
+			delta.Pods = append(delta.Pods, &pb.PodDelta{Key: key, Changed: pod})
+			continue
+		}
+		podDelta := &pb.PodDelta{Key: key, Changed: &pb.Pod{}}
+		diffMessage(previous.ProtoReflect(), pod.ProtoReflect(), podDelta.Changed.ProtoReflect(), "", "", &podDelta.Cleared)
+		if proto.Size(podDelta.Changed) > 0 || len(podDelta.Cleared) > 0 {
+			delta.Pods = append(delta.Pods, podDelta)
+		}
+	}
+	for key := range e.lastPods {
+		if _, found := pods[key]; !found {
+			delta.RemovedPods = append(delta.RemovedPods, key)
+		}
+	}
+
+	request.Delta = delta
+	e.last, e.lastPods = metrics, pods
+	e.sinceSnapshot++
+}
+
+// DeltaDecoder rebuilds the node metrics of a stream from its snapshots and deltas
+type DeltaDecoder struct {
+	sequence uint64
+	base     *pb.NodeMetrics // nil until the first snapshot
+	pods     map[string]*pb.Pod
+	order    []string // Pod keys in the order of the snapshot, new pods are appended
+}
+
+// Decode returns the full metrics of a payload, an error if a delta does not follow the previous
+// payload (the agent must then send a snapshot). Payloads without sequence are returned as is
+func (d *DeltaDecoder) Decode(request *pb.NodeStatsRequest) (*pb.NodeMetrics, error) {
+	if request.Delta == nil {
+		if request.Sequence > 0 {
+			d.snapshot(request.Sequence, request.Metrics)
+		}
+		return request.Metrics, nil
+	}
+
+	if d.base == nil {
+		return nil, fmt.Errorf("delta %d received without snapshot", request.Sequence)
+	}
+	if request.Sequence != d.sequence+1 {
+		d.base = nil
+		return nil, fmt.Errorf("delta %d does not follow payload %d", request.Sequence, d.sequence)
+	}
+	d.sequence = request.Sequence
+
+	delta := request.Delta
+	if delta.Changed != nil {
+		applyMessage(d.base.ProtoReflect(), delta.Changed.ProtoReflect())
+	}
+	if err := clearPaths(d.base.ProtoReflect(), delta.Cleared); err != nil {
+		d.base = nil
+		return nil, err
+	}
+
+	for _, key := range delta.RemovedPods {
+		delete(d.pods, key)
+	}
+	for _, podDelta := range delta.Pods {
+		previous, found := d.pods[podDelta.Key]
+		if !found {
+			d.pods[podDelta.Key] = podDelta.Changed
+			d.order = append(d.order, podDelta.Key)
+			continue
+		}
+		// Pods of previous payloads may still be referenced by their converted stats
+		pod := proto.Clone(previous).(*pb.Pod)
+		if podDelta.Changed != nil {
+			applyMessage(pod.ProtoReflect(), podDelta.Changed.ProtoReflect())
+		}
+		if err := clearPaths(pod.ProtoReflect(), podDelta.Cleared); err != nil {
+			d.base = nil
+			return nil, err
+		}
+		d.pods[podDelta.Key] = pod
+	}
+
+	order := d.order[:0]
+	pods := make([]*pb.Pod, 0, len(d.pods))
+	for _, key := range d.order {
+		if pod, found := d.pods[key]; found {
+			order = append(order, key)
+			pods = append(pods, pod)
+		}
+	}
+	d.order = order
+
+	metrics := proto.Clone(d.base).(*pb.NodeMetrics)
+	metrics.Pods = pods
+	return metrics, nil
+}
+
+func (d *DeltaDecoder) snapshot(sequence uint64, metrics *pb.NodeMetrics) {
+	d.sequence = sequence
+
+	// The pods are kept by key, they are not cloned with the rest of the metrics
+	pods := metrics.Pods
+	metrics.Pods = nil
+	d.base = proto.Clone(metrics).(*pb.NodeMetrics)
+	metrics.Pods = pods
+
+	d.pods = make(map[string]*pb.Pod, len(pods))
+	d.order = make([]string, 0, len(pods))
+	for _, pod := range pods {
+		key := PodKey(pod)
+		d.pods[key] = pod
+		d.order = append(d.order, key)
+	}
+}
+
+// diffMessage sets in changed the fields of current that differ from previous and appends the
+// paths of the fields unset in current to cleared. Lists and maps are replaced whole
+func diffMessage(previous, current, changed protoreflect.Message, prefix string, skip protoreflect.Name, cleared *[]string) {
+	fields := current.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Name() == skip {
+			continue
+		}
+		path := prefix + string(field.Name())
+		had, has := previous.Has(field), current.Has(field)
+
+		switch {
+		case !has:
+			if had {
+				*cleared = append(*cleared, path)
+			}
+		case !had:
+			changed.Set(field, current.Get(field))
+		case field.Kind() == protoreflect.MessageKind && !field.IsList() && !field.IsMap():
+			sub := changed.Mutable(field).Message()
+			diffMessage(previous.Get(field).Message(), current.Get(field).Message(), sub, path+".", "", cleared)
+			if !hasFields(sub) {
+				changed.Clear(field)
+			}
+		case !valuesEqual(field, previous.Get(field), current.Get(field)):
+			changed.Set(field, current.Get(field))
+		}
+	}
+}
+
+// applyMessage sets the fields of changed in base, recursing into the messages base already has
+func applyMessage(base, changed protoreflect.Message) {
+	changed.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if field.Kind() == protoreflect.MessageKind && !field.IsList() && !field.IsMap() && base.Has(field) {
+			applyMessage(base.Mutable(field).Message(), value.Message())
+		} else {
+			base.Set(field, value)
+		}
+		return true
+	})
+}
+
+// clearPaths unsets the fields at the given dotted paths
+func clearPaths(message protoreflect.Message, paths []string) error {
+	for _, path := range paths {
+		current := message
+		names := strings.Split(path, ".")
+		for i, name := range names {
+			field := current.Descriptor().Fields().ByName(protoreflect.Name(name))
+			if field == nil {
+				return fmt.Errorf("unknown field %s in delta", path)
+			}
+			if i == len(names)-1 {
+				current.Clear(field)
+				break
+			}
+			if field.Kind() != protoreflect.MessageKind || field.IsList() || field.IsMap() {
+				return fmt.Errorf("invalid field path %s in delta", path)
+			}
+			if !current.Has(field) {
+				break
+			}
+			current = current.Mutable(field).Message()
+		}
+	}
+	return nil
+}
+
+func hasFields(message protoreflect.Message) bool {
+	found := false
+	message.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// valuesEqual compares two values of a field
+func valuesEqual(field protoreflect.FieldDescriptor, a, b protoreflect.Value) bool {
+	switch {
+	case field.IsList():
+		listA, listB := a.List(), b.List()
+		if listA.Len() != listB.Len() {
+			return false
+		}
+		for i := 0; i < listA.Len(); i++ {
+			if !scalarOrMessageEqual(field, listA.Get(i), listB.Get(i)) {
+				return false
+			}
+		}
+		return true
+	case field.IsMap():
+		mapA, mapB := a.Map(), b.Map()
+		if mapA.Len() != mapB.Len() {
+			return false
+		}
+		equal := true
+		mapA.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			other := mapB.Get(key)
+			equal = mapB.Has(key) && scalarOrMessageEqual(field.MapValue(), value, other)
+			return equal
+		})
+		return equal
+	default:
+		return scalarOrMessageEqual(field, a, b)
+	}
+}
+
+func scalarOrMessageEqual(field protoreflect.FieldDescriptor, a, b protoreflect.Value) bool {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return proto.Equal(a.Message().Interface(), b.Message().Interface())
+	case protoreflect.BytesKind:
+		return bytes.Equal(a.Bytes(), b.Bytes())
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
@@ -0,0 +1,265 @@
+package grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"testing"
+
+	pb "github.com/ThomasCardin/gobservability/proto"
+	"github.com/ThomasCardin/gobservability/shared/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// testNode generates the metrics of a node whose counters grow on every tick
+type testNode struct {
+	tick int
+	pods []*types.Pod
+}
+
+func newTestNode(pods int) *testNode {
+	n := &testNode{}
+	for i := 0; i < pods; i++ {
+		n.addPod(fmt.Sprintf("app-%d", i))
+	}
+	return n
+}
+
+func (n *testNode) addPod(name string) {
+	i := len(n.pods)
+	n.pods = append(n.pods, &types.Pod{
+		Name:             name,
+		Namespace:        "default",
+		UID:              fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+		IP:               fmt.Sprintf("10.0.%d.%d", i/250, i%250+1),
+		ContainerID:      fmt.Sprintf("containerd://%064d", i),
+		PID:              1000 + i,
+		ResourceLimits:   types.ResourceInfo{CPU: "500m", Memory: "512Mi"},
+		ResourceRequests: types.ResourceInfo{CPU: "100m", Memory: "128Mi"},
+		PidDetails:       types.PidDetails{Name: name, State: "S", Threads: 8},
+	})
+}
+
+func (n *testNode) removePod(name string) {
+	for i, pod := range n.pods {
+		if pod.Name == name {
+			n.pods = append(n.pods[:i], n.pods[i+1:]...)
+			return
+		}
+	}
+}
+
+// next returns the metrics of the next tick, the CPU counters of the node and the pods change
+func (n *testNode) next() *pb.NodeMetrics {
+	n.tick++
+	for i, pod := range n.pods {
+		pod.PodMetrics.CPU.UTime += uint64(10 + i%7)
+		pod.PodMetrics.CPU.STime += 2
+		pod.PodMetrics.CPU.CPUPercent = float64(5 + (n.tick+i)%10)
+		pod.PodMetrics.Memory.VmRSS = 100000
+	}
+	return ConvertToGRPCMetrics(types.NodeMetrics{
+		CPU:     &types.CPUStats{User: 1000 * n.tick, System: 300 * n.tick, Idle: 5000 * n.tick, Total: 6300 * n.tick, CPUPercent: 20},
+		Memory:  &types.MemoryStats{MemTotal: 32 << 20, MemFree: 8 << 20, MemAvailable: 16 << 20, MemoryPercent: 50},
+		Network: &types.NetworkStats{BytesReceived: uint64(1 << 20 * n.tick), BytesTransmitted: uint64(1 << 19 * n.tick)},
+		Disk:    &types.DiskStats{ReadsCompleted: uint64(100 * n.tick)},
+		Pods:    n.pods,
+	})
+}
+
+// roundTrip encodes metrics and decodes the payload, failing if the decoded metrics differ
+func roundTrip(t *testing.T, encoder *DeltaEncoder, decoder *DeltaDecoder, metrics *pb.NodeMetrics) *pb.NodeStatsRequest {
+	t.Helper()
+	// The encoder keeps metrics as the base of the next delta, the expected value is a copy
+	expected := proto.Clone(metrics).(*pb.NodeMetrics)
+
+	request := &pb.NodeStatsRequest{NodeName: "node-1"}
+	encoder.Encode(request, metrics)
+
+	// The payload goes through the wire
+	data, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := &pb.NodeStatsRequest{}
+	if err := proto.Unmarshal(data, received); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := decoder.Decode(received)
+	if err != nil {
+		t.Fatalf("payload %d: %v", request.Sequence, err)
+	}
+	if !proto.Equal(decoded, expected) {
+		t.Fatalf("payload %d: decoded metrics differ\ngot:  %v\nwant: %v", request.Sequence, decoded, expected)
+	}
+	return request
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	node := newTestNode(20)
+	encoder := NewDeltaEncoder(100)
+	decoder := &DeltaDecoder{}
+
+	if request := roundTrip(t, encoder, decoder, node.next()); request.Delta != nil {
+		t.Fatal("expected a snapshot as first payload")
+	}
+
+	// Changed counters
+	request := roundTrip(t, encoder, decoder, node.next())
+	if request.Delta == nil {
+		t.Fatal("expected a delta")
+	}
+	if len(request.Delta.Pods) != 20 {
+		t.Errorf("expected the 20 pods with changed counters, got %d", len(request.Delta.Pods))
+	}
+
+	// Added pod
+	node.addPod("added")
+	request = roundTrip(t, encoder, decoder, node.next())
+	if request.Delta == nil || len(request.Delta.RemovedPods) != 0 {
+		t.Fatalf("expected a delta without removed pods, got %v", request.Delta)
+	}
+
+	// Removed pods, in the middle and at the end
+	node.removePod("app-5")
+	node.removePod("added")
+	request = roundTrip(t, encoder, decoder, node.next())
+	if len(request.Delta.RemovedPods) != 2 {
+		t.Errorf("expected 2 removed pods, got %v", request.Delta.RemovedPods)
+	}
+
+	// Unchanged pods are not sent
+	unchanged := node.next()
+	roundTrip(t, encoder, decoder, unchanged)
+	request = roundTrip(t, encoder, decoder, proto.Clone(unchanged).(*pb.NodeMetrics))
+	if len(request.Delta.Pods) != 0 {
+		t.Errorf("expected no changed pod, got %d", len(request.Delta.Pods))
+	}
+
+	// Cleared fields
+	node.pods[0].Partial = true
+	roundTrip(t, encoder, decoder, node.next())
+	node.pods[0].Partial = false
+	node.pods[0].ResourceLimits = types.ResourceInfo{}
+	request = roundTrip(t, encoder, decoder, node.next())
+	if len(request.Delta.Pods) == 0 || len(request.Delta.Pods[0].Cleared) == 0 {
+		t.Errorf("expected cleared fields on the first pod, got %v", request.Delta.Pods)
+	}
+}
+
+func TestDeltaSnapshotEvery(t *testing.T) {
+	node := newTestNode(5)
+	encoder := NewDeltaEncoder(3)
+	decoder := &DeltaDecoder{}
+
+	var snapshots []uint64
+	for i := 0; i < 7; i++ {
+		if request := roundTrip(t, encoder, decoder, node.next()); request.Delta == nil {
+			snapshots = append(snapshots, request.Sequence)
+		}
+	}
+	if fmt.Sprint(snapshots) != "[1 4 7]" {
+		t.Errorf("expected snapshots 1, 4 and 7, got %v", snapshots)
+	}
+}
+
+func TestDeltaResync(t *testing.T) {
+	node := newTestNode(5)
+	encoder := NewDeltaEncoder(100)
+	decoder := &DeltaDecoder{}
+	roundTrip(t, encoder, decoder, node.next())
+
+	// A delta is lost
+	encoder.Encode(&pb.NodeStatsRequest{}, node.next())
+
+	request := &pb.NodeStatsRequest{}
+	encoder.Encode(request, node.next())
+	if _, err := decoder.Decode(request); err == nil {
+		t.Fatal("expected an error for a delta not following the previous payload")
+	}
+
+	// The decoder waits for a snapshot
+	request = &pb.NodeStatsRequest{}
+	encoder.Encode(request, node.next())
+	if _, err := decoder.Decode(request); err == nil {
+		t.Fatal("expected an error for a delta received before the snapshot")
+	}
+
+	// The server asks for a resync, the agent resets its encoder
+	encoder.Reset()
+	if request := roundTrip(t, encoder, decoder, node.next()); request.Delta != nil {
+		t.Fatal("expected a snapshot after the reset")
+	}
+	roundTrip(t, encoder, decoder, node.next())
+}
+
+func TestDeltaDuplicatePodKeys(t *testing.T) {
+	node := newTestNode(3)
+	encoder := NewDeltaEncoder(100)
+	decoder := &DeltaDecoder{}
+	roundTrip(t, encoder, decoder, node.next())
+
+	duplicate := *node.pods[0]
+	node.pods = append(node.pods, &duplicate)
+	if request := roundTrip(t, encoder, decoder, node.next()); request.Delta != nil {
+		t.Fatal("expected a snapshot for pods with duplicate keys")
+	}
+}
+
+// gzipSize returns the size of a message compressed like the gzip compressor of the stream
+func gzipSize(b *testing.B, data []byte) int {
+	var buffer bytes.Buffer
+	w := gzip.NewWriter(&buffer)
+	if _, err := w.Write(data); err != nil {
+		b.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+	return buffer.Len()
+}
+
+// BenchmarkPayloadSize measures the bytes per tick for 200 pods whose CPU counters change every
+// tick over 60 ticks, the figures of the delta payloads documentation
+func BenchmarkPayloadSize(b *testing.B) {
+	const pods, ticks = 200, 60
+
+	for _, mode := range []struct {
+		name  string
+		delta bool
+		gzip  bool
+	}{
+		{"full", false, false},
+		{"full_gzip", false, true},
+		{"delta", true, false},
+		{"delta_gzip", true, true},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			total := 0
+			for i := 0; i < b.N; i++ {
+				node := newTestNode(pods)
+				encoder := NewDeltaEncoder(ticks)
+				total = 0
+				for tick := 0; tick < ticks; tick++ {
+					request := &pb.NodeStatsRequest{NodeName: "node-1"}
+					if mode.delta {
+						encoder.Encode(request, node.next())
+					} else {
+						request.Metrics = node.next()
+					}
+					data, err := proto.Marshal(request)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if mode.gzip {
+						total += gzipSize(b, data)
+					} else {
+						total += len(data)
+					}
+				}
+			}
+			b.ReportMetric(float64(total)/ticks, "bytes/tick")
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// MinCollectInterval is the shortest collection and collector interval an agent accepts, shorter
+// intervals would keep the agents of the whole fleet reading /proc
+const MinCollectInterval = time.Second
+
+// AgentConfig contains the collection settings pushed by the server to an agent, they override
+// the agent config file and flags. The zero value reverts the agent to its own settings
+type AgentConfig struct {
+	Version              int64            `json:"version"`
+	IntervalMs           int64            `json:"interval_ms"` // Collection tick, 0 keeps the agent interval
+	EnabledCollectors    []string         `json:"enabled_collectors"`
+	DisabledCollectors   []string         `json:"disabled_collectors"`
+	CollectorIntervalsMs map[string]int64 `json:"collector_intervals_ms"`
+	Filters              AgentFilters     `json:"filters"` // Empty rules keep the agent filters
+}
+
+// AgentFilters selects the pods, network interfaces and block devices collected by an agent
+type AgentFilters struct {
+	Namespaces FilterRule `json:"namespaces"`
+	Pods       FilterRule `json:"pods"`
+	Interfaces FilterRule `json:"interfaces"`
+	Devices    FilterRule `json:"devices"`
+}
+
+// FilterRule contains include and exclude regexes matching the whole name
+type FilterRule struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// IsEmpty returns true if the rule has no regex
+func (r FilterRule) IsEmpty() bool {
+	return len(r.Include) == 0 && len(r.Exclude) == 0
+}
+
+// AgentConfigAck is the answer of an agent to a pushed config
+type AgentConfigAck struct {
+	Version    int64     `json:"version"`
+	Applied    bool      `json:"applied"`
+	Error      string    `json:"error,omitempty"` // Why the config was rejected
+	ReceivedAt time.Time `json:"received_at"`
+}
@@ -0,0 +1,14 @@
+package types
+
+// BufferStatus reports the payloads buffered by the agent while the server is unreachable
+type BufferStatus struct {
+	Payloads     int    `json:"payloads"`   // Payloads waiting to be replayed (memory and disk)
+	Bytes        int64  `json:"bytes"`      // Size of the payloads kept in memory
+	DiskBytes    int64  `json:"disk_bytes"` // Size of the write-ahead log
+	MaxPayloads  int    `json:"max_payloads"`
+	MaxBytes     int64  `json:"max_bytes"`
+	MaxDiskBytes int64  `json:"max_disk_bytes"` // 0 if payloads are only kept in memory
+	Dropped      uint64 `json:"dropped"`        // Payloads dropped because a limit was reached
+	Spilled      uint64 `json:"spilled"`        // Payloads moved from memory to the write-ahead log
+	Replayed     uint64 `json:"replayed"`       // Payloads replayed after reconnecting
+}
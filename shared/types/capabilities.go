@@ -0,0 +1,54 @@
+package types
+
+// ProtocolVersion is the version of the agent stream protocol, incremented when message types are added
+// Version 1 agents report their features in their hello, version 2 servers rebuild delta payloads,
+// version 3 agents answer pings, version 4 servers answer the pings of agents
+const ProtocolVersion = 4
+
+// ProtocolVersionDelta is the first protocol version of servers accepting delta payloads
+const ProtocolVersionDelta = 2
+
+// ProtocolVersionPing is the first protocol version of agents answering pings
+const ProtocolVersionPing = 3
+
+// ProtocolVersionServerPing is the first protocol version of servers answering pings
+const ProtocolVersionServerPing = 4
+
+// Features the server can request from an agent
+const (
+	FeatureFlamegraph   = "flamegraph"    // perf and stackcollapse-perf.pl are installed
+	FeatureKernelStacks = "kernel_stacks" // Flamegraphs include kernel frames
+	FeatureLogs         = "logs"          // The CRI log directory is readable
+	FeatureLogPatterns  = "log_patterns"  // Log pattern alert rules, requires logs
+	FeaturePodDetails   = "pod_details"   // Process details read on demand
+	FeatureAgentConfig  = "agent_config"  // Configuration pushed by the server
+	FeatureCollect      = "collect"       // Collection requested by the server
+)
+
+// AgentFeature reports whether an agent can serve a feature
+type AgentFeature struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // Why the feature is unavailable
+}
+
+// AgentCapabilities are the protocol version and features reported by an agent in its hello
+type AgentCapabilities struct {
+	AgentVersion    string         `json:"agent_version"`
+	ProtocolVersion uint32         `json:"protocol_version"`
+	Features        []AgentFeature `json:"features"`
+}
+
+// Supports returns whether the agent can serve a feature and why not. Agents of protocol 0 do not
+// report features and are assumed to serve them all
+func (c AgentCapabilities) Supports(name string) (bool, string) {
+	if c.ProtocolVersion == 0 {
+		return true, ""
+	}
+	for _, feature := range c.Features {
+		if feature.Name == name {
+			return feature.Available, feature.Reason
+		}
+	}
+	return false, "not supported by agent version " + c.AgentVersion
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// CollectorStatus reports the configuration and the last run of an agent collector
+type CollectorStatus struct {
+	Name         string    `json:"name"`
+	Enabled      bool      `json:"enabled"`
+	IntervalMs   int64     `json:"interval_ms"`
+	Capabilities []string  `json:"capabilities"`
+	LastRun      time.Time `json:"last_run"`
+	DurationMs   float64   `json:"duration_ms"`     // Duration of the last run
+	Error        string    `json:"error,omitempty"` // Error of the last run (the previous result is kept)
+}
@@ -0,0 +1,15 @@
+package types
+
+import "time"
+
+// ConnectionStatus reports the connection of the agent to the server
+type ConnectionStatus struct {
+	Endpoint       string    `json:"endpoint"`  // Server endpoint in use
+	Connected      bool      `json:"connected"` // False for stats buffered while disconnected
+	Reconnects     uint64    `json:"reconnects"`
+	LastError      string    `json:"last_error"`
+	LastErrorAt    time.Time `json:"last_error_at"`
+	ConnectedSince time.Time `json:"connected_since"`
+	LastOutageMs   int64     `json:"last_outage_ms"`  // Duration of the last disconnection
+	DisconnectedMs int64     `json:"disconnected_ms"` // Time disconnected since the agent started
+}
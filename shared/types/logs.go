@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// LogLine is a container log line read from the CRI log files
+type LogLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Container string    `json:"container"`
+	Stream    string    `json:"stream"` // stdout or stderr
+	Message   string    `json:"message"`
+}
+
+// LogPatternRule is a log pattern alert rule pushed by the server to the agent
+type LogPatternRule struct {
+	RuleID  string `json:"rule_id"`
+	PodName string `json:"pod_name"`
+	Pattern string `json:"pattern"` // Go regular expression (RE2 syntax)
+}
+
+// LogPatternCount contains the regex match counts of a log pattern rule
+type LogPatternCount struct {
+	RuleID            string `json:"rule_id"`
+	PodName           string `json:"pod_name"`
+	MatchesLastMinute uint64 `json:"matches_last_minute"` // Matching lines over the last 60 seconds
+	TotalMatches      uint64 `json:"total_matches"`       // Matching lines since the rule was received
+	Error             string `json:"error,omitempty"`
+}
@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// NodeState is the state of a node derived by the server from its payloads and agent stream
+type NodeState string
+
+const (
+	NodeOnline  NodeState = "online"  // Stream established and recent payload
+	NodeStale   NodeState = "stale"   // No recent payload, or stream lost and the agent may reconnect
+	NodeOffline NodeState = "offline" // Stream lost without goodbye and no payload since
+	NodeStopped NodeState = "stopped" // Agent said goodbye before closing its stream
+)
+
+// NodeStatus reports the state of a node and the events it is derived from
+type NodeStatus struct {
+	NodeName       string    `json:"node_name"`
+	State          NodeState `json:"state"`
+	Reason         string    `json:"reason,omitempty"`
+	Connected      bool      `json:"connected"`       // An agent stream of the node is open
+	LastSeen       time.Time `json:"last_seen"`       // Last payload received, zero if none
+	ConnectedAt    time.Time `json:"connected_at"`    // Last agent hello
+	DisconnectedAt time.Time `json:"disconnected_at"` // Last stream closed
+	OfflineFor     float64   `json:"offline_seconds"` // Time offline, 0 in any other state
+
+	ClockSkewMs     float64         `json:"clock_skew_ms"`               // Agent clock minus server clock, positive if ahead
+	ClockSkewSource ClockSkewSource `json:"clock_skew_source,omitempty"` // Empty if the skew was never measured
+	ClockSkewed     bool            `json:"clock_skewed"`                // Skew above the warning threshold
+
+	RejectedPayloads  uint64    `json:"rejected_payloads"` // Payloads of the agent rejected by the validation
+	LastRejection     string    `json:"last_rejection,omitempty"`
+	LastRejectionAt   time.Time `json:"last_rejection_at"`
+	SanitizedPayloads uint64    `json:"sanitized_payloads"` // Payloads accepted once their values were clamped
+	LastSanitization  string    `json:"last_sanitization,omitempty"`
+}
+
+// ClockSkewSource is how the skew of the clock of an agent was measured
+type ClockSkewSource string
+
+const (
+	ClockSkewPing    ClockSkewSource = "ping"    // Agent time in a pong, against the middle of the round trip
+	ClockSkewPayload ClockSkewSource = "payload" // Timestamp of a live payload against its arrival, short by the transit time
+)
@@ -10,11 +10,31 @@ type NodeMetrics struct {
 	Network *NetworkStats `json:"network"`
 	Disk    *DiskStats    `json:"disk"`
 	Pods    []*Pod        `json:"pods"`
+
+	LogPatterns   []LogPatternCount `json:"log_patterns"`   // Match counts of log pattern alert rules
+	CustomMetrics []CustomMetrics   `json:"custom_metrics"` // Textfile and exec custom collectors
+	Pushed        []PushedMetrics   `json:"pushed"`         // StatsD and OTLP metrics received by the agent
+	Collectors    []CollectorStatus `json:"collectors"`     // Status of the agent collectors
+	Buffer        *BufferStatus     `json:"buffer"`         // Offline buffer of the agent, nil if unknown
+	Connection    *ConnectionStatus `json:"connection"`     // Connection of the agent to the server, nil if unknown
 }
 
 type NodeStatsPayload struct {
-	NodeName  string      `json:"node_name"`
-	Timestamp time.Time   `json:"timestamp"`
-	Metrics   NodeMetrics `json:"metrics"`
+	NodeName  string       `json:"node_name"`
+	Timestamp time.Time    `json:"timestamp"`
+	Metrics   NodeMetrics  `json:"metrics"`
+	Backfill  bool         `json:"backfill"`          // Buffered while the server was unreachable
+	Samples   []NodeSample `json:"samples,omitempty"` // High resolution samples taken since the previous payload
+
+	CollectionMs float64 `json:"collection_ms"` // Duration of the collection that produced the payload
+}
+
+// NodeSample holds the node level metrics of one high resolution sample
+type NodeSample struct {
+	Timestamp time.Time     `json:"timestamp"`
+	CPU       *CPUStats     `json:"cpu"`
+	Memory    *MemoryStats  `json:"memory"`
+	Network   *NetworkStats `json:"network"`
+	Disk      *DiskStats    `json:"disk"`
 }
 
@@ -46,3 +46,34 @@ type PidDetails struct {
 	VmLib   uint64   `json:"vm_lib"`   // Shared library size (KB)
 	VmSwap  uint64   `json:"vm_swap"`  // Swap usage (KB)
 }
+
+// Summary returns the summary tier of the details, the fields read from /proc/{PID}/stat
+// The other fields are only read when the process details are requested
+func (d PidDetails) Summary() PidDetails {
+	return PidDetails{
+		Name:             d.Name,
+		State:            d.State,
+		Priority:         d.Priority,
+		Nice:             d.Nice,
+		Threads:          d.Threads,
+		StartTime:        d.StartTime,
+		RealtimePriority: d.RealtimePriority,
+		CUTime:           d.CUTime,
+		CSTime:           d.CSTime,
+		TaskCPU:          d.TaskCPU,
+	}
+}
+
+// SetSummary replaces the summary tier fields with the ones of summary
+func (d *PidDetails) SetSummary(summary PidDetails) {
+	d.Name = summary.Name
+	d.State = summary.State
+	d.Priority = summary.Priority
+	d.Nice = summary.Nice
+	d.Threads = summary.Threads
+	d.StartTime = summary.StartTime
+	d.RealtimePriority = summary.RealtimePriority
+	d.CUTime = summary.CUTime
+	d.CSTime = summary.CSTime
+	d.TaskCPU = summary.TaskCPU
+}
@@ -1,13 +1,20 @@
 package types
 
 type Pod struct {
-	Name            string          `json:"name"`
-	ContainerID     string          `json:"container_id"`
-	PID             int             `json:"pid"`
-	PodMetrics      PodMetrics      `json:"pod_metrics"`
-	PidDetails      PidDetails      `json:"pid_details"`
-	ResourceLimits  ResourceInfo    `json:"resource_limits"`
-	ResourceRequests ResourceInfo   `json:"resource_requests"`
+	Name             string       `json:"name"`
+	Namespace        string       `json:"namespace"`
+	UID              string       `json:"uid"`
+	IP               string       `json:"ip"`
+	ContainerID      string       `json:"container_id"`
+	PID              int          `json:"pid"`
+	Partial          bool         `json:"partial"` // Metrics collection timed out, pod metrics are not set
+	PodMetrics       PodMetrics   `json:"pod_metrics"`
+	PidDetails       PidDetails   `json:"pid_details"`
+	ResourceLimits   ResourceInfo `json:"resource_limits"`
+	ResourceRequests ResourceInfo `json:"resource_requests"`
+
+	// Agent only (not sent to the server), used to discover Prometheus scrape targets
+	Annotations map[string]string `json:"-"`
 }
 
 // PodMetrics contains only the metrics needed for UI calculations
@@ -16,6 +23,8 @@ type PodMetrics struct {
 	Memory  PodMemoryStats  `json:"memory"`
 	Network PodNetworkStats `json:"network"`
 	Disk    PodDiskStats    `json:"disk"`
+	Storage PodStorageStats `json:"storage"`
+	Scrape  *ScrapeResult   `json:"scrape,omitempty"` // Prometheus endpoint of the pod (nil if not annotated)
 }
 
 // PodCPUStats contains only CPU metrics used by CalculateUIPod
@@ -46,6 +55,7 @@ type PodDiskStats struct {
 
 // ResourceInfo contains resource limits and requests for a pod
 type ResourceInfo struct {
-	CPU    string `json:"cpu"`    // CPU in millicores (e.g., "100m", "2")
-	Memory string `json:"memory"` // Memory in bytes (e.g., "128Mi", "1Gi")
+	CPU              string `json:"cpu"`               // CPU in millicores (e.g., "100m", "2")
+	Memory           string `json:"memory"`            // Memory in bytes (e.g., "128Mi", "1Gi")
+	EphemeralStorage string `json:"ephemeral_storage"` // Ephemeral storage in bytes (e.g., "1Gi")
 }
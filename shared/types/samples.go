@@ -0,0 +1,33 @@
+package types
+
+// MetricSample is a single series value from a Prometheus endpoint or a custom collector
+type MetricSample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+	Type   string            `json:"type,omitempty"` // counter, gauge, histogram, summary or untyped
+}
+
+// ScrapeResult contains the series scraped from the Prometheus endpoint of a pod
+type ScrapeResult struct {
+	URL        string         `json:"url"`
+	Samples    []MetricSample `json:"samples"`
+	DurationMs float64        `json:"duration_ms"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// CustomMetrics contains the samples produced by one custom collector source (a .prom file or a command)
+type CustomMetrics struct {
+	Source     string         `json:"source"` // "textfile:<file>" or "exec:<name>"
+	Samples    []MetricSample `json:"samples"`
+	DurationMs float64        `json:"duration_ms"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// PushedMetrics contains the series pushed over StatsD or OTLP from one source IP during the last flush interval
+type PushedMetrics struct {
+	PodName   string         `json:"pod_name"` // Empty if the source IP does not belong to a pod of the node
+	Namespace string         `json:"namespace"`
+	SourceIP  string         `json:"source_ip"`
+	Samples   []MetricSample `json:"samples"`
+}
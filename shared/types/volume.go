@@ -0,0 +1,32 @@
+package types
+
+// VolumeStats contains usage for a single pod volume
+type VolumeStats struct {
+	Name          string  `json:"name"`           // Volume name from the pod spec
+	Type          string  `json:"type"`           // Volume plugin (empty-dir, csi, configmap, ...)
+	UsedBytes     uint64  `json:"used_bytes"`     // Bytes used on disk
+	UsedInodes    uint64  `json:"used_inodes"`    // Inodes used
+	CapacityBytes uint64  `json:"capacity_bytes"` // Size limit or filesystem capacity (0 if unknown)
+	UsagePercent  float64 `json:"usage_percent"`  // Calculated by agent (0 if capacity unknown)
+}
+
+// PodStorageStats contains volume and ephemeral storage usage for a pod
+type PodStorageStats struct {
+	Volumes             []VolumeStats `json:"volumes"`
+	WritableLayerBytes  uint64        `json:"writable_layer_bytes"`  // Container writable layer (overlay upperdir)
+	WritableLayerInodes uint64        `json:"writable_layer_inodes"` // Inodes used by the writable layer
+	EphemeralUsedBytes  uint64        `json:"ephemeral_used_bytes"`  // emptyDir volumes + writable layers of the pod
+	EphemeralLimitBytes uint64        `json:"ephemeral_limit_bytes"` // Sum of ephemeral-storage limits (0 if unlimited)
+	EphemeralPercent    float64       `json:"ephemeral_percent"`     // Calculated by agent (0 if unlimited)
+}
+
+// MaxUsagePercent returns the highest usage percentage among volumes and ephemeral storage
+func (s *PodStorageStats) MaxUsagePercent() float64 {
+	maxPercent := s.EphemeralPercent
+	for _, volume := range s.Volumes {
+		if volume.UsagePercent > maxPercent {
+			maxPercent = volume.UsagePercent
+		}
+	}
+	return maxPercent
+}